@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/askdba/mysql-mcp-server/internal/secrets"
 )
 
 func TestParseArgs(t *testing.T) {
@@ -11,6 +16,7 @@ func TestParseArgs(t *testing.T) {
 		wantAction     string
 		wantConfigPath string
 		wantValidPath  string
+		wantSecrets    string
 		wantErr        bool
 		errContains    string
 	}{
@@ -90,6 +96,20 @@ func TestParseArgs(t *testing.T) {
 			errContains: "--validate-config requires a path argument",
 		},
 
+		// Encrypt secrets flag
+		{
+			name:        "encrypt-secrets with path",
+			args:        []string{"--encrypt-secrets", "/etc/secrets.enc"},
+			wantAction:  "encrypt-secrets",
+			wantSecrets: "/etc/secrets.enc",
+		},
+		{
+			name:        "encrypt-secrets missing path",
+			args:        []string{"--encrypt-secrets"},
+			wantErr:     true,
+			errContains: "--encrypt-secrets requires a path argument",
+		},
+
 		// Combined flags - the key fix for this PR
 		{
 			name:           "config then print-config",
@@ -185,6 +205,11 @@ func TestParseArgs(t *testing.T) {
 			if result.validatePath != tt.wantValidPath {
 				t.Errorf("parseArgs() validatePath = %q, want %q", result.validatePath, tt.wantValidPath)
 			}
+
+			// Check secrets path
+			if result.secretsPath != tt.wantSecrets {
+				t.Errorf("parseArgs() secretsPath = %q, want %q", result.secretsPath, tt.wantSecrets)
+			}
 		})
 	}
 }
@@ -203,3 +228,29 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestEncryptSecrets(t *testing.T) {
+	key := make([]byte, secrets.KeySize)
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+
+	input := "# production\nprod=p@ss=word\n\n  staging = s3cret\r\n"
+	n, err := encryptSecrets(path, key, strings.NewReader(input))
+	if err != nil || n != 2 {
+		t.Fatalf("encryptSecrets = %d, %v", n, err)
+	}
+	p, err := secrets.OpenFile(path, key)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	for name, want := range map[string]string{"prod": "p@ss=word", "staging": " s3cret"} {
+		if got, err := p.Secret(context.Background(), name); err != nil || got != want {
+			t.Errorf("Secret(%s) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	for _, bad := range []string{"", "novalue\n", "=x\n"} {
+		if _, err := encryptSecrets(path, key, strings.NewReader(bad)); err == nil {
+			t.Errorf("encryptSecrets(%q) should fail", bad)
+		}
+	}
+}
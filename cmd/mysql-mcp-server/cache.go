@@ -0,0 +1,298 @@
+// cmd/mysql-mcp-server/cache.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/cache"
+)
+
+// cachedTools are the metadata tools whose results are cached when the
+// result cache is enabled. run_query is cached only on connections that
+// opt in with cache_queries.
+var cachedTools = map[string]bool{
+	"list_databases":    true,
+	"list_tables":       true,
+	"describe_table":    true,
+	"list_indexes":      true,
+	"show_create_table": true,
+	"list_views":        true,
+	"list_triggers":     true,
+	"list_procedures":   true,
+	"list_functions":    true,
+	"list_partitions":   true,
+	"foreign_keys":      true,
+	"vector_info":       true,
+}
+
+// cachedResult is a tool output stored together with the schema
+// fingerprint it was computed under.
+type cachedResult struct {
+	value       interface{}
+	fingerprint string
+}
+
+// cacheRequest describes one cacheable tool call.
+type cacheRequest struct {
+	results     cache.Store
+	tool        string
+	key         string
+	conn        string
+	database    string
+	query       string
+	fingerprint string
+}
+
+// scope returns the tag shared by results of the same connection and database.
+func (r *cacheRequest) scope() string {
+	if r.database == "" {
+		return r.conn
+	}
+	return r.conn + "/" + r.database
+}
+
+// newCacheRequest returns the cache request for a tool call, or false when
+// the call must not be cached.
+func newCacheRequest(ctx context.Context, toolName string, input interface{}) (*cacheRequest, bool) {
+	conf := settingsFrom(ctx)
+	if conf.resultCache == nil || connManager == nil {
+		return nil, false
+	}
+	db, conn := connManager.GetActive()
+	if db == nil {
+		return nil, false
+	}
+	if !cachedTools[toolName] {
+		connCfg, _ := connManager.Config(conn)
+		if toolName != "run_query" || !connCfg.CacheQueries {
+			return nil, false
+		}
+	}
+
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, false
+	}
+	var fields struct {
+		Database string `json:"database"`
+		SQL      string `json:"sql"`
+	}
+	_ = json.Unmarshal(raw, &fields)
+
+	fingerprint, err := schemaFingerprint(ctx, db, toolName, fields.Database)
+	if err != nil {
+		logWarn("cache bypassed: schema fingerprint failed", map[string]interface{}{
+			"tool":     toolName,
+			"database": fields.Database,
+			"error":    err.Error(),
+		})
+		return nil, false
+	}
+
+	return &cacheRequest{
+		results:     conf.resultCache,
+		tool:        toolName,
+		key:         toolName + "\x00" + conn + "\x00" + string(raw),
+		conn:        conn,
+		database:    fields.Database,
+		query:       fields.SQL,
+		fingerprint: fingerprint,
+	}, true
+}
+
+// schemaFingerprint summarizes the table definitions of a database from
+// information_schema.TABLES, so that cached results are dropped once a
+// table is created, dropped, altered or written to. list_databases is
+// keyed on the schema list instead. Other calls without a database rely on
+// the TTL alone.
+//
+// MySQL 8.0 caches CREATE_TIME and UPDATE_TIME for
+// information_schema_stats_expiry seconds, so the query runs on a session
+// with the expiry set to 0. Servers without the variable (MySQL 5.7,
+// MariaDB) do not cache these columns. Reading uncached statistics makes
+// InnoDB look up every table of the database on each cacheable call.
+func schemaFingerprint(ctx context.Context, db *sql.DB, toolName, database string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	if database == "" {
+		if toolName != "list_databases" {
+			return "", nil
+		}
+		var count int64
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.SCHEMATA").Scan(&count); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("schemata:%d", count), nil
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SET SESSION information_schema_stats_expiry = 0"); err == nil {
+		defer conn.ExecContext(ctx, "SET SESSION information_schema_stats_expiry = DEFAULT")
+	}
+
+	var (
+		count               int64
+		created, lastUpdate sql.NullString
+	)
+	err = conn.QueryRowContext(ctx, `
+		SELECT COUNT(*), MAX(CREATE_TIME), MAX(UPDATE_TIME)
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ?`, database).Scan(&count, &created, &lastUpdate)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d|%s|%s", count, created.String, lastUpdate.String), nil
+}
+
+// lookup returns the cached output for the request. An entry computed under
+// a different schema fingerprint invalidates every result of its scope.
+func (r *cacheRequest) lookup() (interface{}, bool) {
+	v, ok := r.results.Get(r.key)
+	if ok {
+		entry := v.(cachedResult)
+		if entry.fingerprint == r.fingerprint {
+			cacheCounters.record(r.tool, true)
+			return entry.value, true
+		}
+		n := r.results.Invalidate(r.scope())
+		logInfo("cache invalidated: schema changed", map[string]interface{}{
+			"connection": r.conn,
+			"database":   r.database,
+			"removed":    n,
+		})
+	}
+	cacheCounters.record(r.tool, false)
+	return nil, false
+}
+
+// store caches a tool output; its JSON size counts towards the size limit.
+func (r *cacheRequest) store(out interface{}) {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return
+	}
+	tags := []string{r.conn}
+	if r.database != "" {
+		tags = append(tags, r.scope())
+	}
+	r.results.Set(r.key, cachedResult{value: out, fingerprint: r.fingerprint}, int64(len(r.key)+len(data)), tags...)
+}
+
+// toolCacheCounters tracks cache hits and misses per tool.
+type toolCacheCounters struct {
+	mu    sync.Mutex
+	tools map[string]*ToolCacheStats
+}
+
+var cacheCounters = &toolCacheCounters{tools: make(map[string]*ToolCacheStats)}
+
+func (c *toolCacheCounters) record(tool string, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.tools[tool]
+	if s == nil {
+		s = &ToolCacheStats{Tool: tool}
+		c.tools[tool] = s
+	}
+	if hit {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+}
+
+// snapshot returns the per-tool counters sorted by tool name.
+func (c *toolCacheCounters) snapshot() []ToolCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]ToolCacheStats, 0, len(c.tools))
+	for _, s := range c.tools {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tool < out[j].Tool })
+	return out
+}
+
+// resultCacheStats reports the counters of the result cache of conf. Hits
+// and misses are counted per tool call, so a result dropped for a schema
+// change counts as a miss.
+func resultCacheStats(conf *settings) CacheStats {
+	if conf.resultCache == nil {
+		return CacheStats{}
+	}
+	s := conf.resultCache.Stats()
+	stats := CacheStats{
+		Enabled:       true,
+		Entries:       s.Entries,
+		Bytes:         s.Bytes,
+		Evictions:     s.Evictions,
+		Expirations:   s.Expirations,
+		Invalidations: s.Invalidations,
+		Tools:         cacheCounters.snapshot(),
+	}
+	if c := conf.cfg; c != nil {
+		stats.MaxEntries = c.CacheMaxEntries
+		stats.MaxBytes = int64(c.CacheMaxSizeMB) << 20
+		stats.TTLSeconds = int(c.CacheTTL.Seconds())
+	}
+	for _, t := range stats.Tools {
+		stats.Hits += t.Hits
+		stats.Misses += t.Misses
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = roundTo(float64(stats.Hits)/float64(total), 4)
+	}
+	return stats
+}
+
+func toolRefreshCache(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input RefreshCacheInput,
+) (*mcp.CallToolResult, RefreshCacheOutput, error) {
+	conf := settingsFrom(ctx)
+	if conf.resultCache == nil {
+		return nil, RefreshCacheOutput{}, fmt.Errorf("result cache is disabled (set MYSQL_MCP_CACHE=1)")
+	}
+
+	conn := input.Connection
+	if conn != "" {
+		if _, err := connManager.Get(conn); err != nil {
+			return nil, RefreshCacheOutput{}, err
+		}
+	} else if input.Database != "" {
+		_, conn = connManager.GetActive()
+	}
+
+	out := RefreshCacheOutput{}
+	switch {
+	case input.Database != "":
+		out.Scope = conn + "/" + input.Database
+		out.Removed = conf.resultCache.Invalidate(out.Scope)
+	case conn != "":
+		out.Scope = conn
+		out.Removed = conf.resultCache.Invalidate(conn)
+	default:
+		out.Scope = "all"
+		out.Removed = conf.resultCache.Purge()
+	}
+	out.Stats = resultCacheStats(conf)
+
+	logInfo("cache refreshed", map[string]interface{}{
+		"scope":   out.Scope,
+		"removed": out.Removed,
+	})
+	return nil, out, nil
+}
@@ -0,0 +1,230 @@
+// cmd/mysql-mcp-server/cache_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/cache"
+)
+
+// setupResultCache enables an empty result cache for one test.
+func setupResultCache(t *testing.T) {
+	t.Helper()
+	oldCounters := cacheCounters
+	setSettings(t, func(s *settings) { s.resultCache = cache.NewLRU(100, 1<<20, time.Minute) })
+	cacheCounters = &toolCacheCounters{tools: make(map[string]*ToolCacheStats)}
+	t.Cleanup(func() { cacheCounters = oldCounters })
+}
+
+func expectFingerprint(mock sqlmock.Sqlmock, database string, tables int, updated string) {
+	mock.ExpectExec("SET SESSION information_schema_stats_expiry = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM information_schema.TABLES").
+		WithArgs(database).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "created", "updated"}).
+			AddRow(tables, "2026-01-01 00:00:00", updated))
+	mock.ExpectExec("SET SESSION information_schema_stats_expiry = DEFAULT").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestWrapToolCachesMetadata(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+	setupResultCache(t)
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	oldAudit := auditLogger
+	auditLogger, _ = NewAuditLogger(auditPath)
+	defer func() {
+		auditLogger.Close()
+		auditLogger = oldAudit
+	}()
+
+	listTables := wrapTool("list_tables", toolListTables)
+	call := func() ListTablesOutput {
+		t.Helper()
+		_, out, err := listTables(context.Background(), &mcp.CallToolRequest{}, ListTablesInput{Database: "shop"})
+		if err != nil {
+			t.Fatalf("list_tables failed: %v", err)
+		}
+		return out
+	}
+
+	// Miss: the tool runs and its result is stored.
+	expectFingerprint(mock, "shop", 1, "2026-01-02 00:00:00")
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW TABLES FROM `shop`")).
+		WillReturnRows(sqlmock.NewRows([]string{"Tables_in_shop"}).AddRow("orders"))
+	if out := call(); len(out.Tables) != 1 {
+		t.Fatalf("unexpected tables: %+v", out)
+	}
+
+	// Hit: only the fingerprint is checked.
+	expectFingerprint(mock, "shop", 1, "2026-01-02 00:00:00")
+	if out := call(); len(out.Tables) != 1 || out.Tables[0].Name != "orders" {
+		t.Fatalf("unexpected cached tables: %+v", out)
+	}
+
+	// A new table changes the fingerprint and invalidates the result.
+	expectFingerprint(mock, "shop", 2, "2026-01-03 00:00:00")
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW TABLES FROM `shop`")).
+		WillReturnRows(sqlmock.NewRows([]string{"Tables_in_shop"}).AddRow("orders").AddRow("refunds"))
+	if out := call(); len(out.Tables) != 2 {
+		t.Fatalf("expected fresh tables after a schema change, got %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+
+	stats := resultCacheStats(currentSettings())
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 1 || stats.Invalidations != 1 || stats.HitRatio != 0.3333 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(stats.Tools) != 1 || stats.Tools[0].Tool != "list_tables" {
+		t.Errorf("unexpected per-tool stats: %+v", stats.Tools)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one audit entry for the cache hit, got %d", len(lines))
+	}
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("bad audit entry: %v", err)
+	}
+	if !entry.Cached || !entry.Success || entry.Tool != "list_tables" || entry.Database != "shop" {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestWrapToolCacheQueriesOptIn(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+	setupResultCache(t)
+
+	runQuery := wrapTool("run_query", toolRunQuery)
+	expectQuery := func() {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM t")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+	input := RunQueryInput{SQL: "SELECT id FROM t"}
+
+	// Without cache_queries every call reaches the server.
+	expectQuery()
+	expectQuery()
+	for i := 0; i < 2; i++ {
+		if _, _, err := runQuery(context.Background(), &mcp.CallToolRequest{}, input); err != nil {
+			t.Fatalf("run_query failed: %v", err)
+		}
+	}
+
+	mockCfg := connManager.configs["mock"]
+	mockCfg.CacheQueries = true
+	connManager.configs["mock"] = mockCfg
+	expectQuery()
+	for i := 0; i < 2; i++ {
+		_, out, err := runQuery(context.Background(), &mcp.CallToolRequest{}, input)
+		if err != nil {
+			t.Fatalf("run_query failed: %v", err)
+		}
+		if len(out.Rows) != 1 {
+			t.Fatalf("unexpected rows: %+v", out.Rows)
+		}
+	}
+
+	// Invalid queries are never cached.
+	for i := 0; i < 2; i++ {
+		if _, _, err := runQuery(context.Background(), &mcp.CallToolRequest{}, RunQueryInput{SQL: "DELETE FROM t"}); err == nil {
+			t.Fatal("expected a validation error")
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+	if stats := resultCacheStats(currentSettings()); stats.Hits != 1 || stats.Entries != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSchemaFingerprintWithoutStatsExpiry(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	// MySQL 5.7 and MariaDB have no information_schema_stats_expiry.
+	mock.ExpectExec("SET SESSION information_schema_stats_expiry = 0").
+		WillReturnError(&mysql.MySQLError{Number: 1193, Message: "Unknown system variable 'information_schema_stats_expiry'"})
+	mock.ExpectQuery("FROM information_schema.TABLES").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "created", "updated"}).
+			AddRow(3, "2026-01-01 00:00:00", nil))
+
+	fp, err := schemaFingerprint(context.Background(), getDB(), "list_tables", "shop")
+	if err != nil {
+		t.Fatalf("schemaFingerprint failed: %v", err)
+	}
+	if fp != "3|2026-01-01 00:00:00|" {
+		t.Errorf("unexpected fingerprint %q", fp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolRefreshCache(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	if _, _, err := toolRefreshCache(context.Background(), &mcp.CallToolRequest{}, RefreshCacheInput{}); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("expected a disabled error, got %v", err)
+	}
+
+	setupResultCache(t)
+	store := func(key, conn, database string) {
+		r := &cacheRequest{results: currentSettings().resultCache, key: key, conn: conn, database: database}
+		r.store(ListTablesOutput{})
+	}
+	store("a", "mock", "shop")
+	store("b", "mock", "crm")
+	store("c", "mock", "")
+	store("d", "other", "shop")
+
+	tests := []struct {
+		input   RefreshCacheInput
+		scope   string
+		removed int
+	}{
+		{RefreshCacheInput{Database: "shop"}, "mock/shop", 1},
+		{RefreshCacheInput{Connection: "mock"}, "mock", 2},
+		{RefreshCacheInput{}, "all", 1},
+	}
+	for _, tt := range tests {
+		_, out, err := toolRefreshCache(context.Background(), &mcp.CallToolRequest{}, tt.input)
+		if err != nil {
+			t.Fatalf("refresh_cache(%+v) failed: %v", tt.input, err)
+		}
+		if out.Scope != tt.scope || out.Removed != tt.removed {
+			t.Errorf("refresh_cache(%+v) = %s/%d, want %s/%d", tt.input, out.Scope, out.Removed, tt.scope, tt.removed)
+		}
+	}
+	if stats := resultCacheStats(currentSettings()); !stats.Enabled || stats.Entries != 0 || stats.Invalidations != 4 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if _, _, err := toolRefreshCache(context.Background(), &mcp.CallToolRequest{}, RefreshCacheInput{Connection: "missing"}); err == nil {
+		t.Error("expected an error for an unknown connection")
+	}
+}
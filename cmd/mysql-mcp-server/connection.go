@@ -4,46 +4,309 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/askdba/mysql-mcp-server/internal/config"
 	"github.com/askdba/mysql-mcp-server/internal/util"
+	"github.com/go-sql-driver/mysql"
 )
 
 // ===== Multi-DSN Connection Manager =====
 
+// Connection states reported by list_connections.
+const (
+	connStateConnected = "connected"
+	connStateDegraded  = "degraded" // not reachable (yet); retried in the background
+	connStateFailed    = "failed"   // invalid or rejected by the server; not retried
+)
+
+// Backoff of the background retry for degraded connections.
+const (
+	retryInitialDelay = time.Second
+	retryMaxDelay     = time.Minute
+)
+
+// ConnectionStatus is the health of a connection as of its last check.
+type ConnectionStatus struct {
+	State       string
+	LastError   string
+	LastSuccess time.Time
+	Failures    int       // consecutive failed checks
+	NextRetry   time.Time // next background retry; zero if none is scheduled
+
+	Breaker        string    // circuit breaker state, see health.go
+	UnhealthySince time.Time // when the breaker opened; zero while closed
+
+	retrying bool
+	outcomes []bool // recent calls of a closed breaker, true if failed
+	timeouts int    // consecutive timeouts
+	openedAt time.Time
+	trial    bool // a half-open trial call is running
+}
+
 // ConnectionManager manages multiple MySQL connections.
+//
+// Connections are registered without waiting for their server, so the
+// server starts even if a database is not reachable yet (e.g. before a VPN
+// is up). A connection is degraded until a ping succeeds; degraded
+// connections are pinged in the background with exponential backoff, and
+// tools ping the active connection on use until it is connected. A circuit
+// breaker per connection makes calls fail fast while it is unhealthy.
 type ConnectionManager struct {
 	connections map[string]*sql.DB
 	configs     map[string]config.ConnectionConfig
+	status      map[string]*ConnectionStatus
 	activeConn  string
 	mu          sync.RWMutex
+
+	// open creates the pool for a connection without connecting (replaced in tests).
+	open func(connCfg config.ConnectionConfig, cfg *config.Config) (*sql.DB, error)
+
+	retryDelay      time.Duration
+	maxRetryDelay   time.Duration
+	pingTimeout     time.Duration
+	breakerCooldown time.Duration
+	done            chan struct{} // closed by Close to stop background retries and health checks
+	closeOnce       sync.Once
 }
 
 // NewConnectionManager creates a new connection manager.
 func NewConnectionManager() *ConnectionManager {
 	return &ConnectionManager{
-		connections: make(map[string]*sql.DB),
-		configs:     make(map[string]config.ConnectionConfig),
+		connections:     make(map[string]*sql.DB),
+		configs:         make(map[string]config.ConnectionConfig),
+		status:          make(map[string]*ConnectionStatus),
+		open:            openConnection,
+		retryDelay:      retryInitialDelay,
+		maxRetryDelay:   retryMaxDelay,
+		pingTimeout:     time.Duration(config.DefaultPingTimeoutSecs) * time.Second,
+		breakerCooldown: time.Duration(config.DefaultBreakerCooldownSecs) * time.Second,
+		done:            make(chan struct{}),
 	}
 }
 
-// AddConnectionWithPoolConfig adds a new connection with pool configuration.
+// AddConnectionWithPoolConfig registers a connection with pool configuration.
+// It does not wait for the server: the connection starts out degraded and is
+// checked in the background. If the pool cannot be created (an invalid DSN),
+// the connection is registered as failed and the error is returned.
 func (cm *ConnectionManager) AddConnectionWithPoolConfig(connCfg config.ConnectionConfig, cfg *config.Config) error {
+	conn, err := cm.open(connCfg, cfg)
+
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
+	cm.configs[connCfg.Name] = connCfg
+	if err != nil {
+		cm.status[connCfg.Name] = newConnectionStatus(connStateFailed, err.Error())
+		return err
+	}
+	cm.connections[connCfg.Name] = conn
+	cm.status[connCfg.Name] = newConnectionStatus(connStateDegraded, "")
+	cm.startRetryLocked(connCfg.Name, conn)
+
+	// Set as active if it's the first connection
+	if cm.activeConn == "" {
+		cm.activeConn = connCfg.Name
+	}
+
+	return nil
+}
+
+// openConnection opens a pool for connCfg with the pool settings of cfg.
+// sql.Open validates the DSN but does not connect.
+func openConnection(connCfg config.ConnectionConfig, cfg *config.Config) (*sql.DB, error) {
 	// Apply SSL/TLS settings to DSN if configured
 	dsn := config.ApplySSLToDSN(connCfg.DSN, connCfg.SSL)
 
 	conn, err := sql.Open("mysql", dsn)
 	if err != nil {
-		return fmt.Errorf("failed to open connection %s: %w", connCfg.Name, err)
+		return nil, fmt.Errorf("failed to open connection %s: %w", connCfg.Name, err)
+	}
+	setPoolLimits(conn, cfg)
+	return conn, nil
+}
+
+// checkTimeout returns the timeout of health check pings.
+func (cm *ConnectionManager) checkTimeout() time.Duration {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.pingTimeout
+}
+
+// rejected reports whether the server refused a connection for a reason
+// retrying will not fix: wrong credentials or an unknown database.
+func rejected(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	switch myErr.Number {
+	case 1044, 1045, 1049: // ER_DBACCESS_DENIED_ERROR, ER_ACCESS_DENIED_ERROR, ER_BAD_DB_ERROR
+		return true
+	}
+	return false
+}
+
+// statusLocked returns the status of a connection, creating a degraded one
+// for pools that were registered directly. cm.mu must be held for writing.
+func (cm *ConnectionManager) statusLocked(name string) *ConnectionStatus {
+	st, ok := cm.status[name]
+	if !ok {
+		st = newConnectionStatus(connStateDegraded, "")
+		cm.status[name] = st
+	}
+	return st
+}
+
+// ping checks the pool of a connection and records the result, also in its
+// circuit breaker. Results for a pool that has been replaced or removed in
+// the meantime are dropped.
+func (cm *ConnectionManager) ping(ctx context.Context, name string, db *sql.DB) error {
+	err := db.PingContext(ctx)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.connections[name] != db {
+		return err
+	}
+	st := cm.statusLocked(name)
+	st.record(name, err, time.Now(), cm.breakerCooldown)
+	if err == nil {
+		if st.State != connStateConnected {
+			logInfo("connection ready", map[string]interface{}{
+				"name":     name,
+				"failures": st.Failures,
+			})
+		}
+		st.State = connStateConnected
+		st.LastSuccess = time.Now()
+		st.Failures = 0
+		return nil
+	}
+
+	st.Failures++
+	st.LastError = err.Error()
+	if rejected(err) {
+		st.State = connStateFailed
+	} else {
+		st.State = connStateDegraded
+		cm.startRetryLocked(name, db)
+	}
+	return err
+}
+
+// startRetryLocked starts the background retry of a connection unless one
+// is running. cm.mu must be held for writing.
+func (cm *ConnectionManager) startRetryLocked(name string, db *sql.DB) {
+	st := cm.statusLocked(name)
+	if st.retrying {
+		return
+	}
+	st.retrying = true
+	go cm.retry(name, db)
+}
+
+// retry pings a degraded connection, doubling the delay between attempts,
+// until it is connected, rejected, replaced or the manager is closed.
+func (cm *ConnectionManager) retry(name string, db *sql.DB) {
+	delay := cm.retryDelay
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), cm.checkTimeout())
+		err := cm.ping(ctx, name, db)
+		cancel()
+
+		cm.mu.Lock()
+		if cm.connections[name] != db {
+			cm.mu.Unlock()
+			return
+		}
+		st := cm.statusLocked(name)
+		if st.State != connStateDegraded {
+			st.retrying = false
+			st.NextRetry = time.Time{}
+			cm.mu.Unlock()
+			return
+		}
+		st.NextRetry = time.Now().Add(delay)
+		failures := st.Failures
+		cm.mu.Unlock()
+
+		if err != nil {
+			logWarn("connection unavailable; retrying", map[string]interface{}{
+				"name":     name,
+				"error":    err.Error(),
+				"failures": failures,
+				"retry_in": delay.String(),
+			})
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-cm.done:
+			return
+		}
+		delay = min(delay*2, cm.maxRetryDelay)
+	}
+}
+
+// Ready returns an error if the named connection cannot be used. While its
+// circuit breaker is open, Ready fails fast. A connection that is not
+// connected is pinged first, so a server that came back is used right away
+// instead of at the next background retry.
+func (cm *ConnectionManager) Ready(ctx context.Context, name string) error {
+	cm.mu.Lock()
+	db, exists := cm.connections[name]
+	if !exists {
+		defer cm.mu.Unlock()
+		if st, ok := cm.status[name]; ok {
+			return fmt.Errorf("connection '%s' is %s: %s", name, st.State, st.LastError)
+		}
+		return fmt.Errorf("connection '%s' not found", name)
+	}
+	st := cm.statusLocked(name)
+	if !st.allow(time.Now(), cm.breakerCooldown) {
+		defer cm.mu.Unlock()
+		return st.unhealthyError(name)
+	}
+	state := st.State
+	cm.mu.Unlock()
+
+	if state == connStateConnected {
+		return nil
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, cm.checkTimeout())
+	defer cancel()
+	if err := cm.ping(pingCtx, name, db); err != nil {
+		st, _ := cm.Status(name)
+		if st.State == connStateDegraded {
+			return fmt.Errorf("connection '%s' is degraded (retrying in the background): %w", name, err)
+		}
+		return fmt.Errorf("connection '%s' is %s: %w", name, st.State, err)
+	}
+	return nil
+}
+
+// Status returns the health of the named connection.
+func (cm *ConnectionManager) Status(name string) (ConnectionStatus, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if st, ok := cm.status[name]; ok {
+		return *st, true
 	}
+	if _, ok := cm.configs[name]; ok {
+		return *newConnectionStatus(connStateDegraded, ""), true
+	}
+	return ConnectionStatus{}, false
+}
 
-	// Apply pool settings with sensible defaults (defensive against zero values)
+// setPoolLimits applies the pool settings of cfg, with sensible defaults
+// (defensive against zero values).
+func setPoolLimits(conn *sql.DB, cfg *config.Config) {
 	maxOpen := cfg.MaxOpenConns
 	if maxOpen <= 0 {
 		maxOpen = config.DefaultMaxOpenConns
@@ -60,33 +323,134 @@ func (cm *ConnectionManager) AddConnectionWithPoolConfig(connCfg config.Connecti
 	if idleTime <= 0 {
 		idleTime = time.Duration(config.DefaultConnMaxIdleTimeMins) * time.Minute
 	}
-	pingTimeout := cfg.PingTimeout
-	if pingTimeout <= 0 {
-		pingTimeout = time.Duration(config.DefaultPingTimeoutSecs) * time.Second
-	}
 
 	conn.SetMaxOpenConns(maxOpen)
 	conn.SetMaxIdleConns(maxIdle)
 	conn.SetConnMaxLifetime(lifetime)
 	conn.SetConnMaxIdleTime(idleTime)
+}
 
-	// Test connection with configurable timeout
-	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
-	defer cancel()
-	if err := conn.PingContext(ctx); err != nil {
-		conn.Close()
-		return fmt.Errorf("failed to ping connection %s: %w", connCfg.Name, err)
+// ConnectionChanges reports what Reconcile changed.
+type ConnectionChanges struct {
+	Added   []string          `json:"added,omitempty"`
+	Removed []string          `json:"removed,omitempty"`
+	Updated []string          `json:"updated,omitempty"` // reopened with a new DSN or SSL setting
+	Failed  map[string]string `json:"failed,omitempty"`  // connection -> error opening its pool; an old pool is kept
+	Active  string            `json:"active"`
+}
+
+// Reconcile makes the managed connections match conns: new connections are
+// registered, removed ones dropped, and connections whose DSN or SSL setting
+// changed are reopened. Like AddConnectionWithPoolConfig, new pools start out
+// degraded and are checked in the background. Pool limits of cfg are applied
+// to every pool.
+//
+// Replaced and removed pools are closed after drain, so tool calls that
+// already hold them can finish; sql.DB.Close also waits for running
+// queries. If the active connection is removed, the first connection in
+// conns becomes active.
+func (cm *ConnectionManager) Reconcile(conns []config.ConnectionConfig, cfg *config.Config, drain time.Duration) (ConnectionChanges, error) {
+	changes := ConnectionChanges{Failed: make(map[string]string)}
+
+	cm.mu.RLock()
+	current := make(map[string]config.ConnectionConfig, len(cm.configs))
+	for name, c := range cm.configs {
+		current[name] = c
 	}
+	cm.mu.RUnlock()
 
-	cm.connections[connCfg.Name] = conn
-	cm.configs[connCfg.Name] = connCfg
+	// Open new pools without holding the lock, so tools keep running.
+	opened := make(map[string]*sql.DB)
+	wanted := make(map[string]bool, len(conns))
+	for _, c := range conns {
+		wanted[c.Name] = true
+		old, exists := current[c.Name]
+		if exists && old.DSN == c.DSN && old.SSL == c.SSL {
+			continue
+		}
+		db, err := cm.open(c, cfg)
+		if err != nil {
+			changes.Failed[c.Name] = err.Error()
+			continue
+		}
+		opened[c.Name] = db
+		if exists {
+			changes.Updated = append(changes.Updated, c.Name)
+		} else {
+			changes.Added = append(changes.Added, c.Name)
+		}
+	}
 
-	// Set as active if it's the first connection
-	if cm.activeConn == "" {
-		cm.activeConn = connCfg.Name
+	cm.mu.Lock()
+	remaining := 0
+	for name := range cm.connections {
+		if wanted[name] {
+			remaining++
+		}
+	}
+	for name := range opened {
+		if _, exists := cm.connections[name]; !exists {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		cm.mu.Unlock()
+		for _, db := range opened {
+			db.Close()
+		}
+		return changes, fmt.Errorf("no valid MySQL connections in the new configuration; keeping the current ones")
 	}
 
-	return nil
+	var retired []*sql.DB
+	for name := range cm.configs {
+		if !wanted[name] {
+			if db, ok := cm.connections[name]; ok {
+				retired = append(retired, db)
+			}
+			delete(cm.connections, name)
+			delete(cm.configs, name)
+			delete(cm.status, name)
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	for _, c := range conns {
+		if db, ok := opened[c.Name]; ok {
+			if old, exists := cm.connections[c.Name]; exists {
+				retired = append(retired, old)
+			}
+			cm.connections[c.Name] = db
+			cm.status[c.Name] = newConnectionStatus(connStateDegraded, "")
+			cm.startRetryLocked(c.Name, db)
+		}
+		if db, ok := cm.connections[c.Name]; ok {
+			if _, failed := changes.Failed[c.Name]; !failed {
+				cm.configs[c.Name] = c
+			}
+			setPoolLimits(db, cfg)
+		} else if msg, failed := changes.Failed[c.Name]; failed {
+			// Without a pool to keep, the connection is listed as failed.
+			cm.configs[c.Name] = c
+			cm.status[c.Name] = newConnectionStatus(connStateFailed, msg)
+		}
+	}
+	if _, ok := cm.connections[cm.activeConn]; !ok {
+		for _, c := range conns {
+			if _, ok := cm.connections[c.Name]; ok {
+				cm.activeConn = c.Name
+				break
+			}
+		}
+	}
+	changes.Active = cm.activeConn
+	cm.mu.Unlock()
+
+	for _, db := range retired {
+		time.AfterFunc(drain, func() { db.Close() })
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Updated)
+	return changes, nil
 }
 
 // GetActive returns the active database connection and its name.
@@ -102,6 +466,9 @@ func (cm *ConnectionManager) SetActive(name string) error {
 	defer cm.mu.Unlock()
 
 	if _, exists := cm.connections[name]; !exists {
+		if st, ok := cm.status[name]; ok {
+			return fmt.Errorf("connection '%s' is %s: %s", name, st.State, st.LastError)
+		}
 		return fmt.Errorf("connection '%s' not found", name)
 	}
 	cm.activeConn = name
@@ -123,6 +490,39 @@ func (cm *ConnectionManager) List() []config.ConnectionConfig {
 	return list
 }
 
+// Names returns the names of all connections in sorted order.
+func (cm *ConnectionManager) Names() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	names := make([]string, 0, len(cm.connections))
+	for name := range cm.connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the database connection with the given name.
+func (cm *ConnectionManager) Get(name string) (*sql.DB, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	conn, exists := cm.connections[name]
+	if !exists {
+		return nil, fmt.Errorf("connection '%s' not found", name)
+	}
+	return conn, nil
+}
+
+// Config returns the configuration of the named connection.
+func (cm *ConnectionManager) Config(name string) (config.ConnectionConfig, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	c, ok := cm.configs[name]
+	return c, ok
+}
+
 // GetActiveDB returns the active database connection.
 func (cm *ConnectionManager) GetActiveDB() *sql.DB {
 	cm.mu.RLock()
@@ -130,8 +530,11 @@ func (cm *ConnectionManager) GetActiveDB() *sql.DB {
 	return cm.connections[cm.activeConn]
 }
 
-// Close closes all connections managed by the manager.
+// Close stops background retries and closes all connections managed by the
+// manager.
 func (cm *ConnectionManager) Close() {
+	cm.closeOnce.Do(func() { close(cm.done) })
+
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 	for _, conn := range cm.connections {
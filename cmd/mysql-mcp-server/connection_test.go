@@ -2,11 +2,17 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/askdba/mysql-mcp-server/internal/config"
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestNewConnectionManager(t *testing.T) {
@@ -179,6 +185,17 @@ func TestConnectionManagerMultipleConnections(t *testing.T) {
 		t.Errorf("expected 2 connections, got %d", len(list))
 	}
 
+	names := cm.Names()
+	if len(names) != 2 || names[0] != "conn1" || names[1] != "conn2" {
+		t.Errorf("expected sorted names [conn1 conn2], got %v", names)
+	}
+	if got, err := cm.Get("conn1"); err != nil || got != mockDB1 {
+		t.Errorf("Get(conn1) = %v, %v; want mockDB1", got, err)
+	}
+	if _, err := cm.Get("conn3"); err == nil {
+		t.Error("Get should error for nonexistent connection")
+	}
+
 	cm.Close()
 	if err := mock1.ExpectationsWereMet(); err != nil {
 		t.Errorf("mock1 unfulfilled expectations: %v", err)
@@ -293,3 +310,262 @@ func TestConnectionManagerConcurrency(t *testing.T) {
 		<-done
 	}
 }
+
+// stubOpener returns a connection opener backed by sqlmock that fails for
+// DSNs containing "bad", and records the pools it opened by DSN.
+func stubOpener(t *testing.T, opened map[string]sqlmock.Sqlmock) func(config.ConnectionConfig, *config.Config) (*sql.DB, error) {
+	return func(c config.ConnectionConfig, _ *config.Config) (*sql.DB, error) {
+		if strings.Contains(c.DSN, "bad") {
+			return nil, fmt.Errorf("failed to ping connection %s: refused", c.Name)
+		}
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock: %v", err)
+		}
+		opened[c.DSN] = mock
+		return db, nil
+	}
+}
+
+func TestConnectionManagerReconcile(t *testing.T) {
+	opened := make(map[string]sqlmock.Sqlmock)
+	cm := NewConnectionManager()
+	cm.open = stubOpener(t, opened)
+	cfg := &config.Config{MaxOpenConns: 4}
+
+	for _, c := range []config.ConnectionConfig{
+		{Name: "a", DSN: "a-dsn"},
+		{Name: "b", DSN: "b-dsn"},
+		{Name: "keep", DSN: "keep-dsn"},
+	} {
+		if err := cm.AddConnectionWithPoolConfig(c, cfg); err != nil {
+			t.Fatalf("AddConnectionWithPoolConfig(%s) failed: %v", c.Name, err)
+		}
+	}
+	oldA, _ := cm.Get("a")
+	oldB, _ := cm.Get("b")
+
+	changes, err := cm.Reconcile([]config.ConnectionConfig{
+		{Name: "b", DSN: "b-dsn-2", Description: "moved"},
+		{Name: "c", DSN: "c-dsn"},
+		{Name: "d", DSN: "bad-dsn"},
+		{Name: "keep", DSN: "bad-keep-dsn"},
+	}, cfg, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if strings.Join(changes.Added, ",") != "c" || strings.Join(changes.Removed, ",") != "a" || strings.Join(changes.Updated, ",") != "b" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+	if len(changes.Failed) != 2 || changes.Failed["d"] == "" || changes.Failed["keep"] == "" {
+		t.Errorf("expected d and keep to fail: %v", changes.Failed)
+	}
+	// The active connection was removed; the first new connection takes over.
+	if changes.Active != "b" {
+		t.Errorf("expected b to become active, got %s", changes.Active)
+	}
+	if names := strings.Join(cm.Names(), ","); names != "b,c,keep" {
+		t.Errorf("unexpected connections: %s", names)
+	}
+	if st, _ := cm.Status("d"); st.State != connStateFailed {
+		t.Errorf("expected d to be listed as failed, got %+v", st)
+	}
+	if c, _ := cm.Config("keep"); c.DSN != "keep-dsn" {
+		t.Errorf("a failed reopen must keep the old config, got %s", c.DSN)
+	}
+	if c, _ := cm.Config("b"); c.DSN != "b-dsn-2" || c.Description != "moved" {
+		t.Errorf("unexpected config for b: %+v", c)
+	}
+	if newB, _ := cm.Get("b"); newB == oldB {
+		t.Error("expected b to get a new pool")
+	}
+
+	// Retired pools keep serving until the drain delay passes.
+	opened["a-dsn"].ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	if _, err := oldA.Query("SELECT 1"); err != nil {
+		t.Errorf("retired pool closed too early: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	for name, db := range map[string]*sql.DB{"a": oldA, "b": oldB} {
+		if err := db.Ping(); err == nil || !strings.Contains(err.Error(), "closed") {
+			t.Errorf("expected the retired pool %s to be closed, got %v", name, err)
+		}
+	}
+}
+
+func TestConnectionManagerReconcileKeepsLastConnection(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.open = stubOpener(t, make(map[string]sqlmock.Sqlmock))
+	cfg := &config.Config{}
+	if err := cm.AddConnectionWithPoolConfig(config.ConnectionConfig{Name: "a", DSN: "a-dsn"}, cfg); err != nil {
+		t.Fatalf("AddConnectionWithPoolConfig failed: %v", err)
+	}
+
+	_, err := cm.Reconcile([]config.ConnectionConfig{{Name: "b", DSN: "bad-dsn"}}, cfg, 0)
+	if err == nil || !strings.Contains(err.Error(), "no valid MySQL connections") {
+		t.Fatalf("expected an error, got %v", err)
+	}
+	if _, name := cm.GetActive(); name != "a" {
+		t.Errorf("expected a to stay active, got %q", name)
+	}
+}
+
+// waitForStatus polls the status of a connection until cond holds.
+func waitForStatus(t *testing.T, cm *ConnectionManager, name string, cond func(ConnectionStatus) bool) ConnectionStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st, _ := cm.Status(name)
+		if cond(st) {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for connection %s, status %+v", name, st)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// mockOpener opens the given pool for every connection.
+func mockOpener(db *sql.DB) func(config.ConnectionConfig, *config.Config) (*sql.DB, error) {
+	return func(config.ConnectionConfig, *config.Config) (*sql.DB, error) {
+		return db, nil
+	}
+}
+
+func TestConnectionManagerRetriesDegradedConnection(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	refused := errors.New("dial tcp 10.0.0.5:3306: connect: connection refused")
+	mock.ExpectPing().WillReturnError(refused)
+	mock.ExpectPing().WillReturnError(refused)
+	mock.ExpectPing()
+
+	cm := NewConnectionManager()
+	defer cm.Close()
+	cm.open = mockOpener(db)
+	cm.retryDelay = 10 * time.Millisecond
+	cm.maxRetryDelay = 20 * time.Millisecond
+
+	if err := cm.AddConnectionWithPoolConfig(config.ConnectionConfig{Name: "vpn", DSN: "u@tcp(10.0.0.5:3306)/"}, &config.Config{}); err != nil {
+		t.Fatalf("AddConnectionWithPoolConfig failed: %v", err)
+	}
+	if _, name := cm.GetActive(); name != "vpn" {
+		t.Errorf("a degraded connection should become active, got %q", name)
+	}
+
+	st := waitForStatus(t, cm, "vpn", func(st ConnectionStatus) bool { return st.Failures == 2 })
+	if st.State != connStateDegraded || st.LastError != refused.Error() || st.NextRetry.IsZero() {
+		t.Errorf("unexpected status while degraded: %+v", st)
+	}
+
+	st = waitForStatus(t, cm, "vpn", func(st ConnectionStatus) bool { return st.State == connStateConnected })
+	if st.Failures != 0 || st.LastSuccess.IsZero() || !st.NextRetry.IsZero() {
+		t.Errorf("unexpected status once connected: %+v", st)
+	}
+	if err := cm.Ready(context.Background(), "vpn"); err != nil {
+		t.Errorf("Ready failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected pings: %v", err)
+	}
+}
+
+func TestConnectionManagerRejectedConnection(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	mock.ExpectPing().WillReturnError(&mysql.MySQLError{Number: 1045, Message: "Access denied for user 'app'"})
+
+	cm := NewConnectionManager()
+	defer cm.Close()
+	cm.open = mockOpener(db)
+	cm.retryDelay = 10 * time.Millisecond
+
+	if err := cm.AddConnectionWithPoolConfig(config.ConnectionConfig{Name: "prod", DSN: "app@tcp(db:3306)/"}, &config.Config{}); err != nil {
+		t.Fatalf("AddConnectionWithPoolConfig failed: %v", err)
+	}
+
+	// Rejected connections are not retried in the background...
+	st := waitForStatus(t, cm, "prod", func(st ConnectionStatus) bool { return st.State == connStateFailed })
+	if !strings.Contains(st.LastError, "Access denied") {
+		t.Errorf("unexpected last error: %q", st.LastError)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected pings: %v", err)
+	}
+
+	// ...but are pinged again on use, e.g. after the grant was fixed.
+	mock.ExpectPing()
+	if err := cm.Ready(context.Background(), "prod"); err != nil {
+		t.Errorf("Ready failed: %v", err)
+	}
+	if st, _ := cm.Status("prod"); st.State != connStateConnected {
+		t.Errorf("expected connected, got %+v", st)
+	}
+}
+
+func TestConnectionManagerReadyPingsLazily(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectPing().WillReturnError(errors.New("i/o timeout"))
+	mock.ExpectPing()
+
+	cm := NewConnectionManager()
+	defer cm.Close()
+	cm.retryDelay = time.Hour
+	// A pool registered without a status has not been checked yet.
+	cm.connections["lazy"] = db
+	cm.configs["lazy"] = config.ConnectionConfig{Name: "lazy"}
+
+	err = cm.Ready(context.Background(), "lazy")
+	if err == nil || !strings.Contains(err.Error(), "connection 'lazy' is degraded") || !strings.Contains(err.Error(), "i/o timeout") {
+		t.Fatalf("expected a degraded error, got %v", err)
+	}
+	// The background retry pings right away; the next ping succeeds.
+	waitForStatus(t, cm, "lazy", func(st ConnectionStatus) bool { return st.State == connStateConnected })
+	if err := cm.Ready(context.Background(), "lazy"); err != nil {
+		t.Errorf("Ready failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("a connected connection should not be pinged again: %v", err)
+	}
+}
+
+func TestConnectionManagerInvalidDSN(t *testing.T) {
+	cm := NewConnectionManager()
+	defer cm.Close()
+	cm.open = stubOpener(t, make(map[string]sqlmock.Sqlmock))
+	cfg := &config.Config{}
+
+	if err := cm.AddConnectionWithPoolConfig(config.ConnectionConfig{Name: "broken", DSN: "bad-dsn"}, cfg); err == nil {
+		t.Fatal("expected an error for an invalid DSN")
+	}
+	if err := cm.AddConnectionWithPoolConfig(config.ConnectionConfig{Name: "ok", DSN: "ok-dsn"}, cfg); err != nil {
+		t.Fatalf("AddConnectionWithPoolConfig failed: %v", err)
+	}
+
+	if _, name := cm.GetActive(); name != "ok" {
+		t.Errorf("expected ok to become active, got %q", name)
+	}
+	if names := strings.Join(cm.Names(), ","); names != "ok" {
+		t.Errorf("failed connections have no pool, got %s", names)
+	}
+	if len(cm.List()) != 2 {
+		t.Errorf("failed connections should be listed: %+v", cm.List())
+	}
+	if st, _ := cm.Status("broken"); st.State != connStateFailed || st.LastError == "" {
+		t.Errorf("unexpected status: %+v", st)
+	}
+	if err := cm.SetActive("broken"); err == nil || !strings.Contains(err.Error(), "connection 'broken' is failed") {
+		t.Errorf("expected SetActive to fail, got %v", err)
+	}
+}
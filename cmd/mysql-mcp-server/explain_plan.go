@@ -0,0 +1,512 @@
+// cmd/mysql-mcp-server/explain_plan.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Plan warnings attached to ExplainNode.Warnings.
+const (
+	planWarnFullScan      = "full table scan"
+	planWarnFullIndexScan = "full index scan"
+	planWarnTemporary     = "temporary table"
+	planWarnFilesort      = "filesort"
+)
+
+// misestimateFactor is how far estimated and actual rows may drift apart
+// before EXPLAIN ANALYZE output is flagged.
+const misestimateFactor = 10
+
+const planNumber = `(\d+(?:\.\d+)?(?:e[+-]?\d+)?)`
+
+var (
+	reTreeCost   = regexp.MustCompile(`\(cost=(?:` + planNumber + `\.\.)?` + planNumber + ` rows=` + planNumber + `\)`)
+	reTreeActual = regexp.MustCompile(`\(actual time=` + planNumber + `\.\.` + planNumber + ` rows=` + planNumber + ` loops=(\d+)\)`)
+	reTreeNever  = regexp.MustCompile(`\(never executed\)`)
+)
+
+// treeAccessPrefixes maps the operation prefixes of FORMAT=TREE output that
+// read a table to the equivalent traditional access type. Longer prefixes
+// come first so that "Covering index scan on" is not taken for "Index scan on".
+var treeAccessPrefixes = []struct {
+	prefix, access string
+}{
+	{"Single-row covering index lookup on ", "eq_ref"},
+	{"Single-row index lookup on ", "eq_ref"},
+	{"Covering index range scan on ", "range"},
+	{"Covering index lookup on ", "ref"},
+	{"Covering index scan on ", "index"},
+	{"Covering index skip scan on ", "range"},
+	{"Index range scan on ", "range"},
+	{"Index lookup on ", "ref"},
+	{"Index scan on ", "index"},
+	{"Full-text index search on ", "fulltext"},
+	{"Constant row from ", "const"},
+	{"Table scan on ", "ALL"},
+}
+
+// normalizePlan turns the raw EXPLAIN rows of the given format into a plan
+// tree. JSON and tree plans arrive as a single row with one column.
+func normalizePlan(format string, rows []map[string]interface{}) (*ExplainNode, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty plan")
+	}
+	if format == "traditional" {
+		return traditionalPlanTree(rows), nil
+	}
+
+	var text string
+	for _, v := range rows[0] {
+		text = fmt.Sprint(v)
+	}
+	if format == "json" {
+		return parseJSONPlan(text)
+	}
+	return parseTreePlan(text)
+}
+
+// traditionalPlanTree wraps the rows of a tabular EXPLAIN in a single query node.
+func traditionalPlanTree(rows []map[string]interface{}) *ExplainNode {
+	root := &ExplainNode{Operation: "query"}
+	for _, row := range rows {
+		n := ExplainNode{
+			Operation:  planString(row["select_type"]),
+			Table:      planString(row["table"]),
+			AccessType: planString(row["type"]),
+			Index:      planString(row["key"]),
+		}
+		if v, ok := planFloat(row["rows"]); ok {
+			n.RowsEstimated = &v
+		}
+		if v, ok := planFloat(row["filtered"]); ok {
+			n.Filtered = &v
+		}
+		addAccessWarning(&n)
+		extra := planString(row["Extra"])
+		if strings.Contains(extra, "Using temporary") {
+			n.Warnings = append(n.Warnings, planWarnTemporary)
+		}
+		if strings.Contains(extra, "Using filesort") {
+			n.Warnings = append(n.Warnings, planWarnFilesort)
+		}
+		root.Children = append(root.Children, n)
+	}
+	return root
+}
+
+// parseJSONPlan converts EXPLAIN FORMAT=JSON output. Every object that is
+// not plain data (cost_info) becomes a node named after its key; arrays such
+// as nested_loop or attached_subqueries become a node whose children are the
+// objects wrapped by each element.
+func parseJSONPlan(doc string) (*ExplainNode, error) {
+	var top map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &top); err != nil {
+		return nil, fmt.Errorf("invalid JSON plan: %w", err)
+	}
+	qb, ok := top["query_block"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JSON plan has no query_block")
+	}
+	n := jsonPlanNode("query_block", qb)
+	return &n, nil
+}
+
+func jsonPlanNode(op string, m map[string]interface{}) ExplainNode {
+	n := ExplainNode{
+		Operation:  op,
+		Table:      planString(m["table_name"]),
+		AccessType: planString(m["access_type"]),
+		Index:      planString(m["key"]),
+	}
+	if ci, ok := m["cost_info"].(map[string]interface{}); ok {
+		for _, key := range []string{"query_cost", "prefix_cost", "sort_cost"} {
+			if v, ok := planFloat(ci[key]); ok {
+				n.Cost = &v
+				break
+			}
+		}
+	}
+	if v, ok := planFloat(m["rows_examined_per_scan"]); ok {
+		n.RowsEstimated = &v
+	}
+	if v, ok := planFloat(m["filtered"]); ok {
+		n.Filtered = &v
+	}
+	addAccessWarning(&n)
+	if b, _ := m["using_temporary_table"].(bool); b {
+		n.Warnings = append(n.Warnings, planWarnTemporary)
+	}
+	if b, _ := m["using_filesort"].(bool); b {
+		n.Warnings = append(n.Warnings, planWarnFilesort)
+	}
+	n.Children = jsonPlanChildren(m)
+	return n
+}
+
+func jsonPlanChildren(m map[string]interface{}) []ExplainNode {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var children []ExplainNode
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case map[string]interface{}:
+			if k == "cost_info" {
+				continue
+			}
+			children = append(children, jsonPlanNode(k, v))
+		case []interface{}:
+			group := ExplainNode{Operation: k}
+			for _, item := range v {
+				if im, ok := item.(map[string]interface{}); ok {
+					group.Children = append(group.Children, jsonPlanChildren(im)...)
+				}
+			}
+			if len(group.Children) > 0 {
+				children = append(children, group)
+			}
+		}
+	}
+	return children
+}
+
+// treeLine is one "-> operation" entry of FORMAT=TREE output with its
+// indentation; long operations wrapped over several lines are joined.
+type treeLine struct {
+	indent int
+	text   string
+}
+
+// parseTreePlan converts EXPLAIN FORMAT=TREE and EXPLAIN ANALYZE output.
+// Children are indented below their parent.
+func parseTreePlan(text string) (*ExplainNode, error) {
+	var lines []treeLine
+	for _, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "-> ") {
+			lines = append(lines, treeLine{indent: len(raw) - len(trimmed), text: strings.TrimSpace(trimmed[3:])})
+			continue
+		}
+		if trimmed = strings.TrimSpace(trimmed); trimmed != "" && len(lines) > 0 {
+			lines[len(lines)-1].text += " " + trimmed
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("tree plan has no operations")
+	}
+	root, _ := buildTreeNode(lines, 0)
+	return &root, nil
+}
+
+func buildTreeNode(lines []treeLine, i int) (ExplainNode, int) {
+	n := parseTreeOperation(lines[i].text)
+	indent := lines[i].indent
+	i++
+	for i < len(lines) && lines[i].indent > indent {
+		var child ExplainNode
+		child, i = buildTreeNode(lines, i)
+		n.Children = append(n.Children, child)
+	}
+	return n, i
+}
+
+func parseTreeOperation(text string) ExplainNode {
+	end := len(text)
+	for _, re := range []*regexp.Regexp{reTreeCost, reTreeActual, reTreeNever} {
+		if loc := re.FindStringIndex(text); loc != nil && loc[0] < end {
+			end = loc[0]
+		}
+	}
+	n := ExplainNode{Operation: strings.TrimSpace(text[:end])}
+
+	if m := reTreeCost.FindStringSubmatch(text); m != nil {
+		cost, _ := strconv.ParseFloat(m[2], 64)
+		rows, _ := strconv.ParseFloat(m[3], 64)
+		n.Cost, n.RowsEstimated = &cost, &rows
+	}
+	if m := reTreeActual.FindStringSubmatch(text); m != nil {
+		last, _ := strconv.ParseFloat(m[2], 64)
+		rows, _ := strconv.ParseFloat(m[3], 64)
+		n.ActualTimeMs, n.RowsActual = &last, &rows
+		n.Loops, _ = strconv.ParseInt(m[4], 10, 64)
+	}
+
+	for _, p := range treeAccessPrefixes {
+		rest, ok := strings.CutPrefix(n.Operation, p.prefix)
+		if !ok {
+			continue
+		}
+		n.AccessType = p.access
+		n.Table, rest, _ = strings.Cut(rest, " ")
+		if _, after, ok := strings.Cut(" "+rest, " using "); ok {
+			n.Index, _, _ = strings.Cut(after, " ")
+		}
+		break
+	}
+
+	addAccessWarning(&n)
+	if strings.Contains(strings.ToLower(n.Operation), "temporary table") || strings.HasPrefix(n.Operation, "Materialize") {
+		n.Warnings = append(n.Warnings, planWarnTemporary)
+	}
+	if strings.HasPrefix(n.Operation, "Sort") {
+		n.Warnings = append(n.Warnings, planWarnFilesort)
+	}
+	if n.RowsEstimated != nil && n.RowsActual != nil {
+		if w := misestimateWarning(*n.RowsEstimated, *n.RowsActual); w != "" {
+			n.Warnings = append(n.Warnings, w)
+		}
+	}
+	return n
+}
+
+// addAccessWarning flags full scans of base tables. Internal tables such as
+// <temporary> or <derived2> are reported through their temporary table warning.
+func addAccessWarning(n *ExplainNode) {
+	if strings.HasPrefix(n.Table, "<") {
+		return
+	}
+	switch n.AccessType {
+	case "ALL":
+		n.Warnings = append(n.Warnings, planWarnFullScan)
+	case "index":
+		n.Warnings = append(n.Warnings, planWarnFullIndexScan)
+	}
+}
+
+// misestimateWarning reports when the optimizer's row estimate is off from
+// the actual row count by misestimateFactor or more.
+func misestimateWarning(estimated, actual float64) string {
+	lo, hi := estimated, actual
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if hi < misestimateFactor || hi < lo*misestimateFactor {
+		return ""
+	}
+	if lo < 1 {
+		lo = 1
+	}
+	return fmt.Sprintf("row estimate off by %.0fx (estimated %g, actual %g)", hi/lo, estimated, actual)
+}
+
+// planWarnings lists the warnings of all nodes, prefixed with the table or
+// operation they belong to.
+func planWarnings(n *ExplainNode) []string {
+	var out []string
+	var walk func(n *ExplainNode)
+	walk = func(n *ExplainNode) {
+		subject := n.Table
+		if subject == "" {
+			subject = n.Operation
+		}
+		for _, w := range n.Warnings {
+			out = append(out, subject+": "+w)
+		}
+		for i := range n.Children {
+			walk(&n.Children[i])
+		}
+	}
+	walk(n)
+	return out
+}
+
+// accessTypeRank orders access types from cheapest to most expensive per row
+// looked up, following the order of the EXPLAIN documentation.
+var accessTypeRank = map[string]int{
+	"system":          0,
+	"const":           1,
+	"eq_ref":          2,
+	"ref":             3,
+	"fulltext":        4,
+	"ref_or_null":     5,
+	"unique_subquery": 6,
+	"index_subquery":  7,
+	"index_merge":     8,
+	"range":           9,
+	"index":           10,
+	"ALL":             11,
+}
+
+const (
+	// equalCostMargin is the relative cost difference under which two plans
+	// are considered equally expensive.
+	equalCostMargin = 0.01
+	// rowsChangeFactor is how much a table's row estimate must change to be
+	// reported when its access path stays the same.
+	rowsChangeFactor = 2
+)
+
+// summarizePlan extracts the table access paths of a normalized plan.
+func summarizePlan(connection, database string, plan ExplainQueryOutput) PlanSummary {
+	s := PlanSummary{
+		Connection: connection,
+		Database:   database,
+		JoinOrder:  []string{},
+		Tables:     []PlanTableAccess{},
+		Warnings:   plan.Warnings,
+	}
+	if plan.Tree == nil {
+		return s
+	}
+	s.Cost = plan.Tree.Cost
+
+	var walk func(n *ExplainNode)
+	walk = func(n *ExplainNode) {
+		if n.Table != "" && n.AccessType != "" {
+			s.Tables = append(s.Tables, PlanTableAccess{Table: n.Table, AccessType: n.AccessType, Index: n.Index, Rows: n.RowsEstimated})
+			s.JoinOrder = append(s.JoinOrder, n.Table)
+			if n.RowsEstimated != nil {
+				s.RowsExamined += *n.RowsEstimated
+			}
+		}
+		for i := range n.Children {
+			walk(&n.Children[i])
+		}
+	}
+	walk(plan.Tree)
+	return s
+}
+
+// comparePlanSummaries diffs two plans and decides which one is cheaper.
+// Tables are matched by name; a name read several times is matched by
+// occurrence.
+func comparePlanSummaries(left, right PlanSummary) ComparePlansOutput {
+	out := ComparePlansOutput{Left: left, Right: right, Differences: []PlanTableDiff{}}
+
+	rightByName := make(map[string][]PlanTableAccess)
+	for _, t := range right.Tables {
+		key := strings.ToLower(t.Table)
+		rightByName[key] = append(rightByName[key], t)
+	}
+	var leftCommon []string
+	for _, l := range left.Tables {
+		key := strings.ToLower(l.Table)
+		candidates := rightByName[key]
+		if len(candidates) == 0 {
+			out.Differences = append(out.Differences, PlanTableDiff{
+				Table: l.Table, Change: "removed", LeftAccess: l.AccessType, LeftIndex: l.Index, LeftRows: l.Rows,
+			})
+			continue
+		}
+		r := candidates[0]
+		rightByName[key] = candidates[1:]
+		leftCommon = append(leftCommon, key)
+		if change := tableAccessChange(l, r); change != "" {
+			out.Differences = append(out.Differences, PlanTableDiff{
+				Table: l.Table, Change: change,
+				LeftAccess: l.AccessType, RightAccess: r.AccessType,
+				LeftIndex: l.Index, RightIndex: r.Index,
+				LeftRows: l.Rows, RightRows: r.Rows,
+			})
+		}
+	}
+
+	// Tables only on the right, and the join order of tables on both sides.
+	matched := make(map[string]int)
+	for _, key := range leftCommon {
+		matched[key]++
+	}
+	var rightCommon []string
+	for _, r := range right.Tables {
+		key := strings.ToLower(r.Table)
+		if matched[key] > 0 {
+			matched[key]--
+			rightCommon = append(rightCommon, key)
+			continue
+		}
+		out.Differences = append(out.Differences, PlanTableDiff{
+			Table: r.Table, Change: "added", RightAccess: r.AccessType, RightIndex: r.Index, RightRows: r.Rows,
+		})
+	}
+	out.JoinOrderChanged = strings.Join(leftCommon, ",") != strings.Join(rightCommon, ",")
+
+	out.Cheaper, out.Verdict = "unknown", "cannot compare: plan costs are unavailable"
+	switch {
+	case left.Cost != nil && right.Cost != nil:
+		if *left.Cost > 0 {
+			pct := (*right.Cost - *left.Cost) / *left.Cost * 100
+			out.CostChangePct = &pct
+		}
+		out.Cheaper, out.Verdict = planVerdict("cost", *left.Cost, *right.Cost)
+	case len(left.Tables) > 0 && len(right.Tables) > 0:
+		out.Cheaper, out.Verdict = planVerdict("rows examined", left.RowsExamined, right.RowsExamined)
+	}
+	return out
+}
+
+// tableAccessChange classifies how a table's access path changed from l to
+// r, or returns "" when it did not change meaningfully.
+func tableAccessChange(l, r PlanTableAccess) string {
+	lRank, lok := accessTypeRank[l.AccessType]
+	rRank, rok := accessTypeRank[r.AccessType]
+	switch {
+	case lok && rok && rRank < lRank:
+		return "improved"
+	case lok && rok && rRank > lRank:
+		return "regressed"
+	case l.AccessType != r.AccessType:
+		return "changed"
+	}
+	if l.Rows != nil && r.Rows != nil {
+		lo, hi := *l.Rows, *r.Rows
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if hi >= lo*rowsChangeFactor && hi >= misestimateFactor {
+			if *r.Rows < *l.Rows {
+				return "improved"
+			}
+			return "regressed"
+		}
+	}
+	if !strings.EqualFold(l.Index, r.Index) {
+		return "changed"
+	}
+	return ""
+}
+
+// planVerdict compares one measure of both plans.
+func planVerdict(measure string, left, right float64) (string, string) {
+	hi := left
+	if right > hi {
+		hi = right
+	}
+	if hi == 0 || (left-right <= hi*equalCostMargin && right-left <= hi*equalCostMargin) {
+		return "equal", fmt.Sprintf("plans are equally expensive (%s %g vs %g)", measure, left, right)
+	}
+	if right < left {
+		return "right", fmt.Sprintf("right plan is cheaper (%s %g vs %g)", measure, right, left)
+	}
+	return "left", fmt.Sprintf("left plan is cheaper (%s %g vs %g)", measure, left, right)
+}
+
+func planString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// planFloat reads a number that EXPLAIN may report as a number or a string.
+func planFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int64:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
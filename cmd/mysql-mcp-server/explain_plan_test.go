@@ -0,0 +1,178 @@
+// cmd/mysql-mcp-server/explain_plan_test.go
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleJSONPlan = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "45.10"},
+    "ordering_operation": {
+      "using_temporary_table": true,
+      "using_filesort": true,
+      "nested_loop": [
+        {
+          "table": {
+            "table_name": "o",
+            "access_type": "range",
+            "key": "idx_created",
+            "rows_examined_per_scan": 20,
+            "filtered": "100.00",
+            "cost_info": {"read_cost": "9.01", "prefix_cost": "11.01"}
+          }
+        },
+        {
+          "table": {
+            "table_name": "c",
+            "access_type": "eq_ref",
+            "key": "PRIMARY",
+            "rows_examined_per_scan": 1,
+            "cost_info": {"prefix_cost": "18.01"}
+          }
+        }
+      ]
+    }
+  }
+}`
+
+const sampleTreePlan = `-> Sort: o.created_at  (actual time=2.1..2.2 rows=20 loops=1)
+    -> Nested loop inner join  (cost=18.01 rows=20) (actual time=0.1..1.9 rows=20 loops=1)
+        -> Index range scan on o using idx_created over ('2024-01-01' <= created_at), with index condition: (o.created_at >= DATE'2024-01-01')  (cost=11.01 rows=20) (actual time=0.05..0.8 rows=20 loops=1)
+        -> Single-row index lookup on c using PRIMARY (id=o.customer_id)  (cost=0.25 rows=1) (actual time=0.01..0.01 rows=1 loops=20)
+    -> Covering index scan on t using idx_x  (cost=0.35..1.25 rows=10) (never executed)
+`
+
+func TestParseJSONPlan(t *testing.T) {
+	root, err := parseJSONPlan(sampleJSONPlan)
+	if err != nil {
+		t.Fatalf("parseJSONPlan failed: %v", err)
+	}
+	if root.Operation != "query_block" || root.Cost == nil || *root.Cost != 45.10 {
+		t.Errorf("unexpected root: %+v", root)
+	}
+
+	ordering := root.Children[0]
+	if ordering.Operation != "ordering_operation" || strings.Join(ordering.Warnings, ",") != "temporary table,filesort" {
+		t.Errorf("unexpected ordering node: %+v", ordering)
+	}
+	loop := ordering.Children[0]
+	if loop.Operation != "nested_loop" || len(loop.Children) != 2 {
+		t.Fatalf("unexpected nested loop: %+v", loop)
+	}
+	o := loop.Children[0]
+	if o.Table != "o" || o.AccessType != "range" || o.Index != "idx_created" ||
+		*o.Cost != 11.01 || *o.RowsEstimated != 20 || *o.Filtered != 100 || o.Warnings != nil {
+		t.Errorf("unexpected table node: %+v", o)
+	}
+
+	warnings := planWarnings(root)
+	if len(warnings) != 2 || warnings[0] != "ordering_operation: temporary table" {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	if _, err := parseJSONPlan(`{"nope": 1}`); err == nil {
+		t.Error("expected an error for a plan without query_block")
+	}
+}
+
+func TestParseTreePlan(t *testing.T) {
+	root, err := parseTreePlan(sampleTreePlan)
+	if err != nil {
+		t.Fatalf("parseTreePlan failed: %v", err)
+	}
+	if root.Operation != "Sort: o.created_at" || root.Cost != nil || *root.RowsActual != 20 ||
+		len(root.Warnings) != 1 || root.Warnings[0] != planWarnFilesort {
+		t.Errorf("unexpected root: %+v", root)
+	}
+	if len(root.Children) != 2 || len(root.Children[0].Children) != 2 {
+		t.Fatalf("unexpected tree shape: %+v", root)
+	}
+
+	rangeScan := root.Children[0].Children[0]
+	if rangeScan.Table != "o" || rangeScan.AccessType != "range" || rangeScan.Index != "idx_created" ||
+		*rangeScan.Cost != 11.01 || *rangeScan.ActualTimeMs != 0.8 || rangeScan.Loops != 1 {
+		t.Errorf("unexpected range scan: %+v", rangeScan)
+	}
+	lookup := root.Children[0].Children[1]
+	if lookup.Table != "c" || lookup.AccessType != "eq_ref" || lookup.Index != "PRIMARY" || lookup.Loops != 20 {
+		t.Errorf("unexpected lookup: %+v", lookup)
+	}
+	never := root.Children[1]
+	if never.AccessType != "index" || *never.Cost != 1.25 || never.RowsActual != nil ||
+		len(never.Warnings) != 1 || never.Warnings[0] != planWarnFullIndexScan {
+		t.Errorf("unexpected index scan: %+v", never)
+	}
+
+	if _, err := parseTreePlan("EXPLAIN"); err == nil {
+		t.Error("expected an error for a plan without operations")
+	}
+}
+
+func TestTraditionalPlanTree(t *testing.T) {
+	root := traditionalPlanTree([]map[string]interface{}{
+		{"select_type": "PRIMARY", "table": "u", "type": "ALL", "key": nil, "rows": int64(1000), "filtered": "10.00", "Extra": "Using where; Using temporary; Using filesort"},
+		{"select_type": "DERIVED", "table": "<derived2>", "type": "ALL", "key": nil, "rows": "50", "Extra": nil},
+		{"select_type": "SIMPLE", "table": "o", "type": "ref", "key": "idx_user", "rows": int64(3), "Extra": "Using index"},
+	})
+
+	if len(root.Children) != 3 {
+		t.Fatalf("expected 3 nodes, got %+v", root.Children)
+	}
+	want := []string{"u: full table scan", "u: temporary table", "u: filesort"}
+	if got := planWarnings(root); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("planWarnings = %v, want %v", got, want)
+	}
+	if d := root.Children[1]; *d.RowsEstimated != 50 || d.Warnings != nil {
+		t.Errorf("unexpected derived table node: %+v", d)
+	}
+}
+
+func TestMisestimateWarning(t *testing.T) {
+	tests := []struct {
+		estimated, actual float64
+		want              string
+	}{
+		{10, 5000, "row estimate off by 500x (estimated 10, actual 5000)"},
+		{2000, 100, "row estimate off by 20x (estimated 2000, actual 100)"},
+		{0, 40, "row estimate off by 40x (estimated 0, actual 40)"},
+		{1, 5, ""},     // too few rows to matter
+		{100, 500, ""}, // within the factor
+	}
+	for _, tt := range tests {
+		if got := misestimateWarning(tt.estimated, tt.actual); got != tt.want {
+			t.Errorf("misestimateWarning(%g, %g) = %q, want %q", tt.estimated, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestComparePlanSummaries(t *testing.T) {
+	rows := func(v float64) *float64 { return &v }
+	left := PlanSummary{Tables: []PlanTableAccess{
+		{Table: "o", AccessType: "ref", Index: "idx_a", Rows: rows(100)},
+		{Table: "tmp", AccessType: "ALL", Rows: rows(10)},
+	}, RowsExamined: 110}
+	right := PlanSummary{Tables: []PlanTableAccess{
+		{Table: "o", AccessType: "ref", Index: "idx_b", Rows: rows(20)},
+		{Table: "c", AccessType: "eq_ref", Index: "PRIMARY", Rows: rows(1)},
+	}, RowsExamined: 21}
+
+	out := comparePlanSummaries(left, right)
+	var got []string
+	for _, d := range out.Differences {
+		got = append(got, d.Table+":"+d.Change)
+	}
+	if want := "o:improved,tmp:removed,c:added"; strings.Join(got, ",") != want {
+		t.Errorf("differences = %v, want %s", got, want)
+	}
+	// Without costs the verdict falls back to rows examined.
+	if out.Cheaper != "right" || out.CostChangePct != nil || !strings.Contains(out.Verdict, "rows examined 21 vs 110") {
+		t.Errorf("unexpected verdict: %s / %s", out.Cheaper, out.Verdict)
+	}
+
+	if out := comparePlanSummaries(PlanSummary{}, PlanSummary{}); out.Cheaper != "unknown" {
+		t.Errorf("expected an unknown verdict for empty plans, got %s", out.Cheaper)
+	}
+}
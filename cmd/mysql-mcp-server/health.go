@@ -0,0 +1,262 @@
+// cmd/mysql-mcp-server/health.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ===== Circuit Breaker and Health Checks =====
+
+// Circuit breaker states.
+const (
+	breakerClosed   = "closed"    // calls go through
+	breakerOpen     = "open"      // calls fail fast until the cooldown has passed
+	breakerHalfOpen = "half-open" // one trial call decides whether to close or reopen
+)
+
+// The breaker of a connection opens when at least breakerFailureRate of the
+// last breakerWindow calls (and at least breakerMinCalls) failed with
+// connection errors or timeouts, or after breakerMaxTimeouts timeouts in a
+// row, since every timeout costs a caller the full query timeout.
+const (
+	breakerWindow      = 10
+	breakerMinCalls    = 5
+	breakerFailureRate = 0.5
+	breakerMaxTimeouts = 3
+)
+
+// outcome classifies the result of a call or ping for the circuit breaker.
+type outcome int
+
+const (
+	outcomeIgnored outcome = iota // canceled by the caller
+	outcomeSuccess                // includes errors the server answered, e.g. SQL errors
+	outcomeFailure                // connection errors
+	outcomeTimeout
+)
+
+// classifyOutcome tells connection problems apart from errors of the call
+// itself, which say nothing about the health of the connection.
+func classifyOutcome(err error) outcome {
+	var myErr *mysql.MySQLError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return outcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return outcomeIgnored
+	case errors.As(err, &myErr):
+		if myErr.Number == 3024 { // ER_QUERY_TIMEOUT (max_execution_time exceeded)
+			return outcomeTimeout
+		}
+		return outcomeSuccess
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return outcomeTimeout
+		}
+		return outcomeFailure
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn),
+		errors.Is(err, sql.ErrConnDone), errors.Is(err, io.ErrUnexpectedEOF):
+		return outcomeFailure
+	}
+	return outcomeSuccess
+}
+
+// newConnectionStatus returns the status of a newly registered connection.
+func newConnectionStatus(state, lastErr string) *ConnectionStatus {
+	return &ConnectionStatus{State: state, LastError: lastErr, Breaker: breakerClosed}
+}
+
+// Healthy reports whether the connection is reachable and its breaker closed.
+func (st ConnectionStatus) Healthy() bool {
+	return st.State == connStateConnected && st.Breaker == breakerClosed
+}
+
+// allow reports whether a call may use the connection. Once the cooldown
+// has passed, an open breaker turns half-open and lets one trial call
+// through. cm.mu must be held for writing.
+func (st *ConnectionStatus) allow(now time.Time, cooldown time.Duration) bool {
+	switch st.Breaker {
+	case breakerOpen:
+		if now.Sub(st.openedAt) < cooldown {
+			return false
+		}
+		st.Breaker = breakerHalfOpen
+		st.trial = true
+		return true
+	case breakerHalfOpen:
+		if st.trial {
+			return false
+		}
+		st.trial = true
+		return true
+	}
+	return true
+}
+
+// record adds the result of a call or ping to the circuit breaker. cm.mu
+// must be held for writing.
+func (st *ConnectionStatus) record(name string, err error, now time.Time, cooldown time.Duration) {
+	o := classifyOutcome(err)
+	if o == outcomeFailure || o == outcomeTimeout {
+		st.LastError = err.Error()
+	}
+
+	switch st.Breaker {
+	case breakerOpen:
+		// A ping or a call that was already running got through; the
+		// next call is the trial.
+		if o == outcomeSuccess && now.Sub(st.openedAt) >= cooldown {
+			st.Breaker = breakerHalfOpen
+			st.trial = false
+		}
+		return
+	case breakerHalfOpen:
+		switch o {
+		case outcomeSuccess:
+			st.closeBreaker(name, now)
+		case outcomeFailure, outcomeTimeout:
+			st.openBreaker(name, now)
+		default:
+			st.trial = false
+		}
+		return
+	}
+
+	if o == outcomeIgnored {
+		return
+	}
+	st.outcomes = append(st.outcomes, o != outcomeSuccess)
+	if len(st.outcomes) > breakerWindow {
+		st.outcomes = st.outcomes[1:]
+	}
+	if o == outcomeTimeout {
+		st.timeouts++
+	} else {
+		st.timeouts = 0
+	}
+	failures := 0
+	for _, failed := range st.outcomes {
+		if failed {
+			failures++
+		}
+	}
+	if st.timeouts >= breakerMaxTimeouts ||
+		(len(st.outcomes) >= breakerMinCalls && float64(failures) >= breakerFailureRate*float64(len(st.outcomes))) {
+		st.openBreaker(name, now)
+	}
+}
+
+func (st *ConnectionStatus) openBreaker(name string, now time.Time) {
+	if st.Breaker == breakerClosed {
+		st.UnhealthySince = now
+		logWarn("circuit breaker opened", map[string]interface{}{
+			"name":  name,
+			"error": st.LastError,
+		})
+	}
+	st.Breaker = breakerOpen
+	st.openedAt = now
+	st.trial = false
+	st.outcomes = nil
+	st.timeouts = 0
+}
+
+func (st *ConnectionStatus) closeBreaker(name string, now time.Time) {
+	logInfo("circuit breaker closed", map[string]interface{}{
+		"name":      name,
+		"unhealthy": now.Sub(st.UnhealthySince).Round(time.Second).String(),
+	})
+	st.Breaker = breakerClosed
+	st.UnhealthySince = time.Time{}
+	st.trial = false
+	st.outcomes = nil
+	st.timeouts = 0
+}
+
+// unhealthyError is returned to calls rejected by an open breaker.
+func (st *ConnectionStatus) unhealthyError(name string) error {
+	return fmt.Errorf("connection '%s' is unhealthy since %s: %s", name, st.UnhealthySince.UTC().Format(time.RFC3339), st.LastError)
+}
+
+// Record adds the result of a tool call on the named connection to its
+// circuit breaker.
+func (cm *ConnectionManager) Record(name string, err error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if _, ok := cm.connections[name]; !ok {
+		return
+	}
+	cm.statusLocked(name).record(name, err, time.Now(), cm.breakerCooldown)
+}
+
+// SetHealthSettings sets the timeout of health check pings and how long an
+// open circuit breaker rejects calls before it lets a trial call through.
+// Zero values keep the current settings.
+func (cm *ConnectionManager) SetHealthSettings(pingTimeout, breakerCooldown time.Duration) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if pingTimeout > 0 {
+		cm.pingTimeout = pingTimeout
+	}
+	if breakerCooldown > 0 {
+		cm.breakerCooldown = breakerCooldown
+	}
+}
+
+// StartHealthChecks pings every connection that is not degraded each
+// interval, until the manager is closed. Degraded connections are pinged by
+// their background retry instead. An interval of 0 disables the checks.
+func (cm *ConnectionManager) StartHealthChecks(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cm.checkHealth()
+			case <-cm.done:
+				return
+			}
+		}
+	}()
+}
+
+// checkHealth pings the connections that are not degraded concurrently.
+func (cm *ConnectionManager) checkHealth() {
+	cm.mu.RLock()
+	pools := make(map[string]*sql.DB, len(cm.connections))
+	for name, db := range cm.connections {
+		if st, ok := cm.status[name]; !ok || st.State != connStateDegraded {
+			pools[name] = db
+		}
+	}
+	cm.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, db := range pools {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), cm.checkTimeout())
+			defer cancel()
+			_ = cm.ping(ctx, name, db)
+		}()
+	}
+	wg.Wait()
+}
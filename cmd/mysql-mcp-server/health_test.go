@@ -0,0 +1,190 @@
+// cmd/mysql-mcp-server/health_test.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/config"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	tests := []struct {
+		err  error
+		want outcome
+	}{
+		{nil, outcomeSuccess},
+		{fmt.Errorf("query failed: %w", context.DeadlineExceeded), outcomeTimeout},
+		{context.Canceled, outcomeIgnored},
+		{&mysql.MySQLError{Number: 1064, Message: "syntax error"}, outcomeSuccess},
+		{&mysql.MySQLError{Number: 3024, Message: "maximum statement execution time exceeded"}, outcomeTimeout},
+		{fmt.Errorf("query failed: %w", driver.ErrBadConn), outcomeFailure},
+		{mysql.ErrInvalidConn, outcomeFailure},
+		{errors.New("query blocked: only SELECT statements are allowed"), outcomeSuccess},
+	}
+	for _, tt := range tests {
+		if got := classifyOutcome(tt.err); got != tt.want {
+			t.Errorf("classifyOutcome(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// setupHealthyConnection returns a manager with one connected connection.
+func setupHealthyConnection(t *testing.T) (*ConnectionManager, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	cm := NewConnectionManager()
+	t.Cleanup(cm.Close)
+	cm.retryDelay = time.Hour
+	cm.connections["replica"] = db
+	cm.configs["replica"] = config.ConnectionConfig{Name: "replica"}
+	cm.activeConn = "replica"
+
+	mock.ExpectPing()
+	if err := cm.Ready(context.Background(), "replica"); err != nil {
+		t.Fatalf("Ready failed: %v", err)
+	}
+	return cm, mock
+}
+
+func TestCircuitBreakerTimeouts(t *testing.T) {
+	cm, _ := setupHealthyConnection(t)
+	cm.SetHealthSettings(0, 30*time.Millisecond)
+
+	for i := 0; i < breakerMaxTimeouts; i++ {
+		if err := cm.Ready(context.Background(), "replica"); err != nil {
+			t.Fatalf("call %d rejected too early: %v", i, err)
+		}
+		cm.Record("replica", fmt.Errorf("query failed: %w", context.DeadlineExceeded))
+	}
+
+	st, _ := cm.Status("replica")
+	if st.Breaker != breakerOpen || st.UnhealthySince.IsZero() || st.Healthy() {
+		t.Fatalf("expected an open breaker, got %+v", st)
+	}
+	since := st.UnhealthySince
+	err := cm.Ready(context.Background(), "replica")
+	if err == nil || !strings.Contains(err.Error(), "connection 'replica' is unhealthy since "+since.UTC().Format(time.RFC3339)) ||
+		!strings.Contains(err.Error(), "deadline exceeded") {
+		t.Fatalf("expected to fail fast, got %v", err)
+	}
+
+	// After the cooldown one trial call goes through; its failure reopens the breaker.
+	time.Sleep(40 * time.Millisecond)
+	if err := cm.Ready(context.Background(), "replica"); err != nil {
+		t.Fatalf("expected a trial call, got %v", err)
+	}
+	if err := cm.Ready(context.Background(), "replica"); err == nil {
+		t.Fatal("only one trial call may run while half-open")
+	}
+	cm.Record("replica", driver.ErrBadConn)
+	if st, _ := cm.Status("replica"); st.Breaker != breakerOpen || !st.UnhealthySince.Equal(since) {
+		t.Fatalf("expected the breaker to reopen, got %+v", st)
+	}
+
+	// A successful trial closes it.
+	time.Sleep(40 * time.Millisecond)
+	if err := cm.Ready(context.Background(), "replica"); err != nil {
+		t.Fatalf("expected a trial call, got %v", err)
+	}
+	cm.Record("replica", nil)
+	if st, _ := cm.Status("replica"); st.Breaker != breakerClosed || !st.UnhealthySince.IsZero() || !st.Healthy() {
+		t.Errorf("expected a closed breaker, got %+v", st)
+	}
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	cm, _ := setupHealthyConnection(t)
+
+	// SQL errors are answers from a healthy server.
+	for i := 0; i < breakerWindow; i++ {
+		cm.Record("replica", &mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"})
+	}
+	if st, _ := cm.Status("replica"); st.Breaker != breakerClosed {
+		t.Fatalf("SQL errors must not open the breaker: %+v", st)
+	}
+
+	for _, err := range []error{nil, nil, driver.ErrBadConn, nil, mysql.ErrInvalidConn, nil, nil, driver.ErrBadConn} {
+		cm.Record("replica", err)
+	}
+	if st, _ := cm.Status("replica"); st.Breaker != breakerClosed {
+		t.Fatalf("3 of 10 failed calls must not open the breaker: %+v", st)
+	}
+	cm.Record("replica", driver.ErrBadConn)
+	cm.Record("replica", driver.ErrBadConn)
+	if st, _ := cm.Status("replica"); st.Breaker != breakerOpen || st.LastError != driver.ErrBadConn.Error() {
+		t.Errorf("5 of 10 failed calls should open the breaker: %+v", st)
+	}
+}
+
+func TestWithConnectionChargesItsOwnBreaker(t *testing.T) {
+	cm, _ := setupHealthyConnection(t)
+	primary, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	cm.connections["primary"] = primary
+	cm.configs["primary"] = config.ConnectionConfig{Name: "primary"}
+	cm.activeConn = "primary"
+	oldConnManager := connManager
+	connManager = cm
+	defer func() { connManager = oldConnManager }()
+
+	// A tool timing out on the replica charges the replica only.
+	timeout := fmt.Errorf("query failed: %w", context.DeadlineExceeded)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input mockInput) (*mcp.CallToolResult, mockOutput, error) {
+		return nil, mockOutput{}, withConnection(ctx, "replica", func(*sql.DB) error { return timeout })
+	}
+	wrapped := wrapTool("compare_plans", handler)
+	for i := 0; i < breakerMaxTimeouts; i++ {
+		if _, _, err := wrapped(context.Background(), nil, mockInput{}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("call %d: expected a timeout, got %v", i, err)
+		}
+	}
+	if st, _ := cm.Status("replica"); st.Breaker != breakerOpen {
+		t.Errorf("expected the replica breaker to open, got %+v", st)
+	}
+	if st, _ := cm.Status("primary"); st.Breaker != breakerClosed || st.Failures != 0 {
+		t.Errorf("expected the active connection to be unaffected, got %+v", st)
+	}
+
+	// The open breaker fails fast without running the call.
+	called := false
+	err = withConnection(context.Background(), "replica", func(*sql.DB) error {
+		called = true
+		return nil
+	})
+	if err == nil || called || !strings.Contains(err.Error(), "unhealthy") {
+		t.Errorf("expected to fail fast, got called=%v err=%v", called, err)
+	}
+}
+
+func TestHealthCheckDetectsLostConnection(t *testing.T) {
+	cm, mock := setupHealthyConnection(t)
+
+	lost := errors.New("read tcp 10.0.0.7:3306: connection reset by peer")
+	mock.ExpectPing().WillReturnError(lost) // health check
+	mock.ExpectPing().WillReturnError(lost) // first background retry
+	cm.checkHealth()
+
+	st := waitForStatus(t, cm, "replica", func(st ConnectionStatus) bool { return st.Failures == 2 })
+	if st.State != connStateDegraded || st.LastError != lost.Error() || st.LastSuccess.IsZero() {
+		t.Errorf("unexpected status: %+v", st)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected pings: %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -22,7 +23,7 @@ const maxJSONRequestBodyBytes int64 = 1 << 20 // 1 MiB
 // httpContext returns a context with timeout for HTTP handlers.
 // Uses the request's context as parent to properly handle client disconnects.
 func httpContext(r *http.Request) (context.Context, context.CancelFunc) {
-	return context.WithTimeout(r.Context(), cfg.HTTPRequestTimeout)
+	return context.WithTimeout(r.Context(), currentSettings().cfg.HTTPRequestTimeout)
 }
 
 func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
@@ -175,6 +176,35 @@ func httpUseConnection(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccess(w, out)
 }
 
+// httpCacheStats handles GET /api/cache
+func httpCacheStats(w http.ResponseWriter, r *http.Request) {
+	api.WriteSuccess(w, resultCacheStats(currentSettings()))
+}
+
+// httpRefreshCache handles POST /api/cache/refresh with JSON body {"connection"?: "...", "database"?: "..."}
+func httpRefreshCache(w http.ResponseWriter, r *http.Request) {
+	var input RefreshCacheInput
+	if r.ContentLength != 0 {
+		if err := decodeJSONBody(w, r, &input); err != nil {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolRefreshCacheWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
 // ===== Extended HTTP Handlers =====
 
 // httpListIndexes handles GET /api/indexes?database=xxx&table=yyy
@@ -205,7 +235,7 @@ func httpShowCreateTable(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccess(w, out)
 }
 
-// httpExplainQuery handles POST /api/explain with JSON body {"sql": "...", "database": "..."}
+// httpExplainQuery handles POST /api/explain with JSON body {"sql": "...", "database": "...", "format": "...", "analyze": false}
 func httpExplainQuery(w http.ResponseWriter, r *http.Request) {
 	var input ExplainQueryInput
 	if err := decodeJSONBody(w, r, &input); err != nil {
@@ -363,6 +393,336 @@ func httpListVariables(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccess(w, out)
 }
 
+// httpProfileTable handles POST /api/profile with JSON body {"database": "...", "table": "...", ...}
+func httpProfileTable(w http.ResponseWriter, r *http.Request) {
+	var input ProfileTableInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+		return
+	}
+	if input.Database == "" || input.Table == "" {
+		api.WriteBadRequest(w, "database and table fields are required")
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolProfileTableWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpSampleRows handles POST /api/sample with JSON body {"database": "...", "table": "...", ...}
+func httpSampleRows(w http.ResponseWriter, r *http.Request) {
+	var input SampleRowsInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+		return
+	}
+	if input.Database == "" || input.Table == "" {
+		api.WriteBadRequest(w, "database and table fields are required")
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolSampleRowsWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpSummarizeDatabase handles POST /api/summary with JSON body {"database": "...", "max_tokens": 4000}
+func httpSummarizeDatabase(w http.ResponseWriter, r *http.Request) {
+	var input SummarizeDatabaseInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+		return
+	}
+	if input.Database == "" {
+		api.WriteBadRequest(w, "database field is required")
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolSummarizeDatabaseWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpListProcesses handles GET /api/processes?user=&host=&db=&command=&min_time=&state= (all optional)
+func httpListProcesses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	input := ListProcessesInput{
+		User:    q.Get("user"),
+		Host:    q.Get("host"),
+		DB:      q.Get("db"),
+		Command: q.Get("command"),
+		State:   q.Get("state"),
+	}
+	var err error
+	if input.MinTime, err = queryParamInt(r, "min_time"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolListProcessesWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpLockWaits handles GET /api/lock-waits?min_wait=N (optional)
+func httpLockWaits(w http.ResponseWriter, r *http.Request) {
+	minWait, err := queryParamInt(r, "min_wait")
+	if err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	input := LockWaitsInput{MinWait: minWait}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolLockWaitsWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpTopQueries handles GET /api/top-queries?order_by=&schema=&limit=&window= (all optional)
+func httpTopQueries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	input := TopQueriesInput{
+		OrderBy: q.Get("order_by"),
+		Schema:  q.Get("schema"),
+	}
+	var err error
+	if input.Limit, err = queryParamInt(r, "limit"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	if input.Window, err = queryParamInt(r, "window"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolTopQueriesWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpInnodbStatus handles GET /api/innodb-status?sections=deadlock,log (sections optional)
+func httpInnodbStatus(w http.ResponseWriter, r *http.Request) {
+	sections := r.URL.Query().Get("sections")
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolInnodbStatusWrapped(ctx, nil, InnodbStatusInput{Sections: sections})
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpReplicationStatus handles GET /api/replication?all_connections=true (optional)
+func httpReplicationStatus(w http.ResponseWriter, r *http.Request) {
+	var input ReplicationStatusInput
+	if v := r.URL.Query().Get("all_connections"); v != "" {
+		all, err := strconv.ParseBool(v)
+		if err != nil {
+			api.WriteBadRequest(w, "all_connections must be true or false")
+			return
+		}
+		input.AllConnections = all
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolReplicationStatusWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpIndexAdvisor handles GET /api/index-advisor?database=xxx&table=yyy&checks=unused,redundant,missing
+func httpIndexAdvisor(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	input := IndexAdvisorInput{
+		Database: q.Get("database"),
+		Table:    q.Get("table"),
+		Checks:   q.Get("checks"),
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolIndexAdvisorWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpConfigAdvisor handles GET /api/config-advisor?memory_bytes=&rules=
+func httpConfigAdvisor(w http.ResponseWriter, r *http.Request) {
+	input := ConfigAdvisorInput{Rules: r.URL.Query().Get("rules")}
+	if v := r.URL.Query().Get("memory_bytes"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			api.WriteBadRequest(w, "memory_bytes must be a non-negative integer")
+			return
+		}
+		input.MemoryBytes = n
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolConfigAdvisorWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpSecurityAudit handles GET /api/security-audit?checks=&include_locked=&role_grant_limit=
+func httpSecurityAudit(w http.ResponseWriter, r *http.Request) {
+	input := SecurityAuditInput{Checks: r.URL.Query().Get("checks")}
+	if v := r.URL.Query().Get("include_locked"); v != "" {
+		locked, err := strconv.ParseBool(v)
+		if err != nil {
+			api.WriteBadRequest(w, "include_locked must be true or false")
+			return
+		}
+		input.IncludeLocked = locked
+	}
+	var err error
+	if input.RoleGrantLimit, err = queryParamInt(r, "role_grant_limit"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolSecurityAuditWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpCapacityCheck handles GET /api/capacity?database=&window=
+func httpCapacityCheck(w http.ResponseWriter, r *http.Request) {
+	input := CapacityCheckInput{Database: r.URL.Query().Get("database")}
+	var err error
+	if input.Window, err = queryParamInt(r, "window"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolCapacityCheckWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpLintQuery handles POST /api/lint with JSON body {"sql": "...", "database": "..."}
+func httpLintQuery(w http.ResponseWriter, r *http.Request) {
+	var input LintQueryInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+		return
+	}
+	if input.SQL == "" {
+		api.WriteBadRequest(w, "sql field is required")
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolLintQueryWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpComparePlans handles POST /api/compare-plans with JSON body
+// {"sql": "...", "right_sql": "...", "connection": "...", "right_connection": "..."}
+func httpComparePlans(w http.ResponseWriter, r *http.Request) {
+	var input ComparePlansInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+		return
+	}
+	if input.SQL == "" {
+		api.WriteBadRequest(w, "sql field is required")
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolComparePlansWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// queryParamInt parses an optional non-negative integer query parameter (0 when absent).
+func queryParamInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
+
 // ===== Vector HTTP Handlers =====
 
 // httpVectorSearch handles POST /api/vector/search
@@ -387,6 +747,28 @@ func httpVectorSearch(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccess(w, out)
 }
 
+// httpHybridSearch handles POST /api/vector/hybrid
+func httpHybridSearch(w http.ResponseWriter, r *http.Request) {
+	var input HybridSearchInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			api.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		api.WriteBadRequest(w, "invalid JSON body: "+err.Error())
+		return
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolHybridSearchWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
 // httpVectorInfo handles GET /api/vector/info?database=xxx
 func httpVectorInfo(w http.ResponseWriter, r *http.Request) {
 	database := r.URL.Query().Get("database")
@@ -400,35 +782,123 @@ func httpVectorInfo(w http.ResponseWriter, r *http.Request) {
 	api.WriteSuccess(w, out)
 }
 
+// httpVectorStats handles GET /api/vector/stats?database=xxx&table=yyy&column=zzz
+func httpVectorStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	input := VectorStatsInput{Database: q.Get("database"), Table: q.Get("table"), Column: q.Get("column")}
+	var err error
+	if input.SampleSize, err = queryParamInt(r, "sample_size"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	if v := q.Get("duplicate_threshold"); v != "" {
+		if input.DuplicateThreshold, err = strconv.ParseFloat(v, 64); err != nil {
+			api.WriteBadRequest(w, "duplicate_threshold must be a number")
+			return
+		}
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolVectorStatsWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
+// httpVectorDDL handles GET /api/vector/ddl?database=xxx&table=yyy&column=zzz
+func httpVectorDDL(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	input := VectorDDLInput{
+		Database:  q.Get("database"),
+		Table:     q.Get("table"),
+		Column:    q.Get("column"),
+		Target:    q.Get("target"),
+		NewColumn: q.Get("new_column"),
+	}
+	var err error
+	if input.Dimensions, err = queryParamInt(r, "dimensions"); err != nil {
+		api.WriteBadRequest(w, err.Error())
+		return
+	}
+	if v := q.Get("replace"); v != "" {
+		if input.Replace, err = strconv.ParseBool(v); err != nil {
+			api.WriteBadRequest(w, "replace must be true or false")
+			return
+		}
+	}
+	ctx, cancel := httpContext(r)
+	defer cancel()
+	_, out, err := toolVectorDDLWrapped(ctx, nil, input)
+	if err != nil {
+		api.WriteInternalError(w, err.Error())
+		return
+	}
+	api.WriteSuccess(w, out)
+}
+
 // ===== Utility HTTP Handlers =====
 
-// httpHealth handles GET /health
+// httpHealth handles GET /health. It is a readiness check: 200 while the
+// active connection is connected with a closed circuit breaker, 503
+// otherwise. The status is "degraded" when another connection is unhealthy.
+// Connections are not pinged here; the state comes from the health checks.
 func httpHealth(w http.ResponseWriter, r *http.Request) {
-	api.WriteSuccess(w, map[string]interface{}{
-		"status":  "healthy",
-		"service": "mysql-mcp-server",
-	})
+	if connManager == nil {
+		api.WriteError(w, http.StatusServiceUnavailable, "connection manager not initialized")
+		return
+	}
+
+	_, activeName := connManager.GetActive()
+	conns := connectionInfos(activeName)
+	status := "healthy"
+	for _, c := range conns {
+		if st, _ := connManager.Status(c.Name); !st.Healthy() {
+			status = "degraded"
+		}
+	}
+	data := map[string]interface{}{
+		"status":      status,
+		"service":     "mysql-mcp-server",
+		"active":      activeName,
+		"connections": conns,
+	}
+
+	if st, _ := connManager.Status(activeName); !st.Healthy() {
+		data["status"] = "unhealthy"
+		api.WriteJSON(w, http.StatusServiceUnavailable, api.Response{
+			Success: false,
+			Data:    data,
+			Error:   fmt.Sprintf("active connection '%s' is not ready", activeName),
+		})
+		return
+	}
+	api.WriteSuccess(w, data)
 }
 
 // httpAPIIndex handles GET /api
 func httpAPIIndex(w http.ResponseWriter, r *http.Request) {
+	conf := currentSettings()
 	endpoints := map[string]interface{}{
 		"service": "mysql-mcp-server REST API",
 		"version": Version,
 		"endpoints": map[string]string{
-			"GET  /health":              "Health check",
+			"GET  /health":              "Readiness check with connection health",
 			"GET  /api":                 "API index (this page)",
 			"GET  /api/databases":       "List databases",
 			"GET  /api/tables":          "List tables (requires ?database=)",
 			"GET  /api/describe":        "Describe table (requires ?database=&table=)",
-			"POST /api/query":           "Run SQL query (body: {sql, database?, max_rows?})",
+			"POST /api/query":           "Run SQL query (body: {sql, database?, max_rows?, lint?})",
 			"GET  /api/ping":            "Ping database",
 			"GET  /api/server-info":     "Get server info",
 			"GET  /api/connections":     "List connections",
 			"POST /api/connections/use": "Switch connection (body: {name})",
+			"GET  /api/cache":           "Result cache statistics [cache]",
+			"POST /api/cache/refresh":   "Drop cached results (body: {connection?, database?}) [cache]",
 			"GET  /api/indexes":         "List indexes (requires ?database=&table=) [extended]",
 			"GET  /api/create-table":    "Show CREATE TABLE (requires ?database=&table=) [extended]",
-			"POST /api/explain":         "Explain query (body: {sql, database?}) [extended]",
+			"POST /api/explain":         "Explain query (body: {sql, database?, format?, analyze?}) [extended]",
 			"GET  /api/views":           "List views (requires ?database=) [extended]",
 			"GET  /api/triggers":        "List triggers (requires ?database=) [extended]",
 			"GET  /api/procedures":      "List procedures (requires ?database=) [extended]",
@@ -439,12 +909,29 @@ func httpAPIIndex(w http.ResponseWriter, r *http.Request) {
 			"GET  /api/foreign-keys":    "Foreign keys (requires ?database=, optional &table=) [extended]",
 			"GET  /api/status":          "Server status (optional ?pattern=) [extended]",
 			"GET  /api/variables":       "Server variables (optional ?pattern=) [extended]",
+			"POST /api/profile":         "Profile table columns (body: {database, table, ...}) [extended]",
+			"POST /api/sample":          "Sample table rows (body: {database, table, n?, strategy?}) [extended]",
+			"POST /api/summary":         "Token-budgeted database summary (body: {database, max_tokens?}) [extended]",
+			"GET  /api/processes":       "Active sessions (optional ?user=, &host=, &db=, &command=, &min_time=, &state=) [extended]",
+			"GET  /api/lock-waits":      "Blocking chains for row and metadata locks (optional ?min_wait=) [extended]",
+			"GET  /api/top-queries":     "Top statement digests (optional ?order_by=, &schema=, &limit=, &window=) [extended]",
+			"GET  /api/innodb-status":   "Parsed InnoDB engine status (optional ?sections=) [extended]",
+			"GET  /api/replication":     "Replication and Group Replication status (optional ?all_connections=true) [extended]",
+			"GET  /api/index-advisor":   "Unused, redundant and missing index suggestions (requires ?database=, optional &table=, &checks=) [extended]",
+			"POST /api/lint":            "Query anti-pattern linter (body: {sql, database?}) [extended]",
+			"GET  /api/config-advisor":  "Server configuration findings (optional ?memory_bytes=, &rules=) [extended]",
+			"GET  /api/security-audit":  "Account and privilege audit (optional ?checks=, &include_locked=, &role_grant_limit=) [extended]",
+			"GET  /api/capacity":        "AUTO_INCREMENT and INT key headroom (optional ?database=, &window=) [extended]",
+			"POST /api/compare-plans":   "Compare two plans (body: {sql, right_sql?, database?, right_database?, connection?, right_connection?}) [extended]",
 			"POST /api/vector/search":   "Vector search (body: {...}) [vector]",
+			"POST /api/vector/hybrid":   "Hybrid full-text and vector search (body: {database, table, column, text_columns, query_text, ...}) [vector]",
 			"GET  /api/vector/info":     "Vector info (requires ?database=) [vector]",
+			"GET  /api/vector/stats":    "Vector column data quality (requires ?database=, &table=, &column=, optional &sample_size=, &duplicate_threshold=) [vector]",
+			"GET  /api/vector/ddl":      "Proposed VECTOR column DDL (requires ?database=, &table=, &column=, optional &dimensions=, &target=, &new_column=, &replace=) [vector]",
 		},
 		"modes": map[string]bool{
-			"extended": extendedMode,
-			"vector":   os.Getenv("MYSQL_MCP_VECTOR") == "1",
+			"extended": conf.extendedMode,
+			"vector":   conf.cfg.VectorMode,
 		},
 	}
 	api.WriteSuccess(w, endpoints)
@@ -452,6 +939,17 @@ func httpAPIIndex(w http.ResponseWriter, r *http.Request) {
 
 // ===== HTTP Server Setup =====
 
+// featureGate returns middleware that rejects requests while a feature is
+// disabled. The flag is read per request, so a config reload takes effect
+// without restarting the server.
+func featureGate(enabled func() bool, featureName string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			api.RequireFeature(enabled(), featureName, next)(w, r)
+		}
+	}
+}
+
 // httpLogger logs HTTP requests using the application's structured logging.
 func httpLogger(method, path string, status int, duration time.Duration) {
 	logInfo("http request", map[string]interface{}{
@@ -463,8 +961,9 @@ func httpLogger(method, path string, status int, duration time.Duration) {
 }
 
 // startHTTPServer starts the REST API server with graceful shutdown support.
-func startHTTPServer(port int, vectorMode bool) {
+func startHTTPServer(port int) {
 	mux := http.NewServeMux()
+	cfg := currentSettings().cfg
 
 	// Create rate limiter if enabled
 	var rateLimiter *api.RateLimiter
@@ -495,10 +994,13 @@ func startHTTPServer(port int, vectorMode bool) {
 	mux.HandleFunc("/api/connections", api.WithCORS(httpListConnections))
 	mux.HandleFunc("/api/connections/use", api.Chain(httpUseConnection, api.WithCORS, api.RequirePOST))
 
+	// Cache endpoints
+	cacheFeature := featureGate(func() bool { return currentSettings().resultCache != nil }, "result cache (set MYSQL_MCP_CACHE=1)")
+	mux.HandleFunc("/api/cache", api.Chain(httpCacheStats, api.WithCORS, cacheFeature))
+	mux.HandleFunc("/api/cache/refresh", api.Chain(httpRefreshCache, api.WithCORS, cacheFeature, api.RequirePOST))
+
 	// Extended endpoints
-	extendedFeature := func(next http.HandlerFunc) http.HandlerFunc {
-		return api.RequireFeature(extendedMode, "extended mode (set MYSQL_MCP_EXTENDED=1)", next)
-	}
+	extendedFeature := featureGate(func() bool { return currentSettings().extendedMode }, "extended mode (set MYSQL_MCP_EXTENDED=1)")
 	mux.HandleFunc("/api/indexes", api.Chain(httpListIndexes, api.WithCORS, extendedFeature, api.RequireQueryParams([]string{"database", "table"})))
 	mux.HandleFunc("/api/create-table", api.Chain(httpShowCreateTable, api.WithCORS, extendedFeature, api.RequireQueryParams([]string{"database", "table"})))
 	mux.HandleFunc("/api/explain", api.Chain(httpExplainQuery, api.WithCORS, extendedFeature, api.RequirePOST))
@@ -512,13 +1014,28 @@ func startHTTPServer(port int, vectorMode bool) {
 	mux.HandleFunc("/api/foreign-keys", api.Chain(httpForeignKeys, api.WithCORS, extendedFeature, api.RequireQueryParam("database")))
 	mux.HandleFunc("/api/status", api.Chain(httpListStatus, api.WithCORS, extendedFeature))
 	mux.HandleFunc("/api/variables", api.Chain(httpListVariables, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/profile", api.Chain(httpProfileTable, api.WithCORS, extendedFeature, api.RequirePOST))
+	mux.HandleFunc("/api/sample", api.Chain(httpSampleRows, api.WithCORS, extendedFeature, api.RequirePOST))
+	mux.HandleFunc("/api/summary", api.Chain(httpSummarizeDatabase, api.WithCORS, extendedFeature, api.RequirePOST))
+	mux.HandleFunc("/api/processes", api.Chain(httpListProcesses, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/lock-waits", api.Chain(httpLockWaits, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/top-queries", api.Chain(httpTopQueries, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/innodb-status", api.Chain(httpInnodbStatus, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/replication", api.Chain(httpReplicationStatus, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/index-advisor", api.Chain(httpIndexAdvisor, api.WithCORS, extendedFeature, api.RequireQueryParam("database")))
+	mux.HandleFunc("/api/lint", api.Chain(httpLintQuery, api.WithCORS, extendedFeature, api.RequirePOST))
+	mux.HandleFunc("/api/config-advisor", api.Chain(httpConfigAdvisor, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/security-audit", api.Chain(httpSecurityAudit, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/capacity", api.Chain(httpCapacityCheck, api.WithCORS, extendedFeature))
+	mux.HandleFunc("/api/compare-plans", api.Chain(httpComparePlans, api.WithCORS, extendedFeature, api.RequirePOST))
 
 	// Vector endpoints
-	vectorFeature := func(next http.HandlerFunc) http.HandlerFunc {
-		return api.RequireFeature(vectorMode, "vector mode (set MYSQL_MCP_VECTOR=1)", next)
-	}
+	vectorFeature := featureGate(func() bool { return currentSettings().cfg.VectorMode }, "vector mode (set MYSQL_MCP_VECTOR=1)")
 	mux.HandleFunc("/api/vector/search", api.Chain(httpVectorSearch, api.WithCORS, vectorFeature, api.RequirePOST))
+	mux.HandleFunc("/api/vector/hybrid", api.Chain(httpHybridSearch, api.WithCORS, vectorFeature, api.RequirePOST))
 	mux.HandleFunc("/api/vector/info", api.Chain(httpVectorInfo, api.WithCORS, vectorFeature, api.RequireQueryParam("database")))
+	mux.HandleFunc("/api/vector/stats", api.Chain(httpVectorStats, api.WithCORS, vectorFeature, api.RequireQueryParam("database"), api.RequireQueryParam("table"), api.RequireQueryParam("column")))
+	mux.HandleFunc("/api/vector/ddl", api.Chain(httpVectorDDL, api.WithCORS, vectorFeature, api.RequireQueryParam("database"), api.RequireQueryParam("table"), api.RequireQueryParam("column")))
 
 	addr := fmt.Sprintf(":%d", port)
 
@@ -545,8 +1062,8 @@ func startHTTPServer(port int, vectorMode bool) {
 		logInfo("HTTP REST API server starting", map[string]interface{}{
 			"port":         port,
 			"address":      "http://localhost" + addr,
-			"extendedMode": extendedMode,
-			"vectorMode":   vectorMode,
+			"extendedMode": cfg.ExtendedMode,
+			"vectorMode":   cfg.VectorMode,
 			"version":      Version,
 		})
 
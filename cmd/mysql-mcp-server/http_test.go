@@ -3,6 +3,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -40,10 +41,6 @@ func setupHTTPTestFull(t *testing.T) httpMockResult {
 
 	// Save original state
 	oldConnManager := connManager
-	oldCfg := cfg
-	oldMaxRows := maxRows
-	oldQueryTimeout := queryTimeout
-	oldExtendedMode := extendedMode
 
 	// Set up mock connection manager with mock DB
 	cm := NewConnectionManager()
@@ -52,21 +49,19 @@ func setupHTTPTestFull(t *testing.T) httpMockResult {
 	cm.activeConn = "mock"
 	connManager = cm
 
-	maxRows = 1000
-	queryTimeout = 30 * time.Second
-	extendedMode = true
-	cfg = &config.Config{
-		HTTPRequestTimeout: 30 * time.Second,
-		MaxRows:            1000,
-		QueryTimeout:       30 * time.Second,
-	}
+	setSettings(t, func(s *settings) {
+		s.cfg = &config.Config{
+			HTTPRequestTimeout: 30 * time.Second,
+			MaxRows:            1000,
+			QueryTimeout:       30 * time.Second,
+		}
+		s.maxRows = 1000
+		s.queryTimeout = 30 * time.Second
+		s.extendedMode = true
+	})
 
 	cleanup := func() {
 		connManager = oldConnManager
-		cfg = oldCfg
-		maxRows = oldMaxRows
-		queryTimeout = oldQueryTimeout
-		extendedMode = oldExtendedMode
 		mockDB.Close()
 	}
 
@@ -75,6 +70,12 @@ func setupHTTPTestFull(t *testing.T) httpMockResult {
 
 // TestHTTPHealth tests the /health endpoint
 func TestHTTPHealth(t *testing.T) {
+	setup := setupHTTPTestFull(t)
+	defer setup.cleanup()
+	if err := connManager.Ready(context.Background(), "mock"); err != nil {
+		t.Fatalf("Ready failed: %v", err)
+	}
+
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -105,6 +106,33 @@ func TestHTTPHealth(t *testing.T) {
 	if data["service"] != "mysql-mcp-server" {
 		t.Errorf("expected service 'mysql-mcp-server', got '%v'", data["service"])
 	}
+	if conns, _ := data["connections"].([]interface{}); len(conns) != 1 {
+		t.Errorf("expected one connection, got %v", data["connections"])
+	}
+}
+
+// TestHTTPHealthNotReady tests /health before the active connection is up
+func TestHTTPHealthNotReady(t *testing.T) {
+	result := setupHTTPTestFull(t)
+	defer result.cleanup()
+
+	// The mock connection has not been checked yet, so it is degraded.
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	httpHealth(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+	var body api.Response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	data, _ := body.Data.(map[string]interface{})
+	if body.Success || data["status"] != "unhealthy" || !strings.Contains(body.Error, "'mock' is not ready") {
+		t.Errorf("unexpected response: %+v", body)
+	}
 }
 
 // TestHTTPAPIIndex tests the /api endpoint
@@ -776,6 +804,21 @@ func TestHTTPListStatus(t *testing.T) {
 	}
 }
 
+// TestHTTPListProcessesInvalidMinTime tests /api/processes rejects a malformed min_time
+func TestHTTPListProcessesInvalidMinTime(t *testing.T) {
+	_, cleanup := setupHTTPTest(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/processes?min_time=soon", nil)
+	w := httptest.NewRecorder()
+
+	httpListProcesses(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Result().StatusCode)
+	}
+}
+
 // TestHTTPListVariables tests the /api/variables endpoint (extended)
 func TestHTTPListVariables(t *testing.T) {
 	mock, cleanup := setupHTTPTest(t)
@@ -0,0 +1,332 @@
+// cmd/mysql-mcp-server/innodb_status.go
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// innodbSections lists the sections innodb_status can return, keyed by the
+// name used in the tool input.
+var innodbSections = map[string]bool{
+	"deadlock":       true,
+	"buffer_pool":    true,
+	"transactions":   true,
+	"io":             true,
+	"semaphores":     true,
+	"log":            true,
+	"row_operations": true,
+}
+
+var (
+	reMonitorTime   = regexp.MustCompile(`(?m)^(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2})\S* \S+ INNODB MONITOR OUTPUT`)
+	reAveragesOver  = regexp.MustCompile(`averages calculated from the last (\d+) seconds`)
+	reDeadlockTrx   = regexp.MustCompile(`^\*\*\* \((\d+)\) TRANSACTION:`)
+	reDeadlockHolds = regexp.MustCompile(`^\*\*\* \((\d+)\) HOLDS THE LOCK`)
+	reDeadlockWaits = regexp.MustCompile(`^\*\*\* \((\d+)\) WAITING FOR THIS LOCK`)
+	reDeadlockVictm = regexp.MustCompile(`^\*\*\* WE ROLL BACK TRANSACTION \((\d+)\)`)
+	reTrxHeader     = regexp.MustCompile(`^TRANSACTION (\d+), ACTIVE (\d+ sec)`)
+	reThreadID      = regexp.MustCompile(`^MySQL thread id (\d+)`)
+	reLockIndex     = regexp.MustCompile(`index (\S+) of table (\S+)`)
+	reLockTable     = regexp.MustCompile(`^TABLE LOCK table (\S+)`)
+	reLockMode      = regexp.MustCompile(`lock[_ ]mode (.*?)(?: waiting)?$`)
+	reAIOPending    = regexp.MustCompile(`Pending normal aio reads:[^\[]*\[([^\]]*)\][^\[]*aio writes:[^\[]*\[([^\]]*)\]`)
+	reHitRate       = regexp.MustCompile(`Buffer pool hit rate (\d+) / (\d+)`)
+)
+
+// reFloatRate captures the number in front of a per-second rate such as "0.52 reads/s".
+const reFloatRate = `([\d.]+) `
+
+// Patterns for the per-section counters and rates.
+var (
+	reBufferPoolSize   = regexp.MustCompile(`Buffer pool size\s+(\d+)`)
+	reFreeBuffers      = regexp.MustCompile(`Free buffers\s+(\d+)`)
+	reDatabasePages    = regexp.MustCompile(`Database pages\s+(\d+)`)
+	reModifiedPages    = regexp.MustCompile(`Modified db pages\s+(\d+)`)
+	rePagesReadRate    = regexp.MustCompile(reFloatRate + `reads/s, [\d.]+ creates/s`)
+	rePagesWriteRate   = regexp.MustCompile(`creates/s, ` + reFloatRate + `writes/s`)
+	reTrxIDCounter     = regexp.MustCompile(`Trx id counter (\d+)`)
+	reHistoryLength    = regexp.MustCompile(`History list length (\d+)`)
+	rePendingLogFsync  = regexp.MustCompile(`Pending flushes \(fsync\) log: (\d+)`)
+	rePendingPoolFsync = regexp.MustCompile(`Pending flushes \(fsync\) log: \d+; buffer pool: (\d+)`)
+	reIOReadRate       = regexp.MustCompile(reFloatRate + `reads/s, \d+ avg bytes/read`)
+	reIOWriteRate      = regexp.MustCompile(`avg bytes/read, ` + reFloatRate + `writes/s`)
+	reIOFsyncRate      = regexp.MustCompile(`writes/s, ` + reFloatRate + `fsyncs/s`)
+	reReservations     = regexp.MustCompile(`reservation count (\d+)`)
+	reSignals          = regexp.MustCompile(`signal count (\d+)`)
+	reLogSequence      = regexp.MustCompile(`Log sequence number\s+(\d+)`)
+	reLogFlushed       = regexp.MustCompile(`Log flushed up to\s+(\d+)`)
+	reLogPagesFlushed  = regexp.MustCompile(`Pages flushed up to\s+(\d+)`)
+	reLogCheckpoint    = regexp.MustCompile(`Last checkpoint at\s+(\d+)`)
+	reQueriesInside    = regexp.MustCompile(`(\d+) queries inside InnoDB`)
+	reQueriesInQueue   = regexp.MustCompile(`(\d+) queries in queue`)
+	reReadViews        = regexp.MustCompile(`(\d+) read views open`)
+	reInsertRate       = regexp.MustCompile(`(?m)^` + reFloatRate + `inserts/s`)
+	reUpdateRate       = regexp.MustCompile(`(?m)^[\d.]+ inserts/s, ` + reFloatRate + `updates/s`)
+	reDeleteRate       = regexp.MustCompile(`(?m)^[\d.]+ inserts/s, [\d.]+ updates/s, ` + reFloatRate + `deletes/s`)
+	reRowReadRate      = regexp.MustCompile(`(?m)^[\d.]+ inserts/s, [\d.]+ updates/s, [\d.]+ deletes/s, ` + reFloatRate + `reads/s`)
+)
+
+// innodbSectionTitles maps monitor section headings to tool section names.
+var innodbSectionTitles = map[string]string{
+	"LATEST DETECTED DEADLOCK": "deadlock",
+	"BUFFER POOL AND MEMORY":   "buffer_pool",
+	"TRANSACTIONS":             "transactions",
+	"FILE I/O":                 "io",
+	"SEMAPHORES":               "semaphores",
+	"LOG":                      "log",
+	"ROW OPERATIONS":           "row_operations",
+}
+
+// splitInnodbSections splits SHOW ENGINE INNODB STATUS output into its
+// sections. Each heading is a title line framed by lines of dashes.
+func splitInnodbSections(status string) map[string][]string {
+	lines := strings.Split(strings.ReplaceAll(status, "\r\n", "\n"), "\n")
+	sections := make(map[string][]string)
+	current := ""
+	for i := 0; i < len(lines); i++ {
+		if i+2 < len(lines) && isDashLine(lines[i]) && isDashLine(lines[i+2]) && !isDashLine(lines[i+1]) {
+			current = innodbSectionTitles[strings.TrimSpace(lines[i+1])]
+			i += 2
+			continue
+		}
+		if current != "" {
+			sections[current] = append(sections[current], lines[i])
+		}
+	}
+	return sections
+}
+
+func isDashLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) >= 3 && strings.Trim(line, "-") == ""
+}
+
+// parseInnodbStatus parses the sections selected in want (all when empty).
+func parseInnodbStatus(status string, want map[string]bool) InnodbStatusOutput {
+	var out InnodbStatusOutput
+	if m := reMonitorTime.FindStringSubmatch(status); m != nil {
+		out.Time = m[1]
+	}
+	if m := reAveragesOver.FindStringSubmatch(status); m != nil {
+		out.AveragesOverSecs, _ = strconv.ParseInt(m[1], 10, 64)
+	}
+
+	selected := func(name string) bool { return len(want) == 0 || want[name] }
+	sections := splitInnodbSections(status)
+
+	if lines, ok := sections["deadlock"]; ok && selected("deadlock") {
+		out.Deadlock = parseInnodbDeadlock(lines)
+	}
+	if lines, ok := sections["buffer_pool"]; ok && selected("buffer_pool") {
+		out.BufferPool = parseInnodbBufferPool(strings.Join(lines, "\n"))
+	}
+	if lines, ok := sections["transactions"]; ok && selected("transactions") {
+		out.Transactions = parseInnodbTransactions(lines)
+	}
+	if lines, ok := sections["io"]; ok && selected("io") {
+		out.IO = parseInnodbIO(strings.Join(lines, "\n"))
+	}
+	if lines, ok := sections["semaphores"]; ok && selected("semaphores") {
+		out.Semaphores = parseInnodbSemaphores(lines)
+	}
+	if lines, ok := sections["log"]; ok && selected("log") {
+		out.Log = parseInnodbLog(strings.Join(lines, "\n"))
+	}
+	if lines, ok := sections["row_operations"]; ok && selected("row_operations") {
+		out.RowOperations = parseInnodbRowOperations(strings.Join(lines, "\n"))
+	}
+	return out
+}
+
+func parseInnodbDeadlock(lines []string) *InnodbDeadlock {
+	d := &InnodbDeadlock{Transactions: []InnodbDeadlockTransaction{}}
+	var trx *InnodbDeadlockTransaction
+	var locks *[]InnodbLock
+	inQuery := false
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, " ")
+		if d.Time == "" && len(line) >= 19 && line[4] == '-' {
+			d.Time = line[:19]
+			continue
+		}
+		if m := reDeadlockTrx.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			d.Transactions = append(d.Transactions, InnodbDeadlockTransaction{Number: n})
+			trx = &d.Transactions[len(d.Transactions)-1]
+			locks, inQuery = nil, false
+			continue
+		}
+		if m := reDeadlockVictm.FindStringSubmatch(line); m != nil {
+			d.RolledBack, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if trx == nil {
+			continue
+		}
+		switch {
+		case reDeadlockHolds.MatchString(line):
+			locks, inQuery = &trx.Holds, false
+		case reDeadlockWaits.MatchString(line):
+			locks, inQuery = &trx.WaitsFor, false
+		case strings.HasPrefix(line, "RECORD LOCKS ") || strings.HasPrefix(line, "TABLE LOCK "):
+			if locks != nil {
+				*locks = append(*locks, parseInnodbLock(line))
+			}
+		case reTrxHeader.MatchString(line):
+			m := reTrxHeader.FindStringSubmatch(line)
+			trx.TrxID, trx.Active = m[1], m[2]
+		case reThreadID.MatchString(line):
+			trx.ThreadID, _ = strconv.ParseInt(reThreadID.FindStringSubmatch(line)[1], 10, 64)
+			inQuery = true // the statement follows on the next lines
+		case inQuery:
+			if line == "" || strings.HasPrefix(line, "***") {
+				inQuery = false
+				continue
+			}
+			if trx.Query != "" {
+				trx.Query += "\n"
+			}
+			trx.Query = truncateString(trx.Query+line, maxStatementLen)
+		}
+	}
+	if len(d.Transactions) == 0 {
+		return nil
+	}
+	return d
+}
+
+// parseInnodbLock parses a lock line such as
+// "RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table `db`.`t` trx id 5 lock_mode X locks rec but not gap".
+func parseInnodbLock(line string) InnodbLock {
+	var l InnodbLock
+	if m := reLockIndex.FindStringSubmatch(line); m != nil {
+		l.Type, l.Index, l.Table = "RECORD", m[1], m[2]
+	} else if m := reLockTable.FindStringSubmatch(line); m != nil {
+		l.Type, l.Table = "TABLE", m[1]
+	}
+	if m := reLockMode.FindStringSubmatch(line); m != nil {
+		l.Mode = m[1]
+	}
+	return l
+}
+
+func parseInnodbBufferPool(text string) *InnodbBufferPool {
+	// With several instances the totals come first, followed by one block per
+	// instance, so only the first match of each field is used.
+	bp := &InnodbBufferPool{
+		SizePages:          matchInt(text, reBufferPoolSize),
+		FreePages:          matchInt(text, reFreeBuffers),
+		DatabasePages:      matchInt(text, reDatabasePages),
+		ModifiedPages:      matchInt(text, reModifiedPages),
+		PagesReadPerSec:    matchFloat(text, rePagesReadRate),
+		PagesWrittenPerSec: matchFloat(text, rePagesWriteRate),
+	}
+	if m := reHitRate.FindStringSubmatch(text); m != nil {
+		hits, _ := strconv.ParseFloat(m[1], 64)
+		total, _ := strconv.ParseFloat(m[2], 64)
+		if total > 0 {
+			rate := roundTo(hits/total, 4)
+			bp.HitRate = &rate
+		}
+	}
+	return bp
+}
+
+func parseInnodbTransactions(lines []string) *InnodbTransactions {
+	text := strings.Join(lines, "\n")
+	t := &InnodbTransactions{
+		TrxIDCounter:      matchInt(text, reTrxIDCounter),
+		HistoryListLength: matchInt(text, reHistoryLength),
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "---TRANSACTION ") && strings.Contains(line, ", ACTIVE") {
+			t.ActiveTransactions++
+		}
+	}
+	return t
+}
+
+func parseInnodbIO(text string) *InnodbIO {
+	io := &InnodbIO{
+		PendingLogFsyncs:       matchInt(text, rePendingLogFsync),
+		PendingBufferPoolFsync: matchInt(text, rePendingPoolFsync),
+		ReadsPerSec:            matchFloat(text, reIOReadRate),
+		WritesPerSec:           matchFloat(text, reIOWriteRate),
+		FsyncsPerSec:           matchFloat(text, reIOFsyncRate),
+	}
+	if m := reAIOPending.FindStringSubmatch(text); m != nil {
+		io.PendingReads = sumIntList(m[1])
+		io.PendingWrites = sumIntList(m[2])
+	}
+	return io
+}
+
+func parseInnodbSemaphores(lines []string) *InnodbSemaphores {
+	text := strings.Join(lines, "\n")
+	s := &InnodbSemaphores{
+		ReservationCount: matchInt(text, reReservations),
+		SignalCount:      matchInt(text, reSignals),
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "--Thread ") {
+			s.Waits = append(s.Waits, truncateString(strings.TrimPrefix(line, "--"), maxStatementLen))
+		}
+	}
+	return s
+}
+
+func parseInnodbLog(text string) *InnodbLog {
+	l := &InnodbLog{
+		SequenceNumber:   matchInt(text, reLogSequence),
+		FlushedUpTo:      matchInt(text, reLogFlushed),
+		PagesFlushedUpTo: matchInt(text, reLogPagesFlushed),
+		LastCheckpoint:   matchInt(text, reLogCheckpoint),
+	}
+	l.CheckpointAge = l.SequenceNumber - l.LastCheckpoint
+	return l
+}
+
+func parseInnodbRowOperations(text string) *InnodbRowOperations {
+	return &InnodbRowOperations{
+		QueriesInside:  matchInt(text, reQueriesInside),
+		QueriesInQueue: matchInt(text, reQueriesInQueue),
+		ReadViews:      matchInt(text, reReadViews),
+		InsertsPerSec:  matchFloat(text, reInsertRate),
+		UpdatesPerSec:  matchFloat(text, reUpdateRate),
+		DeletesPerSec:  matchFloat(text, reDeleteRate),
+		ReadsPerSec:    matchFloat(text, reRowReadRate),
+	}
+}
+
+// matchInt returns the first capture group of re in text as an integer (0 if absent).
+func matchInt(text string, re *regexp.Regexp) int64 {
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(m[1], 10, 64)
+	return n
+}
+
+// matchFloat returns the first capture group of re in text as a float (0 if absent).
+func matchFloat(text string, re *regexp.Regexp) float64 {
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(m[1], 64)
+	return f
+}
+
+// sumIntList sums a comma-separated list of integers such as "0, 1, 0, 0".
+func sumIntList(list string) int64 {
+	var sum int64
+	for _, part := range strings.Split(list, ",") {
+		n, _ := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		sum += n
+	}
+	return sum
+}
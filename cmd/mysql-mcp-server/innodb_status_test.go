@@ -0,0 +1,211 @@
+// cmd/mysql-mcp-server/innodb_status_test.go
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleInnodbStatus = `
+=====================================
+2024-03-01 10:15:42 0x7f3a2c1f8700 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 20 seconds
+-----------------
+BACKGROUND THREAD
+-----------------
+srv_master_thread loops: 100 srv_active, 0 srv_shutdown, 5000 srv_idle
+----------
+SEMAPHORES
+----------
+OS WAIT ARRAY INFO: reservation count 1234
+--Thread 139876 has waited at btr0sea.ic line 92 for 2.00 seconds the semaphore:
+S-lock on RW-latch at 0x7f3a created in file btr0sea.cc line 202
+OS WAIT ARRAY INFO: signal count 1200
+RW-shared spins 0, rounds 0, OS waits 0
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2024-03-01 10:10:00 0x7f3a2c0f6700
+*** (1) TRANSACTION:
+TRANSACTION 5001, ACTIVE 5 sec starting index read
+mysql tables in use 1, locked 1
+LOCK WAIT 3 lock struct(s), heap size 1128, 2 row lock(s)
+MySQL thread id 11, OS thread handle 139876, query id 100 localhost app updating
+UPDATE orders SET status = 'paid' WHERE id = 2
+
+*** (1) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`shop`.`orders`" + ` trx id 5001 lock_mode X locks rec but not gap
+Record lock, heap no 2 PHYSICAL RECORD: n_fields 5; compact format; info bits 0
+
+*** (1) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`shop`.`orders`" + ` trx id 5001 lock_mode X locks rec but not gap waiting
+Record lock, heap no 3 PHYSICAL RECORD: n_fields 5; compact format; info bits 0
+
+*** (2) TRANSACTION:
+TRANSACTION 5002, ACTIVE 3 sec starting index read
+mysql tables in use 1, locked 1
+MySQL thread id 12, OS thread handle 139877, query id 101 localhost app updating
+UPDATE orders SET status = 'shipped' WHERE id = 1
+
+*** (2) HOLDS THE LOCK(S):
+TABLE LOCK table ` + "`shop`.`orders`" + ` trx id 5002 lock mode IX
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`shop`.`orders`" + ` trx id 5002 lock_mode X locks rec but not gap
+
+*** (2) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`shop`.`orders`" + ` trx id 5002 lock_mode X locks rec but not gap waiting
+
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 5010
+Purge done for trx's n:o < 5000 undo n:o < 0 state: running but idle
+History list length 42
+LIST OF TRANSACTIONS FOR EACH SESSION:
+---TRANSACTION 421, not started
+---TRANSACTION 5009, ACTIVE 12 sec
+---TRANSACTION 5008, ACTIVE 2 sec inserting
+--------
+FILE I/O
+--------
+I/O thread 0 state: waiting for completed aio requests (insert buffer thread)
+Pending normal aio reads: [1, 0, 2, 0] , aio writes: [0, 0, 0, 3] ,
+ ibuf aio reads:, log i/o's:, sync i/o's:
+Pending flushes (fsync) log: 1; buffer pool: 4
+1234 OS file reads, 5678 OS file writes, 910 OS fsyncs
+1.50 reads/s, 16384 avg bytes/read, 2.25 writes/s, 0.75 fsyncs/s
+---
+LOG
+---
+Log sequence number          19553440
+Log buffer assigned up to    19553440
+Log flushed up to            19553400
+Pages flushed up to          19550000
+Last checkpoint at           19540000
+18 log i/o's done, 0.00 log i/o's/second
+----------------------
+BUFFER POOL AND MEMORY
+----------------------
+Total large memory allocated 0
+Buffer pool size   8192
+Free buffers       1035
+Database pages     7153
+Old database pages 2620
+Modified db pages  120
+Pages read 1011, created 142, written 159
+4.10 reads/s, 0.50 creates/s, 3.20 writes/s
+Buffer pool hit rate 995 / 1000, young-making rate 0 / 1000 not 0 / 1000
+----------------------
+INDIVIDUAL BUFFER POOL INFO
+----------------------
+---BUFFER POOL 0
+Buffer pool size   4096
+Free buffers       500
+--------------
+ROW OPERATIONS
+--------------
+2 queries inside InnoDB, 1 queries in queue
+3 read views open inside InnoDB
+Process ID=1, Main thread ID=139870, state=sleeping
+Number of rows inserted 100, updated 50, deleted 5, read 9000
+10.00 inserts/s, 5.50 updates/s, 0.25 deletes/s, 450.00 reads/s
+Number of system rows inserted 0, updated 0, deleted 0, read 0
+0.00 inserts/s, 0.00 updates/s, 0.00 deletes/s, 0.00 reads/s
+----------------------------
+END OF INNODB MONITOR OUTPUT
+============================
+`
+
+func TestParseInnodbStatusAllSections(t *testing.T) {
+	out := parseInnodbStatus(sampleInnodbStatus, nil)
+
+	if out.Time != "2024-03-01 10:15:42" || out.AveragesOverSecs != 20 {
+		t.Errorf("unexpected header: %q, %d", out.Time, out.AveragesOverSecs)
+	}
+
+	if out.Semaphores == nil || out.Semaphores.ReservationCount != 1234 || out.Semaphores.SignalCount != 1200 {
+		t.Errorf("unexpected semaphores: %+v", out.Semaphores)
+	} else if len(out.Semaphores.Waits) != 1 || !strings.HasPrefix(out.Semaphores.Waits[0], "Thread 139876") {
+		t.Errorf("unexpected semaphore waits: %v", out.Semaphores.Waits)
+	}
+
+	if out.Transactions == nil || out.Transactions.TrxIDCounter != 5010 ||
+		out.Transactions.HistoryListLength != 42 || out.Transactions.ActiveTransactions != 2 {
+		t.Errorf("unexpected transactions: %+v", out.Transactions)
+	}
+
+	if io := out.IO; io == nil || io.PendingReads != 3 || io.PendingWrites != 3 ||
+		io.PendingLogFsyncs != 1 || io.PendingBufferPoolFsync != 4 ||
+		io.ReadsPerSec != 1.5 || io.WritesPerSec != 2.25 || io.FsyncsPerSec != 0.75 {
+		t.Errorf("unexpected io: %+v", out.IO)
+	}
+
+	if l := out.Log; l == nil || l.SequenceNumber != 19553440 || l.FlushedUpTo != 19553400 ||
+		l.PagesFlushedUpTo != 19550000 || l.LastCheckpoint != 19540000 || l.CheckpointAge != 13440 {
+		t.Errorf("unexpected log: %+v", out.Log)
+	}
+
+	bp := out.BufferPool
+	if bp == nil || bp.SizePages != 8192 || bp.FreePages != 1035 || bp.DatabasePages != 7153 ||
+		bp.ModifiedPages != 120 || bp.PagesReadPerSec != 4.1 || bp.PagesWrittenPerSec != 3.2 {
+		t.Errorf("unexpected buffer pool: %+v", bp)
+	} else if bp.HitRate == nil || *bp.HitRate != 0.995 {
+		t.Errorf("unexpected hit rate: %v", bp.HitRate)
+	}
+
+	if r := out.RowOperations; r == nil || r.QueriesInside != 2 || r.QueriesInQueue != 1 || r.ReadViews != 3 ||
+		r.InsertsPerSec != 10 || r.UpdatesPerSec != 5.5 || r.DeletesPerSec != 0.25 || r.ReadsPerSec != 450 {
+		t.Errorf("unexpected row operations: %+v", out.RowOperations)
+	}
+}
+
+func TestParseInnodbStatusDeadlock(t *testing.T) {
+	out := parseInnodbStatus(sampleInnodbStatus, map[string]bool{"deadlock": true})
+
+	if out.BufferPool != nil || out.Log != nil || out.Transactions != nil {
+		t.Error("only the deadlock section should be parsed")
+	}
+	d := out.Deadlock
+	if d == nil {
+		t.Fatal("expected a deadlock")
+	}
+	if d.Time != "2024-03-01 10:10:00" || d.RolledBack != 2 || len(d.Transactions) != 2 {
+		t.Fatalf("unexpected deadlock: %+v", d)
+	}
+
+	first := d.Transactions[0]
+	if first.TrxID != "5001" || first.Active != "5 sec" || first.ThreadID != 11 ||
+		first.Query != "UPDATE orders SET status = 'paid' WHERE id = 2" {
+		t.Errorf("unexpected first transaction: %+v", first)
+	}
+	if len(first.Holds) != 1 || len(first.WaitsFor) != 1 {
+		t.Fatalf("unexpected first transaction locks: %+v", first)
+	}
+	wait := first.WaitsFor[0]
+	if wait.Type != "RECORD" || wait.Index != "PRIMARY" || wait.Table != "`shop`.`orders`" || wait.Mode != "X locks rec but not gap" {
+		t.Errorf("unexpected waited lock: %+v", wait)
+	}
+
+	second := d.Transactions[1]
+	if len(second.Holds) != 2 || second.Holds[0].Type != "TABLE" || second.Holds[0].Mode != "IX" {
+		t.Errorf("unexpected second transaction locks: %+v", second.Holds)
+	}
+}
+
+func TestParseInnodbStatusNoDeadlockOrPageGets(t *testing.T) {
+	status := `
+------------------------
+BUFFER POOL AND MEMORY
+------------------------
+Buffer pool size   8192
+No buffer pool page gets since the last printout
+`
+	out := parseInnodbStatus(status, nil)
+	if out.Deadlock != nil {
+		t.Error("expected no deadlock section")
+	}
+	if out.BufferPool == nil || out.BufferPool.SizePages != 8192 || out.BufferPool.HitRate != nil {
+		t.Errorf("unexpected buffer pool: %+v", out.BufferPool)
+	}
+}
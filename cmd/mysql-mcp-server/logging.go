@@ -66,6 +66,7 @@ type AuditEntry struct {
 	OutputTokens int    `json:"output_tokens,omitempty"`
 	Success      bool   `json:"success"`
 	Error        string `json:"error,omitempty"`
+	Cached       bool   `json:"cached,omitempty"` // answered from the result cache
 	// Token efficiency metrics
 	TokensPerRow    float64 `json:"tokens_per_row,omitempty"`
 	IOEfficiency    float64 `json:"io_efficiency,omitempty"`
@@ -2,16 +2,24 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/askdba/mysql-mcp-server/internal/advisor"
+	"github.com/askdba/mysql-mcp-server/internal/cache"
 	"github.com/askdba/mysql-mcp-server/internal/config"
+	"github.com/askdba/mysql-mcp-server/internal/embedding"
+	"github.com/askdba/mysql-mcp-server/internal/secrets"
 	"github.com/askdba/mysql-mcp-server/internal/util"
 )
 
@@ -24,30 +32,99 @@ var (
 
 // ===== Global State =====
 
-// Global configuration and state shared by all tools.
+// Global state shared by all tools.
 var (
-	cfg         *config.Config
 	connManager *ConnectionManager
 	auditLogger *AuditLogger
 
-	// Convenience aliases from config (for tool access)
-	maxRows        int
-	queryTimeout   time.Duration
-	pingTimeout    time.Duration
-	extendedMode   bool
+	// live holds the current settings; a config reload replaces them
+	live atomic.Pointer[settings]
+
+	// Logging and token tracking settings (fixed at startup)
 	jsonLogging    bool
 	tokenTracking  bool
 	tokenModel     string
 	tokenEstimator TokenEstimator
 )
 
+// settings are the configuration-derived values read by tools. They are
+// never modified once published; a config reload stores a new value.
+type settings struct {
+	cfg *config.Config
+
+	// Convenience aliases from config (for tool access)
+	maxRows         int
+	queryTimeout    time.Duration
+	pingTimeout     time.Duration
+	extendedMode    bool
+	lintQueries     bool
+	disableRawWhere bool
+
+	// columnMasker hides values of sensitive columns (nil when no rules are configured)
+	columnMasker *util.ColumnMasker
+
+	// advisorRules are the config_advisor rules (built-in plus rules file)
+	advisorRules []advisor.Rule
+
+	// embedder embeds vector_search query_text (nil when no provider is configured)
+	embedder *embedding.Embedder
+
+	// resultCache holds tool results (nil when the result cache is disabled)
+	resultCache cache.Store
+}
+
+// newSettings returns the settings for c with the given rules, embedder
+// and result cache.
+func newSettings(c *config.Config, rules []advisor.Rule, emb *embedding.Embedder, store cache.Store) *settings {
+	return &settings{
+		cfg:             c,
+		maxRows:         c.MaxRows,
+		queryTimeout:    c.QueryTimeout,
+		pingTimeout:     c.PingTimeout,
+		extendedMode:    c.ExtendedMode,
+		lintQueries:     c.LintQueries,
+		disableRawWhere: c.DisableRawWhere,
+		columnMasker:    util.NewColumnMasker(c.MaskColumns),
+		advisorRules:    rules,
+		embedder:        emb,
+		resultCache:     store,
+	}
+}
+
+// currentSettings returns the settings in effect. They are zero before the
+// configuration is loaded.
+func currentSettings() *settings {
+	if s := live.Load(); s != nil {
+		return s
+	}
+	return &settings{}
+}
+
+// settingsKey is the context key of a tool call's settings snapshot.
+type settingsKey struct{}
+
+// withSettings returns ctx carrying the settings snapshot s.
+func withSettings(ctx context.Context, s *settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, s)
+}
+
+// settingsFrom returns the settings snapshot taken for the tool call of
+// ctx, or the current settings outside of a tool call.
+func settingsFrom(ctx context.Context) *settings {
+	if s, ok := ctx.Value(settingsKey{}).(*settings); ok {
+		return s
+	}
+	return currentSettings()
+}
+
 // ===== Argument Parsing =====
 
 // parsedArgs holds the result of command-line argument parsing.
 type parsedArgs struct {
-	action       string // "", "version", "help", "print-config", "validate-config"
+	action       string // "", "version", "help", "print-config", "validate-config", "encrypt-secrets"
 	configPath   string // path from --config or --config=
 	validatePath string // path for --validate-config
+	secretsPath  string // path for --encrypt-secrets
 	err          error  // parsing error (e.g., unknown flag)
 }
 
@@ -84,6 +161,14 @@ func parseArgs(args []string) parsedArgs {
 			result.action = "validate-config"
 			result.validatePath = args[0]
 			args = args[1:]
+		case "--encrypt-secrets":
+			if len(args) < 1 {
+				result.err = fmt.Errorf("--encrypt-secrets requires a path argument")
+				return result
+			}
+			result.action = "encrypt-secrets"
+			result.secretsPath = args[0]
+			args = args[1:]
 		default:
 			// Check if it's --config=path format
 			if len(arg) > 9 && arg[:9] == "--config=" {
@@ -132,25 +217,42 @@ func main() {
 	case "validate-config":
 		handleValidateConfig(parsed.validatePath)
 		os.Exit(0)
+	case "encrypt-secrets":
+		handleEncryptSecrets(parsed.secretsPath)
+		os.Exit(0)
 	}
 
-	var err error
-
 	// ---- Load configuration ----
-	cfg, err = config.Load()
+	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("config error: %v", err)
 	}
 
-	// Set convenience aliases
-	maxRows = cfg.MaxRows
-	queryTimeout = cfg.QueryTimeout
-	pingTimeout = cfg.PingTimeout
-	extendedMode = cfg.ExtendedMode
+	// Set logging aliases
 	jsonLogging = cfg.JSONLogging
 	tokenTracking = cfg.TokenTracking
 	tokenModel = cfg.TokenModel
 
+	rules, err := advisor.LoadRules(cfg.AdvisorRulesFile)
+	if err != nil {
+		log.Fatalf("advisor rules error: %v", err)
+	}
+
+	var emb *embedding.Embedder
+	if cfg.EmbeddingURL != "" {
+		provider := embedding.NewOpenAIProvider(cfg.EmbeddingURL, cfg.EmbeddingAPIKey, cfg.EmbeddingTimeout)
+		emb, err = embedding.New(provider, cfg.EmbeddingModel, cfg.EmbeddingModels, cfg.EmbeddingCacheSize)
+		if err != nil {
+			log.Fatalf("embedding config error: %v", err)
+		}
+	}
+
+	var store cache.Store
+	if cfg.CacheEnabled {
+		store = cache.NewLRU(cfg.CacheMaxEntries, int64(cfg.CacheMaxSizeMB)<<20, cfg.CacheTTL)
+	}
+	live.Store(newSettings(cfg, rules, emb, store))
+
 	// Initialize audit logger
 	auditLogger, err = NewAuditLogger(cfg.AuditLogPath)
 	if err != nil {
@@ -177,7 +279,8 @@ func main() {
 	connManager = NewConnectionManager()
 	defer connManager.Close()
 
-	// Add all connections from config
+	// Add all connections from config. They are checked in the background,
+	// so servers that are not reachable yet do not hold up startup.
 	for _, connCfg := range cfg.Connections {
 		if err := connManager.AddConnectionWithPoolConfig(connCfg, cfg); err != nil {
 			log.Printf("Warning: failed to add connection '%s': %v", connCfg.Name, err)
@@ -189,11 +292,13 @@ func main() {
 		}
 	}
 
-	// Verify we have at least one valid connection
+	// Verify we have at least one usable connection (reachable or not)
 	if connManager.GetActiveDB() == nil {
 		connManager.Close() // Clean up before exit
 		log.Fatalf("config error: no valid MySQL connections available")
 	}
+	connManager.SetHealthSettings(cfg.PingTimeout, cfg.BreakerCooldown)
+	connManager.StartHealthChecks(cfg.HealthCheckInterval)
 
 	_, activeName := connManager.GetActive()
 
@@ -201,14 +306,15 @@ func main() {
 	logInfo("mysql-mcp-server started", map[string]interface{}{
 		"version":          Version,
 		"buildTime":        BuildTime,
-		"maxRows":          maxRows,
-		"queryTimeout":     queryTimeout.String(),
-		"extendedMode":     extendedMode,
+		"maxRows":          cfg.MaxRows,
+		"queryTimeout":     cfg.QueryTimeout.String(),
+		"extendedMode":     cfg.ExtendedMode,
 		"vectorMode":       cfg.VectorMode,
 		"httpMode":         cfg.HTTPMode,
 		"httpPort":         cfg.HTTPPort,
 		"jsonLogging":      jsonLogging,
 		"auditLogEnabled":  auditLogger.enabled,
+		"resultCache":      store != nil,
 		"tokenTracking":    tokenTracking,
 		"tokenModel":       tokenModel,
 		"connections":      len(cfg.Connections),
@@ -217,7 +323,8 @@ func main() {
 
 	// If HTTP mode is enabled, start REST API server instead of MCP
 	if cfg.HTTPMode {
-		startHTTPServer(cfg.HTTPPort, cfg.VectorMode)
+		startConfigReload(nil)
+		startHTTPServer(cfg.HTTPPort)
 		return
 	}
 
@@ -231,20 +338,17 @@ func main() {
 	)
 
 	// Register core tools
-	registerCoreTools(server)
+	registerCoreTools(&toolSet{server: server})
 
 	// Register multi-DSN tools
-	registerConnectionTools(server)
+	registerConnectionTools(&toolSet{server: server})
 
-	// Register vector tools (MYSQL_MCP_VECTOR=1)
-	if cfg.VectorMode {
-		registerVectorTools(server)
-	}
-
-	// Register extended tools (MYSQL_MCP_EXTENDED=1)
-	if extendedMode {
-		registerExtendedTools(server)
-	}
+	// Register cache (MYSQL_MCP_CACHE=1), vector (MYSQL_MCP_VECTOR=1) and
+	// extended (MYSQL_MCP_EXTENDED=1) tools; a config reload adds or removes
+	// them when these flags change.
+	tools := newFeatureTools(server)
+	tools.sync(cfg)
+	startConfigReload(tools)
 
 	// ---- Run over stdio ----
 	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
@@ -254,131 +358,225 @@ func main() {
 
 // ===== Tool Registration =====
 
-func registerCoreTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerCoreTools(ts *toolSet) {
+	addTool(ts, &mcp.Tool{
 		Name:        "list_databases",
 		Description: "List accessible databases in the configured MySQL server",
 	}, toolListDatabasesWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_tables",
 		Description: "List tables in a given database",
 	}, toolListTablesWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "describe_table",
 		Description: "Describe columns of a given table",
 	}, toolDescribeTableWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "run_query",
 		Description: "Execute a read-only SQL query (SELECT/SHOW/DESCRIBE/EXPLAIN only)",
 	}, toolRunQueryWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "ping",
 		Description: "Test database connectivity and measure latency",
 	}, toolPingWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "server_info",
 		Description: "Get MySQL server version, uptime, and configuration details",
 	}, toolServerInfoWrapped)
 }
 
-func registerConnectionTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerConnectionTools(ts *toolSet) {
+	addTool(ts, &mcp.Tool{
 		Name:        "list_connections",
 		Description: "List all configured MySQL connections and show which is active",
 	}, toolListConnectionsWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "use_connection",
 		Description: "Switch to a different MySQL connection by name",
 	}, toolUseConnectionWrapped)
 }
 
-func registerVectorTools(server *mcp.Server) {
+func registerCacheTools(ts *toolSet) {
+	addTool(ts, &mcp.Tool{
+		Name:        "refresh_cache",
+		Description: "Drop cached metadata and query results (all, one connection, or one database) and report cache hit statistics",
+	}, toolRefreshCacheWrapped)
+}
+
+func registerVectorTools(ts *toolSet) {
 	logInfo("Registering MySQL vector tools (MySQL 9.0+ required)...", nil)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "vector_search",
-		Description: "Perform similarity search on vector columns (MySQL 9.0+ required)",
+		Description: "Perform similarity search on vector columns (MySQL 9.0+ required); pass a query vector, query_text to embed it server-side when an embedding provider is configured, or several queries for per-query or merged results",
 	}, toolVectorSearchWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
+		Name:        "hybrid_search",
+		Description: "Combine FULLTEXT MATCH ... AGAINST and vector DISTANCE() rankings (reciprocal rank fusion or weighted scores) into one result list with both scores (MySQL 9.0+ required)",
+	}, toolHybridSearchWrapped)
+
+	addTool(ts, &mcp.Tool{
 		Name:        "vector_info",
 		Description: "List vector columns and their properties in a database",
 	}, toolVectorInfoWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "vector_stats",
+		Description: "Sample a vector column (VECTOR, or JSON/text arrays) and report null ratio, norm distribution, normalization, dimension mismatches and near-duplicate rate, with a recommended distance function",
+	}, toolVectorStatsWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "vector_ddl",
+		Description: "Propose (never execute) the DDL to add a VECTOR(n) column or convert a JSON/text column to VECTOR, for MySQL 9.x or HeatWave",
+	}, toolVectorDDLWrapped)
 }
 
-func registerExtendedTools(server *mcp.Server) {
+func registerExtendedTools(ts *toolSet) {
 	log.Printf("Registering extended MySQL tools...")
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_indexes",
 		Description: "List indexes on a table",
 	}, toolListIndexesWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "show_create_table",
 		Description: "Show the CREATE TABLE statement for a table",
 	}, toolShowCreateTableWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "explain_query",
-		Description: "Get the execution plan for a SELECT query",
+	addTool(ts, &mcp.Tool{
+		Name:         "explain_query",
+		Description:  "Get the execution plan for a SELECT query (format: traditional, json or tree; analyze runs EXPLAIN ANALYZE) with a normalized plan tree and warnings",
+		OutputSchema: recursiveOutputSchema[ExplainQueryOutput, ExplainNode](),
 	}, toolExplainQueryWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_views",
 		Description: "List views in a database",
 	}, toolListViewsWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_triggers",
 		Description: "List triggers in a database",
 	}, toolListTriggersWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_procedures",
 		Description: "List stored procedures in a database",
 	}, toolListProceduresWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_functions",
 		Description: "List stored functions in a database",
 	}, toolListFunctionsWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_partitions",
 		Description: "List partitions of a table",
 	}, toolListPartitionsWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "database_size",
 		Description: "Get size information for databases",
 	}, toolDatabaseSizeWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "table_size",
 		Description: "Get size information for tables",
 	}, toolTableSizeWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "foreign_keys",
 		Description: "List foreign key constraints",
 	}, toolForeignKeysWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_status",
 		Description: "List MySQL server status variables",
 	}, toolListStatusWrapped)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(ts, &mcp.Tool{
 		Name:        "list_variables",
 		Description: "List MySQL server configuration variables",
 	}, toolListVariablesWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "profile_table",
+		Description: "Profile table columns from a row sample: null ratio, distinct estimate, min/max, top values, lengths and histograms",
+	}, toolProfileTableWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "sample_rows",
+		Description: "Preview rows of a table (first, random or most recent) with long values truncated",
+	}, toolSampleRowsWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "summarize_database",
+		Description: "One token-budgeted overview of a database: tables with rows, primary key, key columns, foreign keys and comments, most important tables first",
+	}, toolSummarizeDatabaseWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "list_processes",
+		Description: "List active sessions with their current statement and InnoDB transaction age; filter by user, host, db, command, min_time or state",
+	}, toolListProcessesWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:         "lock_waits",
+		Description:  "Show who blocks whom: InnoDB row lock and metadata lock waits as blocking trees with queries, lock modes, locked index and wait time",
+		OutputSchema: recursiveOutputSchema[LockWaitsOutput, LockWaitNode](),
+	}, toolLockWaitsWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "top_queries",
+		Description: "Rank normalized statements from performance_schema by latency, rows examined ratio, temp disk tables, no-index scans or errors, optionally over a time window",
+	}, toolTopQueriesWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "innodb_status",
+		Description: "Parse SHOW ENGINE INNODB STATUS into sections: deadlock, buffer_pool, transactions, io, semaphores, log, row_operations",
+	}, toolInnodbStatusWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "replication_status",
+		Description: "Replication health: channel lag, IO/SQL thread states, last errors, GTID gaps and Group Replication member roles; optionally across all connections",
+	}, toolReplicationStatusWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "index_advisor",
+		Description: "Index health report for a database: unused and redundant indexes to drop and missing indexes suggested from full-scan statement digests, each with estimated benefit and DDL to review (never executed)",
+	}, toolIndexAdvisorWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "lint_query",
+		Description: "Check a SQL statement for anti-patterns (SELECT * on wide tables, functions on indexed columns, leading-wildcard LIKE, implicit conversions, OR across columns, ORDER BY without LIMIT, NOT IN over nullable subqueries) with suggested rewrites; the statement is not executed",
+	}, toolLintQueryWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "compare_plans",
+		Description: "Compare the execution plans of two queries, or of one query on two connections or databases: access paths, indexes, join order, estimated rows and cost, with a verdict on which plan is cheaper",
+	}, toolComparePlansWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "config_advisor",
+		Description: "Evaluate server settings against status counters and data size (buffer pool, connections, temporary tables, table cache, binlog and redo durability) and return prioritized findings with evidence and rule ids",
+	}, toolConfigAdvisorWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "security_audit",
+		Description: "Audit MySQL accounts for empty passwords, anonymous users, wildcard hosts, SUPER or ALL privileges, passwords that never expire and broadly granted roles, and report the privileges held by this server's own connection users",
+	}, toolSecurityAuditWrapped)
+
+	addTool(ts, &mcp.Tool{
+		Name:        "capacity_check",
+		Description: "Compare AUTO_INCREMENT counters with their column type's maximum (percent used, projected days to exhaustion from two samples over time) and flag INT primary keys on fast-growing or large tables",
+	}, toolCapacityCheckWrapped)
 }
 
 // ===== Config File Commands =====
@@ -400,6 +598,62 @@ func handleValidateConfig(path string) {
 	fmt.Printf("Config file %s is valid\n", path)
 }
 
+func handleEncryptSecrets(path string) {
+	keyText := os.Getenv("MYSQL_MCP_SECRETS_KEY")
+	if keyFile := os.Getenv("MYSQL_MCP_SECRETS_KEY_FILE"); keyText == "" && keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading secrets key file: %v\n", err)
+			os.Exit(1)
+		}
+		keyText = string(data)
+	}
+	if keyText == "" {
+		fmt.Fprintln(os.Stderr, "Error: set MYSQL_MCP_SECRETS_KEY or MYSQL_MCP_SECRETS_KEY_FILE (generate a key with: openssl rand -base64 32)")
+		os.Exit(1)
+	}
+	key, err := secrets.ParseKey(keyText)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	n, err := encryptSecrets(path, key, os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d secrets to %s\n", n, path)
+}
+
+// encryptSecrets reads NAME=VALUE lines (blank lines and # comments are
+// skipped) and writes them to an encrypted secrets file, replacing it.
+func encryptSecrets(path string, key []byte, in io.Reader) (int, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(strings.TrimSpace(text), "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return 0, fmt.Errorf("line %d: expected NAME=VALUE", line)
+		}
+		values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no secrets on standard input")
+	}
+	if err := secrets.WriteFile(path, key, values); err != nil {
+		return 0, err
+	}
+	return len(values), nil
+}
+
 // ===== Help and Usage =====
 
 func printHelp() {
@@ -414,6 +668,8 @@ OPTIONS:
     -c, --config PATH           Use config file at PATH
     --print-config              Print current configuration as YAML
     --validate-config PATH      Validate config file at PATH
+    --encrypt-secrets PATH      Write NAME=VALUE lines from stdin to an encrypted
+                                secrets file (key from MYSQL_MCP_SECRETS_KEY)
 
 DESCRIPTION:
     A fast, read-only MySQL Server for the Model Context Protocol (MCP).
@@ -436,6 +692,8 @@ CONFIGURATION:
         MYSQL_MAX_ROWS               Max rows returned (default: 200)
         MYSQL_QUERY_TIMEOUT_SECONDS  Query timeout in seconds (default: 30)
         MYSQL_MCP_EXTENDED           Enable extended tools (set to 1)
+        MYSQL_MCP_LINT_QUERIES       Add anti-pattern warnings to run_query results (set to 1)
+        MYSQL_MCP_DISABLE_RAW_WHERE  Reject raw where clauses in vector search tools; use filter (set to 1)
         MYSQL_MCP_JSON_LOGS          Enable JSON structured logging (set to 1)
         MYSQL_MCP_TOKEN_TRACKING     Enable token usage estimation (set to 1)
         MYSQL_MCP_TOKEN_MODEL        Tokenizer encoding to use (default: cl100k_base)
@@ -449,6 +707,28 @@ CONFIGURATION:
         MYSQL_MAX_OPEN_CONNS         Max open database connections (default: 10)
         MYSQL_MAX_IDLE_CONNS         Max idle database connections (default: 5)
         MYSQL_CONN_MAX_LIFETIME_MINUTES  Connection max lifetime in minutes (default: 30)
+        MYSQL_MCP_HEALTH_CHECK_SECONDS  Background connection ping interval (default: 30, 0 disables)
+        MYSQL_MCP_BREAKER_COOLDOWN_SECONDS  Time an unhealthy connection fails fast (default: 30)
+        MYSQL_MCP_MASK_COLUMNS       Column masking rules (e.g., ssn,users.email,*password*)
+        MYSQL_MCP_ADVISOR_RULES      YAML file with extra or overriding config_advisor rules
+        MYSQL_MCP_EMBEDDING_URL      OpenAI-compatible embeddings URL for vector_search query_text
+        MYSQL_MCP_EMBEDDING_API_KEY  API key for the embedding provider
+        MYSQL_MCP_EMBEDDING_MODEL    Default embedding model
+        MYSQL_MCP_EMBEDDING_MODELS   Per-column models (e.g., docs.body=nomic-embed-text)
+        MYSQL_MCP_EMBEDDING_CACHE_SIZE  Query embeddings kept in the LRU cache (default: 1000)
+        MYSQL_MCP_CACHE              Cache metadata tool results (set to 1)
+        MYSQL_MCP_CACHE_TTL_SECONDS  Time a cached result is kept (default: 300)
+        MYSQL_MCP_CACHE_MAX_ENTRIES  Max cached results (default: 1000)
+        MYSQL_MCP_CACHE_MAX_MB       Max size of cached results in MB (default: 64)
+        MYSQL_MCP_CACHE_QUERIES      Connections whose run_query results are cached (e.g., replica or *)
+        MYSQL_MCP_CONFIG_RELOAD_SECONDS  Config file polling interval (default: 5, 0 disables;
+                                     SIGHUP always reloads)
+        MYSQL_MCP_SECRETS_FILE       Encrypted secrets file for password_secret "file:NAME"
+        MYSQL_MCP_SECRETS_KEY        Key of the secrets file (base64, 32 bytes)
+        MYSQL_MCP_SECRETS_KEY_FILE   File holding the secrets file key
+        VAULT_ADDR                   Vault server for password_secret "vault:PATH#KEY"
+        VAULT_TOKEN                  Vault token
+        VAULT_NAMESPACE              Vault namespace (optional)
 
 MULTI-DSN CONFIGURATION:
     Configure multiple MySQL connections using numbered environment variables:
@@ -464,6 +744,10 @@ MULTI-DSN CONFIGURATION:
           {"name": "staging", "dsn": "user:pass@tcp(staging:3306)/db", "description": "Staging"}
         ]'
 
+    Connections can also use host, port, user, database and params instead
+    of dsn, with the password from password_file, password_env or
+    password_secret. ${VAR} in these fields is replaced from the environment.
+
 EXAMPLES:
     # Basic usage with single connection
     export MYSQL_DSN="root:password@tcp(127.0.0.1:3306)/mysql?parseTime=true"
@@ -478,6 +762,10 @@ EXAMPLES:
     # Print current configuration
     mysql-mcp-server --print-config
 
+    # Store passwords in an encrypted secrets file
+    export MYSQL_MCP_SECRETS_KEY="$(openssl rand -base64 32)"
+    echo "prod=s3cret" | mysql-mcp-server --encrypt-secrets /etc/mysql-mcp-server/secrets.enc
+
     # With extended tools enabled
     export MYSQL_DSN="user:pass@tcp(localhost:3306)/mydb"
     export MYSQL_MCP_EXTENDED=1
@@ -500,8 +788,9 @@ FEATURES:
 MCP TOOLS:
     Core: list_databases, list_tables, describe_table, run_query, ping, server_info
     Connections: list_connections, use_connection
+    Cache: refresh_cache (MYSQL_MCP_CACHE=1)
     Extended: list_indexes, show_create_table, explain_query, list_views, etc.
-    Vector: vector_search, vector_info (MySQL 9.0+)
+    Vector: vector_search, hybrid_search, vector_info, vector_stats, vector_ddl (MySQL 9.0+)
 
 SECURITY:
     - SQL validation blocks dangerous operations
@@ -0,0 +1,324 @@
+// cmd/mysql-mcp-server/reload.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/advisor"
+	"github.com/askdba/mysql-mcp-server/internal/cache"
+	"github.com/askdba/mysql-mcp-server/internal/config"
+	"github.com/askdba/mysql-mcp-server/internal/embedding"
+)
+
+// ===== Tool Sets =====
+
+// toolSet is a group of tools registered together. It remembers their
+// names so that the group can be removed again.
+type toolSet struct {
+	server *mcp.Server
+	names  []string
+}
+
+// addTool registers a tool on the set's server.
+func addTool[In, Out any](ts *toolSet, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	mcp.AddTool(ts.server, t, h)
+	ts.names = append(ts.names, t.Name)
+}
+
+// recursiveOutputSchema returns the output schema of Out, whose Node type
+// holds its children in a []Node field. Schemas cannot be inferred for
+// recursive types, so the children of nested nodes are plain objects.
+func recursiveOutputSchema[Out, Node any]() *jsonschema.Schema {
+	nodes := reflect.TypeFor[[]Node]()
+	node, err := jsonschema.For[Node](&jsonschema.ForOptions{
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+			nodes: {Type: "array", Items: &jsonschema.Schema{Type: "object"}},
+		},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("output schema for %v: %v", nodes.Elem(), err))
+	}
+	out, err := jsonschema.For[Out](&jsonschema.ForOptions{
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+			reflect.TypeFor[Node](): node,
+			nodes:                   {Type: "array", Items: node},
+		},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("output schema for %v: %v", reflect.TypeFor[Out](), err))
+	}
+	return out
+}
+
+// featureToolGroups are the optional tool groups and the flag enabling each.
+var featureToolGroups = []struct {
+	name     string
+	enabled  func(*config.Config) bool
+	register func(*toolSet)
+}{
+	{"cache", func(c *config.Config) bool { return c.CacheEnabled }, registerCacheTools},
+	{"vector", func(c *config.Config) bool { return c.VectorMode }, registerVectorTools},
+	{"extended", func(c *config.Config) bool { return c.ExtendedMode }, registerExtendedTools},
+}
+
+// featureTools tracks which optional tool groups are registered on the MCP server.
+type featureTools struct {
+	server *mcp.Server
+	sets   map[string]*toolSet
+}
+
+func newFeatureTools(server *mcp.Server) *featureTools {
+	return &featureTools{server: server, sets: make(map[string]*toolSet)}
+}
+
+// sync registers the groups enabled in c and removes the disabled ones,
+// returning the tools added and removed. The SDK notifies connected
+// clients with tools/list_changed.
+func (ft *featureTools) sync(c *config.Config) (added, removed []string) {
+	for _, g := range featureToolGroups {
+		ts, registered := ft.sets[g.name]
+		switch enabled := g.enabled(c); {
+		case enabled && !registered:
+			ts = &toolSet{server: ft.server}
+			g.register(ts)
+			ft.sets[g.name] = ts
+			added = append(added, ts.names...)
+		case !enabled && registered:
+			ft.server.RemoveTools(ts.names...)
+			delete(ft.sets, g.name)
+			removed = append(removed, ts.names...)
+		}
+	}
+	return added, removed
+}
+
+// ===== Config Reload =====
+
+// reloader re-reads the configuration on SIGHUP or when the config file
+// changes, and applies it to the running server.
+type reloader struct {
+	mu    sync.Mutex
+	tools *featureTools // nil in HTTP mode
+	file  configFileState
+}
+
+// configFileState identifies a version of the config file.
+type configFileState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func currentConfigFileState() configFileState {
+	path := config.FindConfigFile()
+	if path == "" {
+		return configFileState{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return configFileState{path: path}
+	}
+	return configFileState{path: path, modTime: info.ModTime(), size: info.Size()}
+}
+
+// reloadResult reports what a reload changed.
+type reloadResult struct {
+	Connections     ConnectionChanges
+	ToolsAdded      []string
+	ToolsRemoved    []string
+	RestartRequired []string // changed settings that only apply after a restart
+}
+
+// startConfigReload reloads the configuration on SIGHUP and, unless
+// ConfigReloadInterval is 0, whenever the config file changes.
+func startConfigReload(tools *featureTools) *reloader {
+	r := &reloader{tools: tools, file: currentConfigFileState()}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			r.reloadAndLog("SIGHUP")
+		}
+	}()
+
+	if interval := currentSettings().cfg.ConfigReloadInterval; interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				r.checkFile()
+			}
+		}()
+	}
+	return r
+}
+
+// checkFile reloads the configuration if the config file was created,
+// modified or removed since the last check, and reports whether it did.
+func (r *reloader) checkFile() bool {
+	state := currentConfigFileState()
+	r.mu.Lock()
+	changed := state != r.file
+	r.file = state
+	r.mu.Unlock()
+	if changed {
+		r.reloadAndLog("config file")
+	}
+	return changed
+}
+
+func (r *reloader) reloadAndLog(trigger string) {
+	res, err := r.reload()
+	if err != nil {
+		logError("config reload failed; keeping the current configuration", map[string]interface{}{
+			"trigger": trigger,
+			"error":   err.Error(),
+		})
+		return
+	}
+	for name, msg := range res.Connections.Failed {
+		logWarn("connection not reloaded", map[string]interface{}{
+			"name":  name,
+			"error": msg,
+		})
+	}
+	if len(res.RestartRequired) > 0 {
+		logWarn("config changes require a restart", map[string]interface{}{
+			"settings": res.RestartRequired,
+		})
+	}
+	logInfo("config reloaded", map[string]interface{}{
+		"trigger":          trigger,
+		"added":            res.Connections.Added,
+		"removed":          res.Connections.Removed,
+		"updated":          res.Connections.Updated,
+		"activeConnection": res.Connections.Active,
+		"toolsAdded":       res.ToolsAdded,
+		"toolsRemoved":     res.ToolsRemoved,
+	})
+}
+
+// reload loads the configuration and applies it. Nothing is changed when
+// the new configuration is invalid.
+func (r *reloader) reload() (reloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newCfg, err := config.Load()
+	if err != nil {
+		return reloadResult{}, err
+	}
+	cur := currentSettings()
+	old := cur.cfg
+	res := reloadResult{RestartRequired: keepRestartSettings(old, newCfg)}
+
+	// Build everything that can fail before touching the running server.
+	rules := cur.advisorRules
+	if newCfg.AdvisorRulesFile != old.AdvisorRulesFile {
+		if rules, err = advisor.LoadRules(newCfg.AdvisorRulesFile); err != nil {
+			return reloadResult{}, fmt.Errorf("advisor rules error: %w", err)
+		}
+	}
+	emb := cur.embedder
+	if embeddingChanged(old, newCfg) {
+		emb = nil
+		if newCfg.EmbeddingURL != "" {
+			provider := embedding.NewOpenAIProvider(newCfg.EmbeddingURL, newCfg.EmbeddingAPIKey, newCfg.EmbeddingTimeout)
+			if emb, err = embedding.New(provider, newCfg.EmbeddingModel, newCfg.EmbeddingModels, newCfg.EmbeddingCacheSize); err != nil {
+				return reloadResult{}, fmt.Errorf("embedding config error: %w", err)
+			}
+		}
+	}
+
+	// Retired pools stay open for a query timeout, so tool calls that
+	// already picked them up can finish.
+	res.Connections, err = connManager.Reconcile(newCfg.Connections, newCfg, newCfg.QueryTimeout)
+	if err != nil {
+		return reloadResult{}, err
+	}
+
+	connManager.SetHealthSettings(newCfg.PingTimeout, newCfg.BreakerCooldown)
+	store := applyCacheConfig(old, newCfg, cur.resultCache, res.Connections)
+	live.Store(newSettings(newCfg, rules, emb, store))
+
+	if r.tools != nil {
+		res.ToolsAdded, res.ToolsRemoved = r.tools.sync(newCfg)
+	}
+	return res, nil
+}
+
+// keepRestartSettings copies the settings that cannot change while the
+// server runs from old into newCfg, returning the names of those that differ.
+func keepRestartSettings(old, newCfg *config.Config) []string {
+	var changed []string
+	keep := func(name string, oldVal, newVal interface{}, restore func()) {
+		if oldVal != newVal {
+			changed = append(changed, name)
+			restore()
+		}
+	}
+	keep("http.enabled", old.HTTPMode, newCfg.HTTPMode, func() { newCfg.HTTPMode = old.HTTPMode })
+	keep("http.port", old.HTTPPort, newCfg.HTTPPort, func() { newCfg.HTTPPort = old.HTTPPort })
+	keep("http.request_timeout_seconds", old.HTTPRequestTimeout, newCfg.HTTPRequestTimeout, func() { newCfg.HTTPRequestTimeout = old.HTTPRequestTimeout })
+	keep("http.rate_limit.enabled", old.RateLimitEnabled, newCfg.RateLimitEnabled, func() { newCfg.RateLimitEnabled = old.RateLimitEnabled })
+	keep("http.rate_limit.rps", old.RateLimitRPS, newCfg.RateLimitRPS, func() { newCfg.RateLimitRPS = old.RateLimitRPS })
+	keep("http.rate_limit.burst", old.RateLimitBurst, newCfg.RateLimitBurst, func() { newCfg.RateLimitBurst = old.RateLimitBurst })
+	keep("logging.json_format", old.JSONLogging, newCfg.JSONLogging, func() { newCfg.JSONLogging = old.JSONLogging })
+	keep("logging.audit_log_path", old.AuditLogPath, newCfg.AuditLogPath, func() { newCfg.AuditLogPath = old.AuditLogPath })
+	keep("logging.token_tracking", old.TokenTracking, newCfg.TokenTracking, func() { newCfg.TokenTracking = old.TokenTracking })
+	keep("logging.token_model", old.TokenModel, newCfg.TokenModel, func() { newCfg.TokenModel = old.TokenModel })
+	keep("MYSQL_MCP_CONFIG_RELOAD_SECONDS", old.ConfigReloadInterval, newCfg.ConfigReloadInterval, func() { newCfg.ConfigReloadInterval = old.ConfigReloadInterval })
+	keep("pool.health_check_seconds", old.HealthCheckInterval, newCfg.HealthCheckInterval, func() { newCfg.HealthCheckInterval = old.HealthCheckInterval })
+	return changed
+}
+
+// resultSettingsChanged reports whether settings that cached tool results
+// depend on differ between old and newCfg.
+func resultSettingsChanged(old, newCfg *config.Config) bool {
+	return !reflect.DeepEqual(old.MaskColumns, newCfg.MaskColumns) ||
+		old.MaxRows != newCfg.MaxRows ||
+		old.AdvisorRulesFile != newCfg.AdvisorRulesFile
+}
+
+func embeddingChanged(old, newCfg *config.Config) bool {
+	return old.EmbeddingURL != newCfg.EmbeddingURL ||
+		old.EmbeddingAPIKey != newCfg.EmbeddingAPIKey ||
+		old.EmbeddingModel != newCfg.EmbeddingModel ||
+		!reflect.DeepEqual(old.EmbeddingModels, newCfg.EmbeddingModels) ||
+		old.EmbeddingCacheSize != newCfg.EmbeddingCacheSize ||
+		old.EmbeddingTimeout != newCfg.EmbeddingTimeout
+}
+
+// applyCacheConfig returns a new result cache when its settings change, or
+// when settings that shape tool results (masking, max_rows, advisor rules)
+// change, so results cached under the old ones are not served. Calls still
+// running under the old settings store into the old cache. Otherwise it
+// drops the results of removed and reopened connections from store and
+// returns it.
+func applyCacheConfig(old, newCfg *config.Config, store cache.Store, changes ConnectionChanges) cache.Store {
+	if old.CacheEnabled != newCfg.CacheEnabled || old.CacheTTL != newCfg.CacheTTL ||
+		old.CacheMaxEntries != newCfg.CacheMaxEntries || old.CacheMaxSizeMB != newCfg.CacheMaxSizeMB ||
+		resultSettingsChanged(old, newCfg) {
+		if !newCfg.CacheEnabled {
+			return nil
+		}
+		return cache.NewLRU(newCfg.CacheMaxEntries, int64(newCfg.CacheMaxSizeMB)<<20, newCfg.CacheTTL)
+	}
+	if store == nil {
+		return nil
+	}
+	for _, name := range append(changes.Removed, changes.Updated...) {
+		store.Invalidate(name)
+	}
+	return store
+}
@@ -0,0 +1,232 @@
+// cmd/mysql-mcp-server/reload_test.go
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/cache"
+	"github.com/askdba/mysql-mcp-server/internal/config"
+)
+
+func listToolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFeatureToolsSync(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	registerCoreTools(&toolSet{server: server})
+	tools := newFeatureTools(server)
+
+	changed := make(chan struct{}, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) { changed <- struct{}{} },
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer serverSession.Close()
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	defer session.Close()
+
+	waitChanged := func() {
+		t.Helper()
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected a tools/list_changed notification")
+		}
+	}
+
+	added, removed := tools.sync(&config.Config{ExtendedMode: true, CacheEnabled: true})
+	if len(removed) != 0 || added[0] != "refresh_cache" || len(added) < 10 {
+		t.Fatalf("unexpected sync result: added %v, removed %v", added, removed)
+	}
+	waitChanged()
+	names := strings.Join(listToolNames(t, session), ",")
+	if !strings.Contains(names, "list_indexes") || !strings.Contains(names, "refresh_cache") || strings.Contains(names, "vector_search") {
+		t.Errorf("unexpected tools: %s", names)
+	}
+
+	// Syncing the same flags changes nothing.
+	if added, removed := tools.sync(&config.Config{ExtendedMode: true, CacheEnabled: true}); len(added)+len(removed) != 0 {
+		t.Errorf("expected no changes, got %v %v", added, removed)
+	}
+
+	added, removed = tools.sync(&config.Config{VectorMode: true})
+	if len(removed) < 10 || !strings.Contains(strings.Join(added, ","), "vector_search") {
+		t.Fatalf("unexpected sync result: added %v, removed %v", added, removed)
+	}
+	waitChanged()
+	names = strings.Join(listToolNames(t, session), ",")
+	if strings.Contains(names, "list_indexes") || strings.Contains(names, "refresh_cache") || !strings.Contains(names, "vector_search") || !strings.Contains(names, "run_query") {
+		t.Errorf("unexpected tools after toggling: %s", names)
+	}
+}
+
+// setupReloadTest points the config loader at a temporary file and saves
+// the global state a reload changes.
+func setupReloadTest(t *testing.T, content string) (string, map[string]sqlmock.Sqlmock) {
+	t.Helper()
+	for _, v := range []string{"MYSQL_DSN", "MYSQL_CONNECTIONS", "MYSQL_MAX_ROWS", "MYSQL_MCP_EXTENDED", "MYSQL_MCP_CACHE", "MYSQL_MCP_CONFIG"} {
+		t.Setenv(v, "")
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	oldPath := config.ConfigFilePath
+	oldConnManager, oldSettings := connManager, live.Load()
+	t.Cleanup(func() {
+		config.ConfigFilePath = oldPath
+		connManager = oldConnManager
+		live.Store(oldSettings)
+	})
+
+	config.ConfigFilePath = path
+	loaded, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	live.Store(newSettings(loaded, nil, nil, nil))
+
+	opened := make(map[string]sqlmock.Sqlmock)
+	connManager = NewConnectionManager()
+	connManager.open = stubOpener(t, opened)
+	for _, c := range loaded.Connections {
+		if err := connManager.AddConnectionWithPoolConfig(c, loaded); err != nil {
+			t.Fatalf("AddConnectionWithPoolConfig failed: %v", err)
+		}
+	}
+	return path, opened
+}
+
+func TestReloaderReload(t *testing.T) {
+	path, _ := setupReloadTest(t, `
+connections:
+  default:
+    dsn: "user:pass@tcp(primary:3306)/db"
+  replica:
+    dsn: "user:pass@tcp(replica:3306)/db"
+query:
+  max_rows: 100
+http:
+  port: 9306
+`)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	tools := newFeatureTools(server)
+	tools.sync(currentSettings().cfg)
+	r := &reloader{tools: tools, file: currentConfigFileState()}
+
+	if r.checkFile() {
+		t.Error("expected no reload for an unchanged file")
+	}
+
+	if err := os.WriteFile(path, []byte(`
+connections:
+  default:
+    dsn: "user:pass@tcp(primary:3306)/db"
+    description: "Primary"
+  analytics:
+    dsn: "user:pass@tcp(analytics:3306)/db"
+query:
+  max_rows: 50
+  timeout_seconds: 12
+  lint: true
+features:
+  extended_tools: true
+http:
+  port: 9400
+cache:
+  enabled: true
+masking:
+  columns: ["email"]
+`), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	res, err := r.reload()
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+
+	if strings.Join(res.Connections.Added, ",") != "analytics" || strings.Join(res.Connections.Removed, ",") != "replica" || len(res.Connections.Updated) != 0 {
+		t.Errorf("unexpected connection changes: %+v", res.Connections)
+	}
+	if c, _ := connManager.Config("default"); c.Description != "Primary" {
+		t.Errorf("expected the description to be updated, got %q", c.Description)
+	}
+	conf := currentSettings()
+	if conf.maxRows != 50 || conf.queryTimeout != 12*time.Second || !conf.lintQueries || !conf.extendedMode || !conf.columnMasker.ShouldMask("db", "users", "email") {
+		t.Errorf("limits not applied: maxRows=%d timeout=%v lint=%v extended=%v", conf.maxRows, conf.queryTimeout, conf.lintQueries, conf.extendedMode)
+	}
+	if conf.resultCache == nil {
+		t.Error("expected the result cache to be enabled")
+	}
+	if len(res.ToolsAdded) == 0 || res.ToolsAdded[0] != "refresh_cache" {
+		t.Errorf("expected cache and extended tools to be added, got %v", res.ToolsAdded)
+	}
+	if strings.Join(res.RestartRequired, ",") != "http.port" || conf.cfg.HTTPPort != 9306 {
+		t.Errorf("expected http.port to need a restart: %v (port %d)", res.RestartRequired, conf.cfg.HTTPPort)
+	}
+
+	// An invalid file leaves everything as it was.
+	if err := os.WriteFile(path, []byte("connections: [not a map"), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := r.reload(); err == nil {
+		t.Fatal("expected a reload error for an invalid file")
+	}
+	if conf := currentSettings(); conf.maxRows != 50 || len(connManager.Names()) != 2 {
+		t.Errorf("state changed by a failed reload: maxRows=%d connections=%v", conf.maxRows, connManager.Names())
+	}
+}
+
+func TestApplyCacheConfig(t *testing.T) {
+	old := &config.Config{CacheEnabled: true, CacheTTL: time.Minute, CacheMaxEntries: 10, CacheMaxSizeMB: 1, MaxRows: 100}
+	store := cache.NewLRU(10, 1<<20, time.Minute)
+	store.Set("a", "result", 1, "mock")
+	store.Set("b", "result", 1, "replica")
+
+	same := *old
+	got := applyCacheConfig(old, &same, store, ConnectionChanges{Removed: []string{"replica"}})
+	if got != store || got.Stats().Entries != 1 {
+		t.Errorf("expected the cache to be kept without the removed connection, got %+v", got.Stats())
+	}
+
+	masked := *old
+	masked.MaskColumns = []string{"email"}
+	if got := applyCacheConfig(old, &masked, store, ConnectionChanges{}); got == store || got.Stats().Entries != 0 {
+		t.Errorf("expected a new cache after a masking change, got %+v", got.Stats())
+	}
+
+	disabled := *old
+	disabled.CacheEnabled = false
+	if got := applyCacheConfig(old, &disabled, store, ConnectionChanges{}); got != nil {
+		t.Error("expected no cache once disabled")
+	}
+}
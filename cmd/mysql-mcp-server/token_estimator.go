@@ -148,3 +148,19 @@ func estimateTokensForValue(v any) (int, error) {
 
 	return tokenEstimator.Count(buf.String())
 }
+
+// approxTokens estimates the tokens of v's JSON encoding. It uses the
+// configured TokenEstimator when token tracking is on and otherwise falls back
+// to roughly four bytes per token, so budgeted tools work either way.
+func approxTokens(v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	if tokenEstimator != nil {
+		if n, err := tokenEstimator.Count(string(b)); err == nil {
+			return n
+		}
+	}
+	return (len(b) + 3) / 4
+}
@@ -2,15 +2,90 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/util"
 )
 
+// connectionlessTools work while the active connection is unavailable.
+var connectionlessTools = map[string]bool{
+	"list_connections": true,
+	"use_connection":   true,
+	"refresh_cache":    true,
+}
+
+// multiConnectionTools choose the connections they query themselves. They
+// check and record each of them with withConnection, so that errors of one
+// server are never charged to the active connection.
+var multiConnectionTools = map[string]bool{
+	"compare_plans":      true,
+	"replication_status": true,
+	"security_audit":     true,
+}
+
+// activeConnectionReady returns the active connection if toolName needs
+// it, or an error if it cannot be used. The result of the call must be
+// recorded for the returned connection.
+func activeConnectionReady(ctx context.Context, toolName string) (string, error) {
+	if connManager == nil || connectionlessTools[toolName] || multiConnectionTools[toolName] {
+		return "", nil
+	}
+	_, name := connManager.GetActive()
+	if name == "" {
+		return "", nil
+	}
+	return name, connManager.Ready(ctx, name)
+}
+
+// withConnection runs fn on the named connection once its circuit breaker
+// lets the call through, and records the result of fn for that connection.
+func withConnection(ctx context.Context, name string, fn func(db *sql.DB) error) error {
+	if err := connManager.Ready(ctx, name); err != nil {
+		return err
+	}
+	db, err := connManager.Get(name)
+	if err != nil {
+		return err
+	}
+	err = fn(db)
+	connManager.Record(name, err)
+	return err
+}
+
 func wrapTool[I any, O any](toolName string, h mcp.ToolHandlerFor[I, O]) mcp.ToolHandlerFor[I, O] {
 	return func(ctx context.Context, req *mcp.CallToolRequest, input I) (*mcp.CallToolResult, O, error) {
 		start := time.Now()
+		ctx = withSettings(ctx, currentSettings())
+
+		conn, err := activeConnectionReady(ctx, toolName)
+		if err != nil {
+			var zero O
+			return nil, zero, err
+		}
+
+		creq, cacheable := newCacheRequest(ctx, toolName, input)
+		if cacheable {
+			if v, ok := creq.lookup(); ok {
+				if out, ok := v.(O); ok {
+					if conn != "" {
+						connManager.Record(conn, nil)
+					}
+					logCacheHit(creq, input, out, start)
+					return nil, out, nil
+				}
+			}
+		}
+
 		res, out, err := h(ctx, req, input)
+		if conn != "" {
+			connManager.Record(conn, err)
+		}
+		if cacheable && err == nil && res == nil {
+			creq.store(out)
+		}
 
 		// Only emit these extra logs when token tracking is explicitly enabled.
 		// This keeps default behavior unchanged.
@@ -30,6 +105,7 @@ func wrapTool[I any, O any](toolName string, h mcp.ToolHandlerFor[I, O]) mcp.Too
 			fields := map[string]interface{}{
 				"tool":        toolName,
 				"duration_ms": time.Since(start).Milliseconds(),
+				"cached":      false,
 				"tokens": map[string]interface{}{
 					"input_estimated":  tokens.InputEstimated,
 					"output_estimated": tokens.OutputEstimated,
@@ -49,19 +125,63 @@ func wrapTool[I any, O any](toolName string, h mcp.ToolHandlerFor[I, O]) mcp.Too
 	}
 }
 
+// logCacheHit records a result served from the cache in the tool log (with
+// token tracking) and the audit log.
+func logCacheHit(creq *cacheRequest, input, out interface{}, start time.Time) {
+	durationMs := time.Since(start).Milliseconds()
+
+	inputTokens, outputTokens := 0, 0
+	if tokenTracking {
+		inputTokens, _ = estimateTokensForValue(input)
+		outputTokens, _ = estimateTokensForValue(out)
+		logInfo("tool executed", map[string]interface{}{
+			"tool":        creq.tool,
+			"duration_ms": durationMs,
+			"cached":      true,
+			"tokens": map[string]interface{}{
+				"input_estimated":  inputTokens,
+				"output_estimated": outputTokens,
+				"total_estimated":  inputTokens + outputTokens,
+				"model":            tokenModel,
+			},
+		})
+	}
+
+	if auditLogger != nil {
+		entry := &AuditEntry{
+			Tool:         creq.tool,
+			Database:     creq.database,
+			Query:        util.TruncateQuery(creq.query, 500),
+			DurationMs:   durationMs,
+			InputTokens:  inputTokens,
+			OutputTokens: outputTokens,
+			Success:      true,
+			Cached:       true,
+		}
+		if qr, ok := out.(QueryResult); ok {
+			entry.RowCount = len(qr.Rows)
+		}
+		auditLogger.Log(entry)
+	}
+}
+
 // Wrapped tool handlers used by both MCP and HTTP.
 var (
 	toolListDatabasesWrapped   = wrapTool("list_databases", toolListDatabases)
 	toolListTablesWrapped      = wrapTool("list_tables", toolListTables)
 	toolDescribeTableWrapped   = wrapTool("describe_table", toolDescribeTable)
-	toolRunQueryWrapped        = toolRunQuery // run_query has dedicated query/audit logs with tokens
+	toolRunQueryWrapped        = wrapTool("run_query", toolRunQuery) // token logs skipped: run_query has dedicated query/audit logs
 	toolPingWrapped            = wrapTool("ping", toolPing)
 	toolServerInfoWrapped      = wrapTool("server_info", toolServerInfo)
 	toolListConnectionsWrapped = wrapTool("list_connections", toolListConnections)
 	toolUseConnectionWrapped   = wrapTool("use_connection", toolUseConnection)
+	toolRefreshCacheWrapped    = wrapTool("refresh_cache", toolRefreshCache)
 
 	toolVectorSearchWrapped = wrapTool("vector_search", toolVectorSearch)
 	toolVectorInfoWrapped   = wrapTool("vector_info", toolVectorInfo)
+	toolHybridSearchWrapped = wrapTool("hybrid_search", toolHybridSearch)
+	toolVectorStatsWrapped  = wrapTool("vector_stats", toolVectorStats)
+	toolVectorDDLWrapped    = wrapTool("vector_ddl", toolVectorDDL)
 
 	toolListIndexesWrapped     = wrapTool("list_indexes", toolListIndexes)
 	toolShowCreateTableWrapped = wrapTool("show_create_table", toolShowCreateTable)
@@ -76,4 +196,22 @@ var (
 	toolForeignKeysWrapped     = wrapTool("foreign_keys", toolForeignKeys)
 	toolListStatusWrapped      = wrapTool("list_status", toolListStatus)
 	toolListVariablesWrapped   = wrapTool("list_variables", toolListVariables)
+
+	toolProfileTableWrapped      = wrapTool("profile_table", toolProfileTable)
+	toolSampleRowsWrapped        = wrapTool("sample_rows", toolSampleRows)
+	toolSummarizeDatabaseWrapped = wrapTool("summarize_database", toolSummarizeDatabase)
+
+	toolListProcessesWrapped = wrapTool("list_processes", toolListProcesses)
+	toolLockWaitsWrapped     = wrapTool("lock_waits", toolLockWaits)
+	toolTopQueriesWrapped    = wrapTool("top_queries", toolTopQueries)
+	toolInnodbStatusWrapped  = wrapTool("innodb_status", toolInnodbStatus)
+
+	toolReplicationStatusWrapped = wrapTool("replication_status", toolReplicationStatus)
+
+	toolIndexAdvisorWrapped  = wrapTool("index_advisor", toolIndexAdvisor)
+	toolLintQueryWrapped     = wrapTool("lint_query", toolLintQuery)
+	toolComparePlansWrapped  = wrapTool("compare_plans", toolComparePlans)
+	toolConfigAdvisorWrapped = wrapTool("config_advisor", toolConfigAdvisor)
+	toolSecurityAuditWrapped = wrapTool("security_audit", toolSecurityAudit)
+	toolCapacityCheckWrapped = wrapTool("capacity_check", toolCapacityCheck)
 )
@@ -2,10 +2,17 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/askdba/mysql-mcp-server/internal/config"
 )
 
 // mockInput is a simple test input struct
@@ -220,3 +227,81 @@ func TestWrapToolPreservesContext(t *testing.T) {
 		t.Errorf("unexpected result: %s", out.Result)
 	}
 }
+
+func TestWrapToolSettingsSnapshot(t *testing.T) {
+	setSettings(t, func(s *settings) { s.maxRows = 10 })
+
+	// A reload during the call does not change the settings it sees.
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input mockInput) (*mcp.CallToolResult, mockOutput, error) {
+		before := settingsFrom(ctx).maxRows
+		live.Store(&settings{maxRows: 20})
+		return nil, mockOutput{Result: fmt.Sprintf("%d/%d", before, settingsFrom(ctx).maxRows)}, nil
+	}
+
+	_, out, err := wrapTool("test_tool", handler)(context.Background(), nil, mockInput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Result != "10/10" {
+		t.Errorf("expected the snapshot from the start of the call, got %s", out.Result)
+	}
+	if currentSettings().maxRows != 20 {
+		t.Errorf("expected later calls to see the new settings, got maxRows=%d", currentSettings().maxRows)
+	}
+}
+
+func TestWrapToolUnavailableConnection(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	mock.ExpectPing().WillReturnError(errors.New("connect: connection refused"))
+
+	oldConnManager := connManager
+	defer func() { connManager = oldConnManager }()
+	cm := NewConnectionManager()
+	defer cm.Close()
+	cm.retryDelay = time.Hour
+	cm.open = func(config.ConnectionConfig, *config.Config) (*sql.DB, error) { return db, nil }
+	connManager = cm
+	if err := cm.AddConnectionWithPoolConfig(config.ConnectionConfig{Name: "vpn", DSN: "u:secret@tcp(10.0.0.5:3306)/"}, &config.Config{}); err != nil {
+		t.Fatalf("AddConnectionWithPoolConfig failed: %v", err)
+	}
+	for {
+		if st, _ := cm.Status("vpn"); st.Failures > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	called := false
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input mockInput) (*mcp.CallToolResult, mockOutput, error) {
+		called = true
+		return nil, mockOutput{}, nil
+	}
+	// The tool fails with the connection state after another ping.
+	mock.ExpectPing().WillReturnError(errors.New("connect: connection refused"))
+	_, _, err = wrapTool("list_tables", handler)(context.Background(), &mcp.CallToolRequest{}, mockInput{})
+	if err == nil || !strings.Contains(err.Error(), "connection 'vpn' is degraded") {
+		t.Errorf("expected a degraded error, got %v", err)
+	}
+	if called {
+		t.Error("handler should not run without a connection")
+	}
+
+	// list_connections reports the state without touching the server.
+	_, out, err := toolListConnectionsWrapped(context.Background(), &mcp.CallToolRequest{}, ListConnectionsInput{})
+	if err != nil {
+		t.Fatalf("list_connections failed: %v", err)
+	}
+	if len(out.Connections) != 1 {
+		t.Fatalf("unexpected connections: %+v", out.Connections)
+	}
+	c := out.Connections[0]
+	if c.State != connStateDegraded || !strings.Contains(c.LastError, "connection refused") || c.LastSuccess != "" || c.NextRetry == "" {
+		t.Errorf("unexpected connection info: %+v", c)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected pings: %v", err)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/askdba/mysql-mcp-server/internal/util"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -25,8 +27,9 @@ func toolListDatabases(
 	req *mcp.CallToolRequest,
 	input ListDatabasesInput,
 ) (*mcp.CallToolResult, ListDatabasesOutput, error) {
+	conf := settingsFrom(ctx)
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	rows, err := getDB().QueryContext(ctx, "SHOW DATABASES")
@@ -42,7 +45,7 @@ func toolListDatabases(
 			return nil, ListDatabasesOutput{}, fmt.Errorf("scan database name failed: %w", err)
 		}
 		out.Databases = append(out.Databases, DatabaseInfo{Name: name})
-		if len(out.Databases) >= maxRows {
+		if len(out.Databases) >= conf.maxRows {
 			break
 		}
 	}
@@ -58,12 +61,13 @@ func toolListTables(
 	req *mcp.CallToolRequest,
 	input ListTablesInput,
 ) (*mcp.CallToolResult, ListTablesOutput, error) {
+	conf := settingsFrom(ctx)
 
 	if strings.TrimSpace(input.Database) == "" {
 		return nil, ListTablesOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	dbName, err := util.QuoteIdent(input.Database)
@@ -85,7 +89,7 @@ func toolListTables(
 			return nil, ListTablesOutput{}, fmt.Errorf("scan table name failed: %w", err)
 		}
 		out.Tables = append(out.Tables, TableInfo{Name: name})
-		if len(out.Tables) >= maxRows {
+		if len(out.Tables) >= conf.maxRows {
 			break
 		}
 	}
@@ -101,6 +105,7 @@ func toolDescribeTable(
 	req *mcp.CallToolRequest,
 	input DescribeTableInput,
 ) (*mcp.CallToolResult, DescribeTableOutput, error) {
+	conf := settingsFrom(ctx)
 
 	if strings.TrimSpace(input.Database) == "" {
 		return nil, DescribeTableOutput{}, fmt.Errorf("database is required")
@@ -109,7 +114,7 @@ func toolDescribeTable(
 		return nil, DescribeTableOutput{}, fmt.Errorf("table is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	dbName, err := util.QuoteIdent(input.Database)
@@ -161,7 +166,7 @@ func toolDescribeTable(
 		col.Comment = comment.String
 
 		out.Columns = append(out.Columns, col)
-		if len(out.Columns) >= maxRows {
+		if len(out.Columns) >= conf.maxRows {
 			break
 		}
 	}
@@ -177,6 +182,7 @@ func toolRunQuery(
 	req *mcp.CallToolRequest,
 	input RunQueryInput,
 ) (*mcp.CallToolResult, QueryResult, error) {
+	conf := settingsFrom(ctx)
 	timer := NewQueryTimer("run_query")
 
 	sqlText := strings.TrimSpace(input.SQL)
@@ -210,12 +216,12 @@ func toolRunQuery(
 		return nil, QueryResult{}, fmt.Errorf("query validation failed: %w", err)
 	}
 
-	limit := maxRows
-	if input.MaxRows != nil && *input.MaxRows > 0 && *input.MaxRows < maxRows {
+	limit := conf.maxRows
+	if input.MaxRows != nil && *input.MaxRows > 0 && *input.MaxRows < conf.maxRows {
 		limit = *input.MaxRows
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	// Switch to the specified database if provided
@@ -263,9 +269,56 @@ func toolRunQuery(
 	}
 	defer rows.Close()
 
+	result, err := scanQueryResult(rows, limit)
+	if err != nil {
+		return nil, QueryResult{}, err
+	}
+	// Table is unknown for ad-hoc SQL, so only rules with a wildcard table apply.
+	maskQueryResult(conf.columnMasker, &result, database, "")
+
+	// Linting is advisory: a statement the linter cannot parse still returns its rows.
+	if conf.lintQueries || input.Lint {
+		result.LintWarnings, _ = lintStatement(ctx, getDB(), sqlText, database)
+	}
+
+	// Token estimation for output (optional)
+	outputTokens, _ := estimateTokensForValue(result)
+	tokens.OutputEstimated = outputTokens
+	tokens.TotalEstimated = inputTokens + outputTokens
+
+	// Calculate efficiency metrics
+	eff := CalculateEfficiency(inputTokens, outputTokens, len(result.Rows))
+
+	// Log success
+	timer.LogSuccess(len(result.Rows), sqlText, tokens, eff)
+	if auditLogger != nil {
+		entry := &AuditEntry{
+			Tool:         "run_query",
+			Database:     database,
+			Query:        util.TruncateQuery(sqlText, 500),
+			DurationMs:   timer.ElapsedMs(),
+			RowCount:     len(result.Rows),
+			InputTokens:  inputTokens,
+			OutputTokens: outputTokens,
+			Success:      true,
+		}
+		if eff != nil {
+			entry.TokensPerRow = eff.TokensPerRow
+			entry.IOEfficiency = eff.IOEfficiency
+			entry.CostEstimateUSD = eff.CostEstimateUSD
+		}
+		auditLogger.Log(entry)
+	}
+
+	return nil, result, nil
+}
+
+// scanQueryResult reads up to limit rows into a QueryResult, normalizing
+// driver values into JSON-friendly types.
+func scanQueryResult(rows *sql.Rows, limit int) (QueryResult, error) {
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, QueryResult{}, fmt.Errorf("get columns failed: %w", err)
+		return QueryResult{}, fmt.Errorf("get columns failed: %w", err)
 	}
 
 	result := QueryResult{
@@ -281,7 +334,7 @@ func toolRunQuery(
 		}
 
 		if err := rows.Scan(dest...); err != nil {
-			return nil, QueryResult{}, fmt.Errorf("scan row failed: %w", err)
+			return QueryResult{}, fmt.Errorf("scan row failed: %w", err)
 		}
 
 		rowVals := make([]interface{}, len(cols))
@@ -295,39 +348,28 @@ func toolRunQuery(
 		}
 	}
 	if err := rows.Err(); err != nil {
-		return nil, QueryResult{}, err
+		return QueryResult{}, err
 	}
 
-	// Token estimation for output (optional)
-	outputTokens, _ := estimateTokensForValue(result)
-	tokens.OutputEstimated = outputTokens
-	tokens.TotalEstimated = inputTokens + outputTokens
-
-	// Calculate efficiency metrics
-	eff := CalculateEfficiency(inputTokens, outputTokens, len(result.Rows))
+	return result, nil
+}
 
-	// Log success
-	timer.LogSuccess(len(result.Rows), sqlText, tokens, eff)
-	if auditLogger != nil {
-		entry := &AuditEntry{
-			Tool:         "run_query",
-			Database:     database,
-			Query:        util.TruncateQuery(sqlText, 500),
-			DurationMs:   timer.ElapsedMs(),
-			RowCount:     len(result.Rows),
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			Success:      true,
+// maskQueryResult replaces values of columns matched by the masking rules
+// of m. Non-NULL values become util.MaskedValue; NULLs stay NULL.
+func maskQueryResult(m *util.ColumnMasker, result *QueryResult, database, table string) {
+	if !m.Enabled() {
+		return
+	}
+	for i, col := range result.Columns {
+		if !m.ShouldMask(database, table, col) {
+			continue
 		}
-		if eff != nil {
-			entry.TokensPerRow = eff.TokensPerRow
-			entry.IOEfficiency = eff.IOEfficiency
-			entry.CostEstimateUSD = eff.CostEstimateUSD
+		for _, row := range result.Rows {
+			if row[i] != nil {
+				row[i] = util.MaskedValue
+			}
 		}
-		auditLogger.Log(entry)
 	}
-
-	return nil, result, nil
 }
 
 func toolPing(
@@ -336,7 +378,7 @@ func toolPing(
 	input PingInput,
 ) (*mcp.CallToolResult, PingOutput, error) {
 
-	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).pingTimeout)
 	defer cancel()
 
 	start := NewQueryTimer("ping")
@@ -364,7 +406,7 @@ func toolServerInfo(
 	input ServerInfoInput,
 ) (*mcp.CallToolResult, ServerInfoOutput, error) {
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
 	defer cancel()
 
 	out := ServerInfoOutput{}
@@ -469,24 +511,44 @@ func toolListConnections(
 		return nil, ListConnectionsOutput{}, fmt.Errorf("connection manager not initialized")
 	}
 
-	configs := connManager.List()
 	_, activeName := connManager.GetActive()
-
-	out := ListConnectionsOutput{
-		Connections: make([]ConnectionInfo, 0, len(configs)),
+	return nil, ListConnectionsOutput{
+		Connections: connectionInfos(activeName),
 		Active:      activeName,
-	}
+	}, nil
+}
 
+// connectionInfos describes all connections, sorted by name.
+func connectionInfos(activeName string) []ConnectionInfo {
+	configs := connManager.List()
+	infos := make([]ConnectionInfo, 0, len(configs))
 	for _, cfg := range configs {
-		out.Connections = append(out.Connections, ConnectionInfo{
+		info := ConnectionInfo{
 			Name:        cfg.Name,
 			DSN:         cfg.DSN, // Already masked
 			Description: cfg.Description,
 			Active:      cfg.Name == activeName,
-		})
+		}
+		if st, ok := connManager.Status(cfg.Name); ok {
+			info.State = st.State
+			info.LastError = st.LastError
+			info.Breaker = st.Breaker
+			if !st.LastSuccess.IsZero() {
+				info.LastSuccess = st.LastSuccess.UTC().Format(time.RFC3339)
+			}
+			if !st.NextRetry.IsZero() {
+				info.NextRetry = st.NextRetry.UTC().Format(time.RFC3339)
+			}
+			if !st.UnhealthySince.IsZero() {
+				info.UnhealthySince = st.UnhealthySince.UTC().Format(time.RFC3339)
+			}
+		}
+		infos = append(infos, info)
 	}
-
-	return nil, out, nil
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
 }
 
 func toolUseConnection(
@@ -509,10 +571,26 @@ func toolUseConnection(
 		}, nil
 	}
 
+	// A connection that is not reachable yet can still be selected; tools
+	// report its state until it is.
+	if err := connManager.Ready(ctx, input.Name); err != nil {
+		logWarn("switched to an unavailable connection", map[string]interface{}{
+			"connection": input.Name,
+			"error":      err.Error(),
+		})
+		return nil, UseConnectionOutput{
+			Success: true,
+			Active:  input.Name,
+			Message: fmt.Sprintf("Switched to connection '%s', but %v", input.Name, err),
+		}, nil
+	}
+
 	// Get current database (informational, don't fail if this errors)
 	var currentDB sql.NullString
 	var dbQueryErr error
-	if err := getDB().QueryRowContext(ctx, "SELECT DATABASE()").Scan(&currentDB); err != nil {
+	err := getDB().QueryRowContext(ctx, "SELECT DATABASE()").Scan(&currentDB)
+	connManager.Record(input.Name, err)
+	if err != nil {
 		dbQueryErr = err
 		logWarn("failed to get current database after connection switch", map[string]interface{}{
 			"connection": input.Name,
@@ -0,0 +1,1509 @@
+// cmd/mysql-mcp-server/tools_advisor.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/askdba/mysql-mcp-server/internal/advisor"
+	"github.com/askdba/mysql-mcp-server/internal/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits for the advisor tools.
+const (
+	maxAdvisorDigests        = 50 // full-scan digests analyzed for missing indexes
+	maxSuggestedIndexColumns = 4
+	minUsageStatsSecs        = 7 * 24 * 3600 // below this, "unused" is likely to be noise
+)
+
+// indexAdvisorChecks lists the checks index_advisor can run.
+var indexAdvisorChecks = map[string]bool{
+	"unused":    true,
+	"redundant": true,
+	"missing":   true,
+}
+
+// indexDef is one index as described by information_schema.STATISTICS.
+type indexDef struct {
+	name       string
+	unique     bool
+	indexType  string
+	columns    []string // prefix indexes are shown as "col(n)"
+	functional bool
+}
+
+func toolIndexAdvisor(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input IndexAdvisorInput,
+) (*mcp.CallToolResult, IndexAdvisorOutput, error) {
+	if input.Database == "" {
+		return nil, IndexAdvisorOutput{}, fmt.Errorf("database is required")
+	}
+	if _, err := util.QuoteIdent(input.Database); err != nil {
+		return nil, IndexAdvisorOutput{}, fmt.Errorf("invalid database name: %w", err)
+	}
+	if input.Table != "" {
+		if _, err := util.QuoteIdent(input.Table); err != nil {
+			return nil, IndexAdvisorOutput{}, fmt.Errorf("invalid table name: %w", err)
+		}
+	}
+	want := make(map[string]bool)
+	for _, name := range strings.Split(input.Checks, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !indexAdvisorChecks[name] {
+			return nil, IndexAdvisorOutput{}, fmt.Errorf("unknown check: %s", name)
+		}
+		want[name] = true
+	}
+	if len(want) == 0 {
+		want = indexAdvisorChecks
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	db := getDB()
+	indexes, err := loadIndexDefs(ctx, db, input.Database, input.Table)
+	if err != nil {
+		return nil, IndexAdvisorOutput{}, err
+	}
+
+	out := IndexAdvisorOutput{Database: input.Database}
+	sizes, err := loadIndexSizes(ctx, db, input.Database)
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("index sizes unavailable: %v", err))
+	}
+
+	if want["unused"] {
+		var uptime sql.NullInt64
+		if err := db.QueryRowContext(ctx, `SELECT VARIABLE_VALUE FROM performance_schema.global_status
+			WHERE VARIABLE_NAME = 'Uptime'`).Scan(&uptime); err == nil {
+			out.StatsSinceSecs = uptime.Int64
+			if uptime.Int64 < minUsageStatsSecs {
+				out.Warnings = append(out.Warnings, fmt.Sprintf(
+					"usage statistics only cover %s since the last restart; indexes used by periodic jobs may be reported as unused",
+					time.Duration(uptime.Int64)*time.Second))
+			}
+		}
+		out.Unused, err = unusedIndexes(ctx, db, input.Database, input.Table, indexes, sizes)
+		if err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("unused index check skipped: %v", err))
+		}
+	}
+	if want["redundant"] {
+		out.Redundant = redundantIndexes(input.Database, indexes, sizes)
+	}
+	if want["missing"] {
+		var unparsed int
+		out.Missing, unparsed, err = missingIndexes(ctx, db, input.Database, input.Table, indexes)
+		if err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("missing index check skipped: %v", err))
+		}
+		if unparsed > 0 {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%d full-scan statements could not be parsed", unparsed))
+		}
+	}
+
+	return nil, out, nil
+}
+
+// loadIndexDefs returns the indexes of every table in a database (or of one
+// table), with PRIMARY first and the rest sorted by name.
+func loadIndexDefs(ctx context.Context, db *sql.DB, database, table string) (map[string][]*indexDef, error) {
+	query := `SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME, SUB_PART
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?`
+	args := []interface{}{database}
+	if table != "" {
+		query += " AND TABLE_NAME = ?"
+		args = append(args, table)
+	}
+	query += " ORDER BY TABLE_NAME, INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string][]*indexDef)
+	for rows.Next() {
+		var tableName, index, indexType string
+		var nonUnique int
+		var column sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&tableName, &index, &nonUnique, &indexType, &column, &subPart); err != nil {
+			return nil, fmt.Errorf("scan index failed: %w", err)
+		}
+		defs := out[tableName]
+		if n := len(defs); n == 0 || defs[n-1].name != index {
+			defs = append(defs, &indexDef{name: index, unique: nonUnique == 0, indexType: indexType})
+			out[tableName] = defs
+		}
+		def := defs[len(defs)-1]
+		switch {
+		case !column.Valid:
+			def.functional = true
+			def.columns = append(def.columns, "(expression)")
+		case subPart.Valid:
+			def.columns = append(def.columns, fmt.Sprintf("%s(%d)", column.String, subPart.Int64))
+		default:
+			def.columns = append(def.columns, column.String)
+		}
+	}
+	return out, rows.Err()
+}
+
+// loadIndexSizes returns the on-disk size of each index keyed by table and
+// index name, summing partitions. Reading mysql.innodb_index_stats needs
+// SELECT on the mysql schema, so callers treat failure as non-fatal.
+func loadIndexSizes(ctx context.Context, db *sql.DB, database string) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, `SELECT table_name, index_name, stat_value * @@innodb_page_size
+		FROM mysql.innodb_index_stats
+		WHERE database_name = ? AND stat_name = 'size'`, database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]int64)
+	for rows.Next() {
+		var table, index string
+		var size int64
+		if err := rows.Scan(&table, &index, &size); err != nil {
+			return nil, fmt.Errorf("scan index size failed: %w", err)
+		}
+		table, _, _ = strings.Cut(table, "#") // partitions are stored as t#p#p0
+		out[table+"\x00"+index] += size
+	}
+	return out, rows.Err()
+}
+
+func findIndex(defs []*indexDef, name string) *indexDef {
+	for _, def := range defs {
+		if def.name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// unusedIndexes reports secondary indexes performance_schema has seen no
+// I/O on. Unique indexes are left out because they enforce a constraint.
+func unusedIndexes(ctx context.Context, db *sql.DB, database, table string, indexes map[string][]*indexDef, sizes map[string]int64) ([]IndexSuggestion, error) {
+	query := `SELECT OBJECT_NAME, INDEX_NAME
+		FROM performance_schema.table_io_waits_summary_by_index_usage
+		WHERE OBJECT_SCHEMA = ? AND INDEX_NAME IS NOT NULL AND INDEX_NAME <> 'PRIMARY' AND COUNT_STAR = 0`
+	args := []interface{}{database}
+	if table != "" {
+		query += " AND OBJECT_NAME = ?"
+		args = append(args, table)
+	}
+	query += " ORDER BY OBJECT_NAME, INDEX_NAME"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("index usage query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var out []IndexSuggestion
+	for rows.Next() {
+		var tableName, index string
+		if err := rows.Scan(&tableName, &index); err != nil {
+			return nil, fmt.Errorf("scan index usage failed: %w", err)
+		}
+		def := findIndex(indexes[tableName], index)
+		if def == nil || def.unique {
+			continue
+		}
+		size := sizes[tableName+"\x00"+index]
+		out = append(out, IndexSuggestion{
+			Table:            tableName,
+			Index:            index,
+			Columns:          def.columns,
+			Reason:           "no reads or writes through this index since the server started",
+			SizeBytes:        size,
+			Benefit:          sizeBenefit(size),
+			EstimatedBenefit: dropIndexBenefit(size),
+			DDL:              dropIndexDDL(database, tableName, index),
+		})
+	}
+	return out, rows.Err()
+}
+
+// redundantIndexes reports indexes that duplicate another index or are a
+// left prefix of one, like sys.schema_redundant_indexes. Of two identical
+// indexes the one kept is PRIMARY, then a unique index, then the first by
+// name. A unique index is never reported as a prefix of a longer index
+// because the longer one does not enforce the same constraint.
+func redundantIndexes(database string, indexes map[string][]*indexDef, sizes map[string]int64) []IndexSuggestion {
+	tables := make([]string, 0, len(indexes))
+	for table := range indexes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var out []IndexSuggestion
+	for _, table := range tables {
+		defs := indexes[table]
+		for _, r := range defs {
+			if r.name == "PRIMARY" || !redundancyCandidate(r) {
+				continue
+			}
+			for _, d := range defs {
+				if d == r || !redundancyCandidate(d) || d.indexType != r.indexType {
+					continue
+				}
+				var reason string
+				switch {
+				case equalColumns(r.columns, d.columns) && outranks(d, r):
+					reason = fmt.Sprintf("duplicates index %s", d.name)
+				case r.indexType == "BTREE" && !r.unique && len(r.columns) < len(d.columns) && equalColumns(r.columns, d.columns[:len(r.columns)]):
+					reason = fmt.Sprintf("left prefix of index %s (%s)", d.name, strings.Join(d.columns, ", "))
+				default:
+					continue
+				}
+				size := sizes[table+"\x00"+r.name]
+				out = append(out, IndexSuggestion{
+					Table:            table,
+					Index:            r.name,
+					Columns:          r.columns,
+					Reason:           reason,
+					CoveredBy:        d.name,
+					SizeBytes:        size,
+					Benefit:          sizeBenefit(size),
+					EstimatedBenefit: dropIndexBenefit(size),
+					DDL:              dropIndexDDL(database, table, r.name),
+				})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// redundancyCandidate excludes index kinds whose column order has no
+// prefix semantics.
+func redundancyCandidate(def *indexDef) bool {
+	return !def.functional && def.indexType != "FULLTEXT" && def.indexType != "SPATIAL"
+}
+
+func indexRank(def *indexDef) int {
+	switch {
+	case def.name == "PRIMARY":
+		return 2
+	case def.unique:
+		return 1
+	}
+	return 0
+}
+
+// outranks reports whether a should be kept over b when both have the same columns.
+func outranks(a, b *indexDef) bool {
+	ra, rb := indexRank(a), indexRank(b)
+	if ra != rb {
+		return ra > rb
+	}
+	return a.name < b.name
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// missingIndexAgg accumulates the full-scan digests that share an index candidate.
+type missingIndexAgg struct {
+	table                 string
+	columns               []string
+	digests               []string
+	calls, examined, sent int64
+}
+
+// missingIndexes derives index candidates from statement digests that ran
+// without using an index: equality columns first, then the first range
+// column. Candidates already served by the left prefix of an existing
+// index are skipped. It returns the number of statements that could not
+// be parsed alongside the suggestions.
+func missingIndexes(ctx context.Context, db *sql.DB, database, table string, indexes map[string][]*indexDef) ([]IndexSuggestion, int, error) {
+	snapshot, err := readDigestSnapshot(ctx, db, database)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scans := make([]digestStats, 0, len(snapshot))
+	for _, d := range snapshot {
+		if d.noIndexUsed > 0 {
+			scans = append(scans, d)
+		}
+	}
+	sort.Slice(scans, func(i, j int) bool {
+		if scans[i].rowsExamined != scans[j].rowsExamined {
+			return scans[i].rowsExamined > scans[j].rowsExamined
+		}
+		return scans[i].digest < scans[j].digest
+	})
+	if len(scans) > maxAdvisorDigests {
+		scans = scans[:maxAdvisorDigests]
+	}
+
+	var unparsed int
+	var order []string
+	aggs := make(map[string]*missingIndexAgg)
+	for _, d := range scans {
+		text := d.sample
+		if text == "" {
+			text = d.text
+		}
+		filters, err := util.ExtractTableFilters(text)
+		if err != nil {
+			unparsed++
+			continue
+		}
+		for _, f := range filters {
+			if f.Schema != "" && !strings.EqualFold(f.Schema, database) {
+				continue
+			}
+			if table != "" && !strings.EqualFold(f.Table, table) {
+				continue
+			}
+			columns := append([]string{}, f.Equality...)
+			if len(f.Range) > 0 {
+				columns = append(columns, f.Range[0])
+			}
+			if len(columns) > maxSuggestedIndexColumns {
+				columns = columns[:maxSuggestedIndexColumns]
+			}
+			if hasLeftPrefixIndex(indexes[f.Table], columns) {
+				continue
+			}
+
+			key := strings.ToLower(f.Table + "\x00" + strings.Join(columns, ","))
+			agg := aggs[key]
+			if agg == nil {
+				agg = &missingIndexAgg{table: f.Table, columns: columns}
+				aggs[key] = agg
+				order = append(order, key)
+			}
+			agg.digests = append(agg.digests, d.digest)
+			agg.calls += d.calls
+			agg.examined += d.rowsExamined
+			agg.sent += d.rowsSent
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return aggs[order[i]].examined > aggs[order[j]].examined })
+	out := make([]IndexSuggestion, 0, len(order))
+	for _, key := range order {
+		agg := aggs[key]
+		name := proposedIndexName(agg.columns)
+		out = append(out, IndexSuggestion{
+			Table:   agg.table,
+			Index:   name,
+			Columns: agg.columns,
+			Reason:  "statements filtering on these columns scanned the table without an index",
+			Digests: agg.digests,
+			Benefit: scanBenefit(agg.examined, agg.calls),
+			EstimatedBenefit: fmt.Sprintf("%d executions examined %d rows to return %d; an index would read only matching rows",
+				agg.calls, agg.examined, agg.sent),
+			DDL: addIndexDDL(database, agg.table, name, agg.columns),
+		})
+	}
+	return out, unparsed, nil
+}
+
+// hasLeftPrefixIndex reports whether an index starts with the given columns.
+// Prefix key parts such as "name(10)" count as the full column.
+func hasLeftPrefixIndex(defs []*indexDef, columns []string) bool {
+	for _, def := range defs {
+		if len(def.columns) < len(columns) {
+			continue
+		}
+		leading := make([]string, len(columns))
+		for i, col := range def.columns[:len(columns)] {
+			leading[i], _, _ = strings.Cut(col, "(")
+		}
+		if equalColumns(columns, leading) {
+			return true
+		}
+	}
+	return false
+}
+
+func proposedIndexName(columns []string) string {
+	name := "idx_" + strings.ToLower(strings.Join(columns, "_"))
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// quoteDDLIdent quotes an identifier read from the server for display in
+// suggested DDL, escaping embedded backticks.
+func quoteDDLIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func dropIndexDDL(database, table, index string) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s DROP INDEX %s;", quoteDDLIdent(database), quoteDDLIdent(table), quoteDDLIdent(index))
+}
+
+func addIndexDDL(database, table, index string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteDDLIdent(col)
+	}
+	return fmt.Sprintf("ALTER TABLE %s.%s ADD INDEX %s (%s);",
+		quoteDDLIdent(database), quoteDDLIdent(table), quoteDDLIdent(index), strings.Join(quoted, ", "))
+}
+
+// sizeBenefit grades dropping an index by the space it frees.
+func sizeBenefit(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return "high"
+	case size >= 100<<20:
+		return "medium"
+	}
+	return "low"
+}
+
+func dropIndexBenefit(size int64) string {
+	if size <= 0 {
+		return "removes index maintenance from every write to the table"
+	}
+	return fmt.Sprintf("frees %.1f MB and removes index maintenance from every write to the table", float64(size)/(1<<20))
+}
+
+// scanBenefit grades a missing index by the rows its statements examine per execution.
+func scanBenefit(examined, calls int64) string {
+	if calls < 1 {
+		calls = 1
+	}
+	switch perCall := examined / calls; {
+	case perCall >= 100000:
+		return "high"
+	case perCall >= 1000:
+		return "medium"
+	}
+	return "low"
+}
+
+func toolLintQuery(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input LintQueryInput,
+) (*mcp.CallToolResult, LintQueryOutput, error) {
+	sqlText := strings.TrimSpace(input.SQL)
+	if sqlText == "" {
+		return nil, LintQueryOutput{}, fmt.Errorf("sql is required")
+	}
+	if input.Database != "" {
+		if _, err := util.QuoteIdent(input.Database); err != nil {
+			return nil, LintQueryOutput{}, fmt.Errorf("invalid database name: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	findings, err := lintStatement(ctx, getDB(), sqlText, input.Database)
+	if err != nil {
+		return nil, LintQueryOutput{}, fmt.Errorf("failed to parse query: %w", err)
+	}
+	if findings == nil {
+		findings = []LintFinding{}
+	}
+	return nil, LintQueryOutput{Findings: findings}, nil
+}
+
+// lintStatement runs the query linter with column metadata read from
+// information_schema. Unqualified tables are looked up in database, or in
+// the connection's default database when database is empty.
+func lintStatement(ctx context.Context, db *sql.DB, sqlText, database string) ([]LintFinding, error) {
+	lookup := func(schema, table string) *util.LintTable {
+		if schema == "" {
+			schema = database
+		}
+		t, err := loadLintTable(ctx, db, schema, table)
+		if err != nil {
+			return nil
+		}
+		return t
+	}
+
+	found, err := util.LintQuery(sqlText, lookup)
+	if err != nil {
+		return nil, err
+	}
+	var out []LintFinding
+	for _, f := range found {
+		out = append(out, LintFinding{
+			Rule:       f.Rule,
+			Severity:   f.Severity,
+			Fragment:   f.Fragment,
+			Message:    f.Message,
+			Suggestion: f.Suggestion,
+			Rewrite:    f.Rewrite,
+		})
+	}
+	return out, nil
+}
+
+// loadLintTable reads the columns of a table with their type, nullability
+// and whether they lead an index. It returns nil for unknown tables.
+func loadLintTable(ctx context.Context, db *sql.DB, schema, table string) (*util.LintTable, error) {
+	rows, err := db.QueryContext(ctx, `SELECT c.COLUMN_NAME, c.DATA_TYPE, c.IS_NULLABLE = 'YES',
+			EXISTS (SELECT 1 FROM information_schema.STATISTICS s
+				WHERE s.TABLE_SCHEMA = c.TABLE_SCHEMA AND s.TABLE_NAME = c.TABLE_NAME
+				AND s.COLUMN_NAME = c.COLUMN_NAME AND s.SEQ_IN_INDEX = 1)
+		FROM information_schema.COLUMNS c
+		WHERE c.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND c.TABLE_NAME = ?
+		ORDER BY c.ORDINAL_POSITION`, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+
+	var t util.LintTable
+	for rows.Next() {
+		var col util.LintColumn
+		if err := rows.Scan(&col.Name, &col.DataType, &col.Nullable, &col.Indexed); err != nil {
+			return nil, fmt.Errorf("scan column failed: %w", err)
+		}
+		col.DataType = strings.ToLower(col.DataType)
+		t.Columns = append(t.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func toolConfigAdvisor(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ConfigAdvisorInput,
+) (*mcp.CallToolResult, ConfigAdvisorOutput, error) {
+	conf := settingsFrom(ctx)
+	rules := conf.advisorRules
+	if rules == nil {
+		rules = advisor.DefaultRules()
+	}
+	if input.Rules != "" {
+		known := make(map[string]advisor.Rule, len(rules))
+		for _, r := range rules {
+			known[r.ID] = r
+		}
+		var selected []advisor.Rule
+		for _, id := range strings.Split(input.Rules, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			r, ok := known[id]
+			if !ok {
+				return nil, ConfigAdvisorOutput{}, fmt.Errorf("unknown rule: %s", id)
+			}
+			selected = append(selected, r)
+		}
+		rules = selected
+	}
+	if input.MemoryBytes < 0 {
+		return nil, ConfigAdvisorOutput{}, fmt.Errorf("memory_bytes must not be negative")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
+	defer cancel()
+
+	db := getDB()
+	out := ConfigAdvisorOutput{Findings: []ConfigFinding{}}
+	facts := advisor.Facts{}
+	if err := loadNameValues(ctx, db, "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.global_variables", "", facts); err != nil {
+		return nil, ConfigAdvisorOutput{}, fmt.Errorf("failed to read global variables: %w", err)
+	}
+	if err := loadNameValues(ctx, db, "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.global_status", "status.", facts); err != nil {
+		return nil, ConfigAdvisorOutput{}, fmt.Errorf("failed to read global status: %w", err)
+	}
+
+	var dataBytes, innodbBytes float64
+	err := db.QueryRowContext(ctx, `SELECT
+			COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0),
+			COALESCE(SUM(CASE WHEN ENGINE = 'InnoDB' THEN DATA_LENGTH + INDEX_LENGTH END), 0)
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys')`).Scan(&dataBytes, &innodbBytes)
+	if err != nil {
+		out.Warnings = append(out.Warnings, "data size unavailable: "+err.Error())
+	} else {
+		facts.SetNumber("host.data_bytes", dataBytes)
+		facts.SetNumber("host.innodb_data_bytes", innodbBytes)
+	}
+	if input.MemoryBytes > 0 {
+		facts.SetNumber("host.memory_bytes", float64(input.MemoryBytes))
+	}
+
+	for _, res := range advisor.Evaluate(rules, facts) {
+		out.RulesEvaluated++
+		if res.Err != nil {
+			out.Skipped = append(out.Skipped, SkippedRule{Rule: res.Rule.ID, Reason: res.Err.Error()})
+			continue
+		}
+		if !res.Matched {
+			continue
+		}
+		out.Findings = append(out.Findings, ConfigFinding{
+			Rule:           res.Rule.ID,
+			Priority:       res.Rule.Priority,
+			Message:        res.Rule.Message,
+			Recommendation: res.Rule.Recommendation,
+			Evidence:       res.Evidence,
+		})
+	}
+
+	return nil, out, nil
+}
+
+// loadNameValues reads a two-column name/value query into facts, prefixing
+// each name.
+func loadNameValues(ctx context.Context, db *sql.DB, query, prefix string, facts advisor.Facts) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		facts.Set(prefix+name, value.String)
+	}
+	return rows.Err()
+}
+
+// securityAuditChecks lists the checks security_audit can run.
+var securityAuditChecks = map[string]bool{
+	"anonymous":       true,
+	"empty_password":  true,
+	"wildcard_host":   true,
+	"super":           true,
+	"all_privileges":  true,
+	"password_expiry": true,
+	"broad_roles":     true,
+}
+
+// mcpPrivileges are the privileges the server's tools can make use of.
+// Anything else held by a connection user is reported as excess.
+var mcpPrivileges = map[string]bool{
+	"USAGE":              true,
+	"SELECT":             true,
+	"SHOW VIEW":          true,
+	"SHOW DATABASES":     true,
+	"SHOW_ROUTINE":       true,
+	"PROCESS":            true,
+	"REPLICATION CLIENT": true,
+}
+
+// passwordPlugins authenticate with a stored password; accounts using other
+// plugins (auth_socket, PAM, LDAP) legitimately have an empty
+// authentication_string.
+var passwordPlugins = map[string]bool{
+	"":                      true,
+	"mysql_native_password": true,
+	"caching_sha2_password": true,
+	"sha256_password":       true,
+	"mysql_old_password":    true,
+}
+
+const defaultRoleGrantLimit = 5
+
+var severityOrder = map[string]int{"high": 0, "medium": 1, "low": 2}
+
+func toolSecurityAudit(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input SecurityAuditInput,
+) (*mcp.CallToolResult, SecurityAuditOutput, error) {
+	want := make(map[string]bool)
+	for _, name := range strings.Split(input.Checks, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !securityAuditChecks[name] {
+			return nil, SecurityAuditOutput{}, fmt.Errorf("unknown check: %s", name)
+		}
+		want[name] = true
+	}
+	if len(want) == 0 {
+		want = securityAuditChecks
+	}
+	if input.RoleGrantLimit < 0 {
+		return nil, SecurityAuditOutput{}, fmt.Errorf("role_grant_limit must not be negative")
+	}
+	roleLimit := input.RoleGrantLimit
+	if roleLimit == 0 {
+		roleLimit = defaultRoleGrantLimit
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	out := SecurityAuditOutput{Findings: []SecurityFinding{}}
+	for _, name := range connManager.Names() {
+		var cp ConnectionPrivileges
+		queried := false
+		err := withConnection(ctx, name, func(db *sql.DB) error {
+			var err error
+			cp, err = connectionPrivileges(ctx, db, name)
+			queried = true
+			return err
+		})
+		if err != nil && !queried {
+			cp = ConnectionPrivileges{Connection: name, Grants: []string{}, Excess: []string{}, Error: err.Error()}
+		}
+		out.Connections = append(out.Connections, cp)
+	}
+
+	_, active := connManager.GetActive()
+	checked := false
+	err := withConnection(ctx, active, func(db *sql.DB) error {
+		checked = true
+		return auditAccounts(ctx, db, want, input.IncludeLocked, roleLimit, &out)
+	})
+	if err != nil {
+		if !checked {
+			return nil, SecurityAuditOutput{}, err
+		}
+		out.Warnings = append(out.Warnings, fmt.Sprintf(
+			"account checks skipped: mysql.user is not readable (needs SELECT on mysql.user): %v", err))
+		return nil, out, nil
+	}
+
+	sort.SliceStable(out.Findings, func(i, j int) bool {
+		a, b := out.Findings[i], out.Findings[j]
+		if severityOrder[a.Severity] != severityOrder[b.Severity] {
+			return severityOrder[a.Severity] < severityOrder[b.Severity]
+		}
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		return a.Account < b.Account
+	})
+	return nil, out, nil
+}
+
+// auditAccounts runs the account checks of security_audit on db, adding
+// their findings and warnings to out. It returns the error of reading
+// mysql.user, in which case no account was checked.
+func auditAccounts(ctx context.Context, db *sql.DB, want map[string]bool, includeLocked bool, roleLimit int, out *SecurityAuditOutput) error {
+	accounts, err := loadAccounts(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	vars := make(map[string]string)
+	if err := loadVariables(ctx, db, vars, "default_password_lifetime", "mandatory_roles"); err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("global variables unavailable: %v", err))
+	}
+
+	var grantErrors int
+	var firstGrantErr error
+	for _, a := range accounts {
+		if a.locked && !includeLocked {
+			continue
+		}
+		out.AccountsChecked++
+		name := util.QuoteAccount(a.user, a.host)
+		add := func(check, severity, message, recommendation string) {
+			out.Findings = append(out.Findings, SecurityFinding{
+				Check:          check,
+				Severity:       severity,
+				Account:        name,
+				Message:        message,
+				Recommendation: recommendation,
+			})
+		}
+
+		if want["anonymous"] && a.user == "" {
+			add("anonymous", "high", "Anonymous account: any user name is accepted from matching hosts.",
+				"DROP USER "+name+";")
+		}
+		if want["empty_password"] && a.passwordAuth && a.authString == "" {
+			add("empty_password", "high", "The account has no password.",
+				"ALTER USER "+name+" IDENTIFIED BY '<password>';")
+		}
+		if want["wildcard_host"] {
+			switch {
+			case a.host == "%" || a.host == "":
+				add("wildcard_host", "medium", "The account can connect from any host.",
+					"Restrict the host part to the application's addresses or subnet.")
+			case strings.Contains(a.host, "%"):
+				add("wildcard_host", "low", fmt.Sprintf("The account can connect from any host matching %q.", a.host),
+					"Check that the host pattern is no wider than needed.")
+			}
+		}
+
+		// MySQL 8.0 prints GRANT ALL ON *.* as the expanded privilege list,
+		// so global ALL is read from mysql.user as well as from SHOW GRANTS.
+		globalAll := a.allPrivs
+		if want["all_privileges"] || want["super"] {
+			grants, err := loadGrants(ctx, db, "SHOW GRANTS FOR "+name)
+			if err != nil {
+				grantErrors++
+				if firstGrantErr == nil {
+					firstGrantErr = err
+				}
+			}
+			for _, g := range grants {
+				if len(g.Privileges) != 1 || g.Privileges[0] != "ALL PRIVILEGES" {
+					continue
+				}
+				if g.Global() {
+					globalAll = true
+				} else if want["all_privileges"] {
+					add("all_privileges", "low", "The account has ALL PRIVILEGES on "+g.Object+".",
+						"Grant only the privileges the account needs on "+g.Object+".")
+				}
+			}
+		}
+		if want["all_privileges"] && globalAll {
+			add("all_privileges", "high", "The account has ALL PRIVILEGES on *.*.",
+				"Grant only the privileges the account needs, on the schemas it uses.")
+		}
+		// ALL PRIVILEGES on *.* includes SUPER; report it once.
+		if want["super"] && a.superPriv && !(globalAll && want["all_privileges"]) {
+			add("super", "high", "The account has the SUPER privilege.",
+				"REVOKE SUPER ON *.* FROM "+name+"; grant the specific dynamic privileges it needs instead.")
+		}
+
+		if want["password_expiry"] && a.passwordAuth && a.authString != "" && a.hasLifetime {
+			lifetime := a.lifetime
+			if !lifetime.Valid {
+				// NULL: the account follows default_password_lifetime.
+				if v, ok := vars["default_password_lifetime"]; ok {
+					n, _ := strconv.ParseInt(v, 10, 64)
+					lifetime = sql.NullInt64{Int64: n, Valid: true}
+				}
+			}
+			if lifetime.Valid && lifetime.Int64 == 0 {
+				add("password_expiry", "low", "The account's password never expires.",
+					"ALTER USER "+name+" PASSWORD EXPIRE INTERVAL 90 DAY; or set default_password_lifetime.")
+			}
+		}
+	}
+	if grantErrors > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf(
+			"SHOW GRANTS failed for %d accounts; privilege checks are incomplete: %v", grantErrors, firstGrantErr))
+	}
+	if want["password_expiry"] && len(accounts) > 0 && !accounts[0].hasLifetime {
+		out.Warnings = append(out.Warnings, "password_expiry check skipped: this server has no per-account password lifetime")
+	}
+
+	if want["broad_roles"] {
+		if roles := strings.TrimSpace(vars["mandatory_roles"]); roles != "" {
+			out.Findings = append(out.Findings, SecurityFinding{
+				Check:          "broad_roles",
+				Severity:       "medium",
+				Account:        roles,
+				Message:        "mandatory_roles grants these roles to every account.",
+				Recommendation: "Keep mandatory roles to minimal, read-only privileges.",
+			})
+		}
+		findings, err := broadRoleGrants(ctx, db, roleLimit)
+		if err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("broad_roles check skipped: mysql.role_edges is not readable: %v", err))
+		}
+		out.Findings = append(out.Findings, findings...)
+	}
+
+	return nil
+}
+
+// auditAccount is the part of a mysql.user row security_audit looks at.
+type auditAccount struct {
+	user, host   string
+	authString   string
+	passwordAuth bool // authenticates with a stored password
+	locked       bool
+	superPriv    bool
+	allPrivs     bool // every global privilege column except Grant_priv is 'Y'
+	hasLifetime  bool // the server has mysql.user.password_lifetime
+	lifetime     sql.NullInt64
+}
+
+// loadAccounts reads mysql.user. Columns are looked up by name because they
+// differ between MySQL and MariaDB versions; roles are left out.
+func loadAccounts(ctx context.Context, db *sql.DB) ([]auditAccount, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM mysql.user")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var accounts []auditAccount
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]sql.NullString, len(cols))
+		for i, c := range cols {
+			row[strings.ToLower(c)] = values[i]
+		}
+		if strings.EqualFold(row["is_role"].String, "Y") {
+			continue
+		}
+
+		a := auditAccount{
+			user:         row["user"].String,
+			host:         row["host"].String,
+			authString:   row["authentication_string"].String,
+			passwordAuth: passwordPlugins[strings.ToLower(row["plugin"].String)],
+			locked:       strings.EqualFold(row["account_locked"].String, "Y"),
+			superPriv:    strings.EqualFold(row["super_priv"].String, "Y"),
+		}
+		if a.authString == "" {
+			a.authString = row["password"].String // MySQL 5.6 and older MariaDB
+		}
+		privCols := 0
+		a.allPrivs = true
+		for c, v := range row {
+			if !strings.HasSuffix(c, "_priv") || c == "grant_priv" {
+				continue
+			}
+			privCols++
+			if !strings.EqualFold(v.String, "Y") {
+				a.allPrivs = false
+			}
+		}
+		a.allPrivs = a.allPrivs && privCols > 0
+		if v, ok := row["password_lifetime"]; ok {
+			a.hasLifetime = true
+			if v.Valid {
+				n, err := strconv.ParseInt(v.String, 10, 64)
+				a.lifetime = sql.NullInt64{Int64: n, Valid: err == nil}
+			}
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// loadVariables reads the named global variables into vars.
+func loadVariables(ctx context.Context, db *sql.DB, vars map[string]string, names ...string) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	args := make([]interface{}, len(names))
+	for i, n := range names {
+		args[i] = n
+	}
+	rows, err := db.QueryContext(ctx, `SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.global_variables
+		WHERE VARIABLE_NAME IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		vars[strings.ToLower(name)] = value.String
+	}
+	return rows.Err()
+}
+
+// loadGrants runs a SHOW GRANTS statement and parses its output.
+func loadGrants(ctx context.Context, db *sql.DB, query string) ([]util.Grant, error) {
+	lines, err := showGrants(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	var grants []util.Grant
+	for _, line := range lines {
+		if g, ok := util.ParseGrant(line); ok {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+// showGrants returns the statements printed by a SHOW GRANTS query, with
+// password hashes (MariaDB's "IDENTIFIED BY PASSWORD '...'") removed.
+func showGrants(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if i := strings.Index(line, " IDENTIFIED BY"); i >= 0 {
+			line = line[:i]
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// connectionPrivileges reports the account a connection logs in as and the
+// privileges it holds beyond what the server's tools use.
+func connectionPrivileges(ctx context.Context, db *sql.DB, name string) (ConnectionPrivileges, error) {
+	cp := ConnectionPrivileges{Connection: name, Grants: []string{}, Excess: []string{}}
+	if err := db.QueryRowContext(ctx, "SELECT CURRENT_USER()").Scan(&cp.Account); err != nil {
+		cp.Error = err.Error()
+		return cp, err
+	}
+	lines, err := showGrants(ctx, db, "SHOW GRANTS")
+	if err != nil {
+		cp.Error = err.Error()
+		return cp, err
+	}
+	cp.Grants = lines
+	for _, line := range lines {
+		g, ok := util.ParseGrant(line)
+		if !ok {
+			continue
+		}
+		cp.Roles = append(cp.Roles, g.Roles...)
+		for _, p := range g.Privileges {
+			if !mcpPrivileges[p] {
+				cp.Excess = append(cp.Excess, p+" ON "+g.Object)
+			}
+		}
+		if g.GrantOption && g.Object != "" {
+			cp.Excess = append(cp.Excess, "GRANT OPTION ON "+g.Object)
+		}
+	}
+	cp.LeastPrivilege = len(cp.Excess) == 0 && len(cp.Roles) == 0
+	return cp, nil
+}
+
+// broadRoleGrants reports roles granted to more than limit accounts and
+// role grants that allow the grantee to pass the role on.
+func broadRoleGrants(ctx context.Context, db *sql.DB, limit int) ([]SecurityFinding, error) {
+	rows, err := db.QueryContext(ctx, `SELECT FROM_USER, FROM_HOST, TO_USER, TO_HOST, WITH_ADMIN_OPTION
+		FROM mysql.role_edges ORDER BY FROM_USER, FROM_HOST, TO_USER, TO_HOST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []SecurityFinding
+	grantees := make(map[string]int)
+	var roles []string
+	for rows.Next() {
+		var fromUser, fromHost, toUser, toHost, admin string
+		if err := rows.Scan(&fromUser, &fromHost, &toUser, &toHost, &admin); err != nil {
+			return nil, err
+		}
+		role := util.QuoteAccount(fromUser, fromHost)
+		if grantees[role] == 0 {
+			roles = append(roles, role)
+		}
+		grantees[role]++
+		if strings.EqualFold(admin, "Y") {
+			findings = append(findings, SecurityFinding{
+				Check:          "broad_roles",
+				Severity:       "low",
+				Account:        util.QuoteAccount(toUser, toHost),
+				Message:        "The account can grant role " + role + " to others (WITH ADMIN OPTION).",
+				Recommendation: "Re-grant the role without WITH ADMIN OPTION unless the account administers it.",
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if n := grantees[role]; n > limit {
+			findings = append(findings, SecurityFinding{
+				Check:          "broad_roles",
+				Severity:       "medium",
+				Account:        role,
+				Message:        fmt.Sprintf("The role is granted to %d accounts.", n),
+				Recommendation: "Review whether every grantee needs the role's privileges, or split it into narrower roles.",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// Thresholds for capacity_check.
+const (
+	maxCapacityWindow    = 300                // seconds
+	minCapacitySampleAge = time.Minute        // younger history is too noisy to project from
+	maxCapacitySampleAge = 7 * 24 * time.Hour // older samples are replaced once used so rates stay current
+	capacityCriticalPct  = 85.0
+	capacityWarningPct   = 50.0
+	capacityCriticalDays = 30.0
+	capacityWarningDays  = 365.0
+	intKeyHorizonDays    = 3 * 365.0 // INT keys projected to run out within this are flagged
+	intKeyLargeFraction  = 0.1       // without a rate, flag INT keys whose table holds this share of the range
+)
+
+// integerMax is the largest value of each integer type, signed and unsigned.
+var integerMax = map[string][2]uint64{
+	"tinyint":   {127, 255},
+	"smallint":  {32767, 65535},
+	"mediumint": {8388607, 16777215},
+	"int":       {2147483647, 4294967295},
+	"bigint":    {9223372036854775807, 18446744073709551615},
+}
+
+// capacitySample is one observation of a table, kept between capacity_check
+// calls so growth can be projected from two samples.
+type capacitySample struct {
+	at      time.Time
+	nextVal uint64
+	rows    int64
+}
+
+var (
+	capacityMu      sync.Mutex
+	capacitySamples = make(map[string]capacitySample) // keyed by connection, schema and table
+)
+
+// capacityColumn is an integer column that is AUTO_INCREMENT or part of a
+// primary key, with its table's counters.
+type capacityColumn struct {
+	schema, table, column string
+	dataType, columnType  string
+	autoIncrement         bool
+	primary               bool
+	nextVal               uint64 // AUTO_INCREMENT counter; BIGINT UNSIGNED can exceed int64
+	hasNextVal            bool
+	rows                  int64
+	totalMB               float64
+}
+
+func (c capacityColumn) key() string {
+	return c.schema + "." + c.table
+}
+
+func (c capacityColumn) max() uint64 {
+	m := integerMax[c.dataType]
+	if strings.Contains(c.columnType, "unsigned") {
+		return m[1]
+	}
+	return m[0]
+}
+
+func toolCapacityCheck(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input CapacityCheckInput,
+) (*mcp.CallToolResult, CapacityCheckOutput, error) {
+	conf := settingsFrom(ctx)
+	if input.Database != "" {
+		if _, err := util.QuoteIdent(input.Database); err != nil {
+			return nil, CapacityCheckOutput{}, fmt.Errorf("invalid database name: %w", err)
+		}
+	}
+	if input.Window < 0 || input.Window > maxCapacityWindow {
+		return nil, CapacityCheckOutput{}, fmt.Errorf("window must be between 0 and %d seconds", maxCapacityWindow)
+	}
+	if err := checkWindowDeadline(ctx, input.Window, conf.queryTimeout); err != nil {
+		return nil, CapacityCheckOutput{}, err
+	}
+
+	db, connName := connManager.GetActive()
+	current, err := loadCapacityColumns(ctx, db, input.Database)
+	if err != nil {
+		return nil, CapacityCheckOutput{}, err
+	}
+	now := time.Now()
+	out := CapacityCheckOutput{AutoIncrement: []AutoIncrementCapacity{}, IntPrimaryKeys: []IntKeyRisk{}}
+
+	// Growth per table, from a second sample taken now (window) or from the
+	// sample kept by an earlier call.
+	rates := make(map[string]capacityRate)
+	if input.Window > 0 {
+		select {
+		case <-time.After(time.Duration(input.Window) * time.Second):
+		case <-ctx.Done():
+			return nil, CapacityCheckOutput{}, ctx.Err()
+		}
+		baseline := make(map[string]capacitySample)
+		for _, c := range current {
+			baseline[c.key()] = capacitySample{at: now, nextVal: c.nextVal, rows: c.rows}
+		}
+		if current, err = loadCapacityColumns(ctx, db, input.Database); err != nil {
+			return nil, CapacityCheckOutput{}, err
+		}
+		now = time.Now()
+		for _, c := range current {
+			if p, ok := baseline[c.key()]; ok {
+				rates[c.key()] = growthRate(p, c, now)
+			}
+		}
+		out.RateSource = "window"
+		out.RateSecs = int64(input.Window)
+	} else {
+		capacityMu.Lock()
+		for _, c := range current {
+			key := connName + "." + c.key()
+			p, ok := capacitySamples[key]
+			sample := capacitySample{at: now, nextVal: c.nextVal, rows: c.rows}
+			if !ok || sample.nextVal < p.nextVal {
+				// No history, or the table was truncated or rebuilt.
+				capacitySamples[key] = sample
+				continue
+			}
+			age := now.Sub(p.at)
+			if age < minCapacitySampleAge {
+				continue
+			}
+			rates[c.key()] = growthRate(p, c, now)
+			if secs := int64(age.Seconds()); secs > out.RateSecs {
+				out.RateSecs = secs
+			}
+			if age > maxCapacitySampleAge {
+				capacitySamples[key] = sample
+			}
+		}
+		capacityMu.Unlock()
+		if len(rates) > 0 {
+			out.RateSource = "history"
+		} else if len(current) > 0 {
+			out.Warnings = append(out.Warnings, fmt.Sprintf(
+				"no earlier sample to project growth from; run capacity_check again after at least %s, or pass window", minCapacitySampleAge))
+		}
+	}
+
+	primaryCols := make(map[string]int)
+	for _, c := range current {
+		if c.primary {
+			primaryCols[c.key()]++
+		}
+	}
+	for _, c := range current {
+		rate, hasRate := rates[c.key()]
+		if c.autoIncrement && c.hasNextVal {
+			out.AutoIncrement = append(out.AutoIncrement, autoIncrementCapacity(c, rate, hasRate))
+		}
+		if c.primary && primaryCols[c.key()] == 1 && c.dataType != "bigint" {
+			if risk, ok := intKeyRisk(c, rate, hasRate); ok {
+				out.IntPrimaryKeys = append(out.IntPrimaryKeys, risk)
+			}
+		}
+	}
+	sort.SliceStable(out.AutoIncrement, func(i, j int) bool {
+		return out.AutoIncrement[i].PercentUsed > out.AutoIncrement[j].PercentUsed
+	})
+	if len(out.AutoIncrement) > conf.maxRows {
+		out.AutoIncrement = out.AutoIncrement[:conf.maxRows]
+	}
+
+	vars := make(map[string]string)
+	qctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
+	defer cancel()
+	if err := loadVariables(qctx, db, vars, "information_schema_stats_expiry"); err == nil {
+		if v := vars["information_schema_stats_expiry"]; v != "" && v != "0" {
+			out.Warnings = append(out.Warnings, fmt.Sprintf(
+				"information_schema_stats_expiry is %s seconds, so AUTO_INCREMENT values and row counts may be cached that long; growth over a short window can read as zero", v))
+		}
+	}
+	return nil, out, nil
+}
+
+// capacityRate is the growth of a table per day.
+type capacityRate struct {
+	valuesPerDay float64
+	rowsPerDay   float64
+}
+
+func growthRate(prev capacitySample, c capacityColumn, now time.Time) capacityRate {
+	days := now.Sub(prev.at).Hours() / 24
+	if days <= 0 {
+		return capacityRate{}
+	}
+	var r capacityRate
+	if c.nextVal > prev.nextVal {
+		r.valuesPerDay = float64(c.nextVal-prev.nextVal) / days
+	}
+	if c.rows > prev.rows {
+		r.rowsPerDay = float64(c.rows-prev.rows) / days
+	}
+	return r
+}
+
+// loadCapacityColumns reads integer AUTO_INCREMENT and primary key columns
+// with their tables' AUTO_INCREMENT counter and size, as table_size reports it.
+func loadCapacityColumns(ctx context.Context, db *sql.DB, database string) ([]capacityColumn, error) {
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	query := `SELECT c.TABLE_SCHEMA, c.TABLE_NAME, c.COLUMN_NAME, c.DATA_TYPE, c.COLUMN_TYPE, c.EXTRA, c.COLUMN_KEY,
+			t.AUTO_INCREMENT, t.TABLE_ROWS, ROUND((t.DATA_LENGTH + t.INDEX_LENGTH) / 1024 / 1024, 2)
+		FROM information_schema.COLUMNS c
+		JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE t.TABLE_TYPE = 'BASE TABLE'
+			AND c.DATA_TYPE IN ('tinyint', 'smallint', 'mediumint', 'int', 'bigint')
+			AND (c.EXTRA LIKE '%auto_increment%' OR c.COLUMN_KEY = 'PRI')`
+	var args []interface{}
+	if database != "" {
+		query += " AND c.TABLE_SCHEMA = ?"
+		args = append(args, database)
+	} else {
+		query += " AND c.TABLE_SCHEMA NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys')"
+	}
+	query += " ORDER BY c.TABLE_SCHEMA, c.TABLE_NAME, c.ORDINAL_POSITION"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var cols []capacityColumn
+	for rows.Next() {
+		var c capacityColumn
+		var extra, key string
+		var nextVal sql.NullString
+		var tableRows sql.NullInt64
+		var totalMB sql.NullFloat64
+		if err := rows.Scan(&c.schema, &c.table, &c.column, &c.dataType, &c.columnType, &extra, &key,
+			&nextVal, &tableRows, &totalMB); err != nil {
+			return nil, err
+		}
+		if nextVal.Valid {
+			n, err := strconv.ParseUint(nextVal.String, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid AUTO_INCREMENT %q for %s.%s: %w", nextVal.String, c.schema, c.table, err)
+			}
+			c.nextVal, c.hasNextVal = n, true
+		}
+		c.dataType = strings.ToLower(c.dataType)
+		c.columnType = strings.ToLower(c.columnType)
+		c.autoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+		c.primary = key == "PRI"
+		c.rows = tableRows.Int64
+		c.totalMB = totalMB.Float64
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+func autoIncrementCapacity(c capacityColumn, rate capacityRate, hasRate bool) AutoIncrementCapacity {
+	maxVal := c.max()
+	used := uint64(0)
+	if c.nextVal > 1 {
+		used = c.nextVal - 1
+	}
+	a := AutoIncrementCapacity{
+		Database:    c.schema,
+		Table:       c.table,
+		Column:      c.column,
+		ColumnType:  c.columnType,
+		NextValue:   c.nextVal,
+		MaxValue:    maxVal,
+		PercentUsed: math.Round(float64(used)/float64(maxVal)*10000) / 100,
+		Status:      "ok",
+	}
+	if hasRate {
+		a.ValuesPerDay = math.Round(rate.valuesPerDay*100) / 100
+		if rate.valuesPerDay > 0 {
+			days := math.Round(float64(maxVal-used)/rate.valuesPerDay*10) / 10
+			a.DaysToExhaustion = &days
+		}
+	}
+
+	switch {
+	case a.PercentUsed >= capacityCriticalPct || (a.DaysToExhaustion != nil && *a.DaysToExhaustion < capacityCriticalDays):
+		a.Status = "critical"
+	case a.PercentUsed >= capacityWarningPct || (a.DaysToExhaustion != nil && *a.DaysToExhaustion < capacityWarningDays):
+		a.Status = "warning"
+	}
+	if a.Status != "ok" {
+		a.Recommendation = widenRecommendation(c)
+	}
+	return a
+}
+
+// intKeyRisk flags a single-column integer primary key narrower than BIGINT
+// whose table is projected to outgrow it, or already holds a large share of
+// its range when there is no growth data.
+func intKeyRisk(c capacityColumn, rate capacityRate, hasRate bool) (IntKeyRisk, bool) {
+	maxVal := float64(c.max())
+	r := IntKeyRisk{
+		Database:   c.schema,
+		Table:      c.table,
+		Column:     c.column,
+		ColumnType: c.columnType,
+		Rows:       c.rows,
+		TotalMB:    c.totalMB,
+	}
+	// Keys grow with the AUTO_INCREMENT counter when there is one, else with the rows.
+	current, perDay := float64(c.rows), rate.rowsPerDay
+	if c.autoIncrement && c.hasNextVal {
+		current, perDay = float64(c.nextVal), rate.valuesPerDay
+	}
+	switch {
+	case hasRate && perDay > 0:
+		days := (maxVal - current) / perDay
+		if days > intKeyHorizonDays {
+			return IntKeyRisk{}, false
+		}
+		r.RowsPerDay = math.Round(rate.rowsPerDay*100) / 100
+		r.Reason = fmt.Sprintf("at the current growth of %.0f per day the %s range is used up in about %.0f days", perDay, c.columnType, math.Max(days, 0))
+	case !hasRate && current >= intKeyLargeFraction*maxVal:
+		r.Reason = fmt.Sprintf("the table already uses %.0f%% of the %s range", current/maxVal*100, c.columnType)
+	default:
+		return IntKeyRisk{}, false
+	}
+	r.Recommendation = widenRecommendation(c)
+	return r, true
+}
+
+func widenRecommendation(c capacityColumn) string {
+	target := "BIGINT UNSIGNED"
+	if c.dataType == "bigint" {
+		if strings.Contains(c.columnType, "unsigned") {
+			return "The column is already BIGINT UNSIGNED; check for large gaps from auto_increment_increment, bulk deletes or failed inserts."
+		}
+		target = "BIGINT UNSIGNED (if it never holds negative values)"
+	}
+	return fmt.Sprintf("Widen %s.%s.%s to %s with ALTER TABLE ... MODIFY, restating the full column definition and any foreign keys that reference it; the change rebuilds the table, so use an online schema change for large tables.",
+		quoteDDLIdent(c.schema), quoteDDLIdent(c.table), quoteDDLIdent(c.column), target)
+}
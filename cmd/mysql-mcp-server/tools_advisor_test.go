@@ -0,0 +1,661 @@
+// cmd/mysql-mcp-server/tools_advisor_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/askdba/mysql-mcp-server/internal/advisor"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var statisticsColumns = []string{"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "INDEX_TYPE", "COLUMN_NAME", "SUB_PART"}
+
+// ===== toolIndexAdvisor Tests =====
+
+func TestToolIndexAdvisorAllChecks(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM information_schema.STATISTICS\\s+WHERE TABLE_SCHEMA = \\? ORDER BY").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows(statisticsColumns).
+			AddRow("orders", "PRIMARY", 0, "BTREE", "id", nil).
+			AddRow("orders", "idx_cust", 1, "BTREE", "customer_id", nil).
+			AddRow("orders", "idx_cust_created", 1, "BTREE", "customer_id", nil).
+			AddRow("orders", "idx_cust_created", 1, "BTREE", "created_at", nil).
+			AddRow("orders", "idx_status", 1, "BTREE", "status", nil).
+			AddRow("orders", "idx_status_dup", 1, "BTREE", "status", nil).
+			AddRow("orders", "uq_ref", 0, "BTREE", "ref", nil).
+			AddRow("users", "PRIMARY", 0, "BTREE", "id", nil).
+			AddRow("users", "ft_bio", 1, "FULLTEXT", "bio", nil).
+			AddRow("users", "idx_email", 1, "BTREE", "email", 10))
+	mock.ExpectQuery("FROM mysql.innodb_index_stats").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "size"}).
+			AddRow("orders", "idx_cust", int64(50<<20)).
+			AddRow("orders#p#p0", "idx_status", int64(1<<30)).
+			AddRow("orders#p#p1", "idx_status", int64(1<<30)))
+	mock.ExpectQuery("FROM performance_schema.global_status").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_VALUE"}).AddRow(3600))
+	mock.ExpectQuery("FROM performance_schema.table_io_waits_summary_by_index_usage").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"OBJECT_NAME", "INDEX_NAME"}).
+			AddRow("orders", "idx_gone").
+			AddRow("orders", "idx_status").
+			AddRow("orders", "uq_ref"))
+	mock.ExpectQuery("FROM performance_schema.events_statements_summary_by_digest").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows(digestColumns).
+			AddRow("shop", "d1", "SELECT * FROM `orders` WHERE `status` = ? AND `total` > ?",
+				"SELECT * FROM orders WHERE status = 'new' AND total > 100", 10, 1e12, 5000000, 20, 0, 10, 0).
+			AddRow("shop", "d2", "SELECT * FROM `users` WHERE `email` = ?", nil, 100, 1e12, 100000, 100, 0, 2, 0).
+			AddRow("shop", "d3", "SELECT * FROM `customers` WHERE `region` = ?",
+				"SELECT * FROM customers WHERE region = 'eu'", 1, 1e9, 2000, 40, 0, 1, 0).
+			AddRow("shop", "d4", "SELECT * FROM `orders` WHERE `id` = ?", nil, 1000, 1e9, 1000, 1000, 0, 0, 0).
+			AddRow("shop", "d5", "SELECT * FROM `orders` WHERE `a` = ? AND ...", nil, 3, 1e9, 900, 3, 0, 3, 0))
+
+	_, out, err := toolIndexAdvisor(context.Background(), &mcp.CallToolRequest{}, IndexAdvisorInput{Database: "shop"})
+	if err != nil {
+		t.Fatalf("toolIndexAdvisor failed: %v", err)
+	}
+
+	if out.StatsSinceSecs != 3600 {
+		t.Errorf("expected stats_since_secs 3600, got %d", out.StatsSinceSecs)
+	}
+	if len(out.Warnings) != 2 || !strings.Contains(out.Warnings[0], "1h0m0s") || !strings.Contains(out.Warnings[1], "1 full-scan") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	if len(out.Unused) != 1 {
+		t.Fatalf("expected only idx_status to be unused, got %+v", out.Unused)
+	}
+	unused := out.Unused[0]
+	if unused.Index != "idx_status" || unused.SizeBytes != 2<<30 || unused.Benefit != "high" ||
+		unused.DDL != "ALTER TABLE `shop`.`orders` DROP INDEX `idx_status`;" {
+		t.Errorf("unexpected unused index: %+v", unused)
+	}
+
+	if len(out.Redundant) != 2 {
+		t.Fatalf("expected 2 redundant indexes, got %+v", out.Redundant)
+	}
+	if r := out.Redundant[0]; r.Index != "idx_cust" || r.CoveredBy != "idx_cust_created" ||
+		!strings.HasPrefix(r.Reason, "left prefix") || r.Benefit != "low" || !strings.Contains(r.EstimatedBenefit, "50.0 MB") {
+		t.Errorf("unexpected prefix redundancy: %+v", r)
+	}
+	if r := out.Redundant[1]; r.Index != "idx_status_dup" || r.CoveredBy != "idx_status" || !strings.HasPrefix(r.Reason, "duplicates") {
+		t.Errorf("unexpected duplicate: %+v", r)
+	}
+
+	if len(out.Missing) != 2 {
+		t.Fatalf("expected 2 missing indexes, got %+v", out.Missing)
+	}
+	m := out.Missing[0]
+	if m.Table != "orders" || strings.Join(m.Columns, ",") != "status,total" || m.Benefit != "high" ||
+		len(m.Digests) != 1 || m.Digests[0] != "d1" ||
+		m.DDL != "ALTER TABLE `shop`.`orders` ADD INDEX `idx_status_total` (`status`, `total`);" {
+		t.Errorf("unexpected missing index: %+v", m)
+	}
+	if m := out.Missing[1]; m.Table != "customers" || m.Index != "idx_region" || m.Benefit != "medium" {
+		t.Errorf("unexpected missing index: %+v", m)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolIndexAdvisorSingleCheck(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM information_schema.STATISTICS\\s+WHERE TABLE_SCHEMA = \\? AND TABLE_NAME = \\?").
+		WithArgs("shop", "orders").
+		WillReturnRows(sqlmock.NewRows(statisticsColumns).
+			AddRow("orders", "PRIMARY", 0, "BTREE", "id", nil).
+			AddRow("orders", "idx_id", 1, "BTREE", "id", nil).
+			AddRow("orders", "uq_ref", 0, "BTREE", "ref", nil).
+			AddRow("orders", "uq_ref_code", 0, "BTREE", "ref", nil).
+			AddRow("orders", "uq_ref_code", 0, "BTREE", "code", nil))
+	mock.ExpectQuery("FROM mysql.innodb_index_stats").
+		WillReturnError(errors.New("SELECT command denied to user"))
+
+	_, out, err := toolIndexAdvisor(context.Background(), &mcp.CallToolRequest{}, IndexAdvisorInput{
+		Database: "shop",
+		Table:    "orders",
+		Checks:   "redundant",
+	})
+	if err != nil {
+		t.Fatalf("toolIndexAdvisor failed: %v", err)
+	}
+
+	// A unique index stays even when it is a prefix of another unique index.
+	if len(out.Redundant) != 1 || out.Redundant[0].Index != "idx_id" || out.Redundant[0].CoveredBy != "PRIMARY" {
+		t.Errorf("unexpected redundant indexes: %+v", out.Redundant)
+	}
+	if out.Unused != nil || out.Missing != nil {
+		t.Error("only the redundant check should run")
+	}
+	if len(out.Warnings) != 1 || !strings.HasPrefix(out.Warnings[0], "index sizes unavailable") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolIndexAdvisorValidation(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		name  string
+		input IndexAdvisorInput
+		want  string
+	}{
+		{"missing database", IndexAdvisorInput{}, "database is required"},
+		{"bad table", IndexAdvisorInput{Database: "shop", Table: "a;b"}, "invalid table name"},
+		{"unknown check", IndexAdvisorInput{Database: "shop", Checks: "unused,bloat"}, "unknown check: bloat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := toolIndexAdvisor(context.Background(), &mcp.CallToolRequest{}, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// ===== toolLintQuery Tests =====
+
+func TestToolLintQuery(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM information_schema.COLUMNS c").
+		WithArgs("shop", "customers").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE", "nullable", "indexed"}).
+			AddRow("id", "int", false, true).
+			AddRow("phone", "VARCHAR", true, true))
+
+	_, out, err := toolLintQuery(context.Background(), &mcp.CallToolRequest{}, LintQueryInput{
+		SQL:      "SELECT * FROM customers WHERE phone = 5551234 ORDER BY id",
+		Database: "shop",
+	})
+	if err != nil {
+		t.Fatalf("toolLintQuery failed: %v", err)
+	}
+
+	rules := make(map[string]LintFinding)
+	for _, f := range out.Findings {
+		rules[f.Rule] = f
+	}
+	if len(out.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", out.Findings)
+	}
+	if f := rules["implicit_conversion"]; f.Severity != "warning" || f.Rewrite != "phone = '5551234'" {
+		t.Errorf("unexpected implicit conversion finding: %+v", f)
+	}
+	if f := rules["order_by_without_limit"]; !strings.HasSuffix(f.Rewrite, "limit 100") {
+		t.Errorf("unexpected order by finding: %+v", f)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolLintQueryErrors(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	if _, _, err := toolLintQuery(context.Background(), &mcp.CallToolRequest{}, LintQueryInput{}); err == nil || err.Error() != "sql is required" {
+		t.Errorf("expected sql is required, got %v", err)
+	}
+	_, _, err := toolLintQuery(context.Background(), &mcp.CallToolRequest{}, LintQueryInput{SQL: "SELEC id FROM t"})
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse query") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+}
+
+// ===== toolConfigAdvisor Tests =====
+
+func TestToolConfigAdvisor(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).
+			AddRow("innodb_buffer_pool_size", "134217728").
+			AddRow("max_connections", "151").
+			AddRow("log_bin", "ON").
+			AddRow("sync_binlog", "1").
+			AddRow("binlog_format", "ROW").
+			AddRow("innodb_flush_log_at_trx_commit", "2").
+			AddRow("tmp_table_size", "16777216").
+			AddRow("max_heap_table_size", "16777216"))
+	mock.ExpectQuery("FROM performance_schema.global_status").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).
+			AddRow("Max_used_connections", "140").
+			AddRow("Innodb_buffer_pool_read_requests", "1000000").
+			AddRow("Innodb_buffer_pool_reads", "50000").
+			AddRow("Created_tmp_tables", "5000").
+			AddRow("Created_tmp_disk_tables", "100").
+			AddRow("Uptime", "864000"))
+	mock.ExpectQuery("FROM information_schema.TABLES").
+		WillReturnRows(sqlmock.NewRows([]string{"data", "innodb"}).AddRow(int64(10<<30), int64(8<<30)))
+
+	_, out, err := toolConfigAdvisor(context.Background(), &mcp.CallToolRequest{}, ConfigAdvisorInput{})
+	if err != nil {
+		t.Fatalf("toolConfigAdvisor failed: %v", err)
+	}
+
+	var rules []string
+	for _, f := range out.Findings {
+		rules = append(rules, f.Priority+":"+f.Rule)
+	}
+	want := "high:buffer_pool_undersized,high:connections_near_limit,medium:redo_not_durable"
+	if strings.Join(rules, ",") != want {
+		t.Errorf("findings = %v, want %s", rules, want)
+	}
+	if ev := out.Findings[1].Evidence; ev["status.max_used_connections"] != 140.0 || ev["max_connections"] != 151.0 {
+		t.Errorf("unexpected evidence: %v", ev)
+	}
+
+	// Rules depending on facts this server did not report are skipped, not failed.
+	skipped := make(map[string]string)
+	for _, s := range out.Skipped {
+		skipped[s.Rule] = s.Reason
+	}
+	if skipped["buffer_pool_exceeds_memory"] != "missing fact host.memory_bytes" {
+		t.Errorf("unexpected skipped rules: %+v", out.Skipped)
+	}
+	if out.RulesEvaluated != len(advisor.DefaultRules()) {
+		t.Errorf("expected all rules to be evaluated, got %d", out.RulesEvaluated)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolConfigAdvisorRuleSelection(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	if _, _, err := toolConfigAdvisor(context.Background(), &mcp.CallToolRequest{}, ConfigAdvisorInput{Rules: "redo_not_durable,bogus"}); err == nil ||
+		err.Error() != "unknown rule: bogus" {
+		t.Errorf("expected unknown rule error, got %v", err)
+	}
+
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("innodb_buffer_pool_size", "68719476736"))
+	mock.ExpectQuery("FROM performance_schema.global_status").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}))
+	mock.ExpectQuery("FROM information_schema.TABLES").WillReturnError(errors.New("access denied"))
+
+	_, out, err := toolConfigAdvisor(context.Background(), &mcp.CallToolRequest{}, ConfigAdvisorInput{
+		Rules:       "buffer_pool_exceeds_memory",
+		MemoryBytes: 64 << 30,
+	})
+	if err != nil {
+		t.Fatalf("toolConfigAdvisor failed: %v", err)
+	}
+	if out.RulesEvaluated != 1 || len(out.Findings) != 1 || out.Findings[0].Evidence["host.memory_bytes"] != float64(64<<30) {
+		t.Errorf("unexpected output: %+v", out)
+	}
+	if len(out.Warnings) != 1 || !strings.HasPrefix(out.Warnings[0], "data size unavailable") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// ===== toolSecurityAudit Tests =====
+
+func TestToolSecurityAudit(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT CURRENT_USER()")).
+		WillReturnRows(sqlmock.NewRows([]string{"CURRENT_USER()"}).AddRow("mcp@localhost"))
+	mock.ExpectQuery("SHOW GRANTS").
+		WillReturnRows(sqlmock.NewRows([]string{"Grants for mcp@localhost"}).
+			AddRow("GRANT SELECT, PROCESS ON *.* TO `mcp`@`localhost`").
+			AddRow("GRANT INSERT ON `shop`.* TO `mcp`@`localhost`"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mysql.user")).
+		WillReturnRows(sqlmock.NewRows([]string{"Host", "User", "authentication_string", "plugin", "account_locked", "Super_priv", "password_lifetime"}).
+			AddRow("localhost", "root", "$A$005$hash", "caching_sha2_password", "N", "Y", nil).
+			AddRow("%", "app", "", "mysql_native_password", "N", "N", "0").
+			AddRow("localhost", "", "", "mysql_native_password", "N", "N", nil).
+			AddRow("localhost", "mysql.sys", "$A$005$hash", "caching_sha2_password", "Y", "N", nil).
+			AddRow("10.0.%", "report", "$A$005$hash", "caching_sha2_password", "N", "N", nil).
+			AddRow("localhost", "ops", "", "auth_socket", "N", "N", nil))
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WithArgs("default_password_lifetime", "mandatory_roles").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).
+			AddRow("default_password_lifetime", "0").
+			AddRow("mandatory_roles", ""))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR 'root'@'localhost'")).
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION"))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR 'app'@'%'")).
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT USAGE ON *.* TO `app`@`%`"))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR ''@'localhost'")).WillReturnError(errors.New("access denied"))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR 'report'@'10.0.%'")).
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT ALL PRIVILEGES ON `reports`.* TO `report`@`10.0.%`"))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR 'ops'@'localhost'")).
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT USAGE ON *.* TO `ops`@`localhost`"))
+	mock.ExpectQuery("FROM mysql.role_edges").
+		WillReturnRows(sqlmock.NewRows([]string{"FROM_USER", "FROM_HOST", "TO_USER", "TO_HOST", "WITH_ADMIN_OPTION"}).
+			AddRow("reader", "%", "app", "%", "N").
+			AddRow("reader", "%", "report", "10.0.%", "Y").
+			AddRow("reader", "%", "ops", "localhost", "N"))
+
+	_, out, err := toolSecurityAudit(context.Background(), &mcp.CallToolRequest{}, SecurityAuditInput{RoleGrantLimit: 2})
+	if err != nil {
+		t.Fatalf("toolSecurityAudit failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range out.Findings {
+		got = append(got, f.Severity+":"+f.Check+":"+f.Account)
+	}
+	want := []string{
+		"high:all_privileges:'root'@'localhost'",
+		"high:anonymous:''@'localhost'",
+		"high:empty_password:''@'localhost'",
+		"high:empty_password:'app'@'%'",
+		"medium:broad_roles:'reader'@'%'",
+		"medium:wildcard_host:'app'@'%'",
+		"low:all_privileges:'report'@'10.0.%'",
+		"low:broad_roles:'report'@'10.0.%'",
+		"low:password_expiry:'report'@'10.0.%'",
+		"low:password_expiry:'root'@'localhost'",
+		"low:wildcard_host:'report'@'10.0.%'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if out.AccountsChecked != 5 {
+		t.Errorf("expected the locked account to be skipped, got %d accounts", out.AccountsChecked)
+	}
+	if len(out.Warnings) != 1 || !strings.Contains(out.Warnings[0], "SHOW GRANTS failed for 1 accounts") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	if len(out.Connections) != 1 {
+		t.Fatalf("expected one connection, got %+v", out.Connections)
+	}
+	c := out.Connections[0]
+	if c.Connection != "mock" || c.Account != "mcp@localhost" || c.LeastPrivilege ||
+		len(c.Excess) != 1 || c.Excess[0] != "INSERT ON `shop`.*" {
+		t.Errorf("unexpected connection privileges: %+v", c)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSecurityAuditMySQL8GlobalAll(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT CURRENT_USER()")).
+		WillReturnRows(sqlmock.NewRows([]string{"CURRENT_USER()"}).AddRow("mcp@localhost"))
+	mock.ExpectQuery("SHOW GRANTS").
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT SELECT ON *.* TO `mcp`@`localhost`"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mysql.user")).
+		WillReturnRows(sqlmock.NewRows([]string{"Host", "User", "authentication_string", "plugin", "account_locked",
+			"Select_priv", "Insert_priv", "Super_priv", "Grant_priv", "Create_role_priv", "Drop_role_priv"}).
+			AddRow("%", "dba", "$A$005$hash", "caching_sha2_password", "N", "Y", "Y", "Y", "N", "Y", "Y").
+			AddRow("%", "ops", "$A$005$hash", "caching_sha2_password", "N", "Y", "N", "Y", "N", "N", "N"))
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}))
+	// MySQL 8.0 expands GRANT ALL ON *.* into static and dynamic privilege lists.
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR 'dba'@'%'")).
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).
+			AddRow("GRANT SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, RELOAD, SHUTDOWN, PROCESS, FILE, REFERENCES, INDEX, " +
+				"ALTER, SHOW DATABASES, SUPER, CREATE TEMPORARY TABLES, LOCK TABLES, EXECUTE, REPLICATION SLAVE, " +
+				"REPLICATION CLIENT, CREATE VIEW, SHOW VIEW, CREATE ROUTINE, ALTER ROUTINE, CREATE USER, EVENT, TRIGGER, " +
+				"CREATE TABLESPACE, CREATE ROLE, DROP ROLE ON *.* TO `dba`@`%`").
+			AddRow("GRANT APPLICATION_PASSWORD_ADMIN,BACKUP_ADMIN,BINLOG_ADMIN,CONNECTION_ADMIN ON *.* TO `dba`@`%`"))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW GRANTS FOR 'ops'@'%'")).
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT SELECT, SUPER ON *.* TO `ops`@`%`"))
+
+	_, out, err := toolSecurityAudit(context.Background(), &mcp.CallToolRequest{}, SecurityAuditInput{Checks: "all_privileges,super"})
+	if err != nil {
+		t.Fatalf("toolSecurityAudit failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range out.Findings {
+		got = append(got, f.Severity+":"+f.Check+":"+f.Account)
+	}
+	want := []string{
+		"high:all_privileges:'dba'@'%'",
+		"high:super:'ops'@'%'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSecurityAuditRestricted(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	if _, _, err := toolSecurityAudit(context.Background(), &mcp.CallToolRequest{}, SecurityAuditInput{Checks: "super,bogus"}); err == nil ||
+		err.Error() != "unknown check: bogus" {
+		t.Errorf("expected unknown check error, got %v", err)
+	}
+
+	// A least-privilege account cannot read mysql.user; its own grants are still reported.
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT CURRENT_USER()")).
+		WillReturnRows(sqlmock.NewRows([]string{"CURRENT_USER()"}).AddRow("mcp@localhost"))
+	mock.ExpectQuery("SHOW GRANTS").
+		WillReturnRows(sqlmock.NewRows([]string{"g"}).AddRow("GRANT SELECT ON *.* TO `mcp`@`localhost`"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mysql.user")).WillReturnError(errors.New("SELECT command denied"))
+
+	_, out, err := toolSecurityAudit(context.Background(), &mcp.CallToolRequest{}, SecurityAuditInput{})
+	if err != nil {
+		t.Fatalf("toolSecurityAudit failed: %v", err)
+	}
+	if len(out.Findings) != 0 || out.AccountsChecked != 0 {
+		t.Errorf("expected no account findings, got %+v", out)
+	}
+	if len(out.Warnings) != 1 || !strings.HasPrefix(out.Warnings[0], "account checks skipped") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+	if !out.Connections[0].LeastPrivilege {
+		t.Errorf("expected a least-privilege connection: %+v", out.Connections[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// ===== toolCapacityCheck Tests =====
+
+var capacityColumns = []string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "DATA_TYPE", "COLUMN_TYPE", "EXTRA", "COLUMN_KEY",
+	"AUTO_INCREMENT", "TABLE_ROWS", "total_mb"}
+
+func resetCapacitySamples(t *testing.T) {
+	capacityMu.Lock()
+	old := capacitySamples
+	capacitySamples = make(map[string]capacitySample)
+	capacityMu.Unlock()
+	t.Cleanup(func() {
+		capacityMu.Lock()
+		capacitySamples = old
+		capacityMu.Unlock()
+	})
+}
+
+func TestToolCapacityCheck(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+	resetCapacitySamples(t)
+
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	capacitySamples["mock.shop.orders"] = capacitySample{at: dayAgo, nextVal: 3900000001, rows: 3800000000}
+	capacitySamples["mock.shop.users"] = capacitySample{at: dayAgo, nextVal: 900001, rows: 900000}
+
+	mock.ExpectQuery("FROM information_schema.COLUMNS c").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows(capacityColumns).
+			AddRow("shop", "events", "id", "int", "int", "", "PRI", nil, int64(300000000), 20480.5).
+			AddRow("shop", "logs", "id", "bigint", "bigint", "auto_increment", "PRI", int64(10), int64(9), 0.02).
+			AddRow("shop", "orders", "id", "int", "int unsigned", "auto_increment", "PRI", int64(4000000001), int64(3900000000), 512000.0).
+			AddRow("shop", "order_items", "order_id", "int", "int", "", "PRI", nil, int64(500000000), 100.0).
+			AddRow("shop", "order_items", "line", "smallint", "smallint", "", "PRI", nil, int64(500000000), 100.0).
+			AddRow("shop", "small", "id", "tinyint", "tinyint unsigned", "auto_increment", "PRI", int64(201), int64(200), 0.02).
+			AddRow("shop", "users", "id", "int", "int", "auto_increment", "PRI", int64(1000001), int64(1000000), 64.0))
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WithArgs("information_schema_stats_expiry").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("information_schema_stats_expiry", "86400"))
+
+	_, out, err := toolCapacityCheck(context.Background(), &mcp.CallToolRequest{}, CapacityCheckInput{Database: "shop"})
+	if err != nil {
+		t.Fatalf("toolCapacityCheck failed: %v", err)
+	}
+
+	var got []string
+	for _, a := range out.AutoIncrement {
+		got = append(got, fmt.Sprintf("%s:%s:%.2f", a.Table, a.Status, a.PercentUsed))
+	}
+	if want := "orders:critical:93.13,small:warning:78.43,users:ok:0.05,logs:ok:0.00"; strings.Join(got, ",") != want {
+		t.Errorf("auto_increment = %s, want %s", strings.Join(got, ","), want)
+	}
+	orders := out.AutoIncrement[0]
+	if orders.ValuesPerDay < 99e6 || orders.ValuesPerDay > 101e6 || orders.DaysToExhaustion == nil ||
+		*orders.DaysToExhaustion < 2.8 || *orders.DaysToExhaustion > 3 || !strings.Contains(orders.Recommendation, "BIGINT UNSIGNED") {
+		t.Errorf("unexpected projection: %+v", orders)
+	}
+	if out.AutoIncrement[3].DaysToExhaustion != nil {
+		t.Errorf("expected no projection without history: %+v", out.AutoIncrement[3])
+	}
+	if out.RateSource != "history" || out.RateSecs < 86000 {
+		t.Errorf("unexpected rate source: %s over %ds", out.RateSource, out.RateSecs)
+	}
+
+	// events is large without growth data; orders and small run out soon; users
+	// has years left; order_items has a composite key.
+	var keys []string
+	for _, k := range out.IntPrimaryKeys {
+		keys = append(keys, k.Table)
+	}
+	if strings.Join(keys, ",") != "events,orders,small" {
+		t.Errorf("int_primary_keys = %v", keys)
+	}
+	if out.IntPrimaryKeys[0].Rows != 300000000 || out.IntPrimaryKeys[0].TotalMB != 20480.5 {
+		t.Errorf("unexpected table size data: %+v", out.IntPrimaryKeys[0])
+	}
+	if len(out.Warnings) != 1 || !strings.Contains(out.Warnings[0], "information_schema_stats_expiry is 86400") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	// The first call for a table records a sample for the next one.
+	if s := capacitySamples["mock.shop.events"]; s.rows != 300000000 {
+		t.Errorf("expected a sample to be recorded, got %+v", s)
+	}
+	if s := capacitySamples["mock.shop.orders"]; !s.at.Equal(dayAgo) {
+		t.Errorf("expected the earlier sample to be kept, got %+v", s)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolCapacityCheckBigintUnsigned(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+	resetCapacitySamples(t)
+
+	// An AUTO_INCREMENT above MaxInt64, as the text protocol returns it.
+	mock.ExpectQuery("FROM information_schema.COLUMNS c").
+		WillReturnRows(sqlmock.NewRows(capacityColumns).
+			AddRow("app", "events", "id", "bigint", "bigint unsigned", "auto_increment", "PRI", []byte("18000000000000000001"), int64(1000), 1.0))
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("information_schema_stats_expiry", "0"))
+
+	_, out, err := toolCapacityCheck(context.Background(), &mcp.CallToolRequest{}, CapacityCheckInput{})
+	if err != nil {
+		t.Fatalf("toolCapacityCheck failed: %v", err)
+	}
+	if len(out.AutoIncrement) != 1 {
+		t.Fatalf("expected one auto_increment column, got %+v", out.AutoIncrement)
+	}
+	events := out.AutoIncrement[0]
+	if events.NextValue != 18000000000000000001 || events.PercentUsed != 97.58 || events.Status != "critical" {
+		t.Errorf("unexpected capacity: %+v", events)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolCapacityCheckWindow(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+	resetCapacitySamples(t)
+
+	if _, _, err := toolCapacityCheck(context.Background(), &mcp.CallToolRequest{}, CapacityCheckInput{Window: 3600}); err == nil {
+		t.Error("expected an error for a window above the limit")
+	}
+	if _, _, err := toolCapacityCheck(context.Background(), &mcp.CallToolRequest{}, CapacityCheckInput{Database: "bad`db"}); err == nil {
+		t.Error("expected an error for an invalid database name")
+	}
+	deadline, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if _, _, err := toolCapacityCheck(deadline, &mcp.CallToolRequest{}, CapacityCheckInput{Window: 45}); err == nil ||
+		!strings.Contains(err.Error(), "does not fit in the request timeout") {
+		t.Errorf("expected an error for a window past the request deadline, got %v", err)
+	}
+
+	mock.ExpectQuery("FROM information_schema.COLUMNS c").
+		WillReturnRows(sqlmock.NewRows(capacityColumns).
+			AddRow("app", "hits", "id", "mediumint", "mediumint unsigned", "auto_increment", "PRI", int64(1000), int64(999), 0.1))
+	mock.ExpectQuery("FROM information_schema.COLUMNS c").
+		WillReturnRows(sqlmock.NewRows(capacityColumns).
+			AddRow("app", "hits", "id", "mediumint", "mediumint unsigned", "auto_increment", "PRI", int64(1100), int64(1099), 0.1))
+	mock.ExpectQuery("FROM performance_schema.global_variables").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("information_schema_stats_expiry", "0"))
+
+	_, out, err := toolCapacityCheck(context.Background(), &mcp.CallToolRequest{}, CapacityCheckInput{Window: 1})
+	if err != nil {
+		t.Fatalf("toolCapacityCheck failed: %v", err)
+	}
+	hits := out.AutoIncrement[0]
+	// 100 values a second uses up the remaining ~16.8 million in about two days.
+	if out.RateSource != "window" || hits.DaysToExhaustion == nil || *hits.DaysToExhaustion > 2 || hits.Status != "critical" {
+		t.Errorf("unexpected projection: %+v (rate source %s)", hits, out.RateSource)
+	}
+	if len(out.IntPrimaryKeys) != 1 || !strings.Contains(out.IntPrimaryKeys[0].Reason, "used up in about") {
+		t.Errorf("unexpected int_primary_keys: %+v", out.IntPrimaryKeys)
+	}
+	if len(out.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,880 @@
+// cmd/mysql-mcp-server/tools_data.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/askdba/mysql-mcp-server/internal/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults for the data exploration tools.
+const (
+	defaultProfileSampleSize = 10000
+	maxProfileSampleSize     = 100000
+	defaultProfileTopN       = 5
+	defaultProfileBuckets    = 10
+	maxProfileValueLen       = 100 // long values in min/max/top-N are truncated
+	defaultSampleRows        = 10
+	defaultSampleValueLen    = 200
+	defaultSummaryTokens     = 4000
+)
+
+// tableColumn describes a column as reported by information_schema.COLUMNS.
+type tableColumn struct {
+	Name     string
+	DataType string
+	Key      string
+}
+
+// loadTableColumns returns the columns of a table in ordinal order.
+func loadTableColumns(ctx context.Context, db *sql.DB, database, table string) ([]tableColumn, error) {
+	rows, err := db.QueryContext(ctx, `SELECT COLUMN_NAME, DATA_TYPE, COLUMN_KEY
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+
+	var cols []tableColumn
+	for rows.Next() {
+		var c tableColumn
+		if err := rows.Scan(&c.Name, &c.DataType, &c.Key); err != nil {
+			return nil, fmt.Errorf("scan column failed: %w", err)
+		}
+		c.DataType = strings.ToLower(c.DataType)
+		cols = append(cols, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("table %s.%s not found", database, table)
+	}
+	return cols, nil
+}
+
+// pickColumns filters the table columns by a comma-separated list of names.
+// An empty list selects all columns.
+func pickColumns(all []tableColumn, list string) ([]tableColumn, error) {
+	if strings.TrimSpace(list) == "" {
+		return all, nil
+	}
+	byName := make(map[string]tableColumn, len(all))
+	for _, c := range all {
+		byName[strings.ToLower(c.Name)] = c
+	}
+	var picked []tableColumn
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		c, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		picked = append(picked, c)
+	}
+	if len(picked) == 0 {
+		return all, nil
+	}
+	return picked, nil
+}
+
+// quoteColumnList quotes column names for use in a SELECT list.
+func quoteColumnList(cols []tableColumn) (string, error) {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		q, err := util.QuoteIdent(c.Name)
+		if err != nil {
+			return "", fmt.Errorf("invalid column name: %w", err)
+		}
+		quoted[i] = q
+	}
+	return strings.Join(quoted, ", "), nil
+}
+
+// primaryKeyColumns returns the primary key columns of a table, in ordinal order.
+func primaryKeyColumns(cols []tableColumn) []tableColumn {
+	var pk []tableColumn
+	for _, c := range cols {
+		if c.Key == "PRI" {
+			pk = append(pk, c)
+		}
+	}
+	return pk
+}
+
+// estimateTableRows returns the approximate row count from information_schema.TABLES.
+func estimateTableRows(ctx context.Context, db *sql.DB, database, table string) (int64, error) {
+	var n sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT TABLE_ROWS FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, database, table).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read table rows: %w", err)
+	}
+	return n.Int64, nil
+}
+
+// ===== Data Exploration Tool Handlers =====
+
+func toolProfileTable(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ProfileTableInput,
+) (*mcp.CallToolResult, ProfileTableOutput, error) {
+	conf := settingsFrom(ctx)
+	if input.Database == "" || input.Table == "" {
+		return nil, ProfileTableOutput{}, fmt.Errorf("database and table are required")
+	}
+
+	dbName, err := util.QuoteIdent(input.Database)
+	if err != nil {
+		return nil, ProfileTableOutput{}, fmt.Errorf("invalid database name: %w", err)
+	}
+	tableName, err := util.QuoteIdent(input.Table)
+	if err != nil {
+		return nil, ProfileTableOutput{}, fmt.Errorf("invalid table name: %w", err)
+	}
+
+	sampleSize := input.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultProfileSampleSize
+	}
+	if sampleSize > maxProfileSampleSize {
+		sampleSize = maxProfileSampleSize
+	}
+	topN := input.TopN
+	if topN <= 0 {
+		topN = defaultProfileTopN
+	}
+	buckets := input.Buckets
+	if buckets <= 0 {
+		buckets = defaultProfileBuckets
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
+	defer cancel()
+
+	db := getDB()
+	allCols, err := loadTableColumns(ctx, db, input.Database, input.Table)
+	if err != nil {
+		return nil, ProfileTableOutput{}, err
+	}
+	cols, err := pickColumns(allCols, input.Columns)
+	if err != nil {
+		return nil, ProfileTableOutput{}, err
+	}
+	selectList, err := quoteColumnList(cols)
+	if err != nil {
+		return nil, ProfileTableOutput{}, err
+	}
+
+	estimatedRows, err := estimateTableRows(ctx, db, input.Database, input.Table)
+	if err != nil {
+		return nil, ProfileTableOutput{}, err
+	}
+
+	// Choose a sampling strategy. Small tables are scanned in full; larger
+	// tables with a single integer primary key are read from a random key
+	// range, otherwise we fall back to a LIMIT over the primary key index.
+	from := dbName + "." + tableName
+	query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", selectList, from, sampleSize)
+	method := "full_scan"
+	var args []interface{}
+
+	pk := primaryKeyColumns(allCols)
+	if estimatedRows > int64(sampleSize) {
+		method = "limit_scan"
+		if len(pk) > 0 {
+			pkName, err := util.QuoteIdent(pk[0].Name)
+			if err != nil {
+				return nil, ProfileTableOutput{}, fmt.Errorf("invalid primary key name: %w", err)
+			}
+			query = fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT %d", selectList, from, pkName, sampleSize)
+			method = "index_scan"
+
+			if len(pk) == 1 && isIntegerType(pk[0].DataType) {
+				// Bounds are read as strings: a BIGINT UNSIGNED key above
+				// MaxInt64 does not fit in int64 and keeps the index scan.
+				var minPK, maxPK sql.NullString
+				boundsQuery := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", pkName, pkName, from)
+				if err := db.QueryRowContext(ctx, boundsQuery).Scan(&minPK, &maxPK); err != nil {
+					return nil, ProfileTableOutput{}, fmt.Errorf("failed to read key range: %w", err)
+				}
+				lo, loErr := strconv.ParseInt(minPK.String, 10, 64)
+				hi, hiErr := strconv.ParseInt(maxPK.String, 10, 64)
+				if minPK.Valid && maxPK.Valid && loErr == nil && hiErr == nil && hi > lo {
+					start := pkRangeStart(lo, hi, estimatedRows, sampleSize)
+					query = fmt.Sprintf("SELECT %s FROM %s WHERE %s >= ? ORDER BY %s LIMIT %d",
+						selectList, from, pkName, pkName, sampleSize)
+					args = []interface{}{start}
+					method = "pk_range"
+				}
+			}
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, ProfileTableOutput{}, fmt.Errorf("sample query failed: %w", err)
+	}
+	defer rows.Close()
+
+	sample, err := scanQueryResult(rows, sampleSize)
+	if err != nil {
+		return nil, ProfileTableOutput{}, err
+	}
+
+	// A LIMIT that was not reached means we saw every row.
+	exact := method != "pk_range" && len(sample.Rows) < sampleSize
+	if exact || estimatedRows < int64(len(sample.Rows)) {
+		estimatedRows = int64(len(sample.Rows))
+	}
+
+	out := ProfileTableOutput{
+		Table:          input.Table,
+		EstimatedRows:  estimatedRows,
+		SampledRows:    len(sample.Rows),
+		SamplingMethod: method,
+		Exact:          exact,
+		Columns:        make([]ColumnProfile, 0, len(cols)),
+	}
+
+	values := make([]interface{}, len(sample.Rows))
+	for i, col := range cols {
+		for j, row := range sample.Rows {
+			values[j] = row[i]
+		}
+		masked := conf.columnMasker.ShouldMask(input.Database, input.Table, col.Name)
+		out.Columns = append(out.Columns, profileColumn(col, values, estimatedRows, exact, topN, buckets, masked))
+	}
+
+	return nil, out, nil
+}
+
+func toolSampleRows(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input SampleRowsInput,
+) (*mcp.CallToolResult, SampleRowsOutput, error) {
+	conf := settingsFrom(ctx)
+	if input.Database == "" || input.Table == "" {
+		return nil, SampleRowsOutput{}, fmt.Errorf("database and table are required")
+	}
+
+	dbName, err := util.QuoteIdent(input.Database)
+	if err != nil {
+		return nil, SampleRowsOutput{}, fmt.Errorf("invalid database name: %w", err)
+	}
+	tableName, err := util.QuoteIdent(input.Table)
+	if err != nil {
+		return nil, SampleRowsOutput{}, fmt.Errorf("invalid table name: %w", err)
+	}
+
+	n := input.N
+	if n <= 0 {
+		n = defaultSampleRows
+	}
+	if n > conf.maxRows {
+		n = conf.maxRows
+	}
+	maxLen := input.MaxValueLength
+	if maxLen <= 0 {
+		maxLen = defaultSampleValueLen
+	}
+	strategy := strings.ToLower(strings.TrimSpace(input.Strategy))
+	if strategy == "" {
+		strategy = "first"
+	}
+	if strategy != "first" && strategy != "random" && strategy != "recent" {
+		return nil, SampleRowsOutput{}, fmt.Errorf("unknown strategy %q (use first, random or recent)", input.Strategy)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
+	defer cancel()
+
+	db := getDB()
+	allCols, err := loadTableColumns(ctx, db, input.Database, input.Table)
+	if err != nil {
+		return nil, SampleRowsOutput{}, err
+	}
+	cols, err := pickColumns(allCols, input.Columns)
+	if err != nil {
+		return nil, SampleRowsOutput{}, err
+	}
+	selectList, err := quoteColumnList(cols)
+	if err != nil {
+		return nil, SampleRowsOutput{}, err
+	}
+
+	from := dbName + "." + tableName
+	pk := primaryKeyColumns(allCols)
+	var query string
+	var args []interface{}
+
+	switch strategy {
+	case "first":
+		query = fmt.Sprintf("SELECT %s FROM %s", selectList, from)
+		if len(pk) > 0 {
+			pkName, err := util.QuoteIdent(pk[0].Name)
+			if err != nil {
+				return nil, SampleRowsOutput{}, fmt.Errorf("invalid primary key name: %w", err)
+			}
+			query += " ORDER BY " + pkName
+		}
+
+	case "random":
+		if len(pk) != 1 || !isIntegerType(pk[0].DataType) {
+			return nil, SampleRowsOutput{}, fmt.Errorf("random strategy requires a single integer primary key")
+		}
+		pkName, err := util.QuoteIdent(pk[0].Name)
+		if err != nil {
+			return nil, SampleRowsOutput{}, fmt.Errorf("invalid primary key name: %w", err)
+		}
+		estimatedRows, err := estimateTableRows(ctx, db, input.Database, input.Table)
+		if err != nil {
+			return nil, SampleRowsOutput{}, err
+		}
+		// Bounds are read as strings; keys above MaxInt64 (BIGINT UNSIGNED)
+		// and empty tables fall back to an ordered scan from the first key.
+		var minPK, maxPK sql.NullString
+		boundsQuery := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", pkName, pkName, from)
+		if err := db.QueryRowContext(ctx, boundsQuery).Scan(&minPK, &maxPK); err != nil {
+			return nil, SampleRowsOutput{}, fmt.Errorf("failed to read key range: %w", err)
+		}
+		lo, loErr := strconv.ParseInt(minPK.String, 10, 64)
+		hi, hiErr := strconv.ParseInt(maxPK.String, 10, 64)
+		if !minPK.Valid || !maxPK.Valid || loErr != nil || hiErr != nil {
+			query = fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", selectList, from, pkName)
+			break
+		}
+		start := lo
+		if hi > lo && estimatedRows > int64(n) {
+			start = pkRangeStart(lo, hi, estimatedRows, n)
+		}
+		query = fmt.Sprintf("SELECT %s FROM %s WHERE %s >= ? ORDER BY %s", selectList, from, pkName, pkName)
+		args = []interface{}{start}
+
+	case "recent":
+		tsCol, err := findTimestampColumn(allCols, input.TimestampColumn)
+		if err != nil {
+			return nil, SampleRowsOutput{}, err
+		}
+		tsName, err := util.QuoteIdent(tsCol)
+		if err != nil {
+			return nil, SampleRowsOutput{}, fmt.Errorf("invalid timestamp column: %w", err)
+		}
+		query = fmt.Sprintf("SELECT %s FROM %s ORDER BY %s DESC", selectList, from, tsName)
+
+	}
+	query += fmt.Sprintf(" LIMIT %d", n)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, SampleRowsOutput{}, fmt.Errorf("sample query failed: %w", err)
+	}
+	defer rows.Close()
+
+	result, err := scanQueryResult(rows, n)
+	if err != nil {
+		return nil, SampleRowsOutput{}, err
+	}
+	maskQueryResult(conf.columnMasker, &result, input.Database, input.Table)
+
+	out := SampleRowsOutput{
+		Columns:  result.Columns,
+		Rows:     result.Rows,
+		Strategy: strategy,
+	}
+	for _, row := range out.Rows {
+		for i, v := range row {
+			if s, ok := v.(string); ok && utf8.RuneCountInString(s) > maxLen {
+				row[i] = truncateString(s, maxLen)
+				out.Truncated++
+			}
+		}
+	}
+
+	return nil, out, nil
+}
+
+// findTimestampColumn validates the requested timestamp column or picks the
+// first DATETIME/TIMESTAMP column of the table.
+func findTimestampColumn(cols []tableColumn, requested string) (string, error) {
+	if requested != "" {
+		for _, c := range cols {
+			if strings.EqualFold(c.Name, requested) {
+				if !isTemporalType(c.DataType) {
+					return "", fmt.Errorf("column %s is not a date/time column", c.Name)
+				}
+				return c.Name, nil
+			}
+		}
+		return "", fmt.Errorf("unknown column: %s", requested)
+	}
+	for _, c := range cols {
+		if c.DataType == "timestamp" || c.DataType == "datetime" {
+			return c.Name, nil
+		}
+	}
+	return "", fmt.Errorf("recent strategy requires timestamp_column (table has no DATETIME/TIMESTAMP column)")
+}
+
+func toolSummarizeDatabase(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input SummarizeDatabaseInput,
+) (*mcp.CallToolResult, SummarizeDatabaseOutput, error) {
+	if input.Database == "" {
+		return nil, SummarizeDatabaseOutput{}, fmt.Errorf("database is required")
+	}
+	maxTokens := input.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultSummaryTokens
+	}
+
+	_, sizes, err := toolTableSize(ctx, req, TableSizeInput{Database: input.Database})
+	if err != nil {
+		return nil, SummarizeDatabaseOutput{}, err
+	}
+	_, fks, err := toolForeignKeys(ctx, req, ForeignKeysInput{Database: input.Database})
+	if err != nil {
+		return nil, SummarizeDatabaseOutput{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	db := getDB()
+	indexes, err := loadIndexColumns(ctx, db, input.Database)
+	if err != nil {
+		return nil, SummarizeDatabaseOutput{}, err
+	}
+	comments, err := loadTableComments(ctx, db, input.Database)
+	if err != nil {
+		return nil, SummarizeDatabaseOutput{}, err
+	}
+
+	// Connectivity counts both ends of each foreign key edge.
+	degree := make(map[string]int)
+	refs := make(map[string][]string)
+	for _, fk := range fks.ForeignKeys {
+		degree[fk.Table]++
+		if fk.ReferencedTable != fk.Table {
+			degree[fk.ReferencedTable]++
+		}
+		refs[fk.Table] = append(refs[fk.Table],
+			fmt.Sprintf("%s -> %s.%s", fk.Column, fk.ReferencedTable, fk.ReferencedColumn))
+	}
+
+	tables := make([]TableSummary, 0, len(sizes.Tables))
+	for _, t := range sizes.Tables {
+		s := TableSummary{
+			Name:       t.Name,
+			Rows:       t.Rows,
+			TotalMB:    t.TotalMB,
+			References: refs[t.Name],
+			Comment:    comments[t.Name],
+		}
+		if idx := indexes[t.Name]; idx != nil {
+			s.PrimaryKey = idx.primary
+			s.KeyColumns = idx.keys
+		}
+		tables = append(tables, s)
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		pi := tablePriority(tables[i].Rows, degree[tables[i].Name])
+		pj := tablePriority(tables[j].Rows, degree[tables[j].Name])
+		if pi != pj {
+			return pi > pj
+		}
+		return tables[i].Name < tables[j].Name
+	})
+
+	out := SummarizeDatabaseOutput{
+		Database:   input.Database,
+		TableCount: len(tables),
+		Tables:     []TableSummary{},
+	}
+	fitSummaryBudget(&out, tables, maxTokens)
+
+	return nil, out, nil
+}
+
+// tableIndexColumns holds the indexed columns of one table.
+type tableIndexColumns struct {
+	primary []string
+	keys    []string // columns of secondary indexes not already in the primary key
+}
+
+// loadIndexColumns returns the primary key and secondary index columns of every table in a database.
+func loadIndexColumns(ctx context.Context, db *sql.DB, database string) (map[string]*tableIndexColumns, error) {
+	rows, err := db.QueryContext(ctx, `SELECT TABLE_NAME, INDEX_NAME, COLUMN_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]*tableIndexColumns)
+	for rows.Next() {
+		var table, index string
+		var column sql.NullString
+		if err := rows.Scan(&table, &index, &column); err != nil {
+			return nil, fmt.Errorf("scan index failed: %w", err)
+		}
+		if !column.Valid {
+			continue // functional key part
+		}
+		t := out[table]
+		if t == nil {
+			t = &tableIndexColumns{}
+			out[table] = t
+		}
+		if index == "PRIMARY" {
+			t.primary = append(t.primary, column.String)
+			continue
+		}
+		if !slices.Contains(t.primary, column.String) && !slices.Contains(t.keys, column.String) {
+			t.keys = append(t.keys, column.String)
+		}
+	}
+	return out, rows.Err()
+}
+
+// loadTableComments returns the non-empty table comments of a database.
+func loadTableComments(ctx context.Context, db *sql.DB, database string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT TABLE_NAME, TABLE_COMMENT
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_COMMENT <> ''`, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table comments: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var table, comment string
+		if err := rows.Scan(&table, &comment); err != nil {
+			return nil, fmt.Errorf("scan comment failed: %w", err)
+		}
+		out[table] = truncateString(comment, maxProfileValueLen)
+	}
+	return out, rows.Err()
+}
+
+// tablePriority ranks tables for the summary: every foreign key edge counts
+// as much as two orders of magnitude of rows, so small hub tables such as
+// users or accounts are kept ahead of large isolated log tables.
+func tablePriority(rows int64, degree int) float64 {
+	if rows < 0 {
+		rows = 0
+	}
+	return math.Log10(float64(rows)+1) + 2*float64(degree)
+}
+
+// fitSummaryBudget adds tables in priority order until the token budget is
+// spent. A table that does not fit in full is reduced to its name, row count
+// and primary key; once even that does not fit, the remaining tables are omitted.
+func fitSummaryBudget(out *SummarizeDatabaseOutput, tables []TableSummary, maxTokens int) {
+	used := approxTokens(out)
+	for i, t := range tables {
+		cost := approxTokens(t)
+		if used+cost > maxTokens {
+			t = TableSummary{Name: t.Name, Rows: t.Rows, PrimaryKey: t.PrimaryKey}
+			cost = approxTokens(t)
+			if used+cost > maxTokens {
+				out.Omitted = len(tables) - i
+				break
+			}
+			out.Compacted++
+		}
+		out.Tables = append(out.Tables, t)
+		used += cost
+	}
+	out.EstimatedTokens = used
+}
+
+// pkRangeStart picks a random starting key so that a window of roughly
+// sampleSize rows fits between start and maxPK.
+func pkRangeStart(minPK, maxPK, estimatedRows int64, sampleSize int) int64 {
+	span := maxPK - minPK
+	window := int64(float64(span) * float64(sampleSize) / float64(estimatedRows))
+	if span-window <= 0 {
+		return minPK
+	}
+	return minPK + rand.Int64N(span-window+1)
+}
+
+// profileColumn computes statistics for one column over its sampled values.
+func profileColumn(col tableColumn, values []interface{}, totalRows int64, exact bool, topN, buckets int, masked bool) ColumnProfile {
+	p := ColumnProfile{Name: col.Name, Type: col.DataType, Masked: masked}
+	if len(values) == 0 {
+		return p
+	}
+
+	// Frequency table over non-NULL values
+	type freq struct {
+		value interface{}
+		count int
+	}
+	counts := make(map[string]*freq)
+	nonNull := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		nonNull = append(nonNull, v)
+		key := fmt.Sprint(v)
+		if f, ok := counts[key]; ok {
+			f.count++
+		} else {
+			counts[key] = &freq{value: v, count: 1}
+		}
+	}
+
+	p.NullRatio = roundTo(float64(len(values)-len(nonNull))/float64(len(values)), 4)
+
+	// Distinct count: exact when the whole table was read, otherwise the
+	// GEE estimator scaled from the sample.
+	if exact {
+		p.DistinctEstimate = int64(len(counts))
+	} else {
+		freqCounts := make([]int, 0, len(counts))
+		for _, f := range counts {
+			freqCounts = append(freqCounts, f.count)
+		}
+		population := int64(float64(totalRows) * (1 - p.NullRatio))
+		p.DistinctEstimate = estimateDistinct(freqCounts, len(nonNull), population)
+	}
+
+	if masked || len(nonNull) == 0 {
+		return p
+	}
+
+	numeric := isNumericType(col.DataType)
+
+	// Min / max
+	var minV, maxV interface{}
+	var minF, maxF float64
+	for i, v := range nonNull {
+		if numeric {
+			f, ok := toFloat(v)
+			if !ok {
+				continue
+			}
+			if minV == nil || f < minF {
+				minV, minF = v, f
+			}
+			if maxV == nil || f > maxF {
+				maxV, maxF = v, f
+			}
+			continue
+		}
+		s := fmt.Sprint(v)
+		if i == 0 || s < fmt.Sprint(minV) {
+			minV = v
+		}
+		if i == 0 || s > fmt.Sprint(maxV) {
+			maxV = v
+		}
+	}
+	p.Min = displayValue(minV)
+	p.Max = displayValue(maxV)
+
+	// Top-N values (skipped when every sampled value is unique)
+	if len(counts) < len(nonNull) {
+		top := make([]*freq, 0, len(counts))
+		for _, f := range counts {
+			top = append(top, f)
+		}
+		sort.Slice(top, func(i, j int) bool {
+			if top[i].count != top[j].count {
+				return top[i].count > top[j].count
+			}
+			return fmt.Sprint(top[i].value) < fmt.Sprint(top[j].value)
+		})
+		if len(top) > topN {
+			top = top[:topN]
+		}
+		for _, f := range top {
+			p.TopValues = append(p.TopValues, ValueFrequency{Value: displayValue(f.value), Count: f.count})
+		}
+	}
+
+	if isStringType(col.DataType) {
+		p.Lengths = lengthStats(nonNull)
+	}
+	if numeric && maxV != nil && maxF > minF {
+		p.Histogram = numericHistogram(nonNull, minF, maxF, buckets)
+	}
+
+	return p
+}
+
+// estimateDistinct applies the Guaranteed-Error Estimator (Charikar et al.):
+// D = sqrt(N/n) * f1 + sum(fj, j >= 2), where f1 is the number of values seen
+// exactly once in a sample of n rows drawn from N rows.
+func estimateDistinct(freqCounts []int, sampled int, population int64) int64 {
+	if sampled == 0 {
+		return 0
+	}
+	if population < int64(sampled) {
+		population = int64(sampled)
+	}
+	var singletons, repeated int64
+	for _, c := range freqCounts {
+		if c == 1 {
+			singletons++
+		} else {
+			repeated++
+		}
+	}
+	est := int64(math.Round(math.Sqrt(float64(population)/float64(sampled))*float64(singletons))) + repeated
+	if est > population {
+		est = population
+	}
+	return est
+}
+
+// lengthStats summarizes the character length of string values.
+func lengthStats(values []interface{}) *LengthStats {
+	lengths := make([]int, len(values))
+	total := 0
+	for i, v := range values {
+		lengths[i] = utf8.RuneCountInString(fmt.Sprint(v))
+		total += lengths[i]
+	}
+	sort.Ints(lengths)
+	n := len(lengths)
+	return &LengthStats{
+		Min: lengths[0],
+		Max: lengths[n-1],
+		Avg: roundTo(float64(total)/float64(n), 2),
+		P50: lengths[(n-1)*50/100],
+		P90: lengths[(n-1)*90/100],
+	}
+}
+
+// numericHistogram builds an equi-width histogram between min and max.
+func numericHistogram(values []interface{}, minF, maxF float64, buckets int) []HistogramBucket {
+	width := (maxF - minF) / float64(buckets)
+	hist := make([]HistogramBucket, buckets)
+	for i := range hist {
+		hist[i].Low = roundTo(minF+float64(i)*width, 6)
+		hist[i].High = roundTo(minF+float64(i+1)*width, 6)
+	}
+	hist[buckets-1].High = maxF
+	for _, v := range values {
+		f, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+		idx := int((f - minF) / width)
+		if idx >= buckets {
+			idx = buckets - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		hist[idx].Count++
+	}
+	return hist
+}
+
+// displayValue truncates long string values for compact output.
+func displayValue(v interface{}) interface{} {
+	if s, ok := v.(string); ok {
+		return truncateString(s, maxProfileValueLen)
+	}
+	return v
+}
+
+// truncateString shortens s to at most maxLen characters (not bytes),
+// appending "..." when it was cut.
+func truncateString(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxLen]) + "..."
+}
+
+// toFloat converts a normalized driver value to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(string(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func roundTo(f float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(f*scale) / scale
+}
+
+func isIntegerType(dataType string) bool {
+	switch dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return true
+	}
+	return false
+}
+
+func isNumericType(dataType string) bool {
+	switch dataType {
+	case "decimal", "numeric", "float", "double", "real", "year":
+		return true
+	}
+	return isIntegerType(dataType)
+}
+
+func isTemporalType(dataType string) bool {
+	switch dataType {
+	case "date", "datetime", "timestamp":
+		return true
+	}
+	return false
+}
+
+func isStringType(dataType string) bool {
+	switch dataType {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext",
+		"enum", "set", "json", "binary", "varbinary",
+		"tinyblob", "blob", "mediumblob", "longblob":
+		return true
+	}
+	return false
+}
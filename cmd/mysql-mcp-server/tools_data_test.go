@@ -0,0 +1,569 @@
+// cmd/mysql-mcp-server/tools_data_test.go
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/askdba/mysql-mcp-server/internal/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// expectTableColumns registers the information_schema.COLUMNS lookup used by the data tools.
+func expectTableColumns(mock sqlmock.Sqlmock, cols [][3]string) {
+	rows := sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE", "COLUMN_KEY"})
+	for _, c := range cols {
+		rows.AddRow(c[0], c[1], c[2])
+	}
+	mock.ExpectQuery("SELECT COLUMN_NAME, DATA_TYPE, COLUMN_KEY").
+		WithArgs("testdb", "users").
+		WillReturnRows(rows)
+}
+
+// ===== toolProfileTable Tests =====
+
+func TestToolProfileTableFullScan(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "int", "PRI"},
+		{"status", "varchar", ""},
+	})
+	mock.ExpectQuery("SELECT TABLE_ROWS FROM information_schema.TABLES").
+		WithArgs("testdb", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(4))
+	mock.ExpectQuery("SELECT `id`, `status` FROM `testdb`.`users` LIMIT 10000").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).
+			AddRow(1, "active").
+			AddRow(2, "active").
+			AddRow(3, "banned").
+			AddRow(4, nil))
+
+	_, out, err := toolProfileTable(context.Background(), &mcp.CallToolRequest{}, ProfileTableInput{
+		Database: "testdb",
+		Table:    "users",
+	})
+	if err != nil {
+		t.Fatalf("toolProfileTable failed: %v", err)
+	}
+
+	if out.SamplingMethod != "full_scan" || !out.Exact {
+		t.Errorf("expected exact full_scan, got %s (exact=%v)", out.SamplingMethod, out.Exact)
+	}
+	if out.SampledRows != 4 {
+		t.Errorf("expected 4 sampled rows, got %d", out.SampledRows)
+	}
+	if len(out.Columns) != 2 {
+		t.Fatalf("expected 2 column profiles, got %d", len(out.Columns))
+	}
+
+	id := out.Columns[0]
+	if id.DistinctEstimate != 4 || id.NullRatio != 0 {
+		t.Errorf("unexpected id profile: %+v", id)
+	}
+	if id.Min != int64(1) || id.Max != int64(4) {
+		t.Errorf("expected id min/max 1/4, got %v/%v", id.Min, id.Max)
+	}
+	if len(id.Histogram) != defaultProfileBuckets {
+		t.Errorf("expected %d histogram buckets, got %d", defaultProfileBuckets, len(id.Histogram))
+	}
+
+	status := out.Columns[1]
+	if status.NullRatio != 0.25 {
+		t.Errorf("expected status null ratio 0.25, got %v", status.NullRatio)
+	}
+	if len(status.TopValues) == 0 || status.TopValues[0].Value != "active" || status.TopValues[0].Count != 2 {
+		t.Errorf("unexpected status top values: %+v", status.TopValues)
+	}
+	if status.Lengths == nil || status.Lengths.Min != 6 || status.Lengths.Max != 6 {
+		t.Errorf("unexpected status lengths: %+v", status.Lengths)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolProfileTablePKRangeSampling(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "bigint", "PRI"},
+		{"email", "varchar", ""},
+	})
+	mock.ExpectQuery("SELECT TABLE_ROWS FROM information_schema.TABLES").
+		WithArgs("testdb", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(1000000))
+	mock.ExpectQuery("SELECT MIN\\(`id`\\), MAX\\(`id`\\) FROM `testdb`.`users`").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 1000000))
+	mock.ExpectQuery("SELECT `email` FROM `testdb`.`users` WHERE `id` >= \\? ORDER BY `id` LIMIT 2").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).
+			AddRow("a@example.com").
+			AddRow("b@example.com"))
+
+	setSettings(t, func(s *settings) { s.columnMasker = util.NewColumnMasker([]string{"users.email"}) })
+
+	_, out, err := toolProfileTable(context.Background(), &mcp.CallToolRequest{}, ProfileTableInput{
+		Database:   "testdb",
+		Table:      "users",
+		Columns:    "email",
+		SampleSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("toolProfileTable failed: %v", err)
+	}
+
+	if out.SamplingMethod != "pk_range" || out.Exact {
+		t.Errorf("expected inexact pk_range sampling, got %s (exact=%v)", out.SamplingMethod, out.Exact)
+	}
+	email := out.Columns[0]
+	if !email.Masked {
+		t.Error("expected email to be masked")
+	}
+	if email.Min != nil || email.Max != nil || email.TopValues != nil || email.Lengths != nil {
+		t.Errorf("masked column must not expose values: %+v", email)
+	}
+	if email.DistinctEstimate <= 2 {
+		t.Errorf("expected distinct estimate scaled beyond the sample, got %d", email.DistinctEstimate)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolProfileTableUnsignedKeyFallsBackToIndexScan(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "bigint", "PRI"},
+		{"email", "varchar", ""},
+	})
+	mock.ExpectQuery("SELECT TABLE_ROWS FROM information_schema.TABLES").
+		WithArgs("testdb", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(1000000))
+	// BIGINT UNSIGNED keys above MaxInt64 cannot be used for a key range.
+	mock.ExpectQuery("SELECT MIN\\(`id`\\), MAX\\(`id`\\) FROM `testdb`.`users`").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).
+			AddRow([]byte("9223372036854775808"), []byte("18446744073709551615")))
+	mock.ExpectQuery("SELECT `email` FROM `testdb`.`users` ORDER BY `id` LIMIT 2").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).
+			AddRow("a@example.com").
+			AddRow("b@example.com"))
+
+	_, out, err := toolProfileTable(context.Background(), &mcp.CallToolRequest{}, ProfileTableInput{
+		Database:   "testdb",
+		Table:      "users",
+		Columns:    "email",
+		SampleSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("toolProfileTable failed: %v", err)
+	}
+	if out.SamplingMethod != "index_scan" || out.Exact {
+		t.Errorf("expected inexact index_scan sampling, got %s (exact=%v)", out.SamplingMethod, out.Exact)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolProfileTableUnknownColumn(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{{"id", "int", "PRI"}})
+
+	_, _, err := toolProfileTable(context.Background(), &mcp.CallToolRequest{}, ProfileTableInput{
+		Database: "testdb",
+		Table:    "users",
+		Columns:  "id, nope",
+	})
+	if err == nil || err.Error() != "unknown column: nope" {
+		t.Errorf("expected unknown column error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolProfileTableMissingInputs(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	_, _, err := toolProfileTable(context.Background(), &mcp.CallToolRequest{}, ProfileTableInput{Database: "testdb"})
+	if err == nil {
+		t.Error("expected error for missing table")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// ===== toolSampleRows Tests =====
+
+func TestToolSampleRowsFirst(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "int", "PRI"},
+		{"bio", "text", ""},
+		{"email", "varchar", ""},
+	})
+	mock.ExpectQuery("SELECT `id`, `bio`, `email` FROM `testdb`.`users` ORDER BY `id` LIMIT 2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "bio", "email"}).
+			AddRow(1, "a very long biography", "a@example.com").
+			AddRow(2, "short", "b@example.com"))
+
+	setSettings(t, func(s *settings) { s.columnMasker = util.NewColumnMasker([]string{"email"}) })
+
+	_, out, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database:       "testdb",
+		Table:          "users",
+		N:              2,
+		MaxValueLength: 6,
+	})
+	if err != nil {
+		t.Fatalf("toolSampleRows failed: %v", err)
+	}
+
+	if out.Strategy != "first" || len(out.Rows) != 2 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if out.Rows[0][1] != "a very..." || out.Rows[1][1] != "short" {
+		t.Errorf("unexpected bio values: %v, %v", out.Rows[0][1], out.Rows[1][1])
+	}
+	if out.Truncated != 1 {
+		t.Errorf("expected 1 truncated value, got %d", out.Truncated)
+	}
+	if out.Rows[0][2] != util.MaskedValue {
+		t.Errorf("expected email to be masked, got %v", out.Rows[0][2])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSampleRowsRandom(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "bigint", "PRI"},
+		{"name", "varchar", ""},
+	})
+	mock.ExpectQuery("SELECT TABLE_ROWS FROM information_schema.TABLES").
+		WithArgs("testdb", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(50000))
+	mock.ExpectQuery("SELECT MIN\\(`id`\\), MAX\\(`id`\\) FROM `testdb`.`users`").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 50000))
+	mock.ExpectQuery("SELECT `id`, `name` FROM `testdb`.`users` WHERE `id` >= \\? ORDER BY `id` LIMIT 3").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(100, "x"))
+
+	_, out, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database: "testdb",
+		Table:    "users",
+		N:        3,
+		Strategy: "random",
+	})
+	if err != nil {
+		t.Fatalf("toolSampleRows failed: %v", err)
+	}
+	if out.Strategy != "random" || len(out.Rows) != 1 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSampleRowsRandomUnsignedKey(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "bigint", "PRI"},
+		{"name", "varchar", ""},
+	})
+	mock.ExpectQuery("SELECT TABLE_ROWS FROM information_schema.TABLES").
+		WithArgs("testdb", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(50000))
+	// BIGINT UNSIGNED keys above MaxInt64 cannot be used for a key range.
+	mock.ExpectQuery("SELECT MIN\\(`id`\\), MAX\\(`id`\\) FROM `testdb`.`users`").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).
+			AddRow([]byte("9223372036854775808"), []byte("18446744073709551615")))
+	mock.ExpectQuery("SELECT `id`, `name` FROM `testdb`.`users` ORDER BY `id` LIMIT 3").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow([]byte("9223372036854775808"), "x"))
+
+	_, out, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database: "testdb",
+		Table:    "users",
+		N:        3,
+		Strategy: "random",
+	})
+	if err != nil {
+		t.Fatalf("toolSampleRows failed: %v", err)
+	}
+	if out.Strategy != "random" || len(out.Rows) != 1 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSampleRowsRandomRequiresIntegerKey(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{{"uuid", "char", "PRI"}})
+
+	_, _, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database: "testdb",
+		Table:    "users",
+		Strategy: "random",
+	})
+	if err == nil {
+		t.Error("expected error for random strategy without integer primary key")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSampleRowsRecent(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectTableColumns(mock, [][3]string{
+		{"id", "int", "PRI"},
+		{"created_at", "datetime", ""},
+	})
+	mock.ExpectQuery("SELECT `id`, `created_at` FROM `testdb`.`users` ORDER BY `created_at` DESC LIMIT 10").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, "2024-01-02 00:00:00"))
+
+	_, out, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database: "testdb",
+		Table:    "users",
+		Strategy: "recent",
+	})
+	if err != nil {
+		t.Fatalf("toolSampleRows failed: %v", err)
+	}
+	if len(out.Rows) != 1 {
+		t.Errorf("expected 1 row, got %d", len(out.Rows))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSampleRowsInvalidInput(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	if _, _, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{Table: "users"}); err == nil {
+		t.Error("expected error for missing database")
+	}
+
+	_, _, err := toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database: "testdb",
+		Table:    "users",
+		Strategy: "everything",
+	})
+	if err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+
+	expectTableColumns(mock, [][3]string{{"id", "int", "PRI"}})
+	_, _, err = toolSampleRows(context.Background(), &mcp.CallToolRequest{}, SampleRowsInput{
+		Database: "testdb",
+		Table:    "users",
+		Strategy: "recent",
+	})
+	if err == nil {
+		t.Error("expected error for recent strategy without timestamp column")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// ===== toolSummarizeDatabase Tests =====
+
+// wordTokenEstimator counts whitespace/punctuation-free runs, so budgets are deterministic in tests.
+type wordTokenEstimator struct{}
+
+func (wordTokenEstimator) Model() string { return "test" }
+
+func (wordTokenEstimator) Count(text string) (int, error) {
+	return len(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})), nil
+}
+
+// expectSummaryQueries registers the metadata lookups used by summarize_database.
+func expectSummaryQueries(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT (.+) FROM information_schema.TABLES").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "TABLE_ROWS", "data_mb", "index_mb", "total_mb", "ENGINE"}).
+			AddRow("audit_log", 500000, 90.0, 10.0, 100.0, "InnoDB").
+			AddRow("orders", 200000, 40.0, 10.0, 50.0, "InnoDB").
+			AddRow("customers", 20000, 2.0, 1.0, 3.0, "InnoDB"))
+	mock.ExpectQuery("FROM information_schema.KEY_COLUMN_USAGE").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "on_update", "on_delete"}).
+			AddRow("fk_orders_customer", "orders", "customer_id", "customers", "id", "RESTRICT", "CASCADE"))
+	mock.ExpectQuery("FROM information_schema.STATISTICS").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "INDEX_NAME", "COLUMN_NAME"}).
+			AddRow("customers", "PRIMARY", "id").
+			AddRow("customers", "uk_email", "email").
+			AddRow("orders", "PRIMARY", "id").
+			AddRow("orders", "idx_customer", "customer_id").
+			AddRow("orders", "idx_customer_created", "customer_id").
+			AddRow("orders", "idx_customer_created", "created_at"))
+	mock.ExpectQuery("SELECT TABLE_NAME, TABLE_COMMENT").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "TABLE_COMMENT"}).
+			AddRow("orders", "Customer orders"))
+}
+
+func TestToolSummarizeDatabase(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	oldEstimator := tokenEstimator
+	tokenEstimator = wordTokenEstimator{}
+	defer func() { tokenEstimator = oldEstimator }()
+
+	expectSummaryQueries(mock)
+
+	_, out, err := toolSummarizeDatabase(context.Background(), &mcp.CallToolRequest{}, SummarizeDatabaseInput{Database: "shop"})
+	if err != nil {
+		t.Fatalf("toolSummarizeDatabase failed: %v", err)
+	}
+
+	if out.TableCount != 3 || len(out.Tables) != 3 || out.Omitted != 0 || out.Compacted != 0 {
+		t.Fatalf("expected all 3 tables in full, got %+v", out)
+	}
+	// orders and customers are connected, so they rank ahead of the larger audit_log.
+	names := []string{out.Tables[0].Name, out.Tables[1].Name, out.Tables[2].Name}
+	if names[0] != "orders" || names[1] != "customers" || names[2] != "audit_log" {
+		t.Errorf("unexpected priority order: %v", names)
+	}
+
+	orders := out.Tables[0]
+	if len(orders.PrimaryKey) != 1 || orders.PrimaryKey[0] != "id" {
+		t.Errorf("unexpected primary key: %v", orders.PrimaryKey)
+	}
+	if len(orders.KeyColumns) != 2 || orders.KeyColumns[0] != "customer_id" || orders.KeyColumns[1] != "created_at" {
+		t.Errorf("unexpected key columns: %v", orders.KeyColumns)
+	}
+	if len(orders.References) != 1 || orders.References[0] != "customer_id -> customers.id" {
+		t.Errorf("unexpected references: %v", orders.References)
+	}
+	if orders.Comment != "Customer orders" {
+		t.Errorf("unexpected comment: %q", orders.Comment)
+	}
+	if out.EstimatedTokens <= 0 || out.EstimatedTokens > defaultSummaryTokens {
+		t.Errorf("unexpected token estimate: %d", out.EstimatedTokens)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSummarizeDatabaseTightBudget(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	oldEstimator := tokenEstimator
+	tokenEstimator = wordTokenEstimator{}
+	defer func() { tokenEstimator = oldEstimator }()
+
+	expectSummaryQueries(mock)
+
+	_, out, err := toolSummarizeDatabase(context.Background(), &mcp.CallToolRequest{}, SummarizeDatabaseInput{
+		Database:  "shop",
+		MaxTokens: 50,
+	})
+	if err != nil {
+		t.Fatalf("toolSummarizeDatabase failed: %v", err)
+	}
+
+	if len(out.Tables) == 0 || out.Tables[0].Name != "orders" {
+		t.Fatalf("expected orders to be kept first, got %+v", out.Tables)
+	}
+	if out.Compacted+out.Omitted == 0 {
+		t.Errorf("expected some tables to be compacted or omitted, got %+v", out)
+	}
+	if len(out.Tables)+out.Omitted != out.TableCount {
+		t.Errorf("tables (%d) + omitted (%d) != table count (%d)", len(out.Tables), out.Omitted, out.TableCount)
+	}
+	if out.EstimatedTokens > 50 {
+		t.Errorf("summary exceeds budget: %d tokens", out.EstimatedTokens)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolSummarizeDatabaseMissingDatabase(t *testing.T) {
+	_, _, err := toolSummarizeDatabase(context.Background(), &mcp.CallToolRequest{}, SummarizeDatabaseInput{})
+	if err == nil {
+		t.Error("expected error for missing database")
+	}
+}
+
+func TestEstimateDistinct(t *testing.T) {
+	tests := []struct {
+		name       string
+		freqCounts []int
+		sampled    int
+		population int64
+		want       int64
+	}{
+		{"empty sample", nil, 0, 100, 0},
+		{"all repeated", []int{50, 50}, 100, 10000, 2},
+		{"all unique scaled", []int{1, 1, 1, 1}, 4, 400, 40},
+		{"mixed singletons and repeats", []int{1, 1, 3}, 5, 500, 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateDistinct(tt.freqCounts, tt.sampled, tt.population); got != tt.want {
+				t.Errorf("estimateDistinct() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	if got := truncateString("héllo wörld", 5); got != "héllo..." {
+		t.Errorf("truncateString() = %q", got)
+	}
+	if got := truncateString("short", 10); got != "short" {
+		t.Errorf("truncateString() = %q", got)
+	}
+}
@@ -0,0 +1,787 @@
+// cmd/mysql-mcp-server/tools_diagnostics.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askdba/mysql-mcp-server/internal/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits for the diagnostics tools.
+const (
+	maxStatementLen     = 1000 // statement text is truncated to this many characters
+	defaultTopQueries   = 10
+	maxTopQueriesWindow = 300 // seconds
+	picosecondsPerMilli = 1e9
+)
+
+// processlistSources are tried in order. performance_schema.processlist
+// (MySQL 8.0.22+) does not take the global mutex that the
+// information_schema table needs, so it is preferred when available.
+var processlistSources = []string{
+	"performance_schema.processlist",
+	"information_schema.PROCESSLIST",
+}
+
+// ===== Diagnostics Tool Handlers =====
+
+func toolListProcesses(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ListProcessesInput,
+) (*mcp.CallToolResult, ListProcessesOutput, error) {
+	conf := settingsFrom(ctx)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
+	defer cancel()
+
+	var conds []string
+	var args []interface{}
+	if input.User != "" {
+		conds = append(conds, "p.USER = ?")
+		args = append(args, input.User)
+	}
+	if input.Host != "" {
+		conds = append(conds, "(p.HOST = ? OR p.HOST LIKE CONCAT(?, ':%'))")
+		args = append(args, input.Host, input.Host)
+	}
+	if input.DB != "" {
+		conds = append(conds, "p.DB = ?")
+		args = append(args, input.DB)
+	}
+	if input.Command != "" {
+		conds = append(conds, "p.COMMAND = ?")
+		args = append(args, input.Command)
+	}
+	if input.MinTime > 0 {
+		conds = append(conds, "p.TIME >= ?")
+		args = append(args, input.MinTime)
+	}
+	if input.State != "" {
+		conds = append(conds, "p.STATE LIKE ?")
+		args = append(args, input.State)
+	}
+	where := "p.ID <> CONNECTION_ID()"
+	if len(conds) > 0 {
+		where += " AND " + strings.Join(conds, " AND ")
+	}
+
+	db := getDB()
+	var rows *sql.Rows
+	var err error
+	var source string
+	for _, source = range processlistSources {
+		query := fmt.Sprintf(`SELECT p.ID, p.USER, p.HOST, p.DB, p.COMMAND, p.TIME, p.STATE, p.INFO,
+			t.trx_id, t.trx_state, TIMESTAMPDIFF(SECOND, t.trx_started, NOW()), t.trx_rows_locked
+			FROM %s p
+			LEFT JOIN information_schema.INNODB_TRX t ON t.trx_mysql_thread_id = p.ID
+			WHERE %s
+			ORDER BY p.TIME DESC
+			LIMIT %d`, source, where, conf.maxRows)
+		rows, err = db.QueryContext(ctx, query, args...)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, ListProcessesOutput{}, fmt.Errorf("processlist query failed: %w", err)
+	}
+	defer rows.Close()
+
+	out := ListProcessesOutput{Processes: []ProcessInfo{}, Source: source}
+	for rows.Next() {
+		var p ProcessInfo
+		var user, host, db, command, state, info, trxID, trxState sql.NullString
+		var procTime, trxAge, trxRowsLocked sql.NullInt64
+		if err := rows.Scan(&p.ID, &user, &host, &db, &command, &procTime, &state, &info,
+			&trxID, &trxState, &trxAge, &trxRowsLocked); err != nil {
+			return nil, ListProcessesOutput{}, fmt.Errorf("scan process failed: %w", err)
+		}
+		p.User = user.String
+		p.Host = host.String
+		p.DB = db.String
+		p.Command = command.String
+		p.Time = procTime.Int64
+		p.State = state.String
+		p.Statement = truncateString(info.String, maxStatementLen)
+		p.TrxID = trxID.String
+		p.TrxState = trxState.String
+		p.TrxAge = trxAge.Int64
+		p.TrxRowsLocked = trxRowsLocked.Int64
+		out.Processes = append(out.Processes, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ListProcessesOutput{}, err
+	}
+
+	return nil, out, nil
+}
+
+// Row lock waits come from the sys schema view when it is installed, or from
+// the performance_schema tables it is built on. Both return the same columns.
+var lockWaitSources = []struct {
+	name  string
+	query string
+}{
+	{
+		name: "sys.innodb_lock_waits",
+		query: `SELECT waiting_pid, waiting_query, waiting_lock_mode,
+			blocking_pid, blocking_query, blocking_lock_mode,
+			locked_table, locked_index, locked_type, wait_age_secs
+			FROM sys.innodb_lock_waits`,
+	},
+	{
+		name: "performance_schema.data_lock_waits",
+		query: `SELECT wt.PROCESSLIST_ID, r.trx_query, wl.LOCK_MODE,
+			bt.PROCESSLIST_ID, b.trx_query, bl.LOCK_MODE,
+			CONCAT_WS('.', wl.OBJECT_SCHEMA, wl.OBJECT_NAME), wl.INDEX_NAME, wl.LOCK_TYPE,
+			TIMESTAMPDIFF(SECOND, r.trx_wait_started, NOW())
+			FROM performance_schema.data_lock_waits w
+			JOIN performance_schema.data_locks wl ON wl.ENGINE_LOCK_ID = w.REQUESTING_ENGINE_LOCK_ID
+			JOIN performance_schema.data_locks bl ON bl.ENGINE_LOCK_ID = w.BLOCKING_ENGINE_LOCK_ID
+			JOIN performance_schema.threads wt ON wt.THREAD_ID = w.REQUESTING_THREAD_ID
+			JOIN performance_schema.threads bt ON bt.THREAD_ID = w.BLOCKING_THREAD_ID
+			LEFT JOIN information_schema.INNODB_TRX r ON r.trx_id = w.REQUESTING_ENGINE_TRANSACTION_ID
+			LEFT JOIN information_schema.INNODB_TRX b ON b.trx_id = w.BLOCKING_ENGINE_TRANSACTION_ID`,
+	},
+}
+
+// metadataLockWaitsQuery pairs each pending metadata lock with the granted
+// locks other sessions hold on the same object, like sys.schema_table_lock_waits.
+const metadataLockWaitsQuery = `SELECT wt.PROCESSLIST_ID, wt.PROCESSLIST_INFO, w.LOCK_TYPE,
+	bt.PROCESSLIST_ID, bt.PROCESSLIST_INFO, b.LOCK_TYPE,
+	CONCAT_WS('.', w.OBJECT_SCHEMA, w.OBJECT_NAME), NULL, w.OBJECT_TYPE, wt.PROCESSLIST_TIME
+	FROM performance_schema.metadata_locks w
+	JOIN performance_schema.threads wt ON wt.THREAD_ID = w.OWNER_THREAD_ID
+	JOIN performance_schema.metadata_locks b
+		ON b.OBJECT_TYPE = w.OBJECT_TYPE
+		AND b.OBJECT_SCHEMA <=> w.OBJECT_SCHEMA
+		AND b.OBJECT_NAME <=> w.OBJECT_NAME
+		AND b.LOCK_STATUS = 'GRANTED'
+		AND b.OWNER_THREAD_ID <> w.OWNER_THREAD_ID
+	JOIN performance_schema.threads bt ON bt.THREAD_ID = b.OWNER_THREAD_ID
+	WHERE w.LOCK_STATUS = 'PENDING'`
+
+func toolLockWaits(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input LockWaitsInput,
+) (*mcp.CallToolResult, LockWaitsOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	db := getDB()
+	out := LockWaitsOutput{
+		RowLockWaits:      []LockWaitNode{},
+		MetadataLockWaits: []LockWaitNode{},
+	}
+
+	var rowEdges []lockEdge
+	var err error
+	for _, src := range lockWaitSources {
+		rowEdges, err = queryLockEdges(ctx, db, src.query)
+		if err == nil {
+			out.Source = src.name
+			break
+		}
+	}
+	if err != nil {
+		return nil, LockWaitsOutput{}, fmt.Errorf("lock wait query failed: %w", err)
+	}
+	out.RowLockWaits = buildLockTree(filterLockEdges(rowEdges, input.MinWait))
+
+	mdlEdges, err := queryLockEdges(ctx, db, metadataLockWaitsQuery)
+	if err != nil {
+		out.Warnings = append(out.Warnings, "metadata lock waits unavailable: "+err.Error())
+	} else {
+		out.MetadataLockWaits = buildLockTree(filterLockEdges(mdlEdges, input.MinWait))
+	}
+
+	return nil, out, nil
+}
+
+// lockEdge is one "waiter waits for blocker" relation.
+type lockEdge struct {
+	waiter, blocker           int64
+	waiterQuery, blockerQuery string
+	lock                      LockWaitNode // lock fields of the waiter's node
+}
+
+// queryLockEdges runs a lock wait query returning the ten columns shared by
+// lockWaitSources and metadataLockWaitsQuery.
+func queryLockEdges(ctx context.Context, db *sql.DB, query string) ([]lockEdge, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []lockEdge
+	for rows.Next() {
+		var waiter, blocker, waitAge sql.NullInt64
+		var waiterQuery, waiterMode, blockerQuery, blockerMode, object, index, lockType sql.NullString
+		if err := rows.Scan(&waiter, &waiterQuery, &waiterMode, &blocker, &blockerQuery, &blockerMode,
+			&object, &index, &lockType, &waitAge); err != nil {
+			return nil, fmt.Errorf("scan lock wait failed: %w", err)
+		}
+		if !waiter.Valid || !blocker.Valid {
+			continue // background thread without a connection
+		}
+		edges = append(edges, lockEdge{
+			waiter:       waiter.Int64,
+			blocker:      blocker.Int64,
+			waiterQuery:  truncateString(waiterQuery.String, maxStatementLen),
+			blockerQuery: truncateString(blockerQuery.String, maxStatementLen),
+			lock: LockWaitNode{
+				Object:           object.String,
+				Index:            index.String,
+				LockType:         lockType.String,
+				LockMode:         waiterMode.String,
+				BlockingLockMode: blockerMode.String,
+				WaitSeconds:      waitAge.Int64,
+			},
+		})
+	}
+	return edges, rows.Err()
+}
+
+func filterLockEdges(edges []lockEdge, minWait int) []lockEdge {
+	if minWait <= 0 {
+		return edges
+	}
+	var out []lockEdge
+	for _, e := range edges {
+		if e.lock.WaitSeconds >= int64(minWait) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// buildLockTree turns wait edges into trees rooted at head blockers, the
+// sessions that block others without waiting themselves. Sessions that only
+// wait on each other (a cycle) are rooted at their lowest connection id.
+func buildLockTree(edges []lockEdge) []LockWaitNode {
+	queries := make(map[int64]string)
+	waitsOn := make(map[int64]bool)
+	children := make(map[int64][]lockEdge)
+	for _, e := range edges {
+		if queries[e.blocker] == "" {
+			queries[e.blocker] = e.blockerQuery
+		}
+		if e.waiterQuery != "" {
+			queries[e.waiter] = e.waiterQuery
+		}
+		waitsOn[e.waiter] = true
+		children[e.blocker] = append(children[e.blocker], e)
+	}
+
+	blockers := make([]int64, 0, len(children))
+	for pid := range children {
+		blockers = append(blockers, pid)
+	}
+	sort.Slice(blockers, func(i, j int) bool { return blockers[i] < blockers[j] })
+
+	placed := make(map[int64]bool)
+	var build func(node LockWaitNode, path map[int64]bool) LockWaitNode
+	build = func(node LockWaitNode, path map[int64]bool) LockWaitNode {
+		placed[node.PID] = true
+		path[node.PID] = true
+		defer delete(path, node.PID)
+		for _, e := range children[node.PID] {
+			if path[e.waiter] {
+				continue // wait cycle
+			}
+			child := e.lock
+			child.PID = e.waiter
+			child.Query = queries[e.waiter]
+			node.Waiters = append(node.Waiters, build(child, path))
+		}
+		return node
+	}
+
+	roots := []LockWaitNode{}
+	for _, pid := range blockers {
+		if !waitsOn[pid] {
+			roots = append(roots, build(LockWaitNode{PID: pid, Query: queries[pid]}, map[int64]bool{}))
+		}
+	}
+	for _, pid := range blockers {
+		if !placed[pid] {
+			roots = append(roots, build(LockWaitNode{PID: pid, Query: queries[pid]}, map[int64]bool{}))
+		}
+	}
+	return roots
+}
+
+// digestMetrics maps top_queries order_by values to their ranking value.
+var digestMetrics = map[string]func(QueryDigestInfo) float64{
+	"total_latency":       func(q QueryDigestInfo) float64 { return q.TotalLatencyMs },
+	"avg_latency":         func(q QueryDigestInfo) float64 { return q.AvgLatencyMs },
+	"rows_examined_ratio": func(q QueryDigestInfo) float64 { return q.RowsExaminedRatio },
+	"tmp_disk_tables":     func(q QueryDigestInfo) float64 { return float64(q.TmpDiskTables) },
+	"no_index_used":       func(q QueryDigestInfo) float64 { return float64(q.NoIndexUsed) },
+	"errors":              func(q QueryDigestInfo) float64 { return float64(q.Errors) },
+}
+
+func toolTopQueries(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input TopQueriesInput,
+) (*mcp.CallToolResult, TopQueriesOutput, error) {
+	conf := settingsFrom(ctx)
+	orderBy := strings.ToLower(strings.TrimSpace(input.OrderBy))
+	if orderBy == "" {
+		orderBy = "total_latency"
+	}
+	metric, ok := digestMetrics[orderBy]
+	if !ok {
+		return nil, TopQueriesOutput{}, fmt.Errorf("unknown order_by %q", input.OrderBy)
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultTopQueries
+	}
+	if limit > conf.maxRows {
+		limit = conf.maxRows
+	}
+	if input.Window < 0 || input.Window > maxTopQueriesWindow {
+		return nil, TopQueriesOutput{}, fmt.Errorf("window must be between 0 and %d seconds", maxTopQueriesWindow)
+	}
+	if err := checkWindowDeadline(ctx, input.Window, conf.queryTimeout); err != nil {
+		return nil, TopQueriesOutput{}, err
+	}
+
+	db := getDB()
+	current, err := readDigestSnapshot(ctx, db, input.Schema)
+	if err != nil {
+		return nil, TopQueriesOutput{}, err
+	}
+
+	// With a window, rank by what happened between two snapshots instead of
+	// the totals accumulated since the summary table was last truncated.
+	if input.Window > 0 {
+		select {
+		case <-time.After(time.Duration(input.Window) * time.Second):
+		case <-ctx.Done():
+			return nil, TopQueriesOutput{}, ctx.Err()
+		}
+		baseline := current
+		current, err = readDigestSnapshot(ctx, db, input.Schema)
+		if err != nil {
+			return nil, TopQueriesOutput{}, err
+		}
+		for key, d := range current {
+			if prev, ok := baseline[key]; ok {
+				d = d.sub(prev)
+			}
+			if d.calls <= 0 {
+				delete(current, key)
+				continue
+			}
+			current[key] = d
+		}
+	}
+
+	queries := make([]QueryDigestInfo, 0, len(current))
+	for _, d := range current {
+		q := d.info()
+		if orderBy != "total_latency" && orderBy != "avg_latency" && metric(q) <= 0 {
+			continue
+		}
+		queries = append(queries, q)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		mi, mj := metric(queries[i]), metric(queries[j])
+		if mi != mj {
+			return mi > mj
+		}
+		return queries[i].Digest < queries[j].Digest
+	})
+	if len(queries) > limit {
+		queries = queries[:limit]
+	}
+
+	return nil, TopQueriesOutput{Queries: queries, OrderBy: orderBy, Window: input.Window}, nil
+}
+
+// checkWindowDeadline rejects an observation window of window seconds that
+// cannot end before the deadline of ctx (e.g. the HTTP request timeout)
+// while leaving queryTimeout for the queries that follow it.
+func checkWindowDeadline(ctx context.Context, window int, queryTimeout time.Duration) error {
+	deadline, ok := ctx.Deadline()
+	if !ok || window <= 0 {
+		return nil
+	}
+	available := time.Until(deadline) - queryTimeout
+	if time.Duration(window)*time.Second <= available {
+		return nil
+	}
+	return fmt.Errorf("window of %d seconds does not fit in the request timeout: at most %d seconds are left after reserving the %s query timeout",
+		window, max(int64(available/time.Second), 0), queryTimeout)
+}
+
+// digestStats holds the counters of one events_statements_summary_by_digest row.
+type digestStats struct {
+	schema, digest, text, sample string
+	calls                        int64
+	timerWait                    float64 // picoseconds; can exceed int64 on long-running servers
+	rowsExamined, rowsSent       int64
+	tmpDiskTables, noIndexUsed   int64
+	errors                       int64
+}
+
+func (d digestStats) sub(prev digestStats) digestStats {
+	d.calls -= prev.calls
+	d.timerWait -= prev.timerWait
+	d.rowsExamined -= prev.rowsExamined
+	d.rowsSent -= prev.rowsSent
+	d.tmpDiskTables -= prev.tmpDiskTables
+	d.noIndexUsed -= prev.noIndexUsed
+	d.errors -= prev.errors
+	return d
+}
+
+func (d digestStats) info() QueryDigestInfo {
+	q := QueryDigestInfo{
+		Schema:         d.schema,
+		Digest:         d.digest,
+		DigestText:     truncateString(d.text, maxStatementLen),
+		SampleQuery:    d.sample,
+		Calls:          d.calls,
+		TotalLatencyMs: roundTo(d.timerWait/picosecondsPerMilli, 3),
+		RowsExamined:   d.rowsExamined,
+		RowsSent:       d.rowsSent,
+		TmpDiskTables:  d.tmpDiskTables,
+		NoIndexUsed:    d.noIndexUsed,
+		Errors:         d.errors,
+	}
+	if d.calls > 0 {
+		q.AvgLatencyMs = roundTo(d.timerWait/picosecondsPerMilli/float64(d.calls), 3)
+	}
+	sent := d.rowsSent
+	if sent < 1 {
+		sent = 1
+	}
+	q.RowsExaminedRatio = roundTo(float64(d.rowsExamined)/float64(sent), 2)
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(d.sample)), "SELECT") {
+		q.Explain = &ExplainQueryInput{SQL: d.sample, Database: d.schema}
+	}
+	return q
+}
+
+// readDigestSnapshot reads the statement digest summary keyed by schema and digest.
+// QUERY_SAMPLE_TEXT only exists on MySQL 8.0.3+, so older servers are read without it.
+func readDigestSnapshot(ctx context.Context, db *sql.DB, schema string) (map[string]digestStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	var args []interface{}
+	where := "WHERE DIGEST IS NOT NULL"
+	if schema != "" {
+		where += " AND SCHEMA_NAME = ?"
+		args = append(args, schema)
+	}
+
+	var rows *sql.Rows
+	var err error
+	for _, sampleCol := range []string{"QUERY_SAMPLE_TEXT", "NULL"} {
+		query := fmt.Sprintf(`SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, %s,
+			COUNT_STAR, SUM_TIMER_WAIT, SUM_ROWS_EXAMINED, SUM_ROWS_SENT,
+			SUM_CREATED_TMP_DISK_TABLES, SUM_NO_INDEX_USED, SUM_ERRORS
+			FROM performance_schema.events_statements_summary_by_digest
+			%s`, sampleCol, where)
+		rows, err = db.QueryContext(ctx, query, args...)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("digest summary query failed: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]digestStats)
+	for rows.Next() {
+		var d digestStats
+		var schemaName, text, sample sql.NullString
+		var timerWait sql.NullFloat64
+		if err := rows.Scan(&schemaName, &d.digest, &text, &sample,
+			&d.calls, &timerWait, &d.rowsExamined, &d.rowsSent,
+			&d.tmpDiskTables, &d.noIndexUsed, &d.errors); err != nil {
+			return nil, fmt.Errorf("scan digest failed: %w", err)
+		}
+		d.schema = schemaName.String
+		d.text = text.String
+		d.sample = sample.String
+		d.timerWait = timerWait.Float64
+		out[d.schema+"\x00"+d.digest] = d
+	}
+	return out, rows.Err()
+}
+
+func toolInnodbStatus(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input InnodbStatusInput,
+) (*mcp.CallToolResult, InnodbStatusOutput, error) {
+	want := make(map[string]bool)
+	for _, name := range strings.Split(input.Sections, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !innodbSections[name] {
+			return nil, InnodbStatusOutput{}, fmt.Errorf("unknown section: %s", name)
+		}
+		want[name] = true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	var engine, name, status string
+	if err := getDB().QueryRowContext(ctx, "SHOW ENGINE INNODB STATUS").Scan(&engine, &name, &status); err != nil {
+		return nil, InnodbStatusOutput{}, fmt.Errorf("SHOW ENGINE INNODB STATUS failed: %w", err)
+	}
+
+	return nil, parseInnodbStatus(status, want), nil
+}
+
+func toolReplicationStatus(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ReplicationStatusInput,
+) (*mcp.CallToolResult, ReplicationStatusOutput, error) {
+	if connManager == nil {
+		return nil, ReplicationStatusOutput{}, fmt.Errorf("connection manager not initialized")
+	}
+
+	_, active := connManager.GetActive()
+	names := []string{active}
+	if input.AllConnections {
+		names = connManager.Names()
+	}
+
+	out := ReplicationStatusOutput{Servers: make([]ServerReplicationStatus, 0, len(names))}
+	for _, name := range names {
+		var status ServerReplicationStatus
+		queried := false
+		err := withConnection(ctx, name, func(db *sql.DB) error {
+			var err error
+			status, err = replicationStatusFor(ctx, db)
+			queried = true
+			return err
+		})
+		if err != nil && !queried {
+			status = ServerReplicationStatus{Roles: []string{}, Error: err.Error()}
+		}
+		status.Connection = name
+		out.Servers = append(out.Servers, status)
+	}
+
+	return nil, out, nil
+}
+
+// replicationStatusFor inspects one server. Failures are reported in the
+// result so one unreachable server does not hide the rest; the error of the
+// replica status query is also returned for the circuit breaker.
+func replicationStatusFor(ctx context.Context, db *sql.DB) (ServerReplicationStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+
+	var status ServerReplicationStatus
+
+	// SHOW REPLICA STATUS exists from MySQL 8.0.22; older servers only know SHOW SLAVE STATUS.
+	rows, err := queryStringRows(ctx, db, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = queryStringRows(ctx, db, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		status.Roles = []string{}
+		status.Error = "replication status query failed: " + err.Error()
+		return status, err
+	}
+	for _, row := range rows {
+		status.Channels = append(status.Channels, replicationChannelFromRow(row))
+	}
+
+	if len(status.Channels) > 0 {
+		if err := addWorkerErrors(ctx, db, status.Channels); err != nil {
+			status.Warnings = append(status.Warnings, "applier worker status unavailable: "+err.Error())
+		}
+	}
+
+	replicas, err := queryStringRows(ctx, db, "SHOW REPLICAS")
+	if err != nil {
+		replicas, err = queryStringRows(ctx, db, "SHOW SLAVE HOSTS")
+	}
+	if err != nil {
+		status.Warnings = append(status.Warnings, "replica list unavailable: "+err.Error())
+	}
+	for _, r := range replicas {
+		desc := "server_id " + r["Server_Id"].String
+		if host := r["Host"].String; host != "" {
+			desc = fmt.Sprintf("%s:%s (%s)", host, r["Port"].String, desc)
+		}
+		status.Replicas = append(status.Replicas, desc)
+	}
+
+	members, err := groupReplicationMembers(ctx, db)
+	if err != nil {
+		status.Warnings = append(status.Warnings, "group replication status unavailable: "+err.Error())
+	}
+	status.GroupMembers = members
+
+	status.Roles = []string{}
+	for _, m := range members {
+		if m.Self && m.Role != "" {
+			status.Roles = append(status.Roles, "group_"+strings.ToLower(m.Role))
+		}
+	}
+	if len(status.Channels) > 0 && len(members) == 0 {
+		status.Roles = append(status.Roles, "replica")
+	}
+	if len(status.Replicas) > 0 {
+		status.Roles = append(status.Roles, "source")
+	}
+	if len(status.Roles) == 0 {
+		status.Roles = append(status.Roles, "standalone")
+	}
+	return status, nil
+}
+
+// replicationChannelFromRow normalizes a SHOW REPLICA STATUS or SHOW SLAVE STATUS row.
+func replicationChannelFromRow(row map[string]sql.NullString) ReplicationChannel {
+	get := func(names ...string) string {
+		for _, n := range names {
+			if v, ok := row[n]; ok {
+				return v.String
+			}
+		}
+		return ""
+	}
+
+	ch := ReplicationChannel{
+		Channel:          get("Channel_Name"),
+		SourceHost:       get("Source_Host", "Master_Host"),
+		IORunning:        get("Replica_IO_Running", "Slave_IO_Running"),
+		SQLRunning:       get("Replica_SQL_Running", "Slave_SQL_Running"),
+		SQLState:         get("Replica_SQL_Running_State", "Slave_SQL_Running_State"),
+		LastIOError:      get("Last_IO_Error"),
+		LastSQLError:     get("Last_SQL_Error"),
+		AutoPosition:     get("Auto_Position") == "1",
+		RetrievedGTIDSet: strings.Join(strings.Fields(get("Retrieved_Gtid_Set")), ""),
+	}
+	ch.SourcePort, _ = strconv.ParseInt(get("Source_Port", "Master_Port"), 10, 64)
+	if lag, err := strconv.ParseInt(get("Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64); err == nil {
+		ch.LagSeconds = &lag
+	}
+
+	executedRaw := get("Executed_Gtid_Set")
+	ch.ExecutedGTIDSet = truncateString(strings.Join(strings.Fields(executedRaw), ""), maxStatementLen)
+	retrieved, errR := util.ParseGTIDSet(ch.RetrievedGTIDSet)
+	executed, errE := util.ParseGTIDSet(executedRaw)
+	if errR == nil && errE == nil {
+		pending := retrieved.Subtract(executed)
+		ch.PendingGTIDs = truncateString(pending.String(), maxStatementLen)
+		ch.PendingGTIDCount = pending.Count()
+		ch.GTIDGaps = truncateString(executed.Gaps().String(), maxStatementLen)
+	}
+	return ch
+}
+
+// addWorkerErrors attaches the errors of multi-threaded applier workers, which
+// SHOW REPLICA STATUS only summarizes as "Coordinator stopped".
+func addWorkerErrors(ctx context.Context, db *sql.DB, channels []ReplicationChannel) error {
+	rows, err := queryStringRows(ctx, db, `SELECT CHANNEL_NAME, WORKER_ID, LAST_ERROR_NUMBER, LAST_ERROR_MESSAGE
+		FROM performance_schema.replication_applier_status_by_worker
+		WHERE LAST_ERROR_NUMBER <> 0`)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		for i := range channels {
+			if channels[i].Channel == r["CHANNEL_NAME"].String {
+				channels[i].WorkerErrors = append(channels[i].WorkerErrors, fmt.Sprintf("worker %s: error %s: %s",
+					r["WORKER_ID"].String, r["LAST_ERROR_NUMBER"].String, r["LAST_ERROR_MESSAGE"].String))
+			}
+		}
+	}
+	return nil
+}
+
+// groupReplicationMembers lists Group Replication members. A server with the
+// plugin installed but not running reports itself OFFLINE; that is not a group.
+func groupReplicationMembers(ctx context.Context, db *sql.DB) ([]GroupMember, error) {
+	rows, err := queryStringRows(ctx, db, `SELECT m.MEMBER_ID, m.MEMBER_HOST, m.MEMBER_PORT, m.MEMBER_STATE, m.MEMBER_ROLE,
+		m.MEMBER_ID = @@server_uuid AS IS_SELF,
+		s.COUNT_TRANSACTIONS_IN_QUEUE, s.COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE
+		FROM performance_schema.replication_group_members m
+		LEFT JOIN performance_schema.replication_group_member_stats s ON s.MEMBER_ID = m.MEMBER_ID
+		ORDER BY m.MEMBER_HOST, m.MEMBER_PORT`)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []GroupMember
+	online := false
+	for _, r := range rows {
+		m := GroupMember{
+			ID:    r["MEMBER_ID"].String,
+			Host:  r["MEMBER_HOST"].String,
+			State: r["MEMBER_STATE"].String,
+			Role:  r["MEMBER_ROLE"].String,
+			Self:  r["IS_SELF"].String == "1",
+		}
+		m.Port, _ = strconv.ParseInt(r["MEMBER_PORT"].String, 10, 64)
+		m.CertifierQueue, _ = strconv.ParseInt(r["COUNT_TRANSACTIONS_IN_QUEUE"].String, 10, 64)
+		m.ApplierQueue, _ = strconv.ParseInt(r["COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE"].String, 10, 64)
+		if m.State != "OFFLINE" {
+			online = true
+		}
+		members = append(members, m)
+	}
+	if !online {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// queryStringRows runs a query and returns each row as a column-name map.
+// It suits SHOW statements whose column set differs between server versions.
+func queryStringRows(ctx context.Context, db *sql.DB, query string) ([]map[string]sql.NullString, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]sql.NullString
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]sql.NullString, len(cols))
+		for i, col := range cols {
+			row[col] = values[i]
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
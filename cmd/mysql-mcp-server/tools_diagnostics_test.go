@@ -0,0 +1,459 @@
+// cmd/mysql-mcp-server/tools_diagnostics_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var processColumns = []string{
+	"ID", "USER", "HOST", "DB", "COMMAND", "TIME", "STATE", "INFO",
+	"trx_id", "trx_state", "trx_age", "trx_rows_locked",
+}
+
+// ===== toolListProcesses Tests =====
+
+func TestToolListProcessesFilters(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	longQuery := "SELECT " + strings.Repeat("x, ", 600) + "1"
+	mock.ExpectQuery("FROM performance_schema.processlist p\\s+LEFT JOIN information_schema.INNODB_TRX t .*"+
+		"WHERE p.ID <> CONNECTION_ID\\(\\) AND p.USER = \\? AND \\(p.HOST = \\? OR p.HOST LIKE CONCAT\\(\\?, ':%'\\)\\) "+
+		"AND p.COMMAND = \\? AND p.TIME >= \\?").
+		WithArgs("app", "10.0.0.5", "10.0.0.5", "Query", 30).
+		WillReturnRows(sqlmock.NewRows(processColumns).
+			AddRow(42, "app", "10.0.0.5:51234", "shop", "Query", 120, "updating", longQuery,
+				"1234", "RUNNING", 125, 3).
+			AddRow(43, "app", "10.0.0.5:51240", nil, "Query", 31, "executing", "SELECT 1",
+				nil, nil, nil, nil))
+
+	_, out, err := toolListProcesses(context.Background(), &mcp.CallToolRequest{}, ListProcessesInput{
+		User:    "app",
+		Host:    "10.0.0.5",
+		Command: "Query",
+		MinTime: 30,
+	})
+	if err != nil {
+		t.Fatalf("toolListProcesses failed: %v", err)
+	}
+
+	if out.Source != "performance_schema.processlist" {
+		t.Errorf("unexpected source %q", out.Source)
+	}
+	if len(out.Processes) != 2 {
+		t.Fatalf("expected 2 processes, got %d", len(out.Processes))
+	}
+	p := out.Processes[0]
+	if p.ID != 42 || p.DB != "shop" || p.Time != 120 || p.TrxID != "1234" || p.TrxAge != 125 || p.TrxRowsLocked != 3 {
+		t.Errorf("unexpected process: %+v", p)
+	}
+	if !strings.HasSuffix(p.Statement, "...") || len([]rune(p.Statement)) != maxStatementLen+3 {
+		t.Errorf("expected statement truncated to %d characters, got %d", maxStatementLen, len(p.Statement))
+	}
+	if out.Processes[1].TrxID != "" || out.Processes[1].DB != "" {
+		t.Errorf("expected no transaction or db for second process: %+v", out.Processes[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolListProcessesFallsBackToInformationSchema(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM performance_schema.processlist").
+		WillReturnError(errors.New("Table 'performance_schema.processlist' doesn't exist"))
+	mock.ExpectQuery("FROM information_schema.PROCESSLIST p").
+		WithArgs("%lock%").
+		WillReturnRows(sqlmock.NewRows(processColumns).
+			AddRow(7, "root", "localhost", "shop", "Query", 5, "Waiting for table metadata lock", "ALTER TABLE t ADD c INT",
+				nil, nil, nil, nil))
+
+	_, out, err := toolListProcesses(context.Background(), &mcp.CallToolRequest{}, ListProcessesInput{State: "%lock%"})
+	if err != nil {
+		t.Fatalf("toolListProcesses failed: %v", err)
+	}
+	if out.Source != "information_schema.PROCESSLIST" || len(out.Processes) != 1 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// ===== toolLockWaits Tests =====
+
+var lockWaitColumns = []string{
+	"waiting_pid", "waiting_query", "waiting_lock_mode",
+	"blocking_pid", "blocking_query", "blocking_lock_mode",
+	"locked_table", "locked_index", "locked_type", "wait_age_secs",
+}
+
+func TestToolLockWaitsChain(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM sys.innodb_lock_waits").
+		WillReturnRows(sqlmock.NewRows(lockWaitColumns).
+			AddRow(11, "UPDATE orders SET s=1 WHERE id=1", "X,REC_NOT_GAP", 10, nil, "X,REC_NOT_GAP", "`shop`.`orders`", "PRIMARY", "RECORD", 40).
+			AddRow(12, "DELETE FROM orders WHERE id=1", "X,REC_NOT_GAP", 10, nil, "X,REC_NOT_GAP", "`shop`.`orders`", "PRIMARY", "RECORD", 5).
+			AddRow(13, "UPDATE customers SET n='a' WHERE id=7", "X", 11, "UPDATE orders SET s=1 WHERE id=1", "X", "`shop`.`customers`", "PRIMARY", "RECORD", 20))
+	mock.ExpectQuery("FROM performance_schema.metadata_locks w").
+		WillReturnRows(sqlmock.NewRows(lockWaitColumns).
+			AddRow(21, "ALTER TABLE orders ADD COLUMN c INT", "EXCLUSIVE", 20, nil, "SHARED_READ", "shop.orders", nil, "TABLE", 12))
+
+	_, out, err := toolLockWaits(context.Background(), &mcp.CallToolRequest{}, LockWaitsInput{})
+	if err != nil {
+		t.Fatalf("toolLockWaits failed: %v", err)
+	}
+
+	if out.Source != "sys.innodb_lock_waits" {
+		t.Errorf("unexpected source %q", out.Source)
+	}
+	if len(out.RowLockWaits) != 1 {
+		t.Fatalf("expected a single head blocker, got %+v", out.RowLockWaits)
+	}
+	root := out.RowLockWaits[0]
+	if root.PID != 10 || len(root.Waiters) != 2 {
+		t.Fatalf("expected pid 10 blocking two sessions, got %+v", root)
+	}
+	first := root.Waiters[0]
+	if first.PID != 11 || first.Index != "PRIMARY" || first.LockMode != "X,REC_NOT_GAP" || first.WaitSeconds != 40 {
+		t.Errorf("unexpected waiter: %+v", first)
+	}
+	if len(first.Waiters) != 1 || first.Waiters[0].PID != 13 || first.Waiters[0].Object != "`shop`.`customers`" {
+		t.Errorf("expected pid 13 waiting on pid 11, got %+v", first.Waiters)
+	}
+
+	if len(out.MetadataLockWaits) != 1 || out.MetadataLockWaits[0].PID != 20 {
+		t.Fatalf("unexpected metadata lock waits: %+v", out.MetadataLockWaits)
+	}
+	mdl := out.MetadataLockWaits[0].Waiters[0]
+	if mdl.PID != 21 || mdl.LockMode != "EXCLUSIVE" || mdl.BlockingLockMode != "SHARED_READ" || mdl.LockType != "TABLE" {
+		t.Errorf("unexpected metadata lock waiter: %+v", mdl)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolLockWaitsFallbackAndMinWait(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM sys.innodb_lock_waits").
+		WillReturnError(errors.New("Table 'sys.innodb_lock_waits' doesn't exist"))
+	mock.ExpectQuery("FROM performance_schema.data_lock_waits w").
+		WillReturnRows(sqlmock.NewRows(lockWaitColumns).
+			AddRow(11, "UPDATE t SET a=1", "X", 10, nil, "X", "shop.t", "PRIMARY", "RECORD", 40).
+			AddRow(12, "UPDATE t SET a=2", "X", 10, nil, "X", "shop.t", "PRIMARY", "RECORD", 2))
+	mock.ExpectQuery("FROM performance_schema.metadata_locks w").
+		WillReturnError(errors.New("SELECT command denied"))
+
+	_, out, err := toolLockWaits(context.Background(), &mcp.CallToolRequest{}, LockWaitsInput{MinWait: 10})
+	if err != nil {
+		t.Fatalf("toolLockWaits failed: %v", err)
+	}
+
+	if out.Source != "performance_schema.data_lock_waits" {
+		t.Errorf("unexpected source %q", out.Source)
+	}
+	if len(out.RowLockWaits) != 1 || len(out.RowLockWaits[0].Waiters) != 1 || out.RowLockWaits[0].Waiters[0].PID != 11 {
+		t.Errorf("expected only the 40s wait to remain, got %+v", out.RowLockWaits)
+	}
+	if len(out.Warnings) != 1 || len(out.MetadataLockWaits) != 0 {
+		t.Errorf("expected a metadata lock warning, got %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestBuildLockTreeCycle(t *testing.T) {
+	edges := []lockEdge{
+		{waiter: 2, blocker: 1, lock: LockWaitNode{LockMode: "X"}},
+		{waiter: 1, blocker: 2, lock: LockWaitNode{LockMode: "X"}},
+	}
+
+	roots := buildLockTree(edges)
+	if len(roots) != 1 || roots[0].PID != 1 {
+		t.Fatalf("expected cycle rooted at pid 1, got %+v", roots)
+	}
+	if len(roots[0].Waiters) != 1 || roots[0].Waiters[0].PID != 2 || len(roots[0].Waiters[0].Waiters) != 0 {
+		t.Errorf("expected pid 2 under pid 1 with the cycle cut, got %+v", roots[0].Waiters)
+	}
+}
+
+// ===== toolTopQueries Tests =====
+
+var digestColumns = []string{
+	"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "QUERY_SAMPLE_TEXT",
+	"COUNT_STAR", "SUM_TIMER_WAIT", "SUM_ROWS_EXAMINED", "SUM_ROWS_SENT",
+	"SUM_CREATED_TMP_DISK_TABLES", "SUM_NO_INDEX_USED", "SUM_ERRORS",
+}
+
+func TestToolTopQueriesAvgLatency(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, QUERY_SAMPLE_TEXT,.*FROM performance_schema.events_statements_summary_by_digest\\s+WHERE DIGEST IS NOT NULL AND SCHEMA_NAME = \\?").
+		WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows(digestColumns).
+			// 1000 calls, 2s total: 2ms avg
+			AddRow("shop", "aaa", "SELECT * FROM `orders` WHERE `id` = ?", "SELECT * FROM orders WHERE id = 5",
+				1000, 2e12, 1000, 1000, 0, 0, 0).
+			// 10 calls, 1s total: 100ms avg, full scans
+			AddRow("shop", "bbb", "SELECT * FROM `orders` WHERE `note` LIKE ?", "SELECT * FROM orders WHERE note LIKE '%x%'",
+				10, 1e12, 500000, 10, 2, 10, 0).
+			AddRow("shop", "ccc", "UPDATE `orders` SET `s` = ?", "UPDATE orders SET s = 1",
+				5, 1e11, 5, 0, 0, 0, 1))
+
+	_, out, err := toolTopQueries(context.Background(), &mcp.CallToolRequest{}, TopQueriesInput{
+		OrderBy: "avg_latency",
+		Schema:  "shop",
+		Limit:   2,
+	})
+	if err != nil {
+		t.Fatalf("toolTopQueries failed: %v", err)
+	}
+
+	if len(out.Queries) != 2 || out.Queries[0].Digest != "bbb" || out.Queries[1].Digest != "ccc" {
+		t.Fatalf("unexpected ranking: %+v", out.Queries)
+	}
+	top := out.Queries[0]
+	if top.AvgLatencyMs != 100 || top.TotalLatencyMs != 1000 || top.RowsExaminedRatio != 50000 || top.NoIndexUsed != 10 {
+		t.Errorf("unexpected metrics: %+v", top)
+	}
+	if top.Explain == nil || top.Explain.SQL != "SELECT * FROM orders WHERE note LIKE '%x%'" || top.Explain.Database != "shop" {
+		t.Errorf("expected explain_query input for the sample, got %+v", top.Explain)
+	}
+	if out.Queries[1].Explain != nil {
+		t.Error("non-SELECT samples must not be offered to explain_query")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolTopQueriesWindow(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	// Servers without QUERY_SAMPLE_TEXT are read without samples.
+	mock.ExpectQuery("QUERY_SAMPLE_TEXT").WillReturnError(errors.New("Unknown column 'QUERY_SAMPLE_TEXT'"))
+	mock.ExpectQuery("SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, NULL,").
+		WillReturnRows(sqlmock.NewRows(digestColumns).
+			AddRow("shop", "aaa", "SELECT ?", nil, 100, 5e12, 100, 100, 0, 0, 0).
+			AddRow("shop", "bbb", "SELECT ? FROM t", nil, 100, 1e12, 100, 100, 0, 0, 0))
+	mock.ExpectQuery("QUERY_SAMPLE_TEXT").WillReturnError(errors.New("Unknown column 'QUERY_SAMPLE_TEXT'"))
+	mock.ExpectQuery("SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, NULL,").
+		WillReturnRows(sqlmock.NewRows(digestColumns).
+			AddRow("shop", "aaa", "SELECT ?", nil, 100, 5e12, 100, 100, 0, 0, 0).
+			AddRow("shop", "bbb", "SELECT ? FROM t", nil, 110, 1.5e12, 120, 110, 0, 0, 0).
+			AddRow("shop", "ccc", "SELECT 1", nil, 1, 1e9, 1, 1, 0, 0, 0))
+
+	_, out, err := toolTopQueries(context.Background(), &mcp.CallToolRequest{}, TopQueriesInput{Window: 1})
+	if err != nil {
+		t.Fatalf("toolTopQueries failed: %v", err)
+	}
+
+	// aaa did not run during the window; bbb's delta (500ms) outranks new digest ccc (1ms).
+	if len(out.Queries) != 2 || out.Queries[0].Digest != "bbb" || out.Queries[1].Digest != "ccc" {
+		t.Fatalf("unexpected window ranking: %+v", out.Queries)
+	}
+	if out.Queries[0].Calls != 10 || out.Queries[0].TotalLatencyMs != 500 || out.Queries[0].RowsExamined != 20 {
+		t.Errorf("unexpected window delta: %+v", out.Queries[0])
+	}
+	if out.Window != 1 || out.OrderBy != "total_latency" {
+		t.Errorf("unexpected output metadata: %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolTopQueriesInvalidInput(t *testing.T) {
+	if _, _, err := toolTopQueries(context.Background(), &mcp.CallToolRequest{}, TopQueriesInput{OrderBy: "fastest"}); err == nil {
+		t.Error("expected error for unknown order_by")
+	}
+	if _, _, err := toolTopQueries(context.Background(), &mcp.CallToolRequest{}, TopQueriesInput{Window: 3600}); err == nil {
+		t.Error("expected error for window above the maximum")
+	}
+}
+
+func TestToolTopQueriesWindowDeadline(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	// A 60s request timeout with a 30s query timeout leaves under 30s for the window.
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	_, _, err := toolTopQueries(ctx, &mcp.CallToolRequest{}, TopQueriesInput{Window: 45})
+	if err == nil || !strings.Contains(err.Error(), "does not fit in the request timeout: at most 29 seconds") {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+}
+
+// ===== toolInnodbStatus Tests =====
+
+func TestToolInnodbStatusSections(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SHOW ENGINE INNODB STATUS").
+		WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).
+			AddRow("InnoDB", "", sampleInnodbStatus))
+
+	_, out, err := toolInnodbStatus(context.Background(), &mcp.CallToolRequest{}, InnodbStatusInput{Sections: "log, transactions"})
+	if err != nil {
+		t.Fatalf("toolInnodbStatus failed: %v", err)
+	}
+	if out.Log == nil || out.Transactions == nil {
+		t.Errorf("expected log and transactions sections, got %+v", out)
+	}
+	if out.Deadlock != nil || out.BufferPool != nil || out.IO != nil || out.Semaphores != nil || out.RowOperations != nil {
+		t.Errorf("unexpected sections returned: %+v", out)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolInnodbStatusUnknownSection(t *testing.T) {
+	_, _, err := toolInnodbStatus(context.Background(), &mcp.CallToolRequest{}, InnodbStatusInput{Sections: "deadlock,adaptive_hash"})
+	if err == nil || err.Error() != "unknown section: adaptive_hash" {
+		t.Errorf("expected unknown section error, got %v", err)
+	}
+}
+
+// ===== toolReplicationStatus Tests =====
+
+func TestToolReplicationStatusLegacyReplica(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	source := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(errors.New("syntax error"))
+	mock.ExpectQuery("SHOW SLAVE STATUS").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"Slave_IO_Running", "Slave_SQL_Running", "Master_Host", "Master_Port", "Seconds_Behind_Master",
+			"Last_IO_Error", "Last_SQL_Error", "Slave_SQL_Running_State", "Retrieved_Gtid_Set", "Executed_Gtid_Set",
+			"Auto_Position", "Channel_Name",
+		}).AddRow("Yes", "No", "db-primary", 3306, nil,
+			"", "Coordinator stopped because there were error(s) in the worker(s).", "", source+":1-120",
+			source+":1-50:60-100,\n4f2b6a10-71ca-11e1-9e33-c80aa9429562:1-5", 1, ""))
+	mock.ExpectQuery("FROM performance_schema.replication_applier_status_by_worker").
+		WillReturnRows(sqlmock.NewRows([]string{"CHANNEL_NAME", "WORKER_ID", "LAST_ERROR_NUMBER", "LAST_ERROR_MESSAGE"}).
+			AddRow("", 2, 1062, "Duplicate entry '7' for key 'PRIMARY'"))
+	mock.ExpectQuery("SHOW REPLICAS").WillReturnError(errors.New("syntax error"))
+	mock.ExpectQuery("SHOW SLAVE HOSTS").
+		WillReturnRows(sqlmock.NewRows([]string{"Server_id", "Host", "Port", "Master_id", "Slave_UUID"}))
+	mock.ExpectQuery("FROM performance_schema.replication_group_members").
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE", "MEMBER_ROLE", "IS_SELF",
+			"COUNT_TRANSACTIONS_IN_QUEUE", "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE"}))
+
+	_, out, err := toolReplicationStatus(context.Background(), &mcp.CallToolRequest{}, ReplicationStatusInput{})
+	if err != nil {
+		t.Fatalf("toolReplicationStatus failed: %v", err)
+	}
+
+	if len(out.Servers) != 1 {
+		t.Fatalf("expected only the active connection, got %d servers", len(out.Servers))
+	}
+	srv := out.Servers[0]
+	if srv.Connection != "mock" || len(srv.Roles) != 1 || srv.Roles[0] != "replica" || srv.Error != "" {
+		t.Errorf("unexpected server status: %+v", srv)
+	}
+	if len(srv.Channels) != 1 {
+		t.Fatalf("expected 1 channel, got %d", len(srv.Channels))
+	}
+	ch := srv.Channels[0]
+	if ch.SourceHost != "db-primary" || ch.SourcePort != 3306 || ch.IORunning != "Yes" || ch.SQLRunning != "No" || !ch.AutoPosition {
+		t.Errorf("unexpected channel: %+v", ch)
+	}
+	if ch.LagSeconds != nil {
+		t.Errorf("expected unknown lag with the applier stopped, got %d", *ch.LagSeconds)
+	}
+	if ch.PendingGTIDs != source+":51-59:101-120" || ch.PendingGTIDCount != 29 || ch.GTIDGaps != source+":51-59" {
+		t.Errorf("unexpected GTID analysis: pending=%q (%d) gaps=%q", ch.PendingGTIDs, ch.PendingGTIDCount, ch.GTIDGaps)
+	}
+	if len(ch.WorkerErrors) != 1 || !strings.Contains(ch.WorkerErrors[0], "1062") {
+		t.Errorf("unexpected worker errors: %v", ch.WorkerErrors)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolReplicationStatusAllConnections(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	otherDB, other, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer otherDB.Close()
+	connManager.connections["broken"] = otherDB
+
+	// "broken" sorts first and cannot be inspected.
+	other.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(errors.New("access denied"))
+	other.ExpectQuery("SHOW SLAVE STATUS").WillReturnError(errors.New("access denied"))
+
+	// "mock" is the primary of a three-member group.
+	mock.ExpectQuery("SHOW REPLICA STATUS").
+		WillReturnRows(sqlmock.NewRows([]string{"Replica_IO_Running", "Replica_SQL_Running", "Source_Host", "Channel_Name"}))
+	mock.ExpectQuery("SHOW REPLICAS").
+		WillReturnRows(sqlmock.NewRows([]string{"Server_Id", "Host", "Port", "Source_Id", "Replica_UUID"}).
+			AddRow(5, "report-replica", 3306, 1, "uuid-5"))
+	mock.ExpectQuery("FROM performance_schema.replication_group_members").
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE", "MEMBER_ROLE", "IS_SELF",
+			"COUNT_TRANSACTIONS_IN_QUEUE", "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE"}).
+			AddRow("uuid-1", "node1", 3306, "ONLINE", "PRIMARY", 1, 0, 0).
+			AddRow("uuid-2", "node2", 3306, "ONLINE", "SECONDARY", 0, 0, 12).
+			AddRow("uuid-3", "node3", 3306, "RECOVERING", "SECONDARY", 0, 3, 400))
+
+	_, out, err := toolReplicationStatus(context.Background(), &mcp.CallToolRequest{}, ReplicationStatusInput{AllConnections: true})
+	if err != nil {
+		t.Fatalf("toolReplicationStatus failed: %v", err)
+	}
+
+	if len(out.Servers) != 2 || out.Servers[0].Connection != "broken" || out.Servers[1].Connection != "mock" {
+		t.Fatalf("unexpected servers: %+v", out.Servers)
+	}
+	if out.Servers[0].Error == "" {
+		t.Error("expected an error for the broken connection")
+	}
+	primary := out.Servers[1]
+	if len(primary.Roles) != 2 || primary.Roles[0] != "group_primary" || primary.Roles[1] != "source" {
+		t.Errorf("unexpected roles: %v", primary.Roles)
+	}
+	if len(primary.GroupMembers) != 3 || !primary.GroupMembers[0].Self || primary.GroupMembers[2].ApplierQueue != 400 {
+		t.Errorf("unexpected group members: %+v", primary.GroupMembers)
+	}
+	if len(primary.Replicas) != 1 || primary.Replicas[0] != "report-replica:3306 (server_id 5)" {
+		t.Errorf("unexpected replicas: %v", primary.Replicas)
+	}
+
+	for name, m := range map[string]sqlmock.Sqlmock{"mock": mock, "broken": other} {
+		if err := m.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: unfulfilled expectations: %v", name, err)
+		}
+	}
+}
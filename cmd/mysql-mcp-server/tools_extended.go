@@ -5,11 +5,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/askdba/mysql-mcp-server/internal/util"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/xwb1989/sqlparser"
 )
 
 // ===== Extended Tool Handlers (MYSQL_MCP_EXTENDED=1) =====
@@ -32,7 +36,7 @@ func toolListIndexes(
 		return nil, ListIndexesOutput{}, fmt.Errorf("invalid table name: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
 	defer cancel()
 
 	query := fmt.Sprintf("SHOW INDEX FROM %s.%s", dbName, tableName)
@@ -109,7 +113,7 @@ func toolShowCreateTable(
 		return nil, ShowCreateTableOutput{}, fmt.Errorf("invalid table name: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
 	defer cancel()
 
 	query := fmt.Sprintf("SHOW CREATE TABLE %s.%s", dbName, tableName)
@@ -126,54 +130,103 @@ func toolExplainQuery(
 	req *mcp.CallToolRequest,
 	input ExplainQueryInput,
 ) (*mcp.CallToolResult, ExplainQueryOutput, error) {
+	out, err := explainOn(ctx, getDB(), input)
+	if err != nil {
+		return nil, ExplainQueryOutput{}, err
+	}
+	return nil, out, nil
+}
+
+// explainOn runs EXPLAIN for input on db and normalizes the plan. It is
+// shared by explain_query and compare_plans.
+func explainOn(ctx context.Context, db *sql.DB, input ExplainQueryInput) (ExplainQueryOutput, error) {
 	sqlText := strings.TrimSpace(input.SQL)
 	if sqlText == "" {
-		return nil, ExplainQueryOutput{}, fmt.Errorf("sql is required")
+		return ExplainQueryOutput{}, fmt.Errorf("sql is required")
+	}
+
+	// Only allow explaining SELECT statements (including UNIONs). Statements
+	// the parser cannot read are rejected by the validator below.
+	if stmt, err := sqlparser.Parse(sqlText); err == nil {
+		switch stmt.(type) {
+		case *sqlparser.Select, *sqlparser.Union, *sqlparser.ParenSelect:
+		default:
+			return ExplainQueryOutput{}, fmt.Errorf("only SELECT statements can be explained")
+		}
+	}
+	if err := util.ValidateSQLCombined(sqlText); err != nil {
+		return ExplainQueryOutput{}, fmt.Errorf("query validation failed: %w", err)
 	}
 
-	// Only allow explaining SELECT statements
-	upper := strings.ToUpper(sqlText)
-	if !strings.HasPrefix(upper, "SELECT") {
-		return nil, ExplainQueryOutput{}, fmt.Errorf("only SELECT statements can be explained")
+	format := strings.ToLower(strings.TrimSpace(input.Format))
+	switch format {
+	case "":
+		format = "traditional"
+		if input.Analyze {
+			format = "tree"
+		}
+	case "traditional", "json", "tree":
+	default:
+		return ExplainQueryOutput{}, fmt.Errorf("unknown format: %s (use traditional, json or tree)", input.Format)
+	}
+	if input.Analyze && format != "tree" {
+		return ExplainQueryOutput{}, fmt.Errorf("analyze mode only supports the tree format")
 	}
 
+	queryTimeout := settingsFrom(ctx).queryTimeout
 	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
 
-	database := strings.TrimSpace(input.Database)
-	explainSQL := "EXPLAIN " + sqlText
-	var rows *sql.Rows
-	var err error
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return ExplainQueryOutput{}, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
 
-	if database != "" {
-		var dbName string
-		dbName, err = util.QuoteIdent(database)
-		if err != nil {
-			return nil, ExplainQueryOutput{}, fmt.Errorf("invalid database name: %w", err)
+	explainSQL := "EXPLAIN " + sqlText
+	switch {
+	case input.Analyze:
+		var version, comment string
+		if err := conn.QueryRowContext(ctx, "SELECT VERSION(), @@version_comment").Scan(&version, &comment); err != nil {
+			return ExplainQueryOutput{}, fmt.Errorf("failed to get version: %w", err)
 		}
-		var conn *sql.Conn
-		conn, err = getDB().Conn(ctx)
-		if err != nil {
-			return nil, ExplainQueryOutput{}, fmt.Errorf("failed to get connection: %w", err)
+		if isMariaDB(version, comment) {
+			return ExplainQueryOutput{}, fmt.Errorf("analyze mode is not supported on MariaDB (server is %s); run ANALYZE FORMAT=JSON through run_query instead", version)
+		}
+		if !versionAtLeast(version, 8, 0, 18) {
+			return ExplainQueryOutput{}, fmt.Errorf("EXPLAIN ANALYZE requires MySQL 8.0.18 or later (server is %s)", version)
+		}
+		// EXPLAIN ANALYZE executes the query. The context timeout only drops
+		// the client connection, so the server is given the same limit.
+		limit := fmt.Sprintf("SET SESSION max_execution_time = %d", queryTimeout.Milliseconds())
+		if _, err := conn.ExecContext(ctx, limit); err != nil {
+			return ExplainQueryOutput{}, fmt.Errorf("failed to set max_execution_time: %w", err)
 		}
-		defer conn.Close()
+		defer conn.ExecContext(context.Background(), "SET SESSION max_execution_time = DEFAULT")
+		explainSQL = "EXPLAIN ANALYZE " + sqlText
+	case format == "json":
+		explainSQL = "EXPLAIN FORMAT=JSON " + sqlText
+	case format == "tree":
+		explainSQL = "EXPLAIN FORMAT=TREE " + sqlText
+	}
 
-		_, err = conn.ExecContext(ctx, "USE "+dbName)
+	if database := strings.TrimSpace(input.Database); database != "" {
+		dbName, err := util.QuoteIdent(database)
 		if err != nil {
-			return nil, ExplainQueryOutput{}, fmt.Errorf("failed to switch database: %w", err)
+			return ExplainQueryOutput{}, fmt.Errorf("invalid database name: %w", err)
+		}
+		if _, err := conn.ExecContext(ctx, "USE "+dbName); err != nil {
+			return ExplainQueryOutput{}, fmt.Errorf("failed to switch database: %w", err)
 		}
-		rows, err = conn.QueryContext(ctx, explainSQL)
-	} else {
-		rows, err = getDB().QueryContext(ctx, explainSQL)
 	}
-
+	rows, err := conn.QueryContext(ctx, explainSQL)
 	if err != nil {
-		return nil, ExplainQueryOutput{}, fmt.Errorf("EXPLAIN failed: %w", err)
+		return ExplainQueryOutput{}, fmt.Errorf("EXPLAIN failed: %w", err)
 	}
 	defer rows.Close()
 
 	cols, _ := rows.Columns()
-	out := ExplainQueryOutput{Plan: []map[string]interface{}{}}
+	out := ExplainQueryOutput{Plan: []map[string]interface{}{}, Format: format, Analyzed: input.Analyze}
 
 	for rows.Next() {
 		values := make([]interface{}, len(cols))
@@ -190,20 +243,110 @@ func toolExplainQuery(
 		}
 		out.Plan = append(out.Plan, row)
 	}
+	if err := rows.Err(); err != nil {
+		return ExplainQueryOutput{}, fmt.Errorf("EXPLAIN failed: %w", err)
+	}
+
+	tree, err := normalizePlan(format, out.Plan)
+	if err != nil {
+		out.Warnings = []string{"could not normalize plan: " + err.Error()}
+		return out, nil
+	}
+	out.Tree = tree
+	out.Warnings = planWarnings(tree)
+
+	return out, nil
+}
 
+func toolComparePlans(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ComparePlansInput,
+) (*mcp.CallToolResult, ComparePlansOutput, error) {
+	left := ExplainQueryInput{SQL: strings.TrimSpace(input.SQL), Database: strings.TrimSpace(input.Database), Format: "json"}
+	if left.SQL == "" {
+		return nil, ComparePlansOutput{}, fmt.Errorf("sql is required")
+	}
+	right := left
+	if s := strings.TrimSpace(input.RightSQL); s != "" {
+		right.SQL = s
+	}
+	if d := strings.TrimSpace(input.RightDatabase); d != "" {
+		right.Database = d
+	}
+	leftConn := strings.TrimSpace(input.Connection)
+	rightConn := strings.TrimSpace(input.RightConnection)
+	if rightConn == "" {
+		rightConn = leftConn
+	}
+	if right == left && rightConn == leftConn {
+		return nil, ComparePlansOutput{}, fmt.Errorf("nothing to compare: set right_sql, right_database or right_connection")
+	}
+
+	leftName, err := planConnection(leftConn)
+	if err != nil {
+		return nil, ComparePlansOutput{}, fmt.Errorf("left plan: %w", err)
+	}
+	rightName, err := planConnection(rightConn)
+	if err != nil {
+		return nil, ComparePlansOutput{}, fmt.Errorf("right plan: %w", err)
+	}
+	leftPlan, err := planOn(ctx, leftName, left)
+	if err != nil {
+		return nil, ComparePlansOutput{}, fmt.Errorf("left plan: %w", err)
+	}
+	rightPlan, err := planOn(ctx, rightName, right)
+	if err != nil {
+		return nil, ComparePlansOutput{}, fmt.Errorf("right plan: %w", err)
+	}
+
+	out := comparePlanSummaries(
+		summarizePlan(leftName, left.Database, leftPlan),
+		summarizePlan(rightName, right.Database, rightPlan),
+	)
+	if leftName != rightName {
+		out.Notes = append(out.Notes, "plans come from different servers; costs depend on each server's statistics and optimizer version")
+	}
 	return nil, out, nil
 }
 
+// planConnection returns the name of the named connection, or of the
+// active one when name is empty.
+func planConnection(name string) (string, error) {
+	if connManager == nil {
+		return "", fmt.Errorf("connection manager not initialized")
+	}
+	if name == "" {
+		_, active := connManager.GetActive()
+		return active, nil
+	}
+	_, err := connManager.Get(name)
+	return name, err
+}
+
+// planOn explains input on the named connection. The result counts
+// towards the circuit breaker of that connection only.
+func planOn(ctx context.Context, name string, input ExplainQueryInput) (ExplainQueryOutput, error) {
+	var plan ExplainQueryOutput
+	err := withConnection(ctx, name, func(db *sql.DB) error {
+		var err error
+		plan, err = explainOn(ctx, db, input)
+		return err
+	})
+	return plan, err
+}
+
 func toolListViews(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
 	input ListViewsInput,
 ) (*mcp.CallToolResult, ListViewsOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, ListViewsOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT TABLE_NAME, DEFINER, SECURITY_TYPE, IS_UPDATABLE 
@@ -221,7 +364,7 @@ func toolListViews(
 			continue
 		}
 		out.Views = append(out.Views, v)
-		if len(out.Views) >= maxRows {
+		if len(out.Views) >= conf.maxRows {
 			break
 		}
 	}
@@ -237,11 +380,12 @@ func toolListTriggers(
 	req *mcp.CallToolRequest,
 	input ListTriggersInput,
 ) (*mcp.CallToolResult, ListTriggersOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, ListTriggersOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT TRIGGER_NAME, EVENT_MANIPULATION, EVENT_OBJECT_TABLE, ACTION_TIMING, 
@@ -259,7 +403,7 @@ func toolListTriggers(
 			continue
 		}
 		out.Triggers = append(out.Triggers, t)
-		if len(out.Triggers) >= maxRows {
+		if len(out.Triggers) >= conf.maxRows {
 			break
 		}
 	}
@@ -275,11 +419,12 @@ func toolListProcedures(
 	req *mcp.CallToolRequest,
 	input ListProceduresInput,
 ) (*mcp.CallToolResult, ListProceduresOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, ListProceduresOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT ROUTINE_NAME, DEFINER, CREATED, LAST_ALTERED, 
@@ -298,7 +443,7 @@ func toolListProcedures(
 			continue
 		}
 		out.Procedures = append(out.Procedures, p)
-		if len(out.Procedures) >= maxRows {
+		if len(out.Procedures) >= conf.maxRows {
 			break
 		}
 	}
@@ -314,11 +459,12 @@ func toolListFunctions(
 	req *mcp.CallToolRequest,
 	input ListFunctionsInput,
 ) (*mcp.CallToolResult, ListFunctionsOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, ListFunctionsOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT ROUTINE_NAME, DEFINER, DTD_IDENTIFIER, CREATED 
@@ -337,7 +483,7 @@ func toolListFunctions(
 			continue
 		}
 		out.Functions = append(out.Functions, f)
-		if len(out.Functions) >= maxRows {
+		if len(out.Functions) >= conf.maxRows {
 			break
 		}
 	}
@@ -353,11 +499,12 @@ func toolListPartitions(
 	req *mcp.CallToolRequest,
 	input ListPartitionsInput,
 ) (*mcp.CallToolResult, ListPartitionsOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" || input.Table == "" {
 		return nil, ListPartitionsOutput{}, fmt.Errorf("database and table are required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT PARTITION_NAME, PARTITION_METHOD, PARTITION_EXPRESSION, 
@@ -382,7 +529,7 @@ func toolListPartitions(
 		p.Expression = expr.String
 		p.Description = desc.String
 		out.Partitions = append(out.Partitions, p)
-		if len(out.Partitions) >= maxRows {
+		if len(out.Partitions) >= conf.maxRows {
 			break
 		}
 	}
@@ -398,7 +545,8 @@ func toolDatabaseSize(
 	req *mcp.CallToolRequest,
 	input DatabaseSizeInput,
 ) (*mcp.CallToolResult, DatabaseSizeOutput, error) {
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	conf := settingsFrom(ctx)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT 
@@ -435,7 +583,7 @@ func toolDatabaseSize(
 			continue
 		}
 		out.Databases = append(out.Databases, d)
-		if len(out.Databases) >= maxRows {
+		if len(out.Databases) >= conf.maxRows {
 			break
 		}
 	}
@@ -451,11 +599,12 @@ func toolTableSize(
 	req *mcp.CallToolRequest,
 	input TableSizeInput,
 ) (*mcp.CallToolResult, TableSizeOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, TableSizeOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT 
@@ -496,7 +645,7 @@ func toolTableSize(
 		t.TotalMB = totalMB.Float64
 		t.Engine = engine.String
 		out.Tables = append(out.Tables, t)
-		if len(out.Tables) >= maxRows {
+		if len(out.Tables) >= conf.maxRows {
 			break
 		}
 	}
@@ -512,11 +661,12 @@ func toolForeignKeys(
 	req *mcp.CallToolRequest,
 	input ForeignKeysInput,
 ) (*mcp.CallToolResult, ForeignKeysOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, ForeignKeysOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := `SELECT 
@@ -553,7 +703,7 @@ func toolForeignKeys(
 		fk.OnUpdate = onUpdate.String
 		fk.OnDelete = onDelete.String
 		out.ForeignKeys = append(out.ForeignKeys, fk)
-		if len(out.ForeignKeys) >= maxRows {
+		if len(out.ForeignKeys) >= conf.maxRows {
 			break
 		}
 	}
@@ -569,7 +719,8 @@ func toolListStatus(
 	req *mcp.CallToolRequest,
 	input ListStatusInput,
 ) (*mcp.CallToolResult, ListStatusOutput, error) {
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	conf := settingsFrom(ctx)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := "SHOW GLOBAL STATUS"
@@ -596,7 +747,7 @@ func toolListStatus(
 			continue
 		}
 		out.Variables = append(out.Variables, v)
-		if len(out.Variables) >= maxRows {
+		if len(out.Variables) >= conf.maxRows {
 			break
 		}
 	}
@@ -612,7 +763,8 @@ func toolListVariables(
 	req *mcp.CallToolRequest,
 	input ListVariablesInput,
 ) (*mcp.CallToolResult, ListVariablesOutput, error) {
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	conf := settingsFrom(ctx)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	query := "SHOW GLOBAL VARIABLES"
@@ -639,7 +791,7 @@ func toolListVariables(
 			continue
 		}
 		out.Variables = append(out.Variables, v)
-		if len(out.Variables) >= maxRows {
+		if len(out.Variables) >= conf.maxRows {
 			break
 		}
 	}
@@ -652,16 +804,43 @@ func toolListVariables(
 
 // ===== Vector Tool Handlers (MySQL 9.0+) =====
 
+// Limits for batch vector searches.
+const (
+	maxVectorBatchQueries  = 32
+	vectorBatchConcurrency = 4 // searches of one call run at the same time
+)
+
 func toolVectorSearch(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
 	input VectorSearchInput,
 ) (*mcp.CallToolResult, VectorSearchOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" || input.Table == "" || input.Column == "" {
 		return nil, VectorSearchOutput{}, fmt.Errorf("database, table, and column are required")
 	}
-	if len(input.Query) == 0 {
-		return nil, VectorSearchOutput{}, fmt.Errorf("query vector is required")
+	given := 0
+	for _, set := range []bool{len(input.Query) > 0, input.QueryText != "", len(input.Queries) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given == 0 {
+		return nil, VectorSearchOutput{}, fmt.Errorf("query vector or query_text is required")
+	}
+	if given > 1 {
+		return nil, VectorSearchOutput{}, fmt.Errorf("set only one of query, query_text or queries")
+	}
+	if len(input.Queries) > maxVectorBatchQueries {
+		return nil, VectorSearchOutput{}, fmt.Errorf("at most %d queries are allowed per call", maxVectorBatchQueries)
+	}
+	for i, q := range input.Queries {
+		if len(q) == 0 {
+			return nil, VectorSearchOutput{}, fmt.Errorf("queries[%d] is empty", i)
+		}
+	}
+	if input.Merge && len(input.Queries) == 0 {
+		return nil, VectorSearchOutput{}, fmt.Errorf("merge requires queries")
 	}
 
 	dbName, err := util.QuoteIdent(input.Database)
@@ -677,7 +856,16 @@ func toolVectorSearch(
 		return nil, VectorSearchOutput{}, fmt.Errorf("invalid column name: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	out := VectorSearchOutput{Results: []VectorSearchResult{}}
+	queryVec := input.Query
+	if input.QueryText != "" {
+		queryVec, out.EmbeddingModel, out.EmbeddingCached, err = embedQueryText(ctx, input.Database, input.Table, input.Column, input.QueryText)
+		if err != nil {
+			return nil, VectorSearchOutput{}, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	// Set default limit, cap to maxRows for safety
@@ -685,20 +873,8 @@ func toolVectorSearch(
 	if limit <= 0 {
 		limit = 10
 	}
-	if limit > maxRows {
-		limit = maxRows
-	}
-
-	// Build vector string for MySQL
-	vectorStr := buildVectorString(input.Query)
-
-	// Determine distance function
-	distFunc := "COSINE"
-	switch strings.ToLower(input.DistanceFunc) {
-	case "euclidean", "l2":
-		distFunc = "EUCLIDEAN"
-	case "dot", "inner_product":
-		distFunc = "DOT"
+	if limit > conf.maxRows {
+		limit = conf.maxRows
 	}
 
 	// Build SELECT columns with validation
@@ -711,39 +887,216 @@ func toolVectorSearch(
 		selectCols = validatedCols
 	}
 
-	// Build query with vector distance
-	query := fmt.Sprintf(`
-		SELECT %s, 
-			DISTANCE(%s, STRING_TO_VECTOR('%s'), '%s') AS _distance
-		FROM %s.%s
-	`, selectCols, colName, vectorStr, distFunc, dbName, tableName)
+	where, args, err := searchCondition(input.Where, input.Filter, conf.disableRawWhere)
+	if err != nil {
+		return nil, VectorSearchOutput{}, err
+	}
 
-	// Validate WHERE clause if provided
-	if input.Where != "" {
-		if err := util.ValidateWhereClause(input.Where); err != nil {
-			return nil, VectorSearchOutput{}, fmt.Errorf("invalid where clause: %w", err)
+	db := getDB()
+	distFunc := vectorDistanceFunc(input.DistanceFunc)
+
+	if len(input.Queries) == 0 {
+		query := vectorSearchQuery(selectCols, dbName, tableName, colName, queryVec, distFunc, where, limit)
+		scored, err := runVectorSearch(ctx, db, query, args, nil, input.Database, input.Table)
+		if err != nil {
+			return nil, VectorSearchOutput{}, err
 		}
-		query += " WHERE " + input.Where
+		for _, r := range scored {
+			out.Results = append(out.Results, VectorSearchResult{Distance: r.score, Data: r.data})
+		}
+		out.Count = len(out.Results)
+		return nil, out, nil
 	}
 
-	query += fmt.Sprintf(" ORDER BY _distance ASC LIMIT %d", limit)
+	// Merging needs the row key to recognize the same row across queries.
+	var keyAliases []string
+	if input.Merge {
+		keyCols, err := hybridKeyColumns(ctx, db, input.Database, input.Table, input.KeyColumns)
+		if err != nil {
+			return nil, VectorSearchOutput{}, err
+		}
+		for i, k := range keyCols {
+			keyAliases = append(keyAliases, fmt.Sprintf("_key%d", i))
+			selectCols += fmt.Sprintf(", %s AS %s", k, keyAliases[i])
+		}
+	}
 
-	rows, err := getDB().QueryContext(ctx, query)
-	if err != nil {
-		if strings.Contains(err.Error(), "DISTANCE") || strings.Contains(err.Error(), "STRING_TO_VECTOR") {
-			return nil, VectorSearchOutput{}, fmt.Errorf("vector search failed (MySQL 9.0+ required): %w", err)
+	batches := make([][]scoredRow, len(input.Queries))
+	errs := make([]error, len(input.Queries))
+	sem := make(chan struct{}, vectorBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, vec := range input.Queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			query := vectorSearchQuery(selectCols, dbName, tableName, colName, vec, distFunc, where, limit)
+			batches[i], errs[i] = runVectorSearch(ctx, db, query, args, keyAliases, input.Database, input.Table)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, VectorSearchOutput{}, fmt.Errorf("queries[%d]: %w", i, err)
 		}
-		return nil, VectorSearchOutput{}, fmt.Errorf("vector search failed: %w", err)
+	}
+
+	if input.Merge {
+		out.Results = mergeVectorBatches(batches, limit)
+		out.Count = len(out.Results)
+		return nil, out, nil
+	}
+	out.Batches = make([]VectorSearchBatch, len(batches))
+	for i, rows := range batches {
+		batch := VectorSearchBatch{Query: i, Results: []VectorSearchResult{}}
+		for _, r := range rows {
+			batch.Results = append(batch.Results, VectorSearchResult{Distance: r.score, Data: r.data})
+		}
+		batch.Count = len(batch.Results)
+		out.Count += batch.Count
+		out.Batches[i] = batch
+	}
+	return nil, out, nil
+}
+
+// runVectorSearch runs a query built by vectorSearchQuery and reads its rows.
+func runVectorSearch(ctx context.Context, db *sql.DB, query string, args []interface{}, keyCols []string, database, table string) ([]scoredRow, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, vectorSearchError(err)
 	}
 	defer rows.Close()
+	return scanScoredRows(rows, "_distance", keyCols, settingsFrom(ctx).columnMasker, database, table)
+}
 
-	cols, err := rows.Columns()
+// mergeVectorBatches dedupes the results of several queries by row key,
+// keeping each row's smallest distance, and returns the nearest limit rows.
+func mergeVectorBatches(batches [][]scoredRow, limit int) []VectorSearchResult {
+	byKey := make(map[string]*VectorSearchResult)
+	var order []string
+	for i, rows := range batches {
+		for _, r := range rows {
+			res, ok := byKey[r.key]
+			if !ok {
+				res = &VectorSearchResult{Distance: r.score, Data: r.data}
+				byKey[r.key] = res
+				order = append(order, r.key)
+			} else if r.score < res.Distance {
+				res.Distance = r.score
+			}
+			if n := len(res.Queries); n == 0 || res.Queries[n-1] != i {
+				res.Queries = append(res.Queries, i)
+			}
+		}
+	}
+	results := make([]VectorSearchResult, 0, len(order))
+	for _, k := range order {
+		results = append(results, *byKey[k])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// embedQueryText embeds text with the model configured for a vector column.
+func embedQueryText(ctx context.Context, database, table, column, text string) ([]float64, string, bool, error) {
+	conf := settingsFrom(ctx)
+	if conf.embedder == nil {
+		return nil, "", false, fmt.Errorf("query_text requires an embedding provider (set MYSQL_MCP_EMBEDDING_URL)")
+	}
+	vec, model, cached, err := conf.embedder.Embed(ctx, database, table, column, text)
 	if err != nil {
-		return nil, VectorSearchOutput{}, fmt.Errorf("failed to get columns: %w", err)
+		return nil, "", false, fmt.Errorf("failed to embed query_text: %w", err)
 	}
+	return vec, model, cached, nil
+}
 
-	out := VectorSearchOutput{Results: []VectorSearchResult{}}
+// searchCondition combines the raw where clause and the structured filter of
+// a search input into one condition and its arguments. The where clause is
+// checked with ValidateWhereClause and rejected when raw where clauses are
+// disabled by config.
+func searchCondition(where string, filter interface{}, disableRawWhere bool) (string, []interface{}, error) {
+	if where != "" {
+		if disableRawWhere {
+			return "", nil, fmt.Errorf("raw where clauses are disabled on this server (MYSQL_MCP_DISABLE_RAW_WHERE); use filter")
+		}
+		if err := util.ValidateWhereClause(where); err != nil {
+			return "", nil, fmt.Errorf("invalid where clause: %w", err)
+		}
+	}
+	f, err := util.ParseFilter(filter)
+	if err != nil || f == nil {
+		return where, nil, err
+	}
+	cond, args, err := f.Compile()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if where != "" {
+		cond = "(" + where + ") AND " + cond
+	}
+	return cond, args, nil
+}
 
+// vectorDistanceFunc maps a distance_func input to a DISTANCE() metric.
+func vectorDistanceFunc(name string) string {
+	switch strings.ToLower(name) {
+	case "euclidean", "l2":
+		return "EUCLIDEAN"
+	case "dot", "inner_product":
+		return "DOT"
+	}
+	return "COSINE"
+}
+
+// vectorSearchQuery builds a query returning selectCols and the distance of
+// column to vec as _distance, nearest first. Identifiers must be quoted and
+// selectCols and where validated by the caller.
+func vectorSearchQuery(selectCols, dbName, tableName, colName string, vec []float64, distFunc, where string, limit int) string {
+	query := fmt.Sprintf(`
+		SELECT %s, 
+			DISTANCE(%s, STRING_TO_VECTOR('%s'), '%s') AS _distance
+		FROM %s.%s
+	`, selectCols, colName, buildVectorString(vec), distFunc, dbName, tableName)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return query + fmt.Sprintf(" ORDER BY _distance ASC LIMIT %d", limit)
+}
+
+func vectorSearchError(err error) error {
+	if strings.Contains(err.Error(), "DISTANCE") || strings.Contains(err.Error(), "STRING_TO_VECTOR") {
+		return fmt.Errorf("vector search failed (MySQL 9.0+ required): %w", err)
+	}
+	return fmt.Errorf("vector search failed: %w", err)
+}
+
+// scoredRow is one row of a ranked candidate query.
+type scoredRow struct {
+	score float64
+	key   string // values of the key columns, when requested
+	data  map[string]interface{}
+}
+
+// scanScoredRows reads rows carrying a score column (e.g. _distance),
+// applying the masking rules of m for database.table. Columns listed in
+// keyCols are left out of the data and joined into the row key instead.
+func scanScoredRows(rows *sql.Rows, scoreCol string, keyCols []string, m *util.ColumnMasker, database, table string) ([]scoredRow, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	isKey := make(map[string]bool, len(keyCols))
+	for _, k := range keyCols {
+		isKey[k] = true
+	}
+
+	var results []scoredRow
 	for rows.Next() {
 		values := make([]interface{}, len(cols))
 		ptrs := make([]interface{}, len(cols))
@@ -755,37 +1108,333 @@ func toolVectorSearch(
 			continue
 		}
 
-		result := VectorSearchResult{
-			Data: make(map[string]interface{}),
-		}
-
+		result := scoredRow{data: make(map[string]interface{})}
+		var key []string
 		for i, col := range cols {
-			if col == "_distance" {
-				if dist, ok := values[i].(float64); ok {
-					result.Distance = dist
+			switch {
+			case col == scoreCol:
+				if score, ok := toFloat(util.NormalizeValue(values[i])); ok {
+					result.score = score
 				}
-			} else {
-				result.Data[col] = util.NormalizeValue(values[i])
+			case isKey[col]:
+				key = append(key, fmt.Sprint(util.NormalizeValue(values[i])))
+			case values[i] != nil && m.ShouldMask(database, table, col):
+				result.data[col] = util.MaskedValue
+			default:
+				result.data[col] = util.NormalizeValue(values[i])
 			}
 		}
+		result.key = strings.Join(key, "\x00")
 
-		out.Results = append(out.Results, result)
+		results = append(results, result)
 	}
+	return results, rows.Err()
+}
 
+// Hybrid search defaults.
+const (
+	defaultHybridRRFK         = 60
+	defaultHybridVectorWeight = 0.5
+	hybridCandidateFactor     = 4 // candidates per arm, relative to limit
+	minHybridCandidates       = 20
+)
+
+func toolHybridSearch(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input HybridSearchInput,
+) (*mcp.CallToolResult, HybridSearchOutput, error) {
+	conf := settingsFrom(ctx)
+	if input.Database == "" || input.Table == "" || input.Column == "" || input.TextColumns == "" {
+		return nil, HybridSearchOutput{}, fmt.Errorf("database, table, column, and text_columns are required")
+	}
+	if strings.TrimSpace(input.QueryText) == "" {
+		return nil, HybridSearchOutput{}, fmt.Errorf("query_text is required")
+	}
+
+	dbName, err := util.QuoteIdent(input.Database)
+	if err != nil {
+		return nil, HybridSearchOutput{}, fmt.Errorf("invalid database name: %w", err)
+	}
+	tableName, err := util.QuoteIdent(input.Table)
+	if err != nil {
+		return nil, HybridSearchOutput{}, fmt.Errorf("invalid table name: %w", err)
+	}
+	colName, err := util.QuoteIdent(input.Column)
+	if err != nil {
+		return nil, HybridSearchOutput{}, fmt.Errorf("invalid column name: %w", err)
+	}
+	var textCols []string
+	for _, c := range strings.Split(input.TextColumns, ",") {
+		quoted, err := util.QuoteIdent(strings.TrimSpace(c))
+		if err != nil {
+			return nil, HybridSearchOutput{}, fmt.Errorf("invalid text column %q: %w", c, err)
+		}
+		textCols = append(textCols, quoted)
+	}
+
+	fusion := strings.ToLower(input.Fusion)
+	if fusion == "" {
+		fusion = "rrf"
+	}
+	if fusion != "rrf" && fusion != "weighted" {
+		return nil, HybridSearchOutput{}, fmt.Errorf("fusion must be rrf or weighted")
+	}
+	weight := defaultHybridVectorWeight
+	if input.VectorWeight != nil {
+		weight = *input.VectorWeight
+		if weight < 0 || weight > 1 {
+			return nil, HybridSearchOutput{}, fmt.Errorf("vector_weight must be between 0 and 1")
+		}
+	}
+	rrfK := input.RRFK
+	if rrfK < 0 {
+		return nil, HybridSearchOutput{}, fmt.Errorf("rrf_k must be positive")
+	}
+	if rrfK == 0 {
+		rrfK = defaultHybridRRFK
+	}
+	var matchMode string
+	switch strings.ToLower(input.TextMode) {
+	case "", "natural":
+		matchMode = "IN NATURAL LANGUAGE MODE"
+	case "boolean":
+		matchMode = "IN BOOLEAN MODE"
+	default:
+		return nil, HybridSearchOutput{}, fmt.Errorf("text_mode must be natural or boolean")
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > conf.maxRows {
+		limit = conf.maxRows
+	}
+	candidates := input.Candidates
+	if candidates <= 0 {
+		candidates = max(limit*hybridCandidateFactor, minHybridCandidates)
+	}
+	candidates = min(max(candidates, limit), conf.maxRows)
+
+	selectCols := "*"
+	if input.Select != "" {
+		validatedCols, err := util.ValidateSelectColumns(input.Select)
+		if err != nil {
+			return nil, HybridSearchOutput{}, fmt.Errorf("invalid select columns: %w", err)
+		}
+		selectCols = validatedCols
+	}
+	where, whereArgs, err := searchCondition(input.Where, input.Filter, conf.disableRawWhere)
+	if err != nil {
+		return nil, HybridSearchOutput{}, err
+	}
+
+	out := HybridSearchOutput{Results: []HybridSearchResult{}, Fusion: fusion}
+	queryVec := input.Query
+	if len(queryVec) == 0 {
+		queryVec, out.EmbeddingModel, out.EmbeddingCached, err = embedQueryText(ctx, input.Database, input.Table, input.Column, input.QueryText)
+		if err != nil {
+			return nil, HybridSearchOutput{}, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
+	defer cancel()
+	db := getDB()
+
+	keyCols, err := hybridKeyColumns(ctx, db, input.Database, input.Table, input.KeyColumns)
+	if err != nil {
+		return nil, HybridSearchOutput{}, err
+	}
+	// Both arms return the key columns under fixed aliases so rows can be
+	// matched across the two candidate lists.
+	keyAliases := make([]string, len(keyCols))
+	for i, k := range keyCols {
+		keyAliases[i] = fmt.Sprintf("_key%d", i)
+		selectCols += fmt.Sprintf(", %s AS %s", k, keyAliases[i])
+	}
+
+	query := vectorSearchQuery(selectCols, dbName, tableName, colName, queryVec,
+		vectorDistanceFunc(input.DistanceFunc), where, candidates)
+	rows, err := db.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		return nil, HybridSearchOutput{}, vectorSearchError(err)
+	}
+	vectorRows, err := scanScoredRows(rows, "_distance", keyAliases, conf.columnMasker, input.Database, input.Table)
+	rows.Close()
+	if err != nil {
+		return nil, HybridSearchOutput{}, err
+	}
+
+	match := fmt.Sprintf("MATCH(%s) AGAINST(? %s)", strings.Join(textCols, ", "), matchMode)
+	query = fmt.Sprintf("SELECT %s, %s AS _score FROM %s.%s WHERE %s", selectCols, match, dbName, tableName, match)
+	if where != "" {
+		query += " AND (" + where + ")"
+	}
+	query += fmt.Sprintf(" ORDER BY _score DESC LIMIT %d", candidates)
+	rows, err = db.QueryContext(ctx, query, append([]interface{}{input.QueryText, input.QueryText}, whereArgs...)...)
+	if err != nil {
+		return nil, HybridSearchOutput{}, fmt.Errorf("full-text search failed (a FULLTEXT index on text_columns is required): %w", err)
+	}
+	textRows, err := scanScoredRows(rows, "_score", keyAliases, conf.columnMasker, input.Database, input.Table)
+	rows.Close()
+	if err != nil {
+		return nil, HybridSearchOutput{}, err
+	}
+
+	out.VectorCandidates = len(vectorRows)
+	out.TextCandidates = len(textRows)
+	out.Results = fuseHybridResults(vectorRows, textRows, fusion, weight, rrfK)
+	if len(out.Results) > limit {
+		out.Results = out.Results[:limit]
+	}
 	out.Count = len(out.Results)
 	return nil, out, nil
 }
 
+// hybridKeyColumns returns the quoted columns identifying a row: the given
+// comma-separated list, or the table's primary key.
+func hybridKeyColumns(ctx context.Context, db *sql.DB, database, table, keyColumns string) ([]string, error) {
+	var names []string
+	if keyColumns != "" {
+		names = strings.Split(keyColumns, ",")
+	} else {
+		rows, err := db.QueryContext(ctx, `
+			SELECT COLUMN_NAME
+			FROM information_schema.STATISTICS
+			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY'
+			ORDER BY SEQ_IN_INDEX
+		`, database, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read primary key: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, fmt.Errorf("failed to read primary key: %w", err)
+			}
+			names = append(names, name)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read primary key: %w", err)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("table %s.%s has no primary key; set key_columns", database, table)
+		}
+	}
+
+	keyCols := make([]string, len(names))
+	for i, name := range names {
+		quoted, err := util.QuoteIdent(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key column %q: %w", name, err)
+		}
+		keyCols[i] = quoted
+	}
+	return keyCols, nil
+}
+
+// fuseHybridResults merges the vector and full-text candidate lists, both
+// ordered best first, into one list ordered by fused score.
+//
+// With rrf fusion a row scores weight/(k+rank) in the vector list plus
+// (1-weight)/(k+rank) in the text list. With weighted fusion distances are
+// min-max scaled to a 0..1 similarity and text scores divided by the best
+// text score, then combined as weight*vector + (1-weight)*text.
+func fuseHybridResults(vectorRows, textRows []scoredRow, fusion string, weight float64, rrfK int) []HybridSearchResult {
+	byKey := make(map[string]*HybridSearchResult)
+	var order []string
+	entry := func(r scoredRow) *HybridSearchResult {
+		e, ok := byKey[r.key]
+		if !ok {
+			e = &HybridSearchResult{Data: r.data}
+			byKey[r.key] = e
+			order = append(order, r.key)
+		}
+		return e
+	}
+
+	minDist, maxDist := math.Inf(1), math.Inf(-1)
+	for _, r := range vectorRows {
+		minDist = math.Min(minDist, r.score)
+		maxDist = math.Max(maxDist, r.score)
+	}
+	maxText := 0.0
+	for _, r := range textRows {
+		maxText = math.Max(maxText, r.score)
+	}
+
+	for i, r := range vectorRows {
+		e := entry(r)
+		if e.VectorRank != 0 {
+			continue // duplicate key; keep the better rank
+		}
+		dist := r.score
+		e.VectorDistance = &dist
+		e.VectorRank = i + 1
+		if fusion == "rrf" {
+			e.Score += weight / float64(rrfK+e.VectorRank)
+		} else if maxDist > minDist {
+			e.Score += weight * (maxDist - dist) / (maxDist - minDist)
+		} else {
+			e.Score += weight
+		}
+	}
+	for i, r := range textRows {
+		e := entry(r)
+		if e.TextRank != 0 {
+			continue
+		}
+		score := r.score
+		e.TextScore = &score
+		e.TextRank = i + 1
+		if fusion == "rrf" {
+			e.Score += (1 - weight) / float64(rrfK+e.TextRank)
+		} else if maxText > 0 {
+			e.Score += (1 - weight) * score / maxText
+		}
+	}
+
+	results := make([]HybridSearchResult, 0, len(order))
+	for _, k := range order {
+		e := byKey[k]
+		e.Score = math.Round(e.Score*1e6) / 1e6
+		results = append(results, *e)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return bestRank(a) < bestRank(b)
+	})
+	return results
+}
+
+// bestRank returns the better of a result's two ranks, ignoring missing ones.
+func bestRank(r HybridSearchResult) int {
+	switch {
+	case r.VectorRank == 0:
+		return r.TextRank
+	case r.TextRank == 0:
+		return r.VectorRank
+	}
+	return min(r.VectorRank, r.TextRank)
+}
+
 func toolVectorInfo(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
 	input VectorInfoInput,
 ) (*mcp.CallToolResult, VectorInfoOutput, error) {
+	conf := settingsFrom(ctx)
 	if input.Database == "" {
 		return nil, VectorInfoOutput{}, fmt.Errorf("database is required")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, conf.queryTimeout)
 	defer cancel()
 
 	out := VectorInfoOutput{Columns: []VectorColumnInfo{}}
@@ -850,6 +1499,9 @@ func toolVectorInfo(
 		_ = getDB().QueryRowContext(ctx, indexQuery, input.Database, tableName, colName).Scan(&indexName, &indexType)
 		info.IndexName = indexName.String
 		info.IndexType = indexType.String
+		if conf.embedder != nil {
+			info.EmbeddingModel, _ = conf.embedder.ModelFor(input.Database, tableName, colName)
+		}
 
 		out.Columns = append(out.Columns, info)
 	}
@@ -866,6 +1518,38 @@ func buildVectorString(vec []float64) string {
 	return "[" + strings.Join(parts, ",") + "]"
 }
 
+// versionAtLeast reports whether a VERSION() string such as "8.0.36-log" is
+// at least major.minor.patch. MariaDB never qualifies, as its version
+// numbers do not line up with MySQL features.
+func versionAtLeast(version string, major, minor, patch int) bool {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return false
+	}
+	parts := strings.SplitN(version, ".", 3)
+	for i, want := range []int{major, minor, patch} {
+		got := 0
+		if i < len(parts) {
+			digits := parts[i]
+			if j := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); j >= 0 {
+				digits = digits[:j]
+			}
+			got, _ = strconv.Atoi(digits)
+		}
+		if got != want {
+			return got > want
+		}
+	}
+	return true
+}
+
+// isMariaDB reports whether VERSION() and @@version_comment belong to a
+// MariaDB server. Some builds report a bare "10.x" version, so the comment
+// is checked as well.
+func isMariaDB(version, comment string) bool {
+	return strings.Contains(strings.ToLower(version), "mariadb") ||
+		strings.Contains(strings.ToLower(comment), "mariadb")
+}
+
 // isVectorSupported checks if MySQL version supports VECTOR type (9.0+).
 func isVectorSupported(version string) bool {
 	parts := strings.Split(version, ".")
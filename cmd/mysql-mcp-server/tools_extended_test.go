@@ -3,11 +3,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/askdba/mysql-mcp-server/internal/config"
+	"github.com/askdba/mysql-mcp-server/internal/embedding"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -21,8 +27,6 @@ func setupExtendedMockDB(t *testing.T) (sqlmock.Sqlmock, func()) {
 
 	// Save original state
 	oldConnManager := connManager
-	oldMaxRows := maxRows
-	oldQueryTimeout := queryTimeout
 
 	// Set up mock connection manager with mock DB
 	cm := NewConnectionManager()
@@ -31,13 +35,13 @@ func setupExtendedMockDB(t *testing.T) (sqlmock.Sqlmock, func()) {
 	cm.activeConn = "mock"
 	connManager = cm
 
-	maxRows = 1000
-	queryTimeout = 30 * time.Second
+	setSettings(t, func(s *settings) {
+		s.maxRows = 1000
+		s.queryTimeout = 30 * time.Second
+	})
 
 	cleanup := func() {
 		connManager = oldConnManager
-		maxRows = oldMaxRows
-		queryTimeout = oldQueryTimeout
 		mockDB.Close()
 	}
 
@@ -257,6 +261,229 @@ func TestToolExplainQueryNonSelect(t *testing.T) {
 	}
 }
 
+func TestToolExplainQueryFormats(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	jsonPlan := `{"query_block": {"select_id": 1, "cost_info": {"query_cost": "10.25"},
+		"table": {"table_name": "users", "access_type": "ALL", "rows_examined_per_scan": 100, "filtered": "10.00"}}}`
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON SELECT \\* FROM users WHERE name = 'x'").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(jsonPlan))
+	mock.ExpectQuery("EXPLAIN FORMAT=TREE SELECT \\* FROM users WHERE name = 'x'").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).
+			AddRow("-> Filter: (users.name = 'x')  (cost=10.25 rows=10)\n    -> Table scan on users  (cost=10.25 rows=100)\n"))
+
+	for _, format := range []string{"json", "TREE"} {
+		_, out, err := toolExplainQuery(context.Background(), &mcp.CallToolRequest{}, ExplainQueryInput{
+			SQL:    "SELECT * FROM users WHERE name = 'x'",
+			Format: format,
+		})
+		if err != nil {
+			t.Fatalf("toolExplainQuery(%s) failed: %v", format, err)
+		}
+		if out.Tree == nil || len(out.Warnings) != 1 || out.Warnings[0] != "users: full table scan" {
+			t.Errorf("format %s: unexpected output %+v", format, out)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolExplainQueryAnalyze(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	setSettings(t, func(s *settings) { s.queryTimeout = 5 * time.Second })
+
+	// The server enforces the query timeout on the analyzed query.
+	mock.ExpectQuery("SELECT VERSION\\(\\)").WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@version_comment"}).AddRow("8.0.36", "MySQL Community Server - GPL"))
+	mock.ExpectExec("SET SESSION max_execution_time = 5000").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("EXPLAIN ANALYZE SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).
+			AddRow("-> Table scan on users  (cost=1.25 rows=10) (actual time=0.02..0.9 rows=5000 loops=1)\n"))
+	mock.ExpectExec("SET SESSION max_execution_time = DEFAULT").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, out, err := toolExplainQuery(context.Background(), &mcp.CallToolRequest{}, ExplainQueryInput{
+		SQL:     "SELECT id FROM users",
+		Analyze: true,
+	})
+	if err != nil {
+		t.Fatalf("toolExplainQuery failed: %v", err)
+	}
+	if !out.Analyzed || out.Format != "tree" || out.Tree == nil || *out.Tree.RowsActual != 5000 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+	if len(out.Warnings) != 2 || !strings.Contains(out.Warnings[1], "row estimate off by 500x") {
+		t.Errorf("unexpected warnings: %v", out.Warnings)
+	}
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@version_comment"}).AddRow("5.7.44", "MySQL Community Server (GPL)"))
+	_, _, err = toolExplainQuery(context.Background(), &mcp.CallToolRequest{}, ExplainQueryInput{
+		SQL:     "SELECT id FROM users",
+		Analyze: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "requires MySQL 8.0.18") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+
+	// MariaDB has no EXPLAIN ANALYZE, even when VERSION() is a bare "10.x".
+	mock.ExpectQuery("SELECT VERSION\\(\\)").WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@version_comment"}).AddRow("10.11.6", "mariadb.org binary distribution"))
+	_, _, err = toolExplainQuery(context.Background(), &mcp.CallToolRequest{}, ExplainQueryInput{
+		SQL:     "SELECT id FROM users",
+		Analyze: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "not supported on MariaDB") {
+		t.Errorf("expected a MariaDB error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolExplainQueryRejected(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		name  string
+		input ExplainQueryInput
+		want  string
+	}{
+		{"unknown format", ExplainQueryInput{SQL: "SELECT 1", Format: "xml"}, "unknown format: xml"},
+		{"analyze with json", ExplainQueryInput{SQL: "SELECT 1", Format: "json", Analyze: true}, "only supports the tree format"},
+		{"validator", ExplainQueryInput{SQL: "SELECT * FROM mysql.user"}, "query validation failed"},
+		{"multi statement", ExplainQueryInput{SQL: "SELECT 1; SELECT 2"}, "query validation failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := toolExplainQuery(context.Background(), &mcp.CallToolRequest{}, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// ===== toolComparePlans Tests =====
+
+func TestToolComparePlansRewrite(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON SELECT id FROM orders WHERE YEAR").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block": {"cost_info": {"query_cost": "1010.00"},
+			"table": {"table_name": "orders", "access_type": "ALL", "rows_examined_per_scan": 10000}}}`))
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON SELECT id FROM orders WHERE created_at").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block": {"cost_info": {"query_cost": "50.50"},
+			"table": {"table_name": "orders", "access_type": "range", "key": "idx_created", "rows_examined_per_scan": 500}}}`))
+
+	_, out, err := toolComparePlans(context.Background(), &mcp.CallToolRequest{}, ComparePlansInput{
+		SQL:      "SELECT id FROM orders WHERE YEAR(created_at) = 2024",
+		RightSQL: "SELECT id FROM orders WHERE created_at >= '2024-01-01' AND created_at < '2025-01-01'",
+	})
+	if err != nil {
+		t.Fatalf("toolComparePlans failed: %v", err)
+	}
+
+	if out.Cheaper != "right" || out.CostChangePct == nil || *out.CostChangePct != -95 || out.JoinOrderChanged {
+		t.Errorf("unexpected verdict: %+v", out)
+	}
+	if len(out.Differences) != 1 {
+		t.Fatalf("expected 1 difference, got %+v", out.Differences)
+	}
+	if d := out.Differences[0]; d.Table != "orders" || d.Change != "improved" || d.LeftAccess != "ALL" || d.RightIndex != "idx_created" {
+		t.Errorf("unexpected difference: %+v", d)
+	}
+	if out.Left.Connection != "mock" || len(out.Left.Warnings) != 1 || out.Right.Warnings != nil || out.Notes != nil {
+		t.Errorf("unexpected summaries: %+v / %+v", out.Left, out.Right)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolComparePlansConnections(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	stagingDB, staging, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer stagingDB.Close()
+	connManager.connections["staging"] = stagingDB
+
+	query := "SELECT o.id FROM orders o JOIN customers c ON c.id = o.customer_id"
+	mock.ExpectExec("USE `shop`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON SELECT o.id").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block": {"cost_info": {"query_cost": "20.00"},
+			"nested_loop": [
+				{"table": {"table_name": "c", "access_type": "index", "key": "PRIMARY", "rows_examined_per_scan": 10}},
+				{"table": {"table_name": "o", "access_type": "ref", "key": "idx_customer", "rows_examined_per_scan": 5}}]}}`))
+	staging.ExpectExec("USE `shop`").WillReturnResult(sqlmock.NewResult(0, 0))
+	staging.ExpectQuery("EXPLAIN FORMAT=JSON SELECT o.id").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block": {"cost_info": {"query_cost": "20.10"},
+			"nested_loop": [
+				{"table": {"table_name": "o", "access_type": "ALL", "rows_examined_per_scan": 50}},
+				{"table": {"table_name": "c", "access_type": "eq_ref", "key": "PRIMARY", "rows_examined_per_scan": 1}}]}}`))
+
+	_, out, err := toolComparePlans(context.Background(), &mcp.CallToolRequest{}, ComparePlansInput{
+		SQL:             query,
+		Database:        "shop",
+		RightConnection: "staging",
+	})
+	if err != nil {
+		t.Fatalf("toolComparePlans failed: %v", err)
+	}
+
+	if !out.JoinOrderChanged || out.Cheaper != "equal" || len(out.Notes) != 1 {
+		t.Errorf("unexpected comparison: %+v", out)
+	}
+	if strings.Join(out.Right.JoinOrder, ",") != "o,c" || out.Right.Connection != "staging" || out.Right.RowsExamined != 51 {
+		t.Errorf("unexpected right summary: %+v", out.Right)
+	}
+	changes := make(map[string]string)
+	for _, d := range out.Differences {
+		changes[d.Table] = d.Change
+	}
+	if changes["c"] != "improved" || changes["o"] != "regressed" {
+		t.Errorf("unexpected differences: %+v", out.Differences)
+	}
+
+	for name, m := range map[string]sqlmock.Sqlmock{"active": mock, "staging": staging} {
+		if err := m.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: unfulfilled expectations: %v", name, err)
+		}
+	}
+}
+
+func TestToolComparePlansErrors(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		name  string
+		input ComparePlansInput
+		want  string
+	}{
+		{"missing sql", ComparePlansInput{}, "sql is required"},
+		{"same side twice", ComparePlansInput{SQL: "SELECT 1", RightConnection: "mock", Connection: "mock"}, "nothing to compare"},
+		{"unknown connection", ComparePlansInput{SQL: "SELECT 1", RightConnection: "prod"}, "right plan: connection 'prod' not found"},
+		{"rejected statement", ComparePlansInput{SQL: "DELETE FROM t", RightSQL: "SELECT 1"}, "left plan: only SELECT statements"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := toolComparePlans(context.Background(), &mcp.CallToolRequest{}, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
 // ===== toolListViews Tests =====
 
 func TestToolListViewsSuccess(t *testing.T) {
@@ -811,6 +1038,30 @@ func TestIsVectorSupported(t *testing.T) {
 	}
 }
 
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"8.0.18", true},
+		{"8.0.17", false},
+		{"8.0.36-log", true},
+		{"8.4.0", true},
+		{"5.7.44", false},
+		{"9.1.0-commercial", true},
+		{"10.11.6-MariaDB", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := versionAtLeast(tt.version, 8, 0, 18); got != tt.expected {
+				t.Errorf("versionAtLeast(%s, 8.0.18) = %v, expected %v", tt.version, got, tt.expected)
+			}
+		})
+	}
+}
+
 // ===== toolVectorSearch Tests =====
 
 func TestToolVectorSearchMissingInputs(t *testing.T) {
@@ -840,7 +1091,32 @@ func TestToolVectorSearchMissingInputs(t *testing.T) {
 		{
 			name:   "empty query vector",
 			input:  VectorSearchInput{Database: "db", Table: "test", Column: "vec", Query: []float64{}},
-			errMsg: "query vector is required",
+			errMsg: "query vector or query_text is required",
+		},
+		{
+			name:   "query and query_text",
+			input:  VectorSearchInput{Database: "db", Table: "test", Column: "vec", Query: []float64{0.1}, QueryText: "shoes"},
+			errMsg: "set only one of query, query_text or queries",
+		},
+		{
+			name:   "query and queries",
+			input:  VectorSearchInput{Database: "db", Table: "test", Column: "vec", Query: []float64{0.1}, Queries: [][]float64{{0.2}}},
+			errMsg: "set only one of query, query_text or queries",
+		},
+		{
+			name:   "empty batch query",
+			input:  VectorSearchInput{Database: "db", Table: "test", Column: "vec", Queries: [][]float64{{0.2}, {}}},
+			errMsg: "queries[1] is empty",
+		},
+		{
+			name:   "merge without queries",
+			input:  VectorSearchInput{Database: "db", Table: "test", Column: "vec", Query: []float64{0.1}, Merge: true},
+			errMsg: "merge requires queries",
+		},
+		{
+			name:   "query_text without provider",
+			input:  VectorSearchInput{Database: "db", Table: "test", Column: "vec", QueryText: "shoes"},
+			errMsg: "query_text requires an embedding provider (set MYSQL_MCP_EMBEDDING_URL)",
 		},
 	}
 
@@ -863,6 +1139,372 @@ func TestToolVectorSearchMissingInputs(t *testing.T) {
 	}
 }
 
+type stubEmbeddingProvider struct {
+	calls int
+	model string
+}
+
+func (s *stubEmbeddingProvider) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	s.calls++
+	s.model = model
+	return [][]float64{{0.5, 0.25}}, nil
+}
+
+func TestToolVectorSearchQueryText(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	provider := &stubEmbeddingProvider{}
+	e, err := embedding.New(provider, "default-model", map[string]string{"products.embedding": "product-model"}, 10)
+	if err != nil {
+		t.Fatalf("embedding.New failed: %v", err)
+	}
+	setSettings(t, func(s *settings) { s.embedder = e })
+
+	input := VectorSearchInput{Database: "shop", Table: "products", Column: "embedding", QueryText: "running shoes", Limit: 2}
+	for i, wantCached := range []bool{false, true} {
+		mock.ExpectQuery(regexp.QuoteMeta("STRING_TO_VECTOR('[0.500000,0.250000]')")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "_distance"}).AddRow(int64(7), 0.12))
+
+		_, out, err := toolVectorSearch(context.Background(), &mcp.CallToolRequest{}, input)
+		if err != nil {
+			t.Fatalf("call %d: toolVectorSearch failed: %v", i, err)
+		}
+		if out.EmbeddingModel != "product-model" || out.EmbeddingCached != wantCached {
+			t.Errorf("call %d: model %q cached %v", i, out.EmbeddingModel, out.EmbeddingCached)
+		}
+		if out.Count != 1 || out.Results[0].Distance != 0.12 {
+			t.Errorf("call %d: unexpected results: %+v", i, out.Results)
+		}
+	}
+	if provider.calls != 1 || provider.model != "product-model" {
+		t.Errorf("expected one provider call with the column's model, got %d (%s)", provider.calls, provider.model)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolVectorSearchFilter(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	var filter interface{}
+	if err := json.Unmarshal([]byte(`{"or": [
+		{"column": "category", "op": "in", "values": ["shoes", "socks"]},
+		{"column": "price", "op": "between", "values": [10, 20.5]}
+	]}`), &filter); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE (in_stock = 1) AND (`category` IN (?, ?) OR `price` BETWEEN ? AND ?) ORDER BY _distance ASC LIMIT 10")).
+		WithArgs("shoes", "socks", int64(10), 20.5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "_distance"}).AddRow(int64(1), 0.1))
+
+	_, out, err := toolVectorSearch(context.Background(), &mcp.CallToolRequest{}, VectorSearchInput{
+		Database: "shop",
+		Table:    "products",
+		Column:   "embedding",
+		Query:    []float64{1, 0},
+		Where:    "in_stock = 1",
+		Filter:   filter,
+	})
+	if err != nil {
+		t.Fatalf("toolVectorSearch failed: %v", err)
+	}
+	if out.Count != 1 {
+		t.Errorf("expected 1 result, got %d", out.Count)
+	}
+
+	// Invalid filters are rejected before querying.
+	_, _, err = toolVectorSearch(context.Background(), &mcp.CallToolRequest{}, VectorSearchInput{
+		Database: "shop", Table: "products", Column: "embedding", Query: []float64{1},
+		Filter: map[string]interface{}{"column": "price", "op": "~", "value": 1},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported filter operator") {
+		t.Errorf("expected an operator error, got %v", err)
+	}
+
+	// With raw where disabled only the filter is accepted.
+	setSettings(t, func(s *settings) { s.disableRawWhere = true })
+	_, _, err = toolVectorSearch(context.Background(), &mcp.CallToolRequest{}, VectorSearchInput{
+		Database: "shop", Table: "products", Column: "embedding", Query: []float64{1}, Where: "in_stock = 1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "raw where clauses are disabled") {
+		t.Errorf("expected raw where to be rejected, got %v", err)
+	}
+	_, _, err = toolHybridSearch(context.Background(), &mcp.CallToolRequest{}, HybridSearchInput{
+		Database: "shop", Table: "products", Column: "embedding", TextColumns: "name", QueryText: "x",
+		Query: []float64{1}, Where: "in_stock = 1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "raw where clauses are disabled") {
+		t.Errorf("expected raw where to be rejected by hybrid_search, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolVectorSearchBatch(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	// Queries run concurrently, so the mock must not depend on their order.
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery(regexp.QuoteMeta("STRING_TO_VECTOR('[1.000000,0.000000]')") + ".*" + regexp.QuoteMeta("WHERE `lang` = ?")).
+		WithArgs("en").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "_distance"}).AddRow(int64(1), 0.1).AddRow(int64(2), 0.3))
+	mock.ExpectQuery(regexp.QuoteMeta("STRING_TO_VECTOR('[0.000000,1.000000]')") + ".*" + regexp.QuoteMeta("WHERE `lang` = ?")).
+		WithArgs("en").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "_distance"}).AddRow(int64(3), 0.2))
+
+	_, out, err := toolVectorSearch(context.Background(), &mcp.CallToolRequest{}, VectorSearchInput{
+		Database: "shop",
+		Table:    "products",
+		Column:   "embedding",
+		Queries:  [][]float64{{1, 0}, {0, 1}},
+		Filter:   map[string]interface{}{"column": "lang", "op": "=", "value": "en"},
+	})
+	if err != nil {
+		t.Fatalf("toolVectorSearch failed: %v", err)
+	}
+	if len(out.Batches) != 2 || out.Count != 3 || len(out.Results) != 0 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if b := out.Batches[0]; b.Query != 0 || b.Count != 2 || b.Results[0].Data["id"] != int64(1) {
+		t.Errorf("unexpected first batch: %+v", b)
+	}
+	if b := out.Batches[1]; b.Query != 1 || b.Count != 1 || b.Results[0].Distance != 0.2 {
+		t.Errorf("unexpected second batch: %+v", b)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolVectorSearchBatchMerge(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("information_schema.STATISTICS").
+		WithArgs("shop", "products").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("id"))
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `name`, `id` AS _key0,") + ".*" + regexp.QuoteMeta("STRING_TO_VECTOR('[1.000000,0.000000]')")).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "_key0", "_distance"}).
+			AddRow("a", int64(1), 0.1).
+			AddRow("b", int64(2), 0.5).
+			AddRow("c", int64(3), 0.6))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `name`, `id` AS _key0,") + ".*" + regexp.QuoteMeta("STRING_TO_VECTOR('[0.000000,1.000000]')")).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "_key0", "_distance"}).
+			AddRow("b", int64(2), 0.2).
+			AddRow("d", int64(4), 0.3).
+			AddRow("a", int64(1), 0.7))
+
+	_, out, err := toolVectorSearch(context.Background(), &mcp.CallToolRequest{}, VectorSearchInput{
+		Database: "shop",
+		Table:    "products",
+		Column:   "embedding",
+		Queries:  [][]float64{{1, 0}, {0, 1}},
+		Select:   "name",
+		Merge:    true,
+		Limit:    3,
+	})
+	if err != nil {
+		t.Fatalf("toolVectorSearch failed: %v", err)
+	}
+
+	// Rows are deduped by id, keep their best distance and list both queries.
+	want := []struct {
+		name     string
+		distance float64
+		queries  string
+	}{{"a", 0.1, "[0 1]"}, {"b", 0.2, "[0 1]"}, {"d", 0.3, "[1]"}}
+	if out.Count != len(want) || len(out.Batches) != 0 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	for i, w := range want {
+		r := out.Results[i]
+		if r.Data["name"] != w.name || r.Distance != w.distance || fmt.Sprint(r.Queries) != w.queries {
+			t.Errorf("result %d = %v %v %v, want %s %v %s", i, r.Data["name"], r.Distance, r.Queries, w.name, w.distance, w.queries)
+		}
+		if _, ok := r.Data["_key0"]; ok {
+			t.Errorf("key alias leaked into data: %v", r.Data)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// ===== toolHybridSearch Tests =====
+
+func TestToolHybridSearch(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("information_schema.STATISTICS").
+		WithArgs("shop", "products").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("id"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT *, `id` AS _key0,") + ".*" +
+		regexp.QuoteMeta("DISTANCE(`embedding`, STRING_TO_VECTOR('[1.000000,0.000000]'), 'COSINE')") + ".*" +
+		regexp.QuoteMeta("WHERE in_stock = 1 ORDER BY _distance ASC LIMIT 20")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "_key0", "_distance"}).
+			AddRow(int64(1), "trail shoe", int64(1), 0.1).
+			AddRow(int64(2), "road shoe", int64(2), 0.2).
+			AddRow(int64(3), "running sock", int64(3), 0.3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT *, `id` AS _key0, MATCH(`name`, `description`) AGAINST(? IN NATURAL LANGUAGE MODE) AS _score FROM `shop`.`products` WHERE MATCH(`name`, `description`) AGAINST(? IN NATURAL LANGUAGE MODE) AND (in_stock = 1) ORDER BY _score DESC LIMIT 20")).
+		WithArgs("running", "running").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "_key0", "_score"}).
+			AddRow(int64(3), "running sock", int64(3), 5.0).
+			AddRow(int64(4), "running cap", int64(4), 2.0))
+
+	_, out, err := toolHybridSearch(context.Background(), &mcp.CallToolRequest{}, HybridSearchInput{
+		Database:    "shop",
+		Table:       "products",
+		Column:      "embedding",
+		TextColumns: "name, description",
+		QueryText:   "running",
+		Query:       []float64{1, 0},
+		Where:       "in_stock = 1",
+		Limit:       3,
+	})
+	if err != nil {
+		t.Fatalf("toolHybridSearch failed: %v", err)
+	}
+	if out.Fusion != "rrf" || out.VectorCandidates != 3 || out.TextCandidates != 2 || out.Count != 3 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+
+	// Row 3 is in both lists, so it beats the top vector-only row.
+	var ids []interface{}
+	for _, r := range out.Results {
+		ids = append(ids, r.Data["id"])
+		if _, ok := r.Data["_key0"]; ok {
+			t.Errorf("key alias leaked into data: %v", r.Data)
+		}
+	}
+	if fmt.Sprint(ids) != "[3 1 2]" {
+		t.Errorf("unexpected order: %v", ids)
+	}
+	top := out.Results[0]
+	if top.VectorRank != 3 || top.TextRank != 1 || top.VectorDistance == nil || *top.VectorDistance != 0.3 ||
+		top.TextScore == nil || *top.TextScore != 5.0 {
+		t.Errorf("unexpected top result: %+v", top)
+	}
+	if want := math.Round((0.5/63+0.5/61)*1e6) / 1e6; top.Score != want {
+		t.Errorf("score = %v, want %v", top.Score, want)
+	}
+	if out.Results[1].TextScore != nil || out.Results[1].TextRank != 0 {
+		t.Errorf("vector-only row should have no text score: %+v", out.Results[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolHybridSearchWeighted(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `title`, `sku` AS _key0,") + ".*" + regexp.QuoteMeta("'EUCLIDEAN'") + ".*" + regexp.QuoteMeta("WHERE `brand` = ?")).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"title", "_key0", "_distance"}).
+			AddRow("a", "A1", 1.0).
+			AddRow("b", "B1", 2.0).
+			AddRow("c", "C1", 3.0))
+	mock.ExpectQuery(regexp.QuoteMeta("AGAINST(? IN BOOLEAN MODE) AND (`brand` = ?)")).
+		WithArgs("+shoe", "+shoe", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"title", "_key0", "_score"}).
+			AddRow("c", "C1", 5.0).
+			AddRow("d", "D1", 2.0))
+
+	weight := 0.7
+	_, out, err := toolHybridSearch(context.Background(), &mcp.CallToolRequest{}, HybridSearchInput{
+		Database:     "shop",
+		Table:        "products",
+		Column:       "embedding",
+		TextColumns:  "title",
+		QueryText:    "+shoe",
+		Query:        []float64{1, 0},
+		Select:       "title",
+		KeyColumns:   "sku",
+		DistanceFunc: "euclidean",
+		TextMode:     "boolean",
+		Fusion:       "weighted",
+		VectorWeight: &weight,
+		Filter:       map[string]interface{}{"column": "brand", "op": "=", "value": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("toolHybridSearch failed: %v", err)
+	}
+
+	// Vector similarity scales 1.0..3.0 to 1..0, text scores divide by 5.
+	want := []struct {
+		title string
+		score float64
+	}{{"a", 0.7}, {"b", 0.35}, {"c", 0.3}, {"d", 0.12}}
+	if len(out.Results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), out.Results)
+	}
+	for i, w := range want {
+		if r := out.Results[i]; r.Data["title"] != w.title || r.Score != w.score {
+			t.Errorf("result %d = %v (%v), want %s (%v)", i, r.Data["title"], r.Score, w.title, w.score)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolHybridSearchValidation(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	base := HybridSearchInput{Database: "shop", Table: "products", Column: "embedding", TextColumns: "name", QueryText: "x", Query: []float64{1}}
+	badWeight := 1.5
+	tests := []struct {
+		name   string
+		modify func(*HybridSearchInput)
+		want   string
+	}{
+		{"missing text columns", func(in *HybridSearchInput) { in.TextColumns = "" }, "text_columns are required"},
+		{"missing query text", func(in *HybridSearchInput) { in.QueryText = " " }, "query_text is required"},
+		{"bad text column", func(in *HybridSearchInput) { in.TextColumns = "name, bad`col" }, "invalid text column"},
+		{"bad fusion", func(in *HybridSearchInput) { in.Fusion = "max" }, "fusion must be"},
+		{"bad weight", func(in *HybridSearchInput) { in.VectorWeight = &badWeight }, "vector_weight"},
+		{"bad text mode", func(in *HybridSearchInput) { in.TextMode = "query" }, "text_mode"},
+		{"bad where", func(in *HybridSearchInput) { in.Where = "1=1; DROP TABLE t" }, "invalid where clause"},
+		{"no embedder", func(in *HybridSearchInput) { in.Query = nil }, "requires an embedding provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := base
+			tt.modify(&input)
+			_, _, err := toolHybridSearch(context.Background(), &mcp.CallToolRequest{}, input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	mock.ExpectQuery("information_schema.STATISTICS").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}))
+	if _, _, err := toolHybridSearch(context.Background(), &mcp.CallToolRequest{}, base); err == nil || !strings.Contains(err.Error(), "no primary key") {
+		t.Errorf("expected a missing primary key error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
 // ===== toolVectorInfo Tests =====
 
 func TestToolVectorInfoMissingDatabase(t *testing.T) {
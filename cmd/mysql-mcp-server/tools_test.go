@@ -9,6 +9,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/askdba/mysql-mcp-server/internal/config"
+	"github.com/askdba/mysql-mcp-server/internal/util"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -36,9 +37,6 @@ func setupMockDBFull(t *testing.T) mockDBResult {
 
 	// Save original state
 	oldConnManager := connManager
-	oldMaxRows := maxRows
-	oldQueryTimeout := queryTimeout
-	oldPingTimeout := pingTimeout
 
 	// Set up mock connection manager with mock DB
 	cm := NewConnectionManager()
@@ -47,21 +45,31 @@ func setupMockDBFull(t *testing.T) mockDBResult {
 	cm.activeConn = "mock"
 	connManager = cm
 
-	maxRows = 1000
-	queryTimeout = 30 * time.Second
-	pingTimeout = time.Duration(config.DefaultPingTimeoutSecs) * time.Second
+	setSettings(t, func(s *settings) {
+		s.maxRows = 1000
+		s.queryTimeout = 30 * time.Second
+		s.pingTimeout = time.Duration(config.DefaultPingTimeoutSecs) * time.Second
+	})
 
 	cleanup := func() {
 		connManager = oldConnManager
-		maxRows = oldMaxRows
-		queryTimeout = oldQueryTimeout
-		pingTimeout = oldPingTimeout
 		mockDB.Close()
 	}
 
 	return mockDBResult{mock: mock, mockDB: mockDB, cleanup: cleanup}
 }
 
+// setSettings replaces the live settings with a copy of the current ones
+// changed by update, and restores them when the test ends.
+func setSettings(t *testing.T, update func(*settings)) {
+	t.Helper()
+	old := live.Load()
+	s := *currentSettings()
+	update(&s)
+	live.Store(&s)
+	t.Cleanup(func() { live.Store(old) })
+}
+
 func TestToolListDatabases(t *testing.T) {
 	mock, cleanup := setupMockDB(t)
 	defer cleanup()
@@ -298,6 +306,74 @@ func TestToolRunQuerySelectSuccess(t *testing.T) {
 	}
 }
 
+func TestToolRunQueryMasksColumns(t *testing.T) {
+	mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	setSettings(t, func(s *settings) {
+		s.columnMasker = util.NewColumnMasker([]string{"email", "orders.total"})
+	})
+
+	rows := sqlmock.NewRows([]string{"id", "email", "total"}).
+		AddRow(1, "alice@example.com", 10).
+		AddRow(2, nil, 20)
+	mock.ExpectQuery("SELECT id, email, total FROM users").WillReturnRows(rows)
+
+	_, output, err := toolRunQuery(context.Background(), &mcp.CallToolRequest{}, RunQueryInput{
+		SQL: "SELECT id, email, total FROM users",
+	})
+	if err != nil {
+		t.Fatalf("toolRunQuery failed: %v", err)
+	}
+
+	if output.Rows[0][1] != util.MaskedValue {
+		t.Errorf("expected email to be masked, got %v", output.Rows[0][1])
+	}
+	if output.Rows[1][1] != nil {
+		t.Errorf("expected NULL email to stay NULL, got %v", output.Rows[1][1])
+	}
+	// Table-qualified rules cannot match ad-hoc query columns.
+	if output.Rows[0][2] == util.MaskedValue {
+		t.Error("expected total not to be masked without a known table")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolRunQueryLintWarnings(t *testing.T) {
+	mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT id FROM users WHERE email = 42").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("FROM information_schema.COLUMNS c").
+		WithArgs("", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE", "nullable", "indexed"}).
+			AddRow("id", "int", false, true).
+			AddRow("email", "varchar", false, true))
+
+	_, output, err := toolRunQuery(context.Background(), &mcp.CallToolRequest{}, RunQueryInput{
+		SQL:  "SELECT id FROM users WHERE email = 42",
+		Lint: true,
+	})
+	if err != nil {
+		t.Fatalf("toolRunQuery failed: %v", err)
+	}
+
+	if len(output.Rows) != 1 {
+		t.Errorf("expected 1 row, got %d", len(output.Rows))
+	}
+	if len(output.LintWarnings) != 1 || output.LintWarnings[0].Rule != "implicit_conversion" {
+		t.Errorf("unexpected lint warnings: %+v", output.LintWarnings)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
 func TestToolRunQueryEmptySQL(t *testing.T) {
 	mock, cleanup := setupMockDB(t)
 	defer cleanup()
@@ -0,0 +1,456 @@
+// cmd/mysql-mcp-server/tools_vector.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/askdba/mysql-mcp-server/internal/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults for the vector data preparation tools.
+const (
+	defaultVectorStatsSample  = 1000
+	maxVectorStatsSample      = 10000
+	maxDuplicateCompare       = 500 // vectors compared pairwise for near-duplicates
+	defaultDuplicateThreshold = 0.995
+	normalizedTolerance       = 0.01 // |norm - 1| within this counts as unit length
+	normalizedRatio           = 0.99 // share of unit-length vectors to call a column normalized
+	highDuplicateRate         = 0.05
+	ddlDimensionSample        = 100
+	maxVectorDimensions       = 16383
+)
+
+// vectorColumn describes a column considered by the vector tools.
+type vectorColumn struct {
+	DataType   string // lower case, e.g. "vector", "json", "text"
+	ColumnType string // e.g. "vector(768)"
+	Nullable   bool
+}
+
+// loadVectorColumn returns the type of database.table.column. It returns
+// false when the column does not exist.
+func loadVectorColumn(ctx context.Context, db *sql.DB, database, table, column string) (vectorColumn, bool, error) {
+	var c vectorColumn
+	var nullable string
+	err := db.QueryRowContext(ctx, `SELECT DATA_TYPE, COLUMN_TYPE, IS_NULLABLE
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_NAME = ?`, database, table, column).
+		Scan(&c.DataType, &c.ColumnType, &nullable)
+	if errors.Is(err, sql.ErrNoRows) {
+		return vectorColumn{}, false, nil
+	}
+	if err != nil {
+		return vectorColumn{}, false, fmt.Errorf("failed to read column: %w", err)
+	}
+	c.DataType = strings.ToLower(c.DataType)
+	c.ColumnType = strings.ToLower(c.ColumnType)
+	c.Nullable = nullable == "YES"
+	return c, true, nil
+}
+
+// isVectorSourceType reports whether a column type can hold vectors as
+// "[1.0, 2.0, ...]" text.
+func isVectorSourceType(dataType string) bool {
+	switch dataType {
+	case "vector", "json", "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return true
+	}
+	return false
+}
+
+// vectorSample is the parsed content of a sampled vector column.
+type vectorSample struct {
+	rows    int
+	nulls   int
+	invalid int
+	vectors [][]float64
+}
+
+// sampleVectors reads up to n values of a VECTOR column, or of a JSON or
+// text column holding arrays of numbers.
+func sampleVectors(ctx context.Context, db *sql.DB, dbName, tableName, colName string, col vectorColumn, n int) (vectorSample, error) {
+	expr := colName
+	if col.DataType == "vector" {
+		expr = "VECTOR_TO_STRING(" + colName + ")"
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s.%s LIMIT %d", expr, dbName, tableName, n))
+	if err != nil {
+		return vectorSample{}, fmt.Errorf("sample query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var s vectorSample
+	for rows.Next() {
+		var v sql.NullString
+		if err := rows.Scan(&v); err != nil {
+			return vectorSample{}, fmt.Errorf("scan failed: %w", err)
+		}
+		s.rows++
+		if !v.Valid {
+			s.nulls++
+			continue
+		}
+		var vec []float64
+		if err := json.Unmarshal([]byte(v.String), &vec); err != nil || len(vec) == 0 {
+			s.invalid++
+			continue
+		}
+		s.vectors = append(s.vectors, vec)
+	}
+	return s, rows.Err()
+}
+
+// dimensionCounts returns the number of vectors per dimension, most common
+// first.
+func dimensionCounts(vectors [][]float64) []DimensionCount {
+	counts := make(map[int]int)
+	for _, v := range vectors {
+		counts[len(v)]++
+	}
+	out := make([]DimensionCount, 0, len(counts))
+	for dims, n := range counts {
+		out = append(out, DimensionCount{Dimensions: dims, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Dimensions < out[j].Dimensions
+	})
+	return out
+}
+
+func vectorNorm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// nearDuplicatePairs counts pairs of vectors whose cosine similarity is at
+// least threshold, and the number of vectors with at least one such pair.
+func nearDuplicatePairs(vectors [][]float64, norms []float64, threshold float64) (pairs, withDuplicate int) {
+	dup := make([]bool, len(vectors))
+	for i := range vectors {
+		if norms[i] == 0 {
+			continue
+		}
+		for j := i + 1; j < len(vectors); j++ {
+			if norms[j] == 0 {
+				continue
+			}
+			var dot float64
+			for k, x := range vectors[i] {
+				dot += x * vectors[j][k]
+			}
+			if dot/(norms[i]*norms[j]) >= threshold {
+				pairs++
+				dup[i], dup[j] = true, true
+			}
+		}
+	}
+	for _, d := range dup {
+		if d {
+			withDuplicate++
+		}
+	}
+	return pairs, withDuplicate
+}
+
+// ===== Vector Data Preparation Tool Handlers =====
+
+func toolVectorStats(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input VectorStatsInput,
+) (*mcp.CallToolResult, VectorStatsOutput, error) {
+	if input.Database == "" || input.Table == "" || input.Column == "" {
+		return nil, VectorStatsOutput{}, fmt.Errorf("database, table, and column are required")
+	}
+	dbName, err := util.QuoteIdent(input.Database)
+	if err != nil {
+		return nil, VectorStatsOutput{}, fmt.Errorf("invalid database name: %w", err)
+	}
+	tableName, err := util.QuoteIdent(input.Table)
+	if err != nil {
+		return nil, VectorStatsOutput{}, fmt.Errorf("invalid table name: %w", err)
+	}
+	colName, err := util.QuoteIdent(input.Column)
+	if err != nil {
+		return nil, VectorStatsOutput{}, fmt.Errorf("invalid column name: %w", err)
+	}
+
+	sampleSize := input.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultVectorStatsSample
+	}
+	sampleSize = min(sampleSize, maxVectorStatsSample)
+	threshold := input.DuplicateThreshold
+	if threshold == 0 {
+		threshold = defaultDuplicateThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, VectorStatsOutput{}, fmt.Errorf("duplicate_threshold must be between 0 and 1")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+	db := getDB()
+
+	col, found, err := loadVectorColumn(ctx, db, input.Database, input.Table, input.Column)
+	if err != nil {
+		return nil, VectorStatsOutput{}, err
+	}
+	if !found {
+		return nil, VectorStatsOutput{}, fmt.Errorf("column %s not found in %s.%s", input.Column, input.Database, input.Table)
+	}
+	if !isVectorSourceType(col.DataType) {
+		return nil, VectorStatsOutput{}, fmt.Errorf("column %s is %s; expected VECTOR, or JSON or text holding arrays of numbers", input.Column, col.ColumnType)
+	}
+
+	sample, err := sampleVectors(ctx, db, dbName, tableName, colName, col, sampleSize)
+	if err != nil {
+		return nil, VectorStatsOutput{}, err
+	}
+
+	out := VectorStatsOutput{
+		Column:          input.Column,
+		ColumnType:      col.ColumnType,
+		SampledRows:     sample.rows,
+		Nulls:           sample.nulls,
+		Invalid:         sample.invalid,
+		DimensionCounts: dimensionCounts(sample.vectors),
+		Warnings:        []string{},
+	}
+	if sample.rows > 0 {
+		out.NullRatio = roundTo(float64(sample.nulls)/float64(sample.rows), 4)
+	}
+	if len(sample.vectors) == 0 {
+		out.Warnings = append(out.Warnings, "no vectors found in the sample")
+		return nil, out, nil
+	}
+	out.Dimensions = out.DimensionCounts[0].Dimensions
+	out.DimensionMismatches = len(sample.vectors) - out.DimensionCounts[0].Count
+
+	norms := make([]float64, len(sample.vectors))
+	var sum, sumSq float64
+	unit := 0
+	out.Norms.Min = math.Inf(1)
+	for i, v := range sample.vectors {
+		n := vectorNorm(v)
+		norms[i] = n
+		sum += n
+		sumSq += n * n
+		out.Norms.Min = math.Min(out.Norms.Min, n)
+		out.Norms.Max = math.Max(out.Norms.Max, n)
+		if n == 0 {
+			out.ZeroVectors++
+		}
+		if math.Abs(n-1) <= normalizedTolerance {
+			unit++
+		}
+	}
+	count := float64(len(norms))
+	mean := sum / count
+	out.Norms.Mean = roundTo(mean, 6)
+	out.Norms.StdDev = roundTo(math.Sqrt(math.Max(sumSq/count-mean*mean, 0)), 6)
+	out.Norms.Min = roundTo(out.Norms.Min, 6)
+	out.Norms.Max = roundTo(out.Norms.Max, 6)
+	out.NormalizedRatio = roundTo(float64(unit)/count, 4)
+	out.Normalized = out.NormalizedRatio >= normalizedRatio
+
+	// Only vectors of the common dimension can be compared.
+	var compare [][]float64
+	var compareNorms []float64
+	for i, v := range sample.vectors {
+		if len(v) == out.Dimensions && len(compare) < maxDuplicateCompare {
+			compare = append(compare, v)
+			compareNorms = append(compareNorms, norms[i])
+		}
+	}
+	var withDuplicate int
+	out.NearDuplicatePairs, withDuplicate = nearDuplicatePairs(compare, compareNorms, threshold)
+	out.DuplicatesCompared = len(compare)
+	out.NearDuplicateRate = roundTo(float64(withDuplicate)/float64(len(compare)), 4)
+
+	switch {
+	case out.Normalized:
+		out.RecommendedDistance = "cosine"
+		out.RecommendationReason = "vectors are unit length, so cosine, dot and euclidean rank alike; cosine is the portable choice"
+	case out.ZeroVectors > 0:
+		out.RecommendedDistance = "euclidean"
+		out.RecommendationReason = fmt.Sprintf("%d zero vectors have no direction, so cosine distance is undefined for them", out.ZeroVectors)
+	default:
+		out.RecommendedDistance = "cosine"
+		out.RecommendationReason = fmt.Sprintf("norms vary from %g to %g; cosine compares direction only, which is what embedding models encode", out.Norms.Min, out.Norms.Max)
+	}
+
+	if out.Invalid > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%d values are not arrays of numbers", out.Invalid))
+	}
+	if out.DimensionMismatches > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%d vectors do not have %d dimensions; they cannot be compared with the rest", out.DimensionMismatches, out.Dimensions))
+	}
+	if out.ZeroVectors > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%d zero vectors (failed or empty embeddings?)", out.ZeroVectors))
+	}
+	if out.NearDuplicateRate >= highDuplicateRate {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%.1f%% of compared vectors have a near-duplicate (cosine similarity >= %g)", out.NearDuplicateRate*100, threshold))
+	}
+	return nil, out, nil
+}
+
+func toolVectorDDL(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input VectorDDLInput,
+) (*mcp.CallToolResult, VectorDDLOutput, error) {
+	if input.Database == "" || input.Table == "" || input.Column == "" {
+		return nil, VectorDDLOutput{}, fmt.Errorf("database, table, and column are required")
+	}
+	dbName, err := util.QuoteIdent(input.Database)
+	if err != nil {
+		return nil, VectorDDLOutput{}, fmt.Errorf("invalid database name: %w", err)
+	}
+	tableName, err := util.QuoteIdent(input.Table)
+	if err != nil {
+		return nil, VectorDDLOutput{}, fmt.Errorf("invalid table name: %w", err)
+	}
+	colName, err := util.QuoteIdent(input.Column)
+	if err != nil {
+		return nil, VectorDDLOutput{}, fmt.Errorf("invalid column name: %w", err)
+	}
+	target := strings.ToLower(input.Target)
+	if target == "" {
+		target = "mysql"
+	}
+	if target != "mysql" && target != "heatwave" {
+		return nil, VectorDDLOutput{}, fmt.Errorf("target must be mysql or heatwave")
+	}
+	if input.Dimensions < 0 || input.Dimensions > maxVectorDimensions {
+		return nil, VectorDDLOutput{}, fmt.Errorf("dimensions must be between 1 and %d", maxVectorDimensions)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, settingsFrom(ctx).queryTimeout)
+	defer cancel()
+	db := getDB()
+
+	out := VectorDDLOutput{Column: input.Column, Target: target, Statements: []VectorDDLStatement{}, Notes: []string{}}
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return nil, VectorDDLOutput{}, fmt.Errorf("failed to get version: %w", err)
+	}
+	if !isVectorSupported(version) {
+		out.Notes = append(out.Notes, fmt.Sprintf("this server runs MySQL %s; the VECTOR type requires MySQL 9.0+ and these statements will fail here", version))
+	}
+
+	col, found, err := loadVectorColumn(ctx, db, input.Database, input.Table, input.Column)
+	if err != nil {
+		return nil, VectorDDLOutput{}, err
+	}
+	from := dbName + "." + tableName
+	add := func(sql, purpose string) {
+		out.Statements = append(out.Statements, VectorDDLStatement{SQL: sql, Purpose: purpose})
+	}
+
+	switch {
+	case !found:
+		out.Action = "add"
+		if input.Dimensions == 0 {
+			return nil, VectorDDLOutput{}, fmt.Errorf("dimensions is required to add a new column")
+		}
+		out.Dimensions = input.Dimensions
+		add(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s VECTOR(%d) NULL", from, colName, out.Dimensions),
+			"add the vector column")
+
+	case col.DataType == "vector":
+		out.Action = "existing"
+		if m := vectorDimensionsRegex.FindStringSubmatch(col.ColumnType); len(m) > 1 {
+			out.Dimensions, _ = strconv.Atoi(m[1])
+		}
+		if input.Dimensions > 0 && input.Dimensions != out.Dimensions {
+			out.Notes = append(out.Notes, fmt.Sprintf("the column is already %s; changing the dimensions means re-embedding the data into a new column", col.ColumnType))
+		}
+
+	case isVectorSourceType(col.DataType):
+		out.Action = "convert"
+		sample, err := sampleVectors(ctx, db, dbName, tableName, colName, col, ddlDimensionSample)
+		if err != nil {
+			return nil, VectorDDLOutput{}, err
+		}
+		counts := dimensionCounts(sample.vectors)
+		out.Dimensions = input.Dimensions
+		if out.Dimensions == 0 {
+			if len(counts) == 0 {
+				return nil, VectorDDLOutput{}, fmt.Errorf("no vectors found in %s to infer dimensions; set dimensions", input.Column)
+			}
+			out.Dimensions = counts[0].Dimensions
+		}
+		if mismatched := len(sample.vectors) - countWithDimensions(counts, out.Dimensions); mismatched > 0 || sample.invalid > 0 {
+			out.Notes = append(out.Notes, fmt.Sprintf("%d of %d sampled values are not %d-dimensional arrays; STRING_TO_VECTOR will fail on them (see vector_stats)",
+				mismatched+sample.invalid, sample.rows-sample.nulls, out.Dimensions))
+		}
+
+		newCol := input.NewColumn
+		if newCol == "" {
+			newCol = input.Column + "_vec"
+		}
+		newColName, err := util.QuoteIdent(newCol)
+		if err != nil {
+			return nil, VectorDDLOutput{}, fmt.Errorf("invalid new column name: %w", err)
+		}
+		source := colName
+		if col.DataType == "json" {
+			source = "CAST(" + colName + " AS CHAR)"
+		}
+		add(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s VECTOR(%d) NULL", from, newColName, out.Dimensions),
+			"add a vector column next to the source column")
+		add(fmt.Sprintf("UPDATE %s SET %s = STRING_TO_VECTOR(%s) WHERE %s IS NOT NULL", from, newColName, source, colName),
+			"copy the values; on large tables run it in batches of primary key ranges")
+		if input.Replace {
+			add(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s, RENAME COLUMN %s TO %s", from, colName, newColName, colName),
+				"replace the source column once the copy is verified")
+			if !col.Nullable {
+				add(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s VECTOR(%d) NOT NULL", from, colName, out.Dimensions),
+					"restore NOT NULL from the source column")
+			}
+		}
+
+	default:
+		return nil, VectorDDLOutput{}, fmt.Errorf("column %s is %s; only JSON and text columns holding arrays of numbers can be converted", input.Column, col.ColumnType)
+	}
+
+	if target == "heatwave" {
+		add(fmt.Sprintf("ALTER TABLE %s SECONDARY_ENGINE = RAPID", from),
+			"use HeatWave as the secondary engine")
+		add(fmt.Sprintf("ALTER TABLE %s SECONDARY_LOAD", from),
+			"load the table into HeatWave, where DISTANCE() searches run in memory")
+		out.Notes = append(out.Notes, "reload the table (SECONDARY_UNLOAD, SECONDARY_LOAD) after bulk vector updates if HeatWave change propagation lags")
+	} else {
+		out.Notes = append(out.Notes,
+			"VECTOR columns cannot be part of a regular index on MySQL; searches scan the table, so narrow them with filters",
+			"DISTANCE(), used by vector_search, is provided by HeatWave MySQL; choose target heatwave to offload searches")
+	}
+	out.Notes = append(out.Notes, "review-only: nothing was executed")
+	return nil, out, nil
+}
+
+// countWithDimensions returns the count for dims in a dimensionCounts list.
+func countWithDimensions(counts []DimensionCount, dims int) int {
+	for _, c := range counts {
+		if c.Dimensions == dims {
+			return c.Count
+		}
+	}
+	return 0
+}
@@ -0,0 +1,206 @@
+// cmd/mysql-mcp-server/tools_vector_test.go
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func expectVectorColumn(mock sqlmock.Sqlmock, column, dataType, columnType, nullable string) {
+	mock.ExpectQuery("information_schema.COLUMNS").
+		WithArgs("shop", "docs", column).
+		WillReturnRows(sqlmock.NewRows([]string{"DATA_TYPE", "COLUMN_TYPE", "IS_NULLABLE"}).
+			AddRow(dataType, columnType, nullable))
+}
+
+func TestToolVectorStats(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectVectorColumn(mock, "embedding", "json", "json", "YES")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `embedding` FROM `shop`.`docs` LIMIT 1000")).
+		WillReturnRows(sqlmock.NewRows([]string{"embedding"}).
+			AddRow("[1, 0]").
+			AddRow("[1, 0]").
+			AddRow("[0, 1]").
+			AddRow("[0.6, 0.8]").
+			AddRow("oops").
+			AddRow(nil).
+			AddRow("[1, 0, 0]"))
+
+	_, out, err := toolVectorStats(context.Background(), &mcp.CallToolRequest{}, VectorStatsInput{
+		Database: "shop", Table: "docs", Column: "embedding",
+	})
+	if err != nil {
+		t.Fatalf("toolVectorStats failed: %v", err)
+	}
+
+	if out.SampledRows != 7 || out.Nulls != 1 || out.Invalid != 1 || out.NullRatio != 0.1429 {
+		t.Errorf("unexpected counts: %+v", out)
+	}
+	if out.Dimensions != 2 || out.DimensionMismatches != 1 || len(out.DimensionCounts) != 2 {
+		t.Errorf("unexpected dimensions: %d, %d mismatches, %v", out.Dimensions, out.DimensionMismatches, out.DimensionCounts)
+	}
+	if !out.Normalized || out.NormalizedRatio != 1 || out.Norms.Mean != 1 || out.Norms.StdDev != 0 {
+		t.Errorf("expected unit-length vectors: %+v", out.Norms)
+	}
+	// The two [1, 0] rows are the only near-duplicates among 4 comparable vectors.
+	if out.DuplicatesCompared != 4 || out.NearDuplicatePairs != 1 || out.NearDuplicateRate != 0.5 {
+		t.Errorf("unexpected duplicates: %d compared, %d pairs, rate %v", out.DuplicatesCompared, out.NearDuplicatePairs, out.NearDuplicateRate)
+	}
+	if out.RecommendedDistance != "cosine" {
+		t.Errorf("expected cosine, got %s", out.RecommendedDistance)
+	}
+	warnings := strings.Join(out.Warnings, "\n")
+	for _, want := range []string{"1 values are not arrays", "1 vectors do not have 2 dimensions", "near-duplicate"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("expected a warning containing %q, got %v", want, out.Warnings)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolVectorStatsZeroVectors(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectVectorColumn(mock, "embedding", "vector", "vector(2)", "YES")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT VECTOR_TO_STRING(`embedding`) FROM `shop`.`docs` LIMIT 50")).
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).
+			AddRow("[3.00000e+00,4.00000e+00]").
+			AddRow("[0.00000e+00,0.00000e+00]"))
+
+	_, out, err := toolVectorStats(context.Background(), &mcp.CallToolRequest{}, VectorStatsInput{
+		Database: "shop", Table: "docs", Column: "embedding", SampleSize: 50,
+	})
+	if err != nil {
+		t.Fatalf("toolVectorStats failed: %v", err)
+	}
+	if out.Normalized || out.ZeroVectors != 1 || out.Norms.Min != 0 || out.Norms.Max != 5 {
+		t.Errorf("unexpected norms: %+v zero=%d", out.Norms, out.ZeroVectors)
+	}
+	if out.RecommendedDistance != "euclidean" {
+		t.Errorf("expected euclidean for zero vectors, got %s", out.RecommendedDistance)
+	}
+
+	expectVectorColumn(mock, "id", "int", "int", "NO")
+	if _, _, err := toolVectorStats(context.Background(), &mcp.CallToolRequest{}, VectorStatsInput{
+		Database: "shop", Table: "docs", Column: "id",
+	}); err == nil || !strings.Contains(err.Error(), "expected VECTOR") {
+		t.Errorf("expected a column type error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolVectorDDL(t *testing.T) {
+	mock, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	expectVersion := func(v string) {
+		mock.ExpectQuery("SELECT VERSION").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(v))
+	}
+	sqls := func(out VectorDDLOutput) string {
+		var s []string
+		for _, st := range out.Statements {
+			s = append(s, st.SQL)
+		}
+		return strings.Join(s, ";\n")
+	}
+
+	// A new column needs explicit dimensions.
+	expectVersion("9.1.0")
+	mock.ExpectQuery("information_schema.COLUMNS").WillReturnRows(sqlmock.NewRows([]string{"DATA_TYPE", "COLUMN_TYPE", "IS_NULLABLE"}))
+	_, out, err := toolVectorDDL(context.Background(), &mcp.CallToolRequest{}, VectorDDLInput{
+		Database: "shop", Table: "docs", Column: "embedding", Dimensions: 768,
+	})
+	if err != nil {
+		t.Fatalf("toolVectorDDL failed: %v", err)
+	}
+	if out.Action != "add" || sqls(out) != "ALTER TABLE `shop`.`docs` ADD COLUMN `embedding` VECTOR(768) NULL" {
+		t.Errorf("unexpected add proposal: %s\n%s", out.Action, sqls(out))
+	}
+
+	// Converting a JSON column infers the dimensions from the data.
+	expectVersion("9.2.0-cloud")
+	expectVectorColumn(mock, "emb_json", "json", "json", "NO")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `emb_json` FROM `shop`.`docs` LIMIT 100")).
+		WillReturnRows(sqlmock.NewRows([]string{"emb_json"}).AddRow("[1, 2, 3]").AddRow("[4, 5, 6]").AddRow("[7, 8]"))
+	_, out, err = toolVectorDDL(context.Background(), &mcp.CallToolRequest{}, VectorDDLInput{
+		Database: "shop", Table: "docs", Column: "emb_json", Target: "heatwave", Replace: true,
+	})
+	if err != nil {
+		t.Fatalf("toolVectorDDL failed: %v", err)
+	}
+	want := strings.Join([]string{
+		"ALTER TABLE `shop`.`docs` ADD COLUMN `emb_json_vec` VECTOR(3) NULL",
+		"UPDATE `shop`.`docs` SET `emb_json_vec` = STRING_TO_VECTOR(CAST(`emb_json` AS CHAR)) WHERE `emb_json` IS NOT NULL",
+		"ALTER TABLE `shop`.`docs` DROP COLUMN `emb_json`, RENAME COLUMN `emb_json_vec` TO `emb_json`",
+		"ALTER TABLE `shop`.`docs` MODIFY COLUMN `emb_json` VECTOR(3) NOT NULL",
+		"ALTER TABLE `shop`.`docs` SECONDARY_ENGINE = RAPID",
+		"ALTER TABLE `shop`.`docs` SECONDARY_LOAD",
+	}, ";\n")
+	if out.Action != "convert" || out.Dimensions != 3 || sqls(out) != want {
+		t.Errorf("unexpected convert proposal: %s %d\n%s", out.Action, out.Dimensions, sqls(out))
+	}
+	if !strings.Contains(strings.Join(out.Notes, "\n"), "1 of 3 sampled values are not 3-dimensional") {
+		t.Errorf("expected a note on the mismatched value, got %v", out.Notes)
+	}
+
+	// Existing VECTOR columns only get target statements; old servers get a note.
+	expectVersion("8.0.36")
+	expectVectorColumn(mock, "embedding", "vector", "vector(384)", "YES")
+	_, out, err = toolVectorDDL(context.Background(), &mcp.CallToolRequest{}, VectorDDLInput{
+		Database: "shop", Table: "docs", Column: "embedding",
+	})
+	if err != nil {
+		t.Fatalf("toolVectorDDL failed: %v", err)
+	}
+	if out.Action != "existing" || out.Dimensions != 384 || len(out.Statements) != 0 {
+		t.Errorf("unexpected existing proposal: %+v", out)
+	}
+	if !strings.Contains(strings.Join(out.Notes, "\n"), "requires MySQL 9.0+") {
+		t.Errorf("expected a version note, got %v", out.Notes)
+	}
+
+	expectVersion("9.1.0")
+	expectVectorColumn(mock, "blob_col", "blob", "blob", "YES")
+	if _, _, err := toolVectorDDL(context.Background(), &mcp.CallToolRequest{}, VectorDDLInput{
+		Database: "shop", Table: "docs", Column: "blob_col",
+	}); err == nil || !strings.Contains(err.Error(), "can be converted") {
+		t.Errorf("expected a conversion error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestToolVectorDDLValidation(t *testing.T) {
+	_, cleanup := setupExtendedMockDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		input VectorDDLInput
+		want  string
+	}{
+		{VectorDDLInput{Database: "shop", Table: "docs"}, "column are required"},
+		{VectorDDLInput{Database: "shop", Table: "docs", Column: "v", Target: "oracle"}, "target must be"},
+		{VectorDDLInput{Database: "shop", Table: "docs", Column: "v", Dimensions: 20000}, "dimensions must be"},
+	}
+	for _, tt := range tests {
+		if _, _, err := toolVectorDDL(context.Background(), &mcp.CallToolRequest{}, tt.input); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+}
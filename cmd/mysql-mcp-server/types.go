@@ -49,11 +49,13 @@ type RunQueryInput struct {
 	SQL      string `json:"sql" jsonschema:"SQL query to execute; must start with SELECT, SHOW, DESCRIBE, or EXPLAIN"`
 	MaxRows  *int   `json:"max_rows,omitempty" jsonschema:"optional row limit overriding the default max rows"`
 	Database string `json:"database,omitempty" jsonschema:"optional database name to USE before running the query"`
+	Lint     bool   `json:"lint,omitempty" jsonschema:"attach query anti-pattern warnings to the result (always on when enabled in the server config)"`
 }
 
 type QueryResult struct {
-	Columns []string        `json:"columns" jsonschema:"column names"`
-	Rows    [][]interface{} `json:"rows" jsonschema:"rows of values"`
+	Columns      []string        `json:"columns" jsonschema:"column names"`
+	Rows         [][]interface{} `json:"rows" jsonschema:"rows of values"`
+	LintWarnings []LintFinding   `json:"lint_warnings,omitempty" jsonschema:"query anti-patterns found by the linter"`
 }
 
 type PingInput struct{}
@@ -83,10 +85,16 @@ type ServerInfoOutput struct {
 type ListConnectionsInput struct{}
 
 type ConnectionInfo struct {
-	Name        string `json:"name" jsonschema:"connection name"`
-	DSN         string `json:"dsn" jsonschema:"masked DSN (password hidden)"`
-	Description string `json:"description,omitempty" jsonschema:"connection description"`
-	Active      bool   `json:"active" jsonschema:"true if this is the active connection"`
+	Name           string `json:"name" jsonschema:"connection name"`
+	DSN            string `json:"dsn" jsonschema:"masked DSN (password hidden)"`
+	Description    string `json:"description,omitempty" jsonschema:"connection description"`
+	Active         bool   `json:"active" jsonschema:"true if this is the active connection"`
+	State          string `json:"state" jsonschema:"connected, degraded (not reachable; retried in the background) or failed (invalid or rejected by the server)"`
+	LastError      string `json:"last_error,omitempty" jsonschema:"last connection error of a check or tool call"`
+	LastSuccess    string `json:"last_success,omitempty" jsonschema:"time of the last successful check (RFC 3339)"`
+	NextRetry      string `json:"next_retry,omitempty" jsonschema:"time of the next background retry of a degraded connection (RFC 3339)"`
+	Breaker        string `json:"breaker" jsonschema:"circuit breaker: closed, open (calls fail fast) or half-open (one trial call allowed)"`
+	UnhealthySince string `json:"unhealthy_since,omitempty" jsonschema:"time the circuit breaker opened (RFC 3339)"`
 }
 
 type ListConnectionsOutput struct {
@@ -105,40 +113,91 @@ type UseConnectionOutput struct {
 	Database string `json:"database,omitempty" jsonschema:"current database of the connection"`
 }
 
+// ===== Cache Tool Types =====
+
+type RefreshCacheInput struct {
+	Connection string `json:"connection,omitempty" jsonschema:"connection whose cached results are dropped (default: all connections, or the active one when database is set)"`
+	Database   string `json:"database,omitempty" jsonschema:"only drop cached results for this database"`
+}
+
+type ToolCacheStats struct {
+	Tool   string `json:"tool" jsonschema:"tool name"`
+	Hits   uint64 `json:"hits" jsonschema:"calls answered from the cache"`
+	Misses uint64 `json:"misses" jsonschema:"cacheable calls that ran against the server"`
+}
+
+type CacheStats struct {
+	Enabled       bool             `json:"enabled" jsonschema:"true if the result cache is enabled"`
+	Entries       int              `json:"entries" jsonschema:"cached results"`
+	Bytes         int64            `json:"bytes" jsonschema:"approximate size of the cached results"`
+	MaxEntries    int              `json:"max_entries,omitempty" jsonschema:"entry limit"`
+	MaxBytes      int64            `json:"max_bytes,omitempty" jsonschema:"size limit in bytes"`
+	TTLSeconds    int              `json:"ttl_seconds,omitempty" jsonschema:"time a result stays cached"`
+	Hits          uint64           `json:"hits" jsonschema:"calls answered from the cache"`
+	Misses        uint64           `json:"misses" jsonschema:"cacheable calls that ran against the server"`
+	HitRatio      float64          `json:"hit_ratio" jsonschema:"hits / (hits + misses)"`
+	Evictions     uint64           `json:"evictions" jsonschema:"results dropped to respect the size limits"`
+	Expirations   uint64           `json:"expirations" jsonschema:"results dropped after the TTL"`
+	Invalidations uint64           `json:"invalidations" jsonschema:"results dropped for schema changes or refresh_cache"`
+	Tools         []ToolCacheStats `json:"tools,omitempty" jsonschema:"hits and misses per tool"`
+}
+
+type RefreshCacheOutput struct {
+	Scope   string     `json:"scope" jsonschema:"what was dropped: all, a connection, or connection/database"`
+	Removed int        `json:"removed" jsonschema:"number of cached results dropped"`
+	Stats   CacheStats `json:"stats" jsonschema:"cache statistics after the refresh"`
+}
+
 // ===== Vector Tool Types (MySQL 9.0+) =====
 
 type VectorSearchInput struct {
-	Database     string    `json:"database" jsonschema:"database name"`
-	Table        string    `json:"table" jsonschema:"table name containing vector column"`
-	Column       string    `json:"column" jsonschema:"name of the vector column"`
-	Query        []float64 `json:"query" jsonschema:"query vector for similarity search"`
-	Limit        int       `json:"limit,omitempty" jsonschema:"max results to return (default: 10)"`
-	Select       string    `json:"select,omitempty" jsonschema:"additional columns to select (comma-separated)"`
-	Where        string    `json:"where,omitempty" jsonschema:"additional WHERE conditions"`
-	DistanceFunc string    `json:"distance_func,omitempty" jsonschema:"distance function: cosine, euclidean, dot (default: cosine)"`
+	Database     string      `json:"database" jsonschema:"database name"`
+	Table        string      `json:"table" jsonschema:"table name containing vector column"`
+	Column       string      `json:"column" jsonschema:"name of the vector column"`
+	Query        []float64   `json:"query,omitempty" jsonschema:"query vector for similarity search"`
+	QueryText    string      `json:"query_text,omitempty" jsonschema:"text to embed server-side with the column's configured model, instead of query"`
+	Queries      [][]float64 `json:"queries,omitempty" jsonschema:"several query vectors searched in one call (max 32); results are returned per query in batches"`
+	Merge        bool        `json:"merge,omitempty" jsonschema:"with queries: dedupe rows across queries and return the merged nearest limit rows in results"`
+	KeyColumns   string      `json:"key_columns,omitempty" jsonschema:"comma-separated columns identifying a row when merging (default: the primary key)"`
+	Limit        int         `json:"limit,omitempty" jsonschema:"max results to return (default: 10); per query with queries"`
+	Select       string      `json:"select,omitempty" jsonschema:"additional columns to select (comma-separated)"`
+	Where        string      `json:"where,omitempty" jsonschema:"additional WHERE conditions"`
+	Filter       interface{} `json:"filter,omitempty" jsonschema:"structured filter instead of where: {column, op, value} or {column, op, values} with op one of =, !=, <, <=, >, >=, like, not_like, in, not_in, between, not_between, is_null, is_not_null; combine with {and: [...]} or {or: [...]}"`
+	DistanceFunc string      `json:"distance_func,omitempty" jsonschema:"distance function: cosine, euclidean, dot (default: cosine)"`
 }
 
 type VectorSearchResult struct {
 	Distance float64                `json:"distance" jsonschema:"distance/similarity score"`
+	Queries  []int                  `json:"queries,omitempty" jsonschema:"indexes of the queries that returned this row, when merging"`
 	Data     map[string]interface{} `json:"data" jsonschema:"row data"`
 }
 
-type VectorSearchOutput struct {
-	Results []VectorSearchResult `json:"results" jsonschema:"search results ordered by similarity"`
+type VectorSearchBatch struct {
+	Query   int                  `json:"query" jsonschema:"index in queries"`
+	Results []VectorSearchResult `json:"results" jsonschema:"results for this query ordered by similarity"`
 	Count   int                  `json:"count" jsonschema:"number of results"`
 }
 
+type VectorSearchOutput struct {
+	Results         []VectorSearchResult `json:"results" jsonschema:"search results ordered by similarity (merged results with queries and merge)"`
+	Batches         []VectorSearchBatch  `json:"batches,omitempty" jsonschema:"per-query results when queries is set without merge"`
+	Count           int                  `json:"count" jsonschema:"number of results"`
+	EmbeddingModel  string               `json:"embedding_model,omitempty" jsonschema:"model query_text was embedded with"`
+	EmbeddingCached bool                 `json:"embedding_cached,omitempty" jsonschema:"true when the query_text embedding came from the cache"`
+}
+
 type VectorInfoInput struct {
 	Database string `json:"database" jsonschema:"database name"`
 	Table    string `json:"table,omitempty" jsonschema:"table name (optional, lists all if empty)"`
 }
 
 type VectorColumnInfo struct {
-	Table      string `json:"table" jsonschema:"table name"`
-	Column     string `json:"column" jsonschema:"column name"`
-	Dimensions int    `json:"dimensions" jsonschema:"vector dimensions"`
-	IndexName  string `json:"index_name,omitempty" jsonschema:"vector index name if exists"`
-	IndexType  string `json:"index_type,omitempty" jsonschema:"vector index type"`
+	Table          string `json:"table" jsonschema:"table name"`
+	Column         string `json:"column" jsonschema:"column name"`
+	Dimensions     int    `json:"dimensions" jsonschema:"vector dimensions"`
+	IndexName      string `json:"index_name,omitempty" jsonschema:"vector index name if exists"`
+	IndexType      string `json:"index_type,omitempty" jsonschema:"vector index type"`
+	EmbeddingModel string `json:"embedding_model,omitempty" jsonschema:"model used to embed query_text for this column, when an embedding provider is configured"`
 }
 
 type VectorInfoOutput struct {
@@ -147,6 +206,111 @@ type VectorInfoOutput struct {
 	MySQLVersion  string             `json:"mysql_version" jsonschema:"MySQL version"`
 }
 
+type HybridSearchInput struct {
+	Database     string      `json:"database" jsonschema:"database name"`
+	Table        string      `json:"table" jsonschema:"table name"`
+	Column       string      `json:"column" jsonschema:"name of the vector column"`
+	TextColumns  string      `json:"text_columns" jsonschema:"comma-separated columns of a FULLTEXT index to MATCH against"`
+	QueryText    string      `json:"query_text" jsonschema:"search text; matched against text_columns and, unless query is set, embedded for the vector search"`
+	Query        []float64   `json:"query,omitempty" jsonschema:"query vector to use instead of embedding query_text"`
+	Limit        int         `json:"limit,omitempty" jsonschema:"max results to return (default: 10)"`
+	Candidates   int         `json:"candidates,omitempty" jsonschema:"candidates fetched from each search before fusion (default: 4x limit, at least 20)"`
+	Select       string      `json:"select,omitempty" jsonschema:"additional columns to select (comma-separated)"`
+	Where        string      `json:"where,omitempty" jsonschema:"additional WHERE conditions applied to both searches"`
+	Filter       interface{} `json:"filter,omitempty" jsonschema:"structured filter applied to both searches, as in vector_search"`
+	DistanceFunc string      `json:"distance_func,omitempty" jsonschema:"distance function: cosine, euclidean, dot (default: cosine)"`
+	TextMode     string      `json:"text_mode,omitempty" jsonschema:"full-text mode: natural or boolean (default: natural)"`
+	Fusion       string      `json:"fusion,omitempty" jsonschema:"how rankings are combined: rrf (reciprocal rank fusion) or weighted (normalized scores) (default: rrf)"`
+	VectorWeight *float64    `json:"vector_weight,omitempty" jsonschema:"weight of the vector ranking between 0 and 1; the text ranking gets the rest (default: 0.5)"`
+	RRFK         int         `json:"rrf_k,omitempty" jsonschema:"rank constant for rrf fusion (default: 60)"`
+	KeyColumns   string      `json:"key_columns,omitempty" jsonschema:"comma-separated columns identifying a row (default: the primary key)"`
+}
+
+type HybridSearchResult struct {
+	Score          float64                `json:"score" jsonschema:"fused score, higher is better"`
+	VectorDistance *float64               `json:"vector_distance,omitempty" jsonschema:"distance from the vector search, if the row was a vector candidate"`
+	VectorRank     int                    `json:"vector_rank,omitempty" jsonschema:"1-based rank in the vector candidates"`
+	TextScore      *float64               `json:"text_score,omitempty" jsonschema:"MATCH relevance, if the row was a full-text candidate"`
+	TextRank       int                    `json:"text_rank,omitempty" jsonschema:"1-based rank in the full-text candidates"`
+	Data           map[string]interface{} `json:"data" jsonschema:"row data"`
+}
+
+type HybridSearchOutput struct {
+	Results          []HybridSearchResult `json:"results" jsonschema:"results ordered by fused score"`
+	Count            int                  `json:"count" jsonschema:"number of results"`
+	Fusion           string               `json:"fusion" jsonschema:"fusion method used"`
+	VectorCandidates int                  `json:"vector_candidates" jsonschema:"rows returned by the vector search"`
+	TextCandidates   int                  `json:"text_candidates" jsonschema:"rows returned by the full-text search"`
+	EmbeddingModel   string               `json:"embedding_model,omitempty" jsonschema:"model query_text was embedded with"`
+	EmbeddingCached  bool                 `json:"embedding_cached,omitempty" jsonschema:"true when the query_text embedding came from the cache"`
+}
+
+type VectorStatsInput struct {
+	Database           string  `json:"database" jsonschema:"database name"`
+	Table              string  `json:"table" jsonschema:"table name"`
+	Column             string  `json:"column" jsonschema:"VECTOR column, or JSON/text column holding arrays of numbers"`
+	SampleSize         int     `json:"sample_size,omitempty" jsonschema:"rows to sample (default: 1000, max: 10000)"`
+	DuplicateThreshold float64 `json:"duplicate_threshold,omitempty" jsonschema:"cosine similarity at or above which two vectors count as near-duplicates (default: 0.995)"`
+}
+
+type DimensionCount struct {
+	Dimensions int `json:"dimensions" jsonschema:"vector length"`
+	Count      int `json:"count" jsonschema:"sampled vectors with this length"`
+}
+
+type VectorNormStats struct {
+	Min    float64 `json:"min" jsonschema:"smallest L2 norm"`
+	Max    float64 `json:"max" jsonschema:"largest L2 norm"`
+	Mean   float64 `json:"mean" jsonschema:"mean L2 norm"`
+	StdDev float64 `json:"stddev" jsonschema:"standard deviation of the L2 norms"`
+}
+
+type VectorStatsOutput struct {
+	Column               string           `json:"column" jsonschema:"column name"`
+	ColumnType           string           `json:"column_type" jsonschema:"column type, e.g. vector(768) or json"`
+	SampledRows          int              `json:"sampled_rows" jsonschema:"rows read"`
+	Nulls                int              `json:"nulls" jsonschema:"NULL values in the sample"`
+	NullRatio            float64          `json:"null_ratio" jsonschema:"share of NULL values in the sample"`
+	Invalid              int              `json:"invalid,omitempty" jsonschema:"values that are not arrays of numbers"`
+	Dimensions           int              `json:"dimensions" jsonschema:"most common vector length"`
+	DimensionCounts      []DimensionCount `json:"dimension_counts" jsonschema:"vectors per length, most common first"`
+	DimensionMismatches  int              `json:"dimension_mismatches" jsonschema:"vectors whose length differs from dimensions"`
+	ZeroVectors          int              `json:"zero_vectors" jsonschema:"vectors with all components zero"`
+	Norms                VectorNormStats  `json:"norms" jsonschema:"L2 norm distribution"`
+	NormalizedRatio      float64          `json:"normalized_ratio" jsonschema:"share of vectors with unit length"`
+	Normalized           bool             `json:"normalized" jsonschema:"true when nearly all vectors have unit length"`
+	DuplicatesCompared   int              `json:"duplicates_compared" jsonschema:"vectors compared pairwise for near-duplicates"`
+	NearDuplicatePairs   int              `json:"near_duplicate_pairs" jsonschema:"pairs at or above duplicate_threshold"`
+	NearDuplicateRate    float64          `json:"near_duplicate_rate" jsonschema:"share of compared vectors with at least one near-duplicate"`
+	RecommendedDistance  string           `json:"recommended_distance,omitempty" jsonschema:"suggested distance_func for vector_search"`
+	RecommendationReason string           `json:"recommendation_reason,omitempty" jsonschema:"why that distance function fits"`
+	Warnings             []string         `json:"warnings" jsonschema:"data quality problems found"`
+}
+
+type VectorDDLInput struct {
+	Database   string `json:"database" jsonschema:"database name"`
+	Table      string `json:"table" jsonschema:"table name"`
+	Column     string `json:"column" jsonschema:"column to convert, or name of the new VECTOR column"`
+	Dimensions int    `json:"dimensions,omitempty" jsonschema:"vector dimensions (required for a new column; inferred from the data when converting)"`
+	Target     string `json:"target,omitempty" jsonschema:"mysql or heatwave (default: mysql)"`
+	NewColumn  string `json:"new_column,omitempty" jsonschema:"name of the VECTOR column a conversion copies into (default: column_vec)"`
+	Replace    bool   `json:"replace,omitempty" jsonschema:"also drop the source column and rename the new one in its place"`
+}
+
+type VectorDDLStatement struct {
+	SQL     string `json:"sql" jsonschema:"statement to run"`
+	Purpose string `json:"purpose" jsonschema:"what the statement does"`
+}
+
+type VectorDDLOutput struct {
+	Action     string               `json:"action" jsonschema:"add (new column), convert (JSON/text column) or existing (already VECTOR)"`
+	Column     string               `json:"column" jsonschema:"column name"`
+	Dimensions int                  `json:"dimensions" jsonschema:"VECTOR dimensions"`
+	Target     string               `json:"target" jsonschema:"mysql or heatwave"`
+	Statements []VectorDDLStatement `json:"statements" jsonschema:"proposed statements, in order; none are executed"`
+	Notes      []string             `json:"notes" jsonschema:"caveats for the target"`
+}
+
 // ===== Extended Tool Types (MYSQL_MCP_EXTENDED=1) =====
 
 type ListIndexesInput struct {
@@ -178,10 +342,80 @@ type ExplainQueryInput struct {
 	SQL      string `json:"sql" jsonschema:"SELECT query to explain"`
 	Database string `json:"database,omitempty" jsonschema:"optional database context"`
 	Format   string `json:"format,omitempty" jsonschema:"output format: traditional, json, tree (default: traditional)"`
+	Analyze  bool   `json:"analyze,omitempty" jsonschema:"run EXPLAIN ANALYZE (executes the query; MySQL 8.0.18+, tree format only)"`
 }
 
 type ExplainQueryOutput struct {
-	Plan []map[string]interface{} `json:"plan" jsonschema:"query execution plan"`
+	Plan     []map[string]interface{} `json:"plan" jsonschema:"raw EXPLAIN result rows"`
+	Format   string                   `json:"format" jsonschema:"format the plan was produced in"`
+	Analyzed bool                     `json:"analyzed,omitempty" jsonschema:"true when the query was executed with EXPLAIN ANALYZE"`
+	Tree     *ExplainNode             `json:"tree,omitempty" jsonschema:"plan normalized into a tree of operations"`
+	Warnings []string                 `json:"warnings,omitempty" jsonschema:"plan warnings such as full scans or temporary tables"`
+}
+
+// ExplainNode is one operation of a normalized query plan. Costs are
+// cumulative (they include the node's children), as MySQL reports them.
+type ExplainNode struct {
+	Operation     string        `json:"operation" jsonschema:"operation, e.g. Table scan on users or nested_loop"`
+	Table         string        `json:"table,omitempty" jsonschema:"table read by this node"`
+	AccessType    string        `json:"access_type,omitempty" jsonschema:"access type (ALL, index, range, ref, eq_ref, const, ...)"`
+	Index         string        `json:"index,omitempty" jsonschema:"index used"`
+	Cost          *float64      `json:"cost,omitempty" jsonschema:"optimizer cost estimate"`
+	RowsEstimated *float64      `json:"rows_estimated,omitempty" jsonschema:"estimated rows"`
+	RowsActual    *float64      `json:"rows_actual,omitempty" jsonschema:"actual rows per loop (analyze only)"`
+	Loops         int64         `json:"loops,omitempty" jsonschema:"number of times the node ran (analyze only)"`
+	ActualTimeMs  *float64      `json:"actual_time_ms,omitempty" jsonschema:"time to return all rows of one loop in milliseconds (analyze only)"`
+	Filtered      *float64      `json:"filtered,omitempty" jsonschema:"estimated percentage of rows kept by the table condition"`
+	Warnings      []string      `json:"warnings,omitempty" jsonschema:"warnings for this node"`
+	Children      []ExplainNode `json:"children,omitempty" jsonschema:"child operations"`
+}
+
+type ComparePlansInput struct {
+	SQL             string `json:"sql" jsonschema:"SELECT query for the left side"`
+	RightSQL        string `json:"right_sql,omitempty" jsonschema:"SELECT query for the right side (default: sql)"`
+	Database        string `json:"database,omitempty" jsonschema:"database context for the left side"`
+	RightDatabase   string `json:"right_database,omitempty" jsonschema:"database context for the right side (default: database)"`
+	Connection      string `json:"connection,omitempty" jsonschema:"connection for the left side (default: active connection)"`
+	RightConnection string `json:"right_connection,omitempty" jsonschema:"connection for the right side (default: connection)"`
+}
+
+type PlanTableAccess struct {
+	Table      string   `json:"table" jsonschema:"table or alias"`
+	AccessType string   `json:"access_type" jsonschema:"access type"`
+	Index      string   `json:"index,omitempty" jsonschema:"index used"`
+	Rows       *float64 `json:"rows,omitempty" jsonschema:"estimated rows examined per scan"`
+}
+
+type PlanSummary struct {
+	Connection   string            `json:"connection" jsonschema:"connection the plan came from"`
+	Database     string            `json:"database,omitempty" jsonschema:"database context"`
+	Cost         *float64          `json:"cost,omitempty" jsonschema:"total query cost estimate"`
+	RowsExamined float64           `json:"rows_examined" jsonschema:"estimated rows examined per scan, summed over tables"`
+	JoinOrder    []string          `json:"join_order" jsonschema:"tables in the order the plan reads them"`
+	Tables       []PlanTableAccess `json:"tables" jsonschema:"access path of each table"`
+	Warnings     []string          `json:"warnings,omitempty" jsonschema:"plan warnings"`
+}
+
+type PlanTableDiff struct {
+	Table       string   `json:"table" jsonschema:"table or alias"`
+	Change      string   `json:"change" jsonschema:"right compared with left: improved, regressed, changed, added or removed"`
+	LeftAccess  string   `json:"left_access,omitempty" jsonschema:"access type on the left"`
+	RightAccess string   `json:"right_access,omitempty" jsonschema:"access type on the right"`
+	LeftIndex   string   `json:"left_index,omitempty" jsonschema:"index used on the left"`
+	RightIndex  string   `json:"right_index,omitempty" jsonschema:"index used on the right"`
+	LeftRows    *float64 `json:"left_rows,omitempty" jsonschema:"estimated rows on the left"`
+	RightRows   *float64 `json:"right_rows,omitempty" jsonschema:"estimated rows on the right"`
+}
+
+type ComparePlansOutput struct {
+	Left             PlanSummary     `json:"left" jsonschema:"left plan"`
+	Right            PlanSummary     `json:"right" jsonschema:"right plan"`
+	Differences      []PlanTableDiff `json:"differences" jsonschema:"tables whose access path, index or row estimate differs"`
+	JoinOrderChanged bool            `json:"join_order_changed" jsonschema:"true when tables present on both sides are joined in a different order"`
+	CostChangePct    *float64        `json:"cost_change_pct,omitempty" jsonschema:"right cost relative to left in percent (negative is cheaper)"`
+	Cheaper          string          `json:"cheaper" jsonschema:"left, right, equal or unknown"`
+	Verdict          string          `json:"verdict" jsonschema:"one-line summary of the comparison"`
+	Notes            []string        `json:"notes,omitempty" jsonschema:"caveats about the comparison"`
 }
 
 type ListViewsInput struct {
@@ -342,3 +576,462 @@ type ServerVariable struct {
 type ListVariablesOutput struct {
 	Variables []ServerVariable `json:"variables" jsonschema:"server configuration variables"`
 }
+
+// ===== Data Exploration Tool Types =====
+
+type ProfileTableInput struct {
+	Database   string `json:"database" jsonschema:"database name"`
+	Table      string `json:"table" jsonschema:"table name"`
+	Columns    string `json:"columns,omitempty" jsonschema:"columns to profile (comma-separated, default: all)"`
+	SampleSize int    `json:"sample_size,omitempty" jsonschema:"row budget for sampling (default: 10000, max: 100000)"`
+	TopN       int    `json:"top_n,omitempty" jsonschema:"number of most frequent values per column (default: 5)"`
+	Buckets    int    `json:"buckets,omitempty" jsonschema:"histogram buckets for numeric columns (default: 10)"`
+}
+
+type ValueFrequency struct {
+	Value interface{} `json:"value" jsonschema:"column value (long values truncated)"`
+	Count int         `json:"count" jsonschema:"occurrences in the sample"`
+}
+
+type LengthStats struct {
+	Min int     `json:"min" jsonschema:"shortest value length"`
+	Max int     `json:"max" jsonschema:"longest value length"`
+	Avg float64 `json:"avg" jsonschema:"average value length"`
+	P50 int     `json:"p50" jsonschema:"median value length"`
+	P90 int     `json:"p90" jsonschema:"90th percentile value length"`
+}
+
+type HistogramBucket struct {
+	Low   float64 `json:"low" jsonschema:"bucket lower bound (inclusive)"`
+	High  float64 `json:"high" jsonschema:"bucket upper bound"`
+	Count int     `json:"count" jsonschema:"values in the bucket"`
+}
+
+type ColumnProfile struct {
+	Name             string            `json:"name" jsonschema:"column name"`
+	Type             string            `json:"type" jsonschema:"column data type"`
+	NullRatio        float64           `json:"null_ratio" jsonschema:"fraction of sampled values that are NULL"`
+	DistinctEstimate int64             `json:"distinct_estimate" jsonschema:"estimated number of distinct values in the table"`
+	Min              interface{}       `json:"min,omitempty" jsonschema:"smallest sampled value"`
+	Max              interface{}       `json:"max,omitempty" jsonschema:"largest sampled value"`
+	TopValues        []ValueFrequency  `json:"top_values,omitempty" jsonschema:"most frequent sampled values"`
+	Lengths          *LengthStats      `json:"lengths,omitempty" jsonschema:"length distribution for string columns"`
+	Histogram        []HistogramBucket `json:"histogram,omitempty" jsonschema:"equi-width histogram for numeric columns"`
+	Masked           bool              `json:"masked,omitempty" jsonschema:"true if values are hidden by a masking rule"`
+}
+
+type ProfileTableOutput struct {
+	Table          string          `json:"table" jsonschema:"profiled table"`
+	EstimatedRows  int64           `json:"estimated_rows" jsonschema:"approximate table row count"`
+	SampledRows    int             `json:"sampled_rows" jsonschema:"rows read for profiling"`
+	SamplingMethod string          `json:"sampling_method" jsonschema:"full_scan, pk_range, index_scan or limit_scan"`
+	Exact          bool            `json:"exact" jsonschema:"true if the sample covered the whole table"`
+	Columns        []ColumnProfile `json:"columns" jsonschema:"per-column profile"`
+}
+
+type SampleRowsInput struct {
+	Database        string `json:"database" jsonschema:"database name"`
+	Table           string `json:"table" jsonschema:"table name"`
+	N               int    `json:"n,omitempty" jsonschema:"number of rows to return (default: 10, capped by max rows)"`
+	Columns         string `json:"columns,omitempty" jsonschema:"columns to return (comma-separated, default: all)"`
+	Strategy        string `json:"strategy,omitempty" jsonschema:"first, random (random primary key range) or recent (newest by timestamp column) (default: first)"`
+	TimestampColumn string `json:"timestamp_column,omitempty" jsonschema:"column ordering the recent strategy (default: first DATETIME/TIMESTAMP column)"`
+	MaxValueLength  int    `json:"max_value_length,omitempty" jsonschema:"truncate longer string values to this many characters (default: 200)"`
+}
+
+type SampleRowsOutput struct {
+	Columns   []string        `json:"columns" jsonschema:"column names"`
+	Rows      [][]interface{} `json:"rows" jsonschema:"sampled rows"`
+	Strategy  string          `json:"strategy" jsonschema:"strategy used to pick rows"`
+	Truncated int             `json:"truncated,omitempty" jsonschema:"number of values truncated to max_value_length"`
+}
+
+type SummarizeDatabaseInput struct {
+	Database  string `json:"database" jsonschema:"database name"`
+	MaxTokens int    `json:"max_tokens,omitempty" jsonschema:"approximate token budget for the summary (default: 4000)"`
+}
+
+type TableSummary struct {
+	Name       string   `json:"name" jsonschema:"table name"`
+	Rows       int64    `json:"rows" jsonschema:"approximate row count"`
+	TotalMB    float64  `json:"total_mb,omitempty" jsonschema:"data plus index size in megabytes"`
+	PrimaryKey []string `json:"primary_key,omitempty" jsonschema:"primary key columns"`
+	KeyColumns []string `json:"key_columns,omitempty" jsonschema:"columns covered by secondary indexes"`
+	References []string `json:"references,omitempty" jsonschema:"outgoing foreign keys as column -> table.column"`
+	Comment    string   `json:"comment,omitempty" jsonschema:"table comment"`
+}
+
+type SummarizeDatabaseOutput struct {
+	Database        string         `json:"database" jsonschema:"database name"`
+	TableCount      int            `json:"table_count" jsonschema:"number of tables in the database"`
+	Tables          []TableSummary `json:"tables" jsonschema:"tables ordered by priority (size and connectivity)"`
+	Compacted       int            `json:"compacted,omitempty" jsonschema:"tables reduced to name, rows and primary key to fit the budget"`
+	Omitted         int            `json:"omitted,omitempty" jsonschema:"lowest-priority tables left out to fit the budget"`
+	EstimatedTokens int            `json:"estimated_tokens" jsonschema:"estimated tokens of this summary"`
+}
+
+// ===== Diagnostics Tool Types =====
+
+type ListProcessesInput struct {
+	User    string `json:"user,omitempty" jsonschema:"only sessions of this user"`
+	Host    string `json:"host,omitempty" jsonschema:"only sessions from this host (client port is ignored)"`
+	DB      string `json:"db,omitempty" jsonschema:"only sessions using this default database"`
+	Command string `json:"command,omitempty" jsonschema:"only sessions in this command, e.g. Query or Sleep"`
+	MinTime int    `json:"min_time,omitempty" jsonschema:"only sessions in their current state for at least this many seconds"`
+	State   string `json:"state,omitempty" jsonschema:"LIKE pattern on the thread state, e.g. %lock%"`
+}
+
+type ProcessInfo struct {
+	ID            int64  `json:"id" jsonschema:"connection id"`
+	User          string `json:"user" jsonschema:"user"`
+	Host          string `json:"host" jsonschema:"client host and port"`
+	DB            string `json:"db,omitempty" jsonschema:"default database"`
+	Command       string `json:"command" jsonschema:"command type"`
+	Time          int64  `json:"time" jsonschema:"seconds in the current state"`
+	State         string `json:"state,omitempty" jsonschema:"thread state"`
+	Statement     string `json:"statement,omitempty" jsonschema:"current statement (truncated)"`
+	TrxID         string `json:"trx_id,omitempty" jsonschema:"InnoDB transaction id"`
+	TrxState      string `json:"trx_state,omitempty" jsonschema:"InnoDB transaction state"`
+	TrxAge        int64  `json:"trx_age,omitempty" jsonschema:"seconds since the transaction started"`
+	TrxRowsLocked int64  `json:"trx_rows_locked,omitempty" jsonschema:"rows locked by the transaction"`
+}
+
+type ListProcessesOutput struct {
+	Processes []ProcessInfo `json:"processes" jsonschema:"sessions ordered by time, longest first"`
+	Source    string        `json:"source" jsonschema:"processlist table that was read"`
+}
+
+type LockWaitsInput struct {
+	MinWait int `json:"min_wait,omitempty" jsonschema:"only waits of at least this many seconds"`
+}
+
+// LockWaitNode is a session in a blocking chain. Root nodes are head blockers;
+// the lock fields of a waiter describe its wait on the parent node.
+type LockWaitNode struct {
+	PID              int64          `json:"pid" jsonschema:"connection id"`
+	Query            string         `json:"query,omitempty" jsonschema:"current statement (truncated)"`
+	Object           string         `json:"object,omitempty" jsonschema:"locked table or object"`
+	Index            string         `json:"index,omitempty" jsonschema:"locked index"`
+	LockType         string         `json:"lock_type,omitempty" jsonschema:"RECORD or TABLE for row locks, object type for metadata locks"`
+	LockMode         string         `json:"lock_mode,omitempty" jsonschema:"lock mode being waited for"`
+	BlockingLockMode string         `json:"blocking_lock_mode,omitempty" jsonschema:"lock mode held by the parent session"`
+	WaitSeconds      int64          `json:"wait_seconds,omitempty" jsonschema:"seconds spent waiting"`
+	Waiters          []LockWaitNode `json:"waiters,omitempty" jsonschema:"sessions waiting on this one"`
+}
+
+type LockWaitsOutput struct {
+	RowLockWaits      []LockWaitNode `json:"row_lock_waits" jsonschema:"InnoDB row lock blocking chains, rooted at head blockers"`
+	MetadataLockWaits []LockWaitNode `json:"metadata_lock_waits" jsonschema:"metadata lock blocking chains, rooted at head blockers"`
+	Source            string         `json:"source" jsonschema:"view or tables the row lock waits were read from"`
+	Warnings          []string       `json:"warnings,omitempty" jsonschema:"parts of the analysis that could not be run"`
+}
+
+type TopQueriesInput struct {
+	OrderBy string `json:"order_by,omitempty" jsonschema:"total_latency, avg_latency, rows_examined_ratio, tmp_disk_tables, no_index_used or errors (default: total_latency)"`
+	Schema  string `json:"schema,omitempty" jsonschema:"only statements run against this schema"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"number of statements to return (default: 10)"`
+	Window  int    `json:"window,omitempty" jsonschema:"seconds to observe; 0 reports totals since the digest table was last reset (max: 300)"`
+}
+
+type QueryDigestInfo struct {
+	Schema            string             `json:"schema,omitempty" jsonschema:"default schema of the statements"`
+	Digest            string             `json:"digest" jsonschema:"statement digest"`
+	DigestText        string             `json:"digest_text" jsonschema:"normalized statement (truncated)"`
+	SampleQuery       string             `json:"sample_query,omitempty" jsonschema:"one concrete statement with this digest"`
+	Calls             int64              `json:"calls" jsonschema:"executions"`
+	TotalLatencyMs    float64            `json:"total_latency_ms" jsonschema:"total execution time in milliseconds"`
+	AvgLatencyMs      float64            `json:"avg_latency_ms" jsonschema:"average execution time in milliseconds"`
+	RowsExamined      int64              `json:"rows_examined" jsonschema:"rows examined"`
+	RowsSent          int64              `json:"rows_sent" jsonschema:"rows sent"`
+	RowsExaminedRatio float64            `json:"rows_examined_ratio" jsonschema:"rows examined per row sent"`
+	TmpDiskTables     int64              `json:"tmp_disk_tables,omitempty" jsonschema:"on-disk temporary tables created"`
+	NoIndexUsed       int64              `json:"no_index_used,omitempty" jsonschema:"executions that did a full scan without an index"`
+	Errors            int64              `json:"errors,omitempty" jsonschema:"executions that raised an error"`
+	Explain           *ExplainQueryInput `json:"explain,omitempty" jsonschema:"ready-to-use explain_query input for the sample statement"`
+}
+
+type TopQueriesOutput struct {
+	Queries []QueryDigestInfo `json:"queries" jsonschema:"statements ranked by order_by"`
+	OrderBy string            `json:"order_by" jsonschema:"ranking metric"`
+	Window  int               `json:"window,omitempty" jsonschema:"observation window in seconds (0: since reset)"`
+}
+
+type InnodbStatusInput struct {
+	Sections string `json:"sections,omitempty" jsonschema:"comma-separated sections: deadlock, buffer_pool, transactions, io, semaphores, log, row_operations (default: all)"`
+}
+
+type InnodbLock struct {
+	Type  string `json:"type" jsonschema:"RECORD or TABLE"`
+	Table string `json:"table" jsonschema:"locked table"`
+	Index string `json:"index,omitempty" jsonschema:"locked index (record locks)"`
+	Mode  string `json:"mode" jsonschema:"lock mode, e.g. X locks rec but not gap"`
+}
+
+type InnodbDeadlockTransaction struct {
+	Number   int          `json:"number" jsonschema:"transaction number within the deadlock report"`
+	TrxID    string       `json:"trx_id" jsonschema:"InnoDB transaction id"`
+	Active   string       `json:"active,omitempty" jsonschema:"how long the transaction had been active"`
+	ThreadID int64        `json:"thread_id,omitempty" jsonschema:"MySQL connection id"`
+	Query    string       `json:"query,omitempty" jsonschema:"statement being executed (truncated)"`
+	Holds    []InnodbLock `json:"holds,omitempty" jsonschema:"locks held"`
+	WaitsFor []InnodbLock `json:"waits_for,omitempty" jsonschema:"lock waited for"`
+}
+
+type InnodbDeadlock struct {
+	Time         string                      `json:"time" jsonschema:"when the deadlock was detected"`
+	Transactions []InnodbDeadlockTransaction `json:"transactions" jsonschema:"transactions involved"`
+	RolledBack   int                         `json:"rolled_back,omitempty" jsonschema:"number of the transaction InnoDB rolled back"`
+}
+
+type InnodbBufferPool struct {
+	SizePages          int64    `json:"size_pages" jsonschema:"buffer pool size in pages"`
+	FreePages          int64    `json:"free_pages" jsonschema:"free pages"`
+	DatabasePages      int64    `json:"database_pages" jsonschema:"pages holding data"`
+	ModifiedPages      int64    `json:"modified_pages" jsonschema:"dirty pages"`
+	HitRate            *float64 `json:"hit_rate,omitempty" jsonschema:"fraction of page requests served from memory (absent when there were no page gets)"`
+	PagesReadPerSec    float64  `json:"pages_read_per_sec" jsonschema:"pages read from disk per second"`
+	PagesWrittenPerSec float64  `json:"pages_written_per_sec" jsonschema:"pages written per second"`
+}
+
+type InnodbTransactions struct {
+	TrxIDCounter       int64 `json:"trx_id_counter" jsonschema:"next transaction id"`
+	HistoryListLength  int64 `json:"history_list_length" jsonschema:"undo log entries not yet purged"`
+	ActiveTransactions int   `json:"active_transactions" jsonschema:"transactions listed as ACTIVE"`
+}
+
+type InnodbIO struct {
+	PendingReads           int64   `json:"pending_reads" jsonschema:"pending asynchronous reads"`
+	PendingWrites          int64   `json:"pending_writes" jsonschema:"pending asynchronous writes"`
+	PendingLogFsyncs       int64   `json:"pending_log_fsyncs" jsonschema:"pending redo log fsyncs"`
+	PendingBufferPoolFsync int64   `json:"pending_buffer_pool_fsyncs" jsonschema:"pending data file fsyncs"`
+	ReadsPerSec            float64 `json:"reads_per_sec" jsonschema:"OS file reads per second"`
+	WritesPerSec           float64 `json:"writes_per_sec" jsonschema:"OS file writes per second"`
+	FsyncsPerSec           float64 `json:"fsyncs_per_sec" jsonschema:"fsyncs per second"`
+}
+
+type InnodbSemaphores struct {
+	ReservationCount int64    `json:"reservation_count" jsonschema:"OS wait array reservation count"`
+	SignalCount      int64    `json:"signal_count" jsonschema:"OS wait array signal count"`
+	Waits            []string `json:"waits,omitempty" jsonschema:"threads currently waiting on a semaphore"`
+}
+
+type InnodbLog struct {
+	SequenceNumber   int64 `json:"sequence_number" jsonschema:"current log sequence number (LSN)"`
+	FlushedUpTo      int64 `json:"flushed_up_to" jsonschema:"LSN flushed to disk"`
+	PagesFlushedUpTo int64 `json:"pages_flushed_up_to" jsonschema:"LSN up to which dirty pages are flushed"`
+	LastCheckpoint   int64 `json:"last_checkpoint" jsonschema:"LSN of the last checkpoint"`
+	CheckpointAge    int64 `json:"checkpoint_age" jsonschema:"bytes of redo since the last checkpoint"`
+}
+
+type InnodbRowOperations struct {
+	QueriesInside  int64   `json:"queries_inside" jsonschema:"queries executing inside InnoDB"`
+	QueriesInQueue int64   `json:"queries_in_queue" jsonschema:"queries waiting to enter InnoDB"`
+	ReadViews      int64   `json:"read_views" jsonschema:"open read views"`
+	InsertsPerSec  float64 `json:"inserts_per_sec" jsonschema:"rows inserted per second"`
+	UpdatesPerSec  float64 `json:"updates_per_sec" jsonschema:"rows updated per second"`
+	DeletesPerSec  float64 `json:"deletes_per_sec" jsonschema:"rows deleted per second"`
+	ReadsPerSec    float64 `json:"reads_per_sec" jsonschema:"rows read per second"`
+}
+
+type InnodbStatusOutput struct {
+	Time             string               `json:"time,omitempty" jsonschema:"when the status was generated"`
+	AveragesOverSecs int64                `json:"averages_over_secs,omitempty" jsonschema:"interval the per-second averages cover"`
+	Deadlock         *InnodbDeadlock      `json:"deadlock,omitempty" jsonschema:"latest detected deadlock (absent if none since startup)"`
+	BufferPool       *InnodbBufferPool    `json:"buffer_pool,omitempty" jsonschema:"buffer pool usage"`
+	Transactions     *InnodbTransactions  `json:"transactions,omitempty" jsonschema:"transaction and purge state"`
+	IO               *InnodbIO            `json:"io,omitempty" jsonschema:"file I/O"`
+	Semaphores       *InnodbSemaphores    `json:"semaphores,omitempty" jsonschema:"semaphore waits"`
+	Log              *InnodbLog           `json:"log,omitempty" jsonschema:"redo log sequence numbers"`
+	RowOperations    *InnodbRowOperations `json:"row_operations,omitempty" jsonschema:"row operation rates"`
+}
+
+type ReplicationStatusInput struct {
+	AllConnections bool `json:"all_connections,omitempty" jsonschema:"report every configured connection instead of only the active one"`
+}
+
+type ReplicationChannel struct {
+	Channel          string   `json:"channel" jsonschema:"replication channel name (empty for the default channel)"`
+	SourceHost       string   `json:"source_host" jsonschema:"source host"`
+	SourcePort       int64    `json:"source_port" jsonschema:"source port"`
+	IORunning        string   `json:"io_running" jsonschema:"receiver (IO) thread state: Yes, No or Connecting"`
+	SQLRunning       string   `json:"sql_running" jsonschema:"applier (SQL) thread state: Yes or No"`
+	SQLState         string   `json:"sql_state,omitempty" jsonschema:"what the applier thread is doing"`
+	LagSeconds       *int64   `json:"lag_seconds" jsonschema:"seconds behind the source (null when the applier is not running)"`
+	LastIOError      string   `json:"last_io_error,omitempty" jsonschema:"last receiver error"`
+	LastSQLError     string   `json:"last_sql_error,omitempty" jsonschema:"last applier error"`
+	WorkerErrors     []string `json:"worker_errors,omitempty" jsonschema:"errors reported by parallel applier workers"`
+	AutoPosition     bool     `json:"auto_position" jsonschema:"GTID auto-positioning enabled"`
+	RetrievedGTIDSet string   `json:"retrieved_gtid_set,omitempty" jsonschema:"GTIDs received from the source"`
+	ExecutedGTIDSet  string   `json:"executed_gtid_set,omitempty" jsonschema:"GTIDs applied on this server (truncated)"`
+	PendingGTIDs     string   `json:"pending_gtids,omitempty" jsonschema:"received but not yet applied GTIDs"`
+	PendingGTIDCount int64    `json:"pending_gtid_count,omitempty" jsonschema:"number of received but not yet applied transactions"`
+	GTIDGaps         string   `json:"gtid_gaps,omitempty" jsonschema:"holes in the executed GTID set"`
+}
+
+type GroupMember struct {
+	ID             string `json:"id" jsonschema:"member server UUID"`
+	Host           string `json:"host" jsonschema:"member host"`
+	Port           int64  `json:"port" jsonschema:"member port"`
+	State          string `json:"state" jsonschema:"ONLINE, RECOVERING, UNREACHABLE, ERROR or OFFLINE"`
+	Role           string `json:"role,omitempty" jsonschema:"PRIMARY or SECONDARY"`
+	Self           bool   `json:"self,omitempty" jsonschema:"true for the server this connection points to"`
+	CertifierQueue int64  `json:"certifier_queue,omitempty" jsonschema:"transactions waiting for conflict detection"`
+	ApplierQueue   int64  `json:"applier_queue,omitempty" jsonschema:"remote transactions waiting to be applied"`
+}
+
+type ServerReplicationStatus struct {
+	Connection   string               `json:"connection" jsonschema:"connection name"`
+	Roles        []string             `json:"roles" jsonschema:"replica, source, group_primary, group_secondary or standalone"`
+	Channels     []ReplicationChannel `json:"channels,omitempty" jsonschema:"replication channels this server replicates from"`
+	Replicas     []string             `json:"replicas,omitempty" jsonschema:"replicas registered with this server"`
+	GroupMembers []GroupMember        `json:"group_members,omitempty" jsonschema:"Group Replication / InnoDB Cluster members"`
+	Warnings     []string             `json:"warnings,omitempty" jsonschema:"status sources that could not be read"`
+	Error        string               `json:"error,omitempty" jsonschema:"why the server could not be inspected"`
+}
+
+type ReplicationStatusOutput struct {
+	Servers []ServerReplicationStatus `json:"servers" jsonschema:"replication status per connection"`
+}
+
+// ===== Advisor Tool Types =====
+
+type IndexAdvisorInput struct {
+	Database string `json:"database" jsonschema:"database to analyze"`
+	Table    string `json:"table,omitempty" jsonschema:"limit the analysis to one table"`
+	Checks   string `json:"checks,omitempty" jsonschema:"comma-separated checks: unused, redundant, missing (default: all)"`
+}
+
+type IndexSuggestion struct {
+	Table            string   `json:"table" jsonschema:"table the suggestion applies to"`
+	Index            string   `json:"index" jsonschema:"existing index to drop, or proposed name of the index to add"`
+	Columns          []string `json:"columns" jsonschema:"index columns in order"`
+	Reason           string   `json:"reason" jsonschema:"why the change is suggested"`
+	CoveredBy        string   `json:"covered_by,omitempty" jsonschema:"index that makes a redundant index unnecessary"`
+	Digests          []string `json:"digests,omitempty" jsonschema:"statement digests that would use a missing index"`
+	SizeBytes        int64    `json:"size_bytes,omitempty" jsonschema:"on-disk size of an existing index"`
+	Benefit          string   `json:"benefit" jsonschema:"estimated benefit: high, medium or low"`
+	EstimatedBenefit string   `json:"estimated_benefit" jsonschema:"what the change is expected to save"`
+	DDL              string   `json:"ddl" jsonschema:"statement to review and run manually; never executed by the tool"`
+}
+
+type IndexAdvisorOutput struct {
+	Database       string            `json:"database" jsonschema:"database analyzed"`
+	Unused         []IndexSuggestion `json:"unused,omitempty" jsonschema:"indexes not used since statistics were last reset"`
+	Redundant      []IndexSuggestion `json:"redundant,omitempty" jsonschema:"indexes duplicated by or a left prefix of another index"`
+	Missing        []IndexSuggestion `json:"missing,omitempty" jsonschema:"indexes that would avoid frequent full scans"`
+	StatsSinceSecs int64             `json:"stats_since_secs,omitempty" jsonschema:"server uptime, i.e. how much history the usage statistics cover"`
+	Warnings       []string          `json:"warnings,omitempty" jsonschema:"checks that could not be completed and caveats"`
+}
+
+type LintQueryInput struct {
+	SQL      string `json:"sql" jsonschema:"SQL statement to check; it is parsed but never executed"`
+	Database string `json:"database,omitempty" jsonschema:"default database for unqualified table names (used to look up column types and indexes)"`
+}
+
+type LintFinding struct {
+	Rule       string `json:"rule" jsonschema:"rule id: select_star, function_on_column, leading_wildcard_like, implicit_conversion, or_across_columns, order_by_without_limit or not_in_nullable_subquery"`
+	Severity   string `json:"severity" jsonschema:"warning or info"`
+	Fragment   string `json:"fragment" jsonschema:"offending part of the statement"`
+	Message    string `json:"message" jsonschema:"what is wrong"`
+	Suggestion string `json:"suggestion" jsonschema:"how to fix it"`
+	Rewrite    string `json:"rewrite,omitempty" jsonschema:"suggested replacement for the fragment or the whole statement"`
+}
+
+type LintQueryOutput struct {
+	Findings []LintFinding `json:"findings" jsonschema:"anti-patterns found (empty when the statement looks fine)"`
+}
+
+type ConfigAdvisorInput struct {
+	MemoryBytes int64  `json:"memory_bytes,omitempty" jsonschema:"host memory in bytes; enables rules that compare settings with it"`
+	Rules       string `json:"rules,omitempty" jsonschema:"comma-separated rule ids to evaluate (default: all enabled rules)"`
+}
+
+type ConfigFinding struct {
+	Rule           string                 `json:"rule" jsonschema:"rule id"`
+	Priority       string                 `json:"priority" jsonschema:"high, medium or low"`
+	Message        string                 `json:"message" jsonschema:"what the rule found"`
+	Recommendation string                 `json:"recommendation,omitempty" jsonschema:"suggested change"`
+	Evidence       map[string]interface{} `json:"evidence" jsonschema:"values of the variables, status counters and facts the rule checked"`
+}
+
+type SkippedRule struct {
+	Rule   string `json:"rule" jsonschema:"rule id"`
+	Reason string `json:"reason" jsonschema:"why the rule could not be evaluated, e.g. a missing fact"`
+}
+
+type ConfigAdvisorOutput struct {
+	Findings       []ConfigFinding `json:"findings" jsonschema:"matching rules, highest priority first"`
+	RulesEvaluated int             `json:"rules_evaluated" jsonschema:"number of enabled rules evaluated"`
+	Skipped        []SkippedRule   `json:"skipped,omitempty" jsonschema:"rules that could not be evaluated on this server"`
+	Warnings       []string        `json:"warnings,omitempty" jsonschema:"facts that could not be collected"`
+}
+
+type SecurityAuditInput struct {
+	Checks         string `json:"checks,omitempty" jsonschema:"comma-separated checks: anonymous, empty_password, wildcard_host, super, all_privileges, password_expiry, broad_roles (default: all)"`
+	IncludeLocked  bool   `json:"include_locked,omitempty" jsonschema:"also audit locked accounts (system accounts and roles are locked)"`
+	RoleGrantLimit int    `json:"role_grant_limit,omitempty" jsonschema:"flag roles granted to more than this many accounts (default 5)"`
+}
+
+type SecurityFinding struct {
+	Check          string `json:"check" jsonschema:"check that produced the finding"`
+	Severity       string `json:"severity" jsonschema:"high, medium or low"`
+	Account        string `json:"account" jsonschema:"account or role the finding is about, as 'user'@'host'"`
+	Message        string `json:"message" jsonschema:"what was found"`
+	Recommendation string `json:"recommendation" jsonschema:"suggested fix; never executed by the tool"`
+}
+
+type ConnectionPrivileges struct {
+	Connection     string   `json:"connection" jsonschema:"configured connection name"`
+	Account        string   `json:"account,omitempty" jsonschema:"account the connection is authenticated as (CURRENT_USER())"`
+	Grants         []string `json:"grants" jsonschema:"SHOW GRANTS output for the account"`
+	Roles          []string `json:"roles,omitempty" jsonschema:"roles granted to the account; their privileges are not expanded"`
+	Excess         []string `json:"excess" jsonschema:"privileges beyond what the server's read-only tools use"`
+	LeastPrivilege bool     `json:"least_privilege" jsonschema:"true when the account holds no excess privileges and no roles"`
+	Error          string   `json:"error,omitempty" jsonschema:"why the privileges could not be read"`
+}
+
+type SecurityAuditOutput struct {
+	Findings        []SecurityFinding      `json:"findings" jsonschema:"account findings, most severe first"`
+	AccountsChecked int                    `json:"accounts_checked" jsonschema:"number of accounts audited"`
+	Connections     []ConnectionPrivileges `json:"connections" jsonschema:"privileges of this server's own connection users"`
+	Warnings        []string               `json:"warnings,omitempty" jsonschema:"checks that could not be completed"`
+}
+
+type CapacityCheckInput struct {
+	Database string `json:"database,omitempty" jsonschema:"database to check (default: all user databases)"`
+	Window   int    `json:"window,omitempty" jsonschema:"seconds between two samples to measure growth; 0 uses the sample kept from an earlier call (max: 300)"`
+}
+
+type AutoIncrementCapacity struct {
+	Database         string   `json:"database" jsonschema:"database name"`
+	Table            string   `json:"table" jsonschema:"table name"`
+	Column           string   `json:"column" jsonschema:"AUTO_INCREMENT column"`
+	ColumnType       string   `json:"column_type" jsonschema:"column type, e.g. int unsigned"`
+	NextValue        uint64   `json:"next_value" jsonschema:"next AUTO_INCREMENT value"`
+	MaxValue         uint64   `json:"max_value" jsonschema:"largest value the column type can hold"`
+	PercentUsed      float64  `json:"percent_used" jsonschema:"share of the type's range already used"`
+	ValuesPerDay     float64  `json:"values_per_day,omitempty" jsonschema:"AUTO_INCREMENT growth per day between the two samples"`
+	DaysToExhaustion *float64 `json:"days_to_exhaustion,omitempty" jsonschema:"projected days until the range is used up, when growth was measured"`
+	Status           string   `json:"status" jsonschema:"ok, warning (50% used or under a year left) or critical (85% used or under 30 days left)"`
+	Recommendation   string   `json:"recommendation,omitempty" jsonschema:"suggested fix for warning and critical columns"`
+}
+
+type IntKeyRisk struct {
+	Database       string  `json:"database" jsonschema:"database name"`
+	Table          string  `json:"table" jsonschema:"table name"`
+	Column         string  `json:"column" jsonschema:"primary key column"`
+	ColumnType     string  `json:"column_type" jsonschema:"column type"`
+	Rows           int64   `json:"rows" jsonschema:"approximate row count"`
+	TotalMB        float64 `json:"total_mb" jsonschema:"data and index size in megabytes"`
+	RowsPerDay     float64 `json:"rows_per_day,omitempty" jsonschema:"approximate row growth per day between the two samples"`
+	Reason         string  `json:"reason" jsonschema:"why the key is at risk"`
+	Recommendation string  `json:"recommendation" jsonschema:"suggested fix; never executed by the tool"`
+}
+
+type CapacityCheckOutput struct {
+	AutoIncrement  []AutoIncrementCapacity `json:"auto_increment" jsonschema:"AUTO_INCREMENT columns, most used first"`
+	IntPrimaryKeys []IntKeyRisk            `json:"int_primary_keys" jsonschema:"integer primary keys narrower than BIGINT on tables that grow fast or are already large"`
+	RateSource     string                  `json:"rate_source,omitempty" jsonschema:"where growth rates come from: window or history (an earlier call)"`
+	RateSecs       int64                   `json:"rate_secs,omitempty" jsonschema:"seconds between the samples growth rates are measured over (the longest, for history)"`
+	Warnings       []string                `json:"warnings,omitempty" jsonschema:"caveats about the projections"`
+}
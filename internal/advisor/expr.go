@@ -0,0 +1,223 @@
+// internal/advisor/expr.go
+package advisor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// MissingFactError is returned when a condition references a fact that was
+// not collected, e.g. a status counter the server does not have.
+type MissingFactError struct {
+	Name string
+}
+
+func (e *MissingFactError) Error() string {
+	return "missing fact " + e.Name
+}
+
+// parseCondition parses a rule condition. Conditions use Go expression
+// syntax: numbers, "strings", facts (max_connections, status.uptime),
+// arithmetic, comparisons, !, && and ||.
+func parseCondition(cond string) (ast.Expr, error) {
+	expr, err := parser.ParseExpr(cond)
+	if err != nil {
+		return nil, err
+	}
+	var bad error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if bad != nil || n == nil {
+			return false
+		}
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			if _, ok := x.X.(*ast.Ident); !ok {
+				bad = fmt.Errorf("unsupported fact reference %s", exprString(x))
+			}
+			return false
+		case *ast.Ident, *ast.BasicLit, *ast.ParenExpr:
+		case *ast.BinaryExpr:
+			switch x.Op {
+			case token.ADD, token.SUB, token.MUL, token.QUO,
+				token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ,
+				token.LAND, token.LOR:
+			default:
+				bad = fmt.Errorf("unsupported operator %s", x.Op)
+			}
+		case *ast.UnaryExpr:
+			if x.Op != token.NOT && x.Op != token.SUB {
+				bad = fmt.Errorf("unsupported operator %s", x.Op)
+			}
+		default:
+			bad = fmt.Errorf("unsupported expression %s", exprString(x.(ast.Expr)))
+		}
+		return bad == nil
+	})
+	if bad != nil {
+		return nil, bad
+	}
+	return expr, nil
+}
+
+// factNames lists the facts a condition references, in order of appearance.
+func factNames(expr ast.Expr) []string {
+	var names []string
+	seen := make(map[string]bool)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		var name string
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			name = exprString(x)
+		case *ast.Ident:
+			if x.Name == "true" || x.Name == "false" {
+				return false
+			}
+			name = x.Name
+		default:
+			return true
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		return false
+	})
+	return names
+}
+
+// eval evaluates a condition against facts. Values are float64, string or bool.
+func eval(expr ast.Expr, facts Facts) (interface{}, error) {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return eval(x.X, facts)
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.INT, token.FLOAT:
+			return strconv.ParseFloat(x.Value, 64)
+		case token.STRING:
+			return strconv.Unquote(x.Value)
+		}
+		return nil, fmt.Errorf("unsupported literal %s", x.Value)
+	case *ast.Ident:
+		switch x.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return facts.lookup(x.Name)
+	case *ast.SelectorExpr:
+		return facts.lookup(exprString(x))
+	case *ast.UnaryExpr:
+		v, err := eval(x.X, facts)
+		if err != nil {
+			return nil, err
+		}
+		if x.Op == token.NOT {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("! needs a boolean, got %v", v)
+			}
+			return !b, nil
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("- needs a number, got %q", v)
+		}
+		return -f, nil
+	case *ast.BinaryExpr:
+		return evalBinary(x, facts)
+	}
+	return nil, fmt.Errorf("unsupported expression %s", exprString(expr))
+}
+
+func evalBinary(x *ast.BinaryExpr, facts Facts) (interface{}, error) {
+	left, err := eval(x.X, facts)
+	if err != nil {
+		return nil, err
+	}
+	// && and || short-circuit, so guards such as "b > 0 && a / b > 0.5" work.
+	if x.Op == token.LAND || x.Op == token.LOR {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs booleans, got %v", x.Op, left)
+		}
+		if (x.Op == token.LAND && !l) || (x.Op == token.LOR && l) {
+			return l, nil
+		}
+		right, err := eval(x.Y, facts)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs booleans, got %v", x.Op, right)
+		}
+		return r, nil
+	}
+
+	right, err := eval(x.Y, facts)
+	if err != nil {
+		return nil, err
+	}
+
+	if ls, ok := left.(string); ok {
+		rs, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %q with %v", ls, right)
+		}
+		switch x.Op {
+		case token.EQL:
+			return strings.EqualFold(ls, rs), nil
+		case token.NEQ:
+			return !strings.EqualFold(ls, rs), nil
+		}
+		return nil, fmt.Errorf("operator %s is not defined for strings", x.Op)
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s needs numbers, got %v and %v", x.Op, left, right)
+	}
+	switch x.Op {
+	case token.ADD:
+		return l + r, nil
+	case token.SUB:
+		return l - r, nil
+	case token.MUL:
+		return l * r, nil
+	case token.QUO:
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case token.EQL:
+		return l == r, nil
+	case token.NEQ:
+		return l != r, nil
+	case token.LSS:
+		return l < r, nil
+	case token.LEQ:
+		return l <= r, nil
+	case token.GTR:
+		return l > r, nil
+	case token.GEQ:
+		return l >= r, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", x.Op)
+}
+
+func exprString(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return exprString(x.X) + "." + x.Sel.Name
+	}
+	return fmt.Sprintf("%T", expr)
+}
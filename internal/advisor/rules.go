@@ -0,0 +1,207 @@
+// internal/advisor/rules.go
+package advisor
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"go/ast"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule priorities, from most to least urgent.
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+var priorityOrder = map[string]int{PriorityHigh: 0, PriorityMedium: 1, PriorityLow: 2}
+
+//go:embed default_rules.yaml
+var defaultRulesYAML []byte
+
+// Rule is one configuration check. When is a condition over facts that is
+// true when the finding applies.
+type Rule struct {
+	ID             string `yaml:"id"`
+	Priority       string `yaml:"priority"`
+	When           string `yaml:"when"`
+	Message        string `yaml:"message"`
+	Recommendation string `yaml:"recommendation"`
+	Disabled       bool   `yaml:"disabled,omitempty"`
+
+	cond ast.Expr
+}
+
+// Facts holds the values rule conditions are evaluated against: global
+// variables by name, status counters as status.<name> and collected facts
+// such as host.<name>. Names are lower case.
+type Facts map[string]interface{}
+
+// Set stores a fact, converting numeric values to float64.
+func (f Facts) Set(name, value string) {
+	if n, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		f[strings.ToLower(name)] = n
+		return
+	}
+	f[strings.ToLower(name)] = value
+}
+
+// SetNumber stores a numeric fact.
+func (f Facts) SetNumber(name string, value float64) {
+	f[strings.ToLower(name)] = value
+}
+
+func (f Facts) lookup(name string) (interface{}, error) {
+	v, ok := f[strings.ToLower(name)]
+	if !ok {
+		return nil, &MissingFactError{Name: name}
+	}
+	return v, nil
+}
+
+// Result is the outcome of evaluating one rule.
+type Result struct {
+	Rule     Rule
+	Matched  bool
+	Evidence map[string]interface{} // referenced facts that are available
+	Err      error                  // set when the rule could not be evaluated
+}
+
+// DefaultRules returns the built-in rule set.
+func DefaultRules() []Rule {
+	rules, err := ParseRules(defaultRulesYAML)
+	if err != nil {
+		panic("advisor: invalid built-in rules: " + err.Error())
+	}
+	return rules
+}
+
+// ParseRules parses a YAML document with a top-level "rules" list.
+// Disabled entries may omit everything but the id.
+func ParseRules(data []byte) ([]Rule, error) {
+	var doc struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i := range doc.Rules {
+		r := &doc.Rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("rule %d: id is required", i+1)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("rule %s: duplicate id", r.ID)
+		}
+		seen[r.ID] = true
+		if r.Disabled {
+			continue
+		}
+		if r.Priority == "" {
+			r.Priority = PriorityMedium
+		}
+		if _, ok := priorityOrder[r.Priority]; !ok {
+			return nil, fmt.Errorf("rule %s: invalid priority %q (use high, medium or low)", r.ID, r.Priority)
+		}
+		if r.When == "" || r.Message == "" {
+			return nil, fmt.Errorf("rule %s: when and message are required", r.ID)
+		}
+		cond, err := parseCondition(r.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid condition: %w", r.ID, err)
+		}
+		r.cond = cond
+	}
+	return doc.Rules, nil
+}
+
+// LoadRules returns the built-in rules merged with the rules file at path
+// (the built-in rules alone when path is empty). A file rule replaces the
+// built-in rule with the same id, or disables it with "disabled: true";
+// other file rules are added.
+func LoadRules(path string) ([]Rule, error) {
+	rules := DefaultRules()
+	if path == "" {
+		return rules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	custom, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+	return MergeRules(rules, custom), nil
+}
+
+// MergeRules applies overrides to base as described in LoadRules.
+func MergeRules(base, overrides []Rule) []Rule {
+	index := make(map[string]int, len(base))
+	merged := append([]Rule(nil), base...)
+	for i, r := range merged {
+		index[r.ID] = i
+	}
+	for _, r := range overrides {
+		if i, ok := index[r.ID]; ok {
+			if r.Disabled && r.When == "" {
+				merged[i].Disabled = true
+				continue
+			}
+			merged[i] = r
+			continue
+		}
+		index[r.ID] = len(merged)
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Evaluate runs the enabled rules against facts. Results are ordered by
+// priority, then id.
+func Evaluate(rules []Rule, facts Facts) []Result {
+	var results []Result
+	for _, r := range rules {
+		if r.Disabled || r.cond == nil {
+			continue
+		}
+		res := Result{Rule: r, Evidence: make(map[string]interface{})}
+		for _, name := range factNames(r.cond) {
+			if v, err := facts.lookup(name); err == nil {
+				res.Evidence[name] = v
+			}
+		}
+		v, err := eval(r.cond, facts)
+		if err != nil {
+			res.Err = err
+		} else if matched, ok := v.(bool); ok {
+			res.Matched = matched
+		} else {
+			res.Err = fmt.Errorf("condition is not a comparison: %v", v)
+		}
+		results = append(results, res)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		pi, pj := priorityOrder[results[i].Rule.Priority], priorityOrder[results[j].Rule.Priority]
+		if pi != pj {
+			return pi < pj
+		}
+		return results[i].Rule.ID < results[j].Rule.ID
+	})
+	return results
+}
+
+// IsMissingFact reports whether err is caused by a fact that was not collected.
+func IsMissingFact(err error) bool {
+	var mf *MissingFactError
+	return errors.As(err, &mf)
+}
@@ -0,0 +1,155 @@
+package advisor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+	if len(rules) == 0 {
+		t.Fatal("expected built-in rules")
+	}
+	for _, r := range rules {
+		if r.cond == nil || r.Recommendation == "" {
+			t.Errorf("rule %s is incomplete", r.ID)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	facts := Facts{}
+	facts.Set("max_connections", "151")
+	facts.Set("status.Max_used_connections", "150")
+	facts.Set("log_bin", "ON")
+	facts.Set("sync_binlog", "0")
+	facts.Set("binlog_format", "row")
+	facts.Set("innodb_flush_log_at_trx_commit", "1")
+
+	rules, err := ParseRules([]byte(`
+rules:
+  - id: near_limit
+    priority: high
+    when: status.max_used_connections >= 0.85 * max_connections
+    message: near the limit
+  - id: binlog
+    when: log_bin == "on" && sync_binlog != 1
+    message: binlog not durable
+  - id: format
+    priority: low
+    when: binlog_format != "ROW"
+    message: not row
+  - id: memory
+    priority: high
+    when: max_connections * 1048576 > 0.5 * host.memory_bytes
+    message: too many connections for memory
+  - id: guarded
+    when: status.missing > 0 || (max_connections > 0 && -max_connections < 0)
+    message: a missing fact fails the rule even when the other side could decide it
+`))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+
+	results := Evaluate(rules, facts)
+	var got []string
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			got = append(got, r.Rule.ID+":error")
+		case r.Matched:
+			got = append(got, r.Rule.ID+":match")
+		default:
+			got = append(got, r.Rule.ID+":ok")
+		}
+	}
+	// Sorted by priority, then id.
+	if want := "memory:error,near_limit:match,binlog:match,guarded:error,format:ok"; strings.Join(got, ",") != want {
+		t.Errorf("results = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	memory := results[0]
+	if !IsMissingFact(memory.Err) || !strings.Contains(memory.Err.Error(), "host.memory_bytes") {
+		t.Errorf("expected a missing fact error, got %v", memory.Err)
+	}
+	if memory.Evidence["max_connections"] != 151.0 || len(memory.Evidence) != 1 {
+		t.Errorf("unexpected evidence: %v", memory.Evidence)
+	}
+	if ev := results[1].Evidence; ev["status.max_used_connections"] != 150.0 || ev["max_connections"] != 151.0 {
+		t.Errorf("unexpected evidence: %v", ev)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, want string
+	}{
+		{"missing id", "rules:\n  - when: a > 1\n    message: m", "id is required"},
+		{"duplicate", "rules:\n  - {id: a, when: x > 1, message: m}\n  - {id: a, when: x > 1, message: m}", "duplicate id"},
+		{"bad priority", "rules:\n  - {id: a, priority: urgent, when: x > 1, message: m}", "invalid priority"},
+		{"no condition", "rules:\n  - {id: a, message: m}", "when and message are required"},
+		{"syntax", "rules:\n  - {id: a, when: 'x >', message: m}", "invalid condition"},
+		{"function call", "rules:\n  - {id: a, when: 'len(x) > 1', message: m}", "unsupported expression"},
+		{"bitwise", "rules:\n  - {id: a, when: 'x & 1 == 1', message: m}", "unsupported operator &"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadRulesMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	doc := `
+rules:
+  - id: redo_not_durable
+    disabled: true
+  - id: binlog_disabled
+    priority: high
+    when: log_bin == "OFF"
+    message: no binary log
+  - id: custom_max_allowed_packet
+    priority: low
+    when: max_allowed_packet < 67108864
+    message: small packets
+`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	if len(rules) != len(DefaultRules())+1 {
+		t.Errorf("expected one added rule, got %d rules", len(rules))
+	}
+	byID := make(map[string]Rule)
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+	if !byID["redo_not_durable"].Disabled || byID["redo_not_durable"].When == "" {
+		t.Errorf("expected the built-in rule to be kept but disabled: %+v", byID["redo_not_durable"])
+	}
+	if r := byID["binlog_disabled"]; r.Priority != PriorityHigh || r.Message != "no binary log" {
+		t.Errorf("expected the built-in rule to be replaced: %+v", r)
+	}
+
+	facts := Facts{}
+	facts.Set("innodb_flush_log_at_trx_commit", "2")
+	for _, res := range Evaluate(rules, facts) {
+		if res.Rule.ID == "redo_not_durable" {
+			t.Error("disabled rules must not be evaluated")
+		}
+	}
+
+	if _, err := LoadRules(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing rules file")
+	}
+}
@@ -0,0 +1,190 @@
+// internal/cache/cache.go
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Store caches tool results. Entries carry tags (for example a connection
+// or a connection/database pair) so that related results can be dropped
+// together. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the value stored under key, if present and not expired.
+	Get(key string) (interface{}, bool)
+	// Set stores a value of the given approximate size in bytes.
+	Set(key string, value interface{}, size int64, tags ...string)
+	// Delete removes one entry and reports whether it was present.
+	Delete(key string) bool
+	// Invalidate removes every entry carrying tag and returns how many were removed.
+	Invalidate(tag string) int
+	// Purge removes every entry and returns how many were removed.
+	Purge() int
+	// Stats returns a snapshot of the cache counters.
+	Stats() Stats
+}
+
+// Stats is a snapshot of cache counters.
+type Stats struct {
+	Entries       int    `json:"entries"`
+	Bytes         int64  `json:"bytes"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`   // removed to respect the size limits
+	Expirations   uint64 `json:"expirations"` // removed because the TTL passed
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// LRU is an in-memory Store that evicts the least recently used entries
+// once MaxEntries or MaxBytes is exceeded, and expires entries after TTL.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	ttl        time.Duration
+	now        func() time.Time
+
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+	tags  map[string]map[string]struct{} // tag -> keys
+	stats Stats
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	size    int64
+	tags    []string
+	expires time.Time
+}
+
+// NewLRU returns an LRU store. A zero maxEntries, maxBytes or ttl disables
+// that limit.
+func NewLRU(maxEntries int, maxBytes int64, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		now:        time.Now,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements Store.
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.remove(el)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+	return e.value, true
+}
+
+// Set implements Store. A value larger than MaxBytes is not stored.
+func (c *LRU) Set(key string, value interface{}, size int64, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	e := &lruEntry{key: key, value: value, size: size, tags: tags}
+	if c.ttl > 0 {
+		e.expires = c.now().Add(c.ttl)
+	}
+	c.items[key] = c.order.PushFront(e)
+	c.stats.Bytes += size
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.stats.Bytes > c.maxBytes) {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete implements Store.
+func (c *LRU) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if ok {
+		c.remove(el)
+		c.stats.Invalidations++
+	}
+	return ok
+}
+
+// Invalidate implements Store.
+func (c *LRU) Invalidate(tag string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for key := range c.tags[tag] {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+			n++
+		}
+	}
+	c.stats.Invalidations += uint64(n)
+	return n
+}
+
+// Purge implements Store.
+func (c *LRU) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.order.Len()
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.tags = make(map[string]map[string]struct{})
+	c.stats.Bytes = 0
+	c.stats.Invalidations += uint64(n)
+	return n
+}
+
+// Stats implements Store.
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.order.Len()
+	return s
+}
+
+// remove drops an entry and its tag index references. Callers hold c.mu.
+func (c *LRU) remove(el *list.Element) {
+	e := el.Value.(*lruEntry)
+	c.order.Remove(el)
+	delete(c.items, e.key)
+	c.stats.Bytes -= e.size
+	for _, tag := range e.tags {
+		if keys := c.tags[tag]; keys != nil {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
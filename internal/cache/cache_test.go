@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU(2, 0, 0)
+	c.Set("a", 1, 10)
+	c.Set("b", 2, 10)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.Set("c", 3, 10) // evicts b, the least recently used
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if v, ok := c.Get("c"); !ok || v.(int) != 3 {
+		t.Errorf("Get(c) = %v, %v", v, ok)
+	}
+
+	s := c.Stats()
+	if s.Entries != 2 || s.Bytes != 20 || s.Evictions != 1 || s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestLRUMaxBytes(t *testing.T) {
+	c := NewLRU(0, 100, 0)
+	c.Set("a", "x", 60)
+	c.Set("b", "y", 60) // evicts a to stay under 100 bytes
+	c.Set("huge", "z", 200)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a to be evicted")
+	}
+	if _, ok := c.Get("huge"); ok {
+		t.Error("expected an oversized value not to be stored")
+	}
+	if s := c.Stats(); s.Entries != 1 || s.Bytes != 60 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestLRUTTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewLRU(10, 0, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1, 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a before the TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a to expire")
+	}
+	if s := c.Stats(); s.Entries != 0 || s.Expirations != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestLRUInvalidate(t *testing.T) {
+	c := NewLRU(0, 0, 0)
+	c.Set("t1", 1, 1, "prod", "prod/shop")
+	c.Set("t2", 2, 1, "prod", "prod/crm")
+	c.Set("t3", 3, 1, "dev", "dev/shop")
+
+	if n := c.Invalidate("prod/shop"); n != 1 {
+		t.Errorf("Invalidate(prod/shop) = %d, want 1", n)
+	}
+	if n := c.Invalidate("prod"); n != 1 {
+		t.Errorf("Invalidate(prod) = %d, want 1", n)
+	}
+	if _, ok := c.Get("t3"); !ok {
+		t.Error("expected other connections to stay cached")
+	}
+
+	// Replacing an entry drops its old tags.
+	c.Set("t3", 4, 1, "dev")
+	if n := c.Invalidate("dev/shop"); n != 0 {
+		t.Errorf("Invalidate(dev/shop) = %d, want 0", n)
+	}
+	if !c.Delete("t3") || c.Delete("t3") {
+		t.Error("expected Delete to remove t3 once")
+	}
+
+	c.Set("x", 1, 1)
+	c.Set("y", 1, 1)
+	if n := c.Purge(); n != 2 {
+		t.Errorf("Purge() = %d, want 2", n)
+	}
+	if s := c.Stats(); s.Entries != 0 || s.Bytes != 0 || s.Invalidations != 5 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
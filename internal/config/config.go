@@ -19,10 +19,19 @@ const (
 	DefaultConnMaxLifetimeMins = 30
 	DefaultConnMaxIdleTimeMins = 5
 	DefaultPingTimeoutSecs     = 5
+	DefaultHealthCheckSecs     = 30
+	DefaultBreakerCooldownSecs = 30
 	DefaultHTTPPort            = 9306
 	DefaultHTTPRequestTimeoutS = 60
 	DefaultRateLimitRPS        = 100 // requests per second
 	DefaultRateLimitBurst      = 200 // burst size
+	DefaultEmbeddingCacheSize  = 1000
+	DefaultEmbeddingTimeoutS   = 30
+	DefaultCacheTTLSecs        = 300
+	DefaultCacheMaxEntries     = 1000
+	DefaultCacheMaxSizeMB      = 64
+	DefaultConfigReloadSecs    = 5
+	DefaultVaultTimeoutSecs    = 10
 )
 
 // ConnectionConfig represents a single MySQL connection configuration.
@@ -32,6 +41,11 @@ type ConnectionConfig struct {
 	Description string `json:"description,omitempty"`
 	ReadOnly    bool   `json:"read_only,omitempty"`
 	SSL         string `json:"ssl,omitempty"` // "true", "false", "skip-verify", or empty (use DSN as-is)
+	// CacheQueries caches run_query results for this connection when the
+	// result cache is enabled.
+	CacheQueries bool `json:"cache_queries,omitempty"`
+	// ConnectionParams are resolved into DSN by Load.
+	ConnectionParams
 }
 
 // Config holds all configuration for the MySQL MCP server.
@@ -42,6 +56,10 @@ type Config struct {
 	// Query limits
 	MaxRows      int
 	QueryTimeout time.Duration
+	LintQueries  bool // attach anti-pattern warnings to run_query results
+	// DisableRawWhere rejects free-form where clauses in the vector search
+	// tools, leaving only the structured filter.
+	DisableRawWhere bool
 
 	// Connection pool settings
 	MaxOpenConns    int
@@ -49,6 +67,12 @@ type Config struct {
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
 	PingTimeout     time.Duration
+	// HealthCheckInterval is how often connections are pinged in the
+	// background (0 disables the health checks)
+	HealthCheckInterval time.Duration
+	// BreakerCooldown is how long the circuit breaker of an unhealthy
+	// connection rejects calls before letting a trial call through
+	BreakerCooldown time.Duration
 
 	// Feature flags
 	ExtendedMode bool
@@ -71,6 +95,41 @@ type Config struct {
 
 	// Audit logging
 	AuditLogPath string
+
+	// Data masking: column rules whose values are hidden in tool output
+	MaskColumns []string
+
+	// Advisor rules file extending or overriding the built-in config_advisor rules
+	AdvisorRulesFile string
+
+	// Embedding provider for vector_search query_text (disabled when EmbeddingURL is empty)
+	EmbeddingURL       string            // OpenAI-compatible base URL, e.g. http://localhost:11434/v1
+	EmbeddingAPIKey    string            // sent as a bearer token when set
+	EmbeddingModel     string            // model for columns no rule in EmbeddingModels matches
+	EmbeddingModels    map[string]string // column rule (as in MaskColumns) -> model
+	EmbeddingCacheSize int               // number of query embeddings kept in the LRU cache
+	EmbeddingTimeout   time.Duration
+
+	// Result cache for metadata tools (and run_query on connections with
+	// CacheQueries set); disabled by default
+	CacheEnabled    bool
+	CacheTTL        time.Duration
+	CacheMaxEntries int
+	CacheMaxSizeMB  int
+
+	// ConfigReloadInterval is how often the config file is checked for
+	// changes (0 disables polling; SIGHUP still reloads)
+	ConfigReloadInterval time.Duration
+
+	// Secret providers for connection password_secret references. The
+	// secrets file key and the Vault token are read from the environment
+	// (MYSQL_MCP_SECRETS_KEY, VAULT_TOKEN) or the key/token files.
+	SecretsFile    string // AES-256-GCM encrypted file (scheme "file")
+	SecretsKeyFile string
+	VaultAddress   string // Vault-compatible HTTP API (scheme "vault")
+	VaultTokenFile string
+	VaultNamespace string
+	VaultTimeout   time.Duration
 }
 
 // Load reads configuration from config file (if present) and environment variables.
@@ -100,11 +159,23 @@ func Load() (*Config, error) {
 			RateLimitRPS:       float64(DefaultRateLimitRPS),
 			RateLimitBurst:     DefaultRateLimitBurst,
 			TokenModel:         "cl100k_base",
+			EmbeddingCacheSize: DefaultEmbeddingCacheSize,
+			EmbeddingTimeout:   time.Duration(DefaultEmbeddingTimeoutS) * time.Second,
+			CacheTTL:           time.Duration(DefaultCacheTTLSecs) * time.Second,
+			CacheMaxEntries:    DefaultCacheMaxEntries,
+			CacheMaxSizeMB:     DefaultCacheMaxSizeMB,
+
+			ConfigReloadInterval: time.Duration(DefaultConfigReloadSecs) * time.Second,
+			VaultTimeout:         time.Duration(DefaultVaultTimeoutSecs) * time.Second,
+			HealthCheckInterval:  time.Duration(DefaultHealthCheckSecs) * time.Second,
+			BreakerCooldown:      time.Duration(DefaultBreakerCooldownSecs) * time.Second,
 		}
 	}
 
 	// Apply environment variable overrides (env vars take precedence)
-	applyEnvOverrides(cfg)
+	if err := applyEnvOverrides(cfg); err != nil {
+		return nil, err
+	}
 
 	// Load connections from environment (if any defined, they override file config)
 	envConns, err := loadConnections()
@@ -114,18 +185,28 @@ func Load() (*Config, error) {
 	if len(envConns) > 0 {
 		cfg.Connections = envConns
 	}
+	if v := os.Getenv("MYSQL_MCP_CACHE_QUERIES"); v != "" {
+		if err := applyCacheQueries(cfg.Connections, splitList(v)); err != nil {
+			return nil, fmt.Errorf("invalid MYSQL_MCP_CACHE_QUERIES: %w", err)
+		}
+	}
 
 	// Ensure we have at least one connection
 	if len(cfg.Connections) == 0 {
 		return nil, fmt.Errorf("no MySQL connections configured. Set MYSQL_DSN, MYSQL_CONNECTIONS, or use a config file")
 	}
 
+	// Build DSNs from structured fields, password sources and secrets
+	if err := resolveConnections(cfg); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
 // applyEnvOverrides applies environment variable overrides to the config.
 // Only overrides values if the environment variable is explicitly set.
-func applyEnvOverrides(cfg *Config) {
+func applyEnvOverrides(cfg *Config) error {
 	if v := os.Getenv("MYSQL_MAX_ROWS"); v != "" {
 		cfg.MaxRows = getEnvInt("MYSQL_MAX_ROWS", cfg.MaxRows)
 	}
@@ -147,6 +228,22 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("MYSQL_PING_TIMEOUT_SECONDS"); v != "" {
 		cfg.PingTimeout = time.Duration(getEnvInt("MYSQL_PING_TIMEOUT_SECONDS", int(cfg.PingTimeout.Seconds()))) * time.Second
 	}
+	if v := os.Getenv("MYSQL_MCP_HEALTH_CHECK_SECONDS"); v != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid MYSQL_MCP_HEALTH_CHECK_SECONDS: must be a non-negative integer")
+		}
+		cfg.HealthCheckInterval = time.Duration(n) * time.Second
+	}
+	if v := os.Getenv("MYSQL_MCP_BREAKER_COOLDOWN_SECONDS"); v != "" {
+		cfg.BreakerCooldown = time.Duration(getEnvInt("MYSQL_MCP_BREAKER_COOLDOWN_SECONDS", int(cfg.BreakerCooldown.Seconds()))) * time.Second
+	}
+	if v := os.Getenv("MYSQL_MCP_LINT_QUERIES"); v != "" {
+		cfg.LintQueries = getEnvBool("MYSQL_MCP_LINT_QUERIES")
+	}
+	if v := os.Getenv("MYSQL_MCP_DISABLE_RAW_WHERE"); v != "" {
+		cfg.DisableRawWhere = getEnvBool("MYSQL_MCP_DISABLE_RAW_WHERE")
+	}
 	if v := os.Getenv("MYSQL_MCP_EXTENDED"); v != "" {
 		cfg.ExtendedMode = getEnvBool("MYSQL_MCP_EXTENDED")
 	}
@@ -183,6 +280,84 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("MYSQL_MCP_AUDIT_LOG"); v != "" {
 		cfg.AuditLogPath = strings.TrimSpace(v)
 	}
+	if v := os.Getenv("MYSQL_MCP_MASK_COLUMNS"); v != "" {
+		cfg.MaskColumns = splitList(v)
+	}
+	if v := os.Getenv("MYSQL_MCP_ADVISOR_RULES"); v != "" {
+		cfg.AdvisorRulesFile = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("MYSQL_MCP_EMBEDDING_URL"); v != "" {
+		cfg.EmbeddingURL = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("MYSQL_MCP_EMBEDDING_API_KEY"); v != "" {
+		cfg.EmbeddingAPIKey = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("MYSQL_MCP_EMBEDDING_MODEL"); v != "" {
+		cfg.EmbeddingModel = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("MYSQL_MCP_EMBEDDING_MODELS"); v != "" {
+		models, err := parseModelMap(v)
+		if err != nil {
+			return fmt.Errorf("invalid MYSQL_MCP_EMBEDDING_MODELS: %w", err)
+		}
+		cfg.EmbeddingModels = models
+	}
+	if v := os.Getenv("MYSQL_MCP_EMBEDDING_CACHE_SIZE"); v != "" {
+		cfg.EmbeddingCacheSize = getEnvInt("MYSQL_MCP_EMBEDDING_CACHE_SIZE", cfg.EmbeddingCacheSize)
+	}
+	if v := os.Getenv("MYSQL_MCP_EMBEDDING_TIMEOUT_SECONDS"); v != "" {
+		cfg.EmbeddingTimeout = time.Duration(getEnvInt("MYSQL_MCP_EMBEDDING_TIMEOUT_SECONDS", int(cfg.EmbeddingTimeout.Seconds()))) * time.Second
+	}
+	if v := os.Getenv("MYSQL_MCP_CACHE"); v != "" {
+		cfg.CacheEnabled = getEnvBool("MYSQL_MCP_CACHE")
+	}
+	if v := os.Getenv("MYSQL_MCP_CACHE_TTL_SECONDS"); v != "" {
+		cfg.CacheTTL = time.Duration(getEnvInt("MYSQL_MCP_CACHE_TTL_SECONDS", int(cfg.CacheTTL.Seconds()))) * time.Second
+	}
+	if v := os.Getenv("MYSQL_MCP_CACHE_MAX_ENTRIES"); v != "" {
+		cfg.CacheMaxEntries = getEnvInt("MYSQL_MCP_CACHE_MAX_ENTRIES", cfg.CacheMaxEntries)
+	}
+	if v := os.Getenv("MYSQL_MCP_CACHE_MAX_MB"); v != "" {
+		cfg.CacheMaxSizeMB = getEnvInt("MYSQL_MCP_CACHE_MAX_MB", cfg.CacheMaxSizeMB)
+	}
+	if v := os.Getenv("MYSQL_MCP_CONFIG_RELOAD_SECONDS"); v != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid MYSQL_MCP_CONFIG_RELOAD_SECONDS: must be a non-negative integer")
+		}
+		cfg.ConfigReloadInterval = time.Duration(n) * time.Second
+	}
+	if v := os.Getenv("MYSQL_MCP_SECRETS_FILE"); v != "" {
+		cfg.SecretsFile = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("MYSQL_MCP_SECRETS_KEY_FILE"); v != "" {
+		cfg.SecretsKeyFile = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("VAULT_ADDR"); v != "" {
+		cfg.VaultAddress = strings.TrimSpace(v)
+	}
+	if v := os.Getenv("VAULT_NAMESPACE"); v != "" {
+		cfg.VaultNamespace = strings.TrimSpace(v)
+	}
+	return nil
+}
+
+// applyCacheQueries turns on CacheQueries for the named connections; "*"
+// selects every connection.
+func applyCacheQueries(conns []ConnectionConfig, names []string) error {
+	for _, name := range names {
+		found := false
+		for i := range conns {
+			if name == "*" || conns[i].Name == name {
+				conns[i].CacheQueries = true
+				found = true
+			}
+		}
+		if !found && name != "*" {
+			return fmt.Errorf("unknown connection %q", name)
+		}
+	}
+	return nil
 }
 
 // loadConnections loads DSN configurations from environment variables.
@@ -251,6 +426,32 @@ func loadConnections() ([]ConnectionConfig, error) {
 	return configs, nil
 }
 
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseModelMap parses "rule=model" pairs separated by commas, e.g.
+// "docs.body=nomic-embed-text,*.embedding=text-embedding-3-small".
+func parseModelMap(v string) (map[string]string, error) {
+	models := make(map[string]string)
+	for _, pair := range splitList(v) {
+		rule, model, ok := strings.Cut(pair, "=")
+		rule, model = strings.TrimSpace(rule), strings.TrimSpace(model)
+		if !ok || rule == "" || model == "" {
+			return nil, fmt.Errorf("expected rule=model, got %q", pair)
+		}
+		models[rule] = model
+	}
+	return models, nil
+}
+
 // getEnvInt reads an integer from an environment variable with a default value.
 func getEnvInt(key string, def int) int {
 	val := strings.TrimSpace(os.Getenv(key))
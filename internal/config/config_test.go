@@ -26,6 +26,30 @@ func clearEnv() {
 		"MYSQL_HTTP_PORT",
 		"MYSQL_MCP_AUDIT_LOG",
 		"MYSQL_SSL",
+		"MYSQL_MCP_MASK_COLUMNS",
+		"MYSQL_MCP_LINT_QUERIES",
+		"MYSQL_MCP_DISABLE_RAW_WHERE",
+		"MYSQL_MCP_ADVISOR_RULES",
+		"MYSQL_MCP_EMBEDDING_URL",
+		"MYSQL_MCP_EMBEDDING_API_KEY",
+		"MYSQL_MCP_EMBEDDING_MODEL",
+		"MYSQL_MCP_EMBEDDING_MODELS",
+		"MYSQL_MCP_EMBEDDING_CACHE_SIZE",
+		"MYSQL_MCP_EMBEDDING_TIMEOUT_SECONDS",
+		"MYSQL_MCP_CACHE",
+		"MYSQL_MCP_CACHE_TTL_SECONDS",
+		"MYSQL_MCP_CACHE_MAX_ENTRIES",
+		"MYSQL_MCP_CACHE_MAX_MB",
+		"MYSQL_MCP_CACHE_QUERIES",
+		"MYSQL_MCP_CONFIG_RELOAD_SECONDS",
+		"MYSQL_MCP_HEALTH_CHECK_SECONDS",
+		"MYSQL_MCP_BREAKER_COOLDOWN_SECONDS",
+		"MYSQL_MCP_SECRETS_FILE",
+		"MYSQL_MCP_SECRETS_KEY",
+		"MYSQL_MCP_SECRETS_KEY_FILE",
+		"VAULT_ADDR",
+		"VAULT_TOKEN",
+		"VAULT_NAMESPACE",
 	}
 	for _, v := range envVars {
 		os.Unsetenv(v)
@@ -389,3 +413,197 @@ func TestLoadNumberedDSNsWithGlobalSSL(t *testing.T) {
 		t.Errorf("expected SSL 'true' for server1, got '%s'", cfg.Connections[1].SSL)
 	}
 }
+
+func TestLoadMaskColumnsFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+	os.Setenv("MYSQL_MCP_MASK_COLUMNS", "ssn, users.email,, *password* ")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := []string{"ssn", "users.email", "*password*"}
+	if len(cfg.MaskColumns) != len(want) {
+		t.Fatalf("expected %d mask rules, got %v", len(want), cfg.MaskColumns)
+	}
+	for i, rule := range want {
+		if cfg.MaskColumns[i] != rule {
+			t.Errorf("MaskColumns[%d] = %q, want %q", i, cfg.MaskColumns[i], rule)
+		}
+	}
+}
+
+func TestLoadLintQueriesFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.LintQueries {
+		t.Error("expected query linting to be off by default")
+	}
+
+	os.Setenv("MYSQL_MCP_LINT_QUERIES", "1")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.LintQueries {
+		t.Error("expected MYSQL_MCP_LINT_QUERIES=1 to enable query linting")
+	}
+}
+
+func TestLoadDisableRawWhereFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+	os.Setenv("MYSQL_MCP_DISABLE_RAW_WHERE", "1")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.DisableRawWhere {
+		t.Error("expected MYSQL_MCP_DISABLE_RAW_WHERE=1 to disable raw where clauses")
+	}
+}
+
+func TestLoadEmbeddingFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.EmbeddingURL != "" || cfg.EmbeddingCacheSize != DefaultEmbeddingCacheSize || cfg.EmbeddingTimeout != 30*time.Second {
+		t.Errorf("unexpected embedding defaults: %q %d %v", cfg.EmbeddingURL, cfg.EmbeddingCacheSize, cfg.EmbeddingTimeout)
+	}
+
+	os.Setenv("MYSQL_MCP_EMBEDDING_URL", "http://localhost:11434/v1")
+	os.Setenv("MYSQL_MCP_EMBEDDING_MODEL", "nomic-embed-text")
+	os.Setenv("MYSQL_MCP_EMBEDDING_MODELS", "docs.body=mxbai-embed-large, *.title_vec = all-minilm")
+	os.Setenv("MYSQL_MCP_EMBEDDING_CACHE_SIZE", "50")
+	os.Setenv("MYSQL_MCP_EMBEDDING_TIMEOUT_SECONDS", "5")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.EmbeddingURL != "http://localhost:11434/v1" || cfg.EmbeddingModel != "nomic-embed-text" ||
+		cfg.EmbeddingCacheSize != 50 || cfg.EmbeddingTimeout != 5*time.Second {
+		t.Errorf("unexpected embedding settings: %+v", cfg)
+	}
+	if len(cfg.EmbeddingModels) != 2 || cfg.EmbeddingModels["docs.body"] != "mxbai-embed-large" || cfg.EmbeddingModels["*.title_vec"] != "all-minilm" {
+		t.Errorf("unexpected EmbeddingModels: %v", cfg.EmbeddingModels)
+	}
+
+	os.Setenv("MYSQL_MCP_EMBEDDING_MODELS", "docs.body")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for a rule without a model")
+	}
+}
+
+func TestLoadCacheFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+	os.Setenv("MYSQL_DSN_1", "user:pass@tcp(replica:3306)/testdb")
+	os.Setenv("MYSQL_DSN_1_NAME", "replica")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.CacheEnabled || cfg.CacheTTL != 5*time.Minute || cfg.CacheMaxEntries != DefaultCacheMaxEntries || cfg.CacheMaxSizeMB != DefaultCacheMaxSizeMB {
+		t.Errorf("unexpected cache defaults: %v %v %d %d", cfg.CacheEnabled, cfg.CacheTTL, cfg.CacheMaxEntries, cfg.CacheMaxSizeMB)
+	}
+
+	os.Setenv("MYSQL_MCP_CACHE", "1")
+	os.Setenv("MYSQL_MCP_CACHE_TTL_SECONDS", "60")
+	os.Setenv("MYSQL_MCP_CACHE_MAX_ENTRIES", "100")
+	os.Setenv("MYSQL_MCP_CACHE_MAX_MB", "8")
+	os.Setenv("MYSQL_MCP_CACHE_QUERIES", "replica")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.CacheEnabled || cfg.CacheTTL != time.Minute || cfg.CacheMaxEntries != 100 || cfg.CacheMaxSizeMB != 8 {
+		t.Errorf("unexpected cache settings: %+v", cfg)
+	}
+	if cfg.Connections[0].CacheQueries || !cfg.Connections[1].CacheQueries {
+		t.Errorf("expected query caching only on replica: %+v", cfg.Connections)
+	}
+
+	os.Setenv("MYSQL_MCP_CACHE_QUERIES", "*")
+	if cfg, err = Load(); err != nil || !cfg.Connections[0].CacheQueries || !cfg.Connections[1].CacheQueries {
+		t.Errorf("expected * to cache queries on every connection: %v", err)
+	}
+
+	os.Setenv("MYSQL_MCP_CACHE_QUERIES", "missing")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for an unknown connection")
+	}
+}
+
+func TestLoadConfigReloadFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.ConfigReloadInterval != 5*time.Second {
+		t.Errorf("expected a 5s default reload interval, got %v", cfg.ConfigReloadInterval)
+	}
+
+	os.Setenv("MYSQL_MCP_CONFIG_RELOAD_SECONDS", "0")
+	if cfg, err = Load(); err != nil || cfg.ConfigReloadInterval != 0 {
+		t.Errorf("expected 0 to disable polling, got %v, %v", cfg, err)
+	}
+
+	os.Setenv("MYSQL_MCP_CONFIG_RELOAD_SECONDS", "-1")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for a negative interval")
+	}
+}
+
+func TestLoadHealthCheckFromEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("MYSQL_DSN", "user:pass@tcp(localhost:3306)/testdb")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.HealthCheckInterval != 30*time.Second || cfg.BreakerCooldown != 30*time.Second {
+		t.Errorf("unexpected defaults: health check %v, breaker cooldown %v", cfg.HealthCheckInterval, cfg.BreakerCooldown)
+	}
+
+	os.Setenv("MYSQL_MCP_HEALTH_CHECK_SECONDS", "0")
+	os.Setenv("MYSQL_MCP_BREAKER_COOLDOWN_SECONDS", "90")
+	if cfg, err = Load(); err != nil || cfg.HealthCheckInterval != 0 || cfg.BreakerCooldown != 90*time.Second {
+		t.Errorf("unexpected health settings: %+v, %v", cfg, err)
+	}
+
+	os.Setenv("MYSQL_MCP_HEALTH_CHECK_SECONDS", "soon")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for an invalid interval")
+	}
+}
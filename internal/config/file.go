@@ -33,6 +33,21 @@ type FileConfig struct {
 
 	// HTTP/REST API settings
 	HTTP FileHTTPConfig `yaml:"http" json:"http"`
+
+	// Data masking settings
+	Masking FileMaskingConfig `yaml:"masking" json:"masking"`
+
+	// Advisor settings
+	Advisor FileAdvisorConfig `yaml:"advisor" json:"advisor"`
+
+	// Embedding provider settings
+	Embedding FileEmbeddingConfig `yaml:"embedding" json:"embedding"`
+
+	// Result cache settings
+	Cache FileCacheConfig `yaml:"cache" json:"cache"`
+
+	// Secret providers for connection passwords
+	Secrets FileSecretsConfig `yaml:"secrets,omitempty" json:"secrets,omitempty"`
 }
 
 // FileConnectionConfig represents a connection in the config file.
@@ -41,12 +56,19 @@ type FileConnectionConfig struct {
 	Description string `yaml:"description" json:"description"`
 	ReadOnly    bool   `yaml:"read_only" json:"read_only"`
 	SSL         string `yaml:"ssl" json:"ssl"` // "true", "false", "skip-verify", or empty
+	// CacheQueries opts this connection's run_query results into the result cache.
+	CacheQueries bool `yaml:"cache_queries,omitempty" json:"cache_queries,omitempty"`
+	// Structured fields and password sources, used instead of or with the DSN.
+	ConnectionParams `yaml:",inline"`
 }
 
 // FileQueryConfig represents query settings in the config file.
 type FileQueryConfig struct {
-	MaxRows        int `yaml:"max_rows" json:"max_rows"`
-	TimeoutSeconds int `yaml:"timeout_seconds" json:"timeout_seconds"`
+	MaxRows        int  `yaml:"max_rows" json:"max_rows"`
+	TimeoutSeconds int  `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Lint           bool `yaml:"lint" json:"lint"`
+	// DisableRawWhere rejects the free-form where input of vector search tools.
+	DisableRawWhere bool `yaml:"disable_raw_where" json:"disable_raw_where"`
 }
 
 // FilePoolConfig represents connection pool settings in the config file.
@@ -56,6 +78,8 @@ type FilePoolConfig struct {
 	ConnMaxLifetimeMinutes int `yaml:"conn_max_lifetime_minutes" json:"conn_max_lifetime_minutes"`
 	ConnMaxIdleTimeMinutes int `yaml:"conn_max_idle_time_minutes" json:"conn_max_idle_time_minutes"`
 	PingTimeoutSeconds     int `yaml:"ping_timeout_seconds" json:"ping_timeout_seconds"`
+	HealthCheckSeconds     int `yaml:"health_check_seconds,omitempty" json:"health_check_seconds,omitempty"`
+	BreakerCooldownSeconds int `yaml:"breaker_cooldown_seconds,omitempty" json:"breaker_cooldown_seconds,omitempty"`
 }
 
 // FileFeatureConfig represents feature flags in the config file.
@@ -87,6 +111,59 @@ type FileRateLimitConfig struct {
 	Burst   int  `yaml:"burst" json:"burst"`
 }
 
+// FileMaskingConfig represents data masking rules in the config file.
+type FileMaskingConfig struct {
+	// Columns lists "column", "table.column" or "db.table.column" rules (wildcards allowed).
+	Columns []string `yaml:"columns,omitempty" json:"columns,omitempty"`
+}
+
+// FileAdvisorConfig represents advisor settings in the config file.
+type FileAdvisorConfig struct {
+	// RulesFile is a YAML file of config_advisor rules merged with the built-in ones.
+	RulesFile string `yaml:"rules_file,omitempty" json:"rules_file,omitempty"`
+}
+
+// FileEmbeddingConfig represents the embedding provider used by vector_search query_text.
+type FileEmbeddingConfig struct {
+	URL    string `yaml:"url,omitempty" json:"url,omitempty"`
+	APIKey string `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	Model  string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Models maps column rules ("column", "table.column", "db.table.column") to a model.
+	Models         map[string]string `yaml:"models,omitempty" json:"models,omitempty"`
+	CacheSize      int               `yaml:"cache_size,omitempty" json:"cache_size,omitempty"`
+	TimeoutSeconds int               `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`
+}
+
+// FileCacheConfig represents the tool result cache in the config file.
+type FileCacheConfig struct {
+	Enabled    bool `yaml:"enabled" json:"enabled"`
+	TTLSeconds int  `yaml:"ttl_seconds,omitempty" json:"ttl_seconds,omitempty"`
+	MaxEntries int  `yaml:"max_entries,omitempty" json:"max_entries,omitempty"`
+	MaxSizeMB  int  `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty"`
+}
+
+// FileSecretsConfig represents the secret providers in the config file.
+type FileSecretsConfig struct {
+	File  FileSecretsFileConfig `yaml:"file,omitempty" json:"file,omitempty"`
+	Vault FileVaultConfig       `yaml:"vault,omitempty" json:"vault,omitempty"`
+}
+
+// FileSecretsFileConfig represents the local encrypted secrets file.
+type FileSecretsFileConfig struct {
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// KeyFile holds the key when MYSQL_MCP_SECRETS_KEY is not set.
+	KeyFile string `yaml:"key_file,omitempty" json:"key_file,omitempty"`
+}
+
+// FileVaultConfig represents a Vault-compatible secret server.
+type FileVaultConfig struct {
+	Address string `yaml:"address,omitempty" json:"address,omitempty"`
+	// TokenFile holds the token when VAULT_TOKEN is not set.
+	TokenFile      string `yaml:"token_file,omitempty" json:"token_file,omitempty"`
+	Namespace      string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	TimeoutSeconds int    `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`
+}
+
 // ConfigFilePath holds the path to the config file (set by command line flag).
 var ConfigFilePath string
 
@@ -196,9 +273,12 @@ func ValidateConfigFile(path string) error {
 	}
 
 	for name, conn := range cfg.Connections {
-		if conn.DSN == "" {
+		if conn.DSN == "" && !conn.structured() {
 			return fmt.Errorf("connection '%s' has empty DSN", name)
 		}
+		if err := conn.check(conn.DSN); err != nil {
+			return fmt.Errorf("connection '%s': %w", name, err)
+		}
 	}
 
 	return nil
@@ -221,6 +301,16 @@ func (fc *FileConfig) ToConfig() *Config {
 		RateLimitRPS:       float64(DefaultRateLimitRPS),
 		RateLimitBurst:     DefaultRateLimitBurst,
 		TokenModel:         "cl100k_base",
+		EmbeddingCacheSize: DefaultEmbeddingCacheSize,
+		EmbeddingTimeout:   time.Duration(DefaultEmbeddingTimeoutS) * time.Second,
+		CacheTTL:           time.Duration(DefaultCacheTTLSecs) * time.Second,
+		CacheMaxEntries:    DefaultCacheMaxEntries,
+		CacheMaxSizeMB:     DefaultCacheMaxSizeMB,
+
+		ConfigReloadInterval: time.Duration(DefaultConfigReloadSecs) * time.Second,
+		VaultTimeout:         time.Duration(DefaultVaultTimeoutSecs) * time.Second,
+		HealthCheckInterval:  time.Duration(DefaultHealthCheckSecs) * time.Second,
+		BreakerCooldown:      time.Duration(DefaultBreakerCooldownSecs) * time.Second,
 	}
 
 	// Apply file config values (if set)
@@ -246,6 +336,15 @@ func (fc *FileConfig) ToConfig() *Config {
 	if fc.Pool.PingTimeoutSeconds > 0 {
 		cfg.PingTimeout = secondsToDuration(fc.Pool.PingTimeoutSeconds)
 	}
+	if fc.Pool.HealthCheckSeconds > 0 {
+		cfg.HealthCheckInterval = secondsToDuration(fc.Pool.HealthCheckSeconds)
+	}
+	if fc.Pool.BreakerCooldownSeconds > 0 {
+		cfg.BreakerCooldown = secondsToDuration(fc.Pool.BreakerCooldownSeconds)
+	}
+
+	cfg.LintQueries = fc.Query.Lint
+	cfg.DisableRawWhere = fc.Query.DisableRawWhere
 
 	cfg.ExtendedMode = fc.Features.ExtendedTools
 	cfg.VectorMode = fc.Features.VectorTools
@@ -273,6 +372,40 @@ func (fc *FileConfig) ToConfig() *Config {
 		cfg.RateLimitBurst = fc.HTTP.RateLimit.Burst
 	}
 
+	cfg.MaskColumns = fc.Masking.Columns
+	cfg.AdvisorRulesFile = strings.TrimSpace(fc.Advisor.RulesFile)
+
+	cfg.EmbeddingURL = strings.TrimSpace(fc.Embedding.URL)
+	cfg.EmbeddingAPIKey = strings.TrimSpace(fc.Embedding.APIKey)
+	cfg.EmbeddingModel = strings.TrimSpace(fc.Embedding.Model)
+	cfg.EmbeddingModels = fc.Embedding.Models
+	if fc.Embedding.CacheSize > 0 {
+		cfg.EmbeddingCacheSize = fc.Embedding.CacheSize
+	}
+	if fc.Embedding.TimeoutSeconds > 0 {
+		cfg.EmbeddingTimeout = secondsToDuration(fc.Embedding.TimeoutSeconds)
+	}
+
+	cfg.CacheEnabled = fc.Cache.Enabled
+	if fc.Cache.TTLSeconds > 0 {
+		cfg.CacheTTL = secondsToDuration(fc.Cache.TTLSeconds)
+	}
+	if fc.Cache.MaxEntries > 0 {
+		cfg.CacheMaxEntries = fc.Cache.MaxEntries
+	}
+	if fc.Cache.MaxSizeMB > 0 {
+		cfg.CacheMaxSizeMB = fc.Cache.MaxSizeMB
+	}
+
+	cfg.SecretsFile = strings.TrimSpace(fc.Secrets.File.Path)
+	cfg.SecretsKeyFile = strings.TrimSpace(fc.Secrets.File.KeyFile)
+	cfg.VaultAddress = strings.TrimSpace(fc.Secrets.Vault.Address)
+	cfg.VaultTokenFile = strings.TrimSpace(fc.Secrets.Vault.TokenFile)
+	cfg.VaultNamespace = strings.TrimSpace(fc.Secrets.Vault.Namespace)
+	if fc.Secrets.Vault.TimeoutSeconds > 0 {
+		cfg.VaultTimeout = secondsToDuration(fc.Secrets.Vault.TimeoutSeconds)
+	}
+
 	// Convert connections - sort keys for deterministic ordering
 	// "default" connection is placed first if it exists, then alphabetically
 	names := make([]string, 0, len(fc.Connections))
@@ -292,11 +425,14 @@ func (fc *FileConfig) ToConfig() *Config {
 	for _, name := range names {
 		conn := fc.Connections[name]
 		cfg.Connections = append(cfg.Connections, ConnectionConfig{
-			Name:        name,
-			DSN:         conn.DSN,
-			Description: conn.Description,
-			ReadOnly:    conn.ReadOnly,
-			SSL:         conn.SSL,
+			Name:         name,
+			DSN:          conn.DSN,
+			Description:  conn.Description,
+			ReadOnly:     conn.ReadOnly,
+			SSL:          conn.SSL,
+			CacheQueries: conn.CacheQueries,
+
+			ConnectionParams: conn.ConnectionParams,
 		})
 	}
 
@@ -308,8 +444,10 @@ func PrintConfig(cfg *Config) string {
 	fc := &FileConfig{
 		Connections: make(map[string]FileConnectionConfig),
 		Query: FileQueryConfig{
-			MaxRows:        cfg.MaxRows,
-			TimeoutSeconds: int(cfg.QueryTimeout.Seconds()),
+			MaxRows:         cfg.MaxRows,
+			TimeoutSeconds:  int(cfg.QueryTimeout.Seconds()),
+			Lint:            cfg.LintQueries,
+			DisableRawWhere: cfg.DisableRawWhere,
 		},
 		Pool: FilePoolConfig{
 			MaxOpenConns:           cfg.MaxOpenConns,
@@ -317,6 +455,8 @@ func PrintConfig(cfg *Config) string {
 			ConnMaxLifetimeMinutes: int(cfg.ConnMaxLifetime.Minutes()),
 			ConnMaxIdleTimeMinutes: int(cfg.ConnMaxIdleTime.Minutes()),
 			PingTimeoutSeconds:     int(cfg.PingTimeout.Seconds()),
+			HealthCheckSeconds:     int(cfg.HealthCheckInterval.Seconds()),
+			BreakerCooldownSeconds: int(cfg.BreakerCooldown.Seconds()),
 		},
 		Features: FileFeatureConfig{
 			ExtendedTools: cfg.ExtendedMode,
@@ -338,14 +478,51 @@ func PrintConfig(cfg *Config) string {
 				Burst:   cfg.RateLimitBurst,
 			},
 		},
+		Masking: FileMaskingConfig{
+			Columns: cfg.MaskColumns,
+		},
+		Advisor: FileAdvisorConfig{
+			RulesFile: cfg.AdvisorRulesFile,
+		},
+		Embedding: FileEmbeddingConfig{
+			URL:            cfg.EmbeddingURL,
+			Model:          cfg.EmbeddingModel,
+			Models:         cfg.EmbeddingModels,
+			CacheSize:      cfg.EmbeddingCacheSize,
+			TimeoutSeconds: int(cfg.EmbeddingTimeout.Seconds()),
+		},
+		Cache: FileCacheConfig{
+			Enabled:    cfg.CacheEnabled,
+			TTLSeconds: int(cfg.CacheTTL.Seconds()),
+			MaxEntries: cfg.CacheMaxEntries,
+			MaxSizeMB:  cfg.CacheMaxSizeMB,
+		},
+		Secrets: FileSecretsConfig{
+			File: FileSecretsFileConfig{
+				Path:    cfg.SecretsFile,
+				KeyFile: cfg.SecretsKeyFile,
+			},
+			Vault: FileVaultConfig{
+				Address:   cfg.VaultAddress,
+				TokenFile: cfg.VaultTokenFile,
+				Namespace: cfg.VaultNamespace,
+			},
+		},
+	}
+	if cfg.EmbeddingAPIKey != "" {
+		fc.Embedding.APIKey = "***"
+	}
+	if cfg.VaultAddress != "" {
+		fc.Secrets.Vault.TimeoutSeconds = int(cfg.VaultTimeout.Seconds())
 	}
 
 	for _, conn := range cfg.Connections {
 		fc.Connections[conn.Name] = FileConnectionConfig{
-			DSN:         maskDSN(conn.DSN),
-			Description: conn.Description,
-			ReadOnly:    conn.ReadOnly,
-			SSL:         conn.SSL,
+			DSN:          maskDSN(conn.DSN),
+			Description:  conn.Description,
+			ReadOnly:     conn.ReadOnly,
+			SSL:          conn.SSL,
+			CacheQueries: conn.CacheQueries,
 		}
 	}
 
@@ -357,12 +534,15 @@ func PrintConfig(cfg *Config) string {
 func maskDSN(dsn string) string {
 	// Simple masking: replace password with ***
 	// DSN format: user:password@tcp(host:port)/db
-	// Use LastIndex for @ to handle passwords containing @ characters
+	// The user part ends at the last @ before the database, so passwords
+	// may contain @, / or ?
 	// e.g., user:p@ssword@tcp(host:3306)/db should mask to user:***@tcp(host:3306)/db
-	if idx := strings.Index(dsn, ":"); idx > 0 {
-		if atIdx := strings.LastIndex(dsn, "@"); atIdx > idx {
-			return dsn[:idx+1] + "***" + dsn[atIdx:]
-		}
+	atIdx := dsnUserInfoEnd(dsn)
+	if atIdx == -1 {
+		atIdx = strings.LastIndex(dsn, "@")
+	}
+	if idx := strings.Index(dsn, ":"); idx > 0 && atIdx > idx {
+		return dsn[:idx+1] + "***" + dsn[atIdx:]
 	}
 	return dsn
 }
@@ -387,13 +567,12 @@ func ApplySSLToDSN(dsn, ssl string) string {
 		return dsn
 	}
 
-	// Check for existing tls= parameter only in the query string (after ?)
-	// to avoid false positives from passwords containing "tls="
-	if idx := strings.Index(dsn, "?"); idx != -1 {
-		queryString := dsn[idx:]
-		if strings.Contains(queryString, "tls=") {
-			return dsn
-		}
+	// Check for existing tls= parameter only in the query string (after
+	// the ? following the database) to avoid false positives from passwords
+	// containing "?" or "tls="
+	params := dsnParamsStart(dsn)
+	if params != -1 && strings.Contains(dsn[params:], "tls=") {
+		return dsn
 	}
 
 	// Determine the tls parameter value
@@ -415,7 +594,7 @@ func ApplySSLToDSN(dsn, ssl string) string {
 
 	// Append tls parameter to DSN
 	// DSN format: user:pass@tcp(host:port)/db?param=value
-	if strings.Contains(dsn, "?") {
+	if params != -1 {
 		return dsn + "&tls=" + tlsValue
 	}
 	return dsn + "?tls=" + tlsValue
@@ -166,14 +166,17 @@ func TestFileConfigToConfig(t *testing.T) {
 	fc := &FileConfig{
 		Connections: map[string]FileConnectionConfig{
 			"default": {
-				DSN:         "user:pass@tcp(localhost:3306)/db",
-				Description: "Test",
-				ReadOnly:    true,
+				DSN:          "user:pass@tcp(localhost:3306)/db",
+				Description:  "Test",
+				ReadOnly:     true,
+				CacheQueries: true,
 			},
 		},
 		Query: FileQueryConfig{
-			MaxRows:        300,
-			TimeoutSeconds: 45,
+			MaxRows:         300,
+			TimeoutSeconds:  45,
+			Lint:            true,
+			DisableRawWhere: true,
 		},
 		Pool: FilePoolConfig{
 			MaxOpenConns:           15,
@@ -181,6 +184,7 @@ func TestFileConfigToConfig(t *testing.T) {
 			ConnMaxLifetimeMinutes: 45,
 			ConnMaxIdleTimeMinutes: 10,
 			PingTimeoutSeconds:     7,
+			HealthCheckSeconds:     15,
 		},
 		Features: FileFeatureConfig{
 			ExtendedTools: true,
@@ -202,6 +206,22 @@ func TestFileConfigToConfig(t *testing.T) {
 				Burst:   150,
 			},
 		},
+		Masking: FileMaskingConfig{
+			Columns: []string{"*.users.email"},
+		},
+		Advisor: FileAdvisorConfig{
+			RulesFile: "/etc/mysql-mcp/rules.yaml",
+		},
+		Embedding: FileEmbeddingConfig{
+			URL:       "http://localhost:11434/v1",
+			Model:     "nomic-embed-text",
+			Models:    map[string]string{"docs.body": "mxbai-embed-large"},
+			CacheSize: 20,
+		},
+		Cache: FileCacheConfig{
+			Enabled:    true,
+			TTLSeconds: 120,
+		},
 	}
 
 	cfg := fc.ToConfig()
@@ -224,6 +244,12 @@ func TestFileConfigToConfig(t *testing.T) {
 	if cfg.QueryTimeout != 45*time.Second {
 		t.Errorf("expected QueryTimeout 45s, got %v", cfg.QueryTimeout)
 	}
+	if !cfg.LintQueries {
+		t.Error("expected LintQueries true")
+	}
+	if !cfg.DisableRawWhere {
+		t.Error("expected DisableRawWhere true")
+	}
 
 	// Verify pool settings
 	if cfg.MaxOpenConns != 15 {
@@ -238,6 +264,12 @@ func TestFileConfigToConfig(t *testing.T) {
 	if cfg.PingTimeout != 7*time.Second {
 		t.Errorf("expected PingTimeout 7s, got %v", cfg.PingTimeout)
 	}
+	if cfg.HealthCheckInterval != 15*time.Second {
+		t.Errorf("expected HealthCheckInterval 15s, got %v", cfg.HealthCheckInterval)
+	}
+	if cfg.BreakerCooldown != time.Duration(DefaultBreakerCooldownSecs)*time.Second {
+		t.Errorf("expected BreakerCooldown %ds, got %v", DefaultBreakerCooldownSecs, cfg.BreakerCooldown)
+	}
 
 	// Verify features
 	if !cfg.ExtendedMode {
@@ -274,6 +306,33 @@ func TestFileConfigToConfig(t *testing.T) {
 	if cfg.RateLimitRPS != 75 {
 		t.Errorf("expected RateLimitRPS 75, got %f", cfg.RateLimitRPS)
 	}
+
+	// Verify masking
+	if len(cfg.MaskColumns) != 1 || cfg.MaskColumns[0] != "*.users.email" {
+		t.Errorf("unexpected MaskColumns: %v", cfg.MaskColumns)
+	}
+
+	// Verify advisor
+	if cfg.AdvisorRulesFile != "/etc/mysql-mcp/rules.yaml" {
+		t.Errorf("unexpected AdvisorRulesFile: %s", cfg.AdvisorRulesFile)
+	}
+
+	// Verify embedding
+	if cfg.EmbeddingURL != "http://localhost:11434/v1" || cfg.EmbeddingModel != "nomic-embed-text" ||
+		cfg.EmbeddingModels["docs.body"] != "mxbai-embed-large" || cfg.EmbeddingCacheSize != 20 {
+		t.Errorf("unexpected embedding settings: %+v", cfg)
+	}
+	if cfg.EmbeddingTimeout != time.Duration(DefaultEmbeddingTimeoutS)*time.Second {
+		t.Errorf("expected default EmbeddingTimeout, got %v", cfg.EmbeddingTimeout)
+	}
+
+	// Verify cache
+	if !cfg.CacheEnabled || cfg.CacheTTL != 2*time.Minute || cfg.CacheMaxEntries != DefaultCacheMaxEntries || cfg.CacheMaxSizeMB != DefaultCacheMaxSizeMB {
+		t.Errorf("unexpected cache settings: %v %v %d %d", cfg.CacheEnabled, cfg.CacheTTL, cfg.CacheMaxEntries, cfg.CacheMaxSizeMB)
+	}
+	if !cfg.Connections[0].CacheQueries {
+		t.Error("expected cache_queries to be mapped")
+	}
 }
 
 // TestMinimalConfigDefaults verifies that a minimal config file (connections only)
@@ -451,6 +510,9 @@ func TestMaskDSN(t *testing.T) {
 		{"user:p@ssword@tcp(localhost:3306)/db", "user:***@tcp(localhost:3306)/db"},
 		{"user:p@ss@word@tcp(host:3306)/db", "user:***@tcp(host:3306)/db"},
 		{"root:@dm1n@123@tcp(127.0.0.1:3306)/mysql", "root:***@tcp(127.0.0.1:3306)/mysql"},
+		// Passwords containing / or ?, and params containing @
+		{"user:a/b?c@tcp(host:3306)/db", "user:***@tcp(host:3306)/db"},
+		{"user:pass@tcp(host:3306)/db?init=a@b", "user:***@tcp(host:3306)/db?init=a@b"},
 	}
 
 	for _, tt := range tests {
@@ -482,10 +544,16 @@ func TestPrintConfig(t *testing.T) {
 		RateLimitEnabled:   false,
 		RateLimitRPS:       100,
 		RateLimitBurst:     200,
+		EmbeddingURL:       "https://api.openai.com/v1",
+		EmbeddingAPIKey:    "sk-secret",
 	}
 
 	output := PrintConfig(cfg)
 
+	if contains(output, "sk-secret") || !contains(output, `api_key: '***'`) {
+		t.Error("expected the embedding API key to be masked")
+	}
+
 	// Check that password is masked
 	if !contains(output, "user:***@tcp(localhost:3306)/db") {
 		t.Error("expected DSN password to be masked")
@@ -686,6 +754,19 @@ func TestApplySSLToDSN(t *testing.T) {
 			ssl:      "unknown",
 			expected: "user:pass@tcp(localhost:3306)/db?tls=true",
 		},
+		// Passwords containing ? or tls= are not taken for parameters
+		{
+			name:     "password with question mark",
+			dsn:      "user:pa?ss@tcp(localhost:3306)/db",
+			ssl:      "true",
+			expected: "user:pa?ss@tcp(localhost:3306)/db?tls=true",
+		},
+		{
+			name:     "password with tls= and params",
+			dsn:      "user:x?tls=false@tcp(localhost:3306)/db?parseTime=true",
+			ssl:      "true",
+			expected: "user:x?tls=false@tcp(localhost:3306)/db?parseTime=true&tls=true",
+		},
 		// Whitespace handling
 		{
 			name:     "ssl with whitespace",
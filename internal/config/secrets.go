@@ -0,0 +1,313 @@
+// internal/config/secrets.go
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askdba/mysql-mcp-server/internal/secrets"
+)
+
+// ConnectionParams describe a connection field by field, as an alternative
+// to writing out a DSN. The password can be read from a file, an environment
+// variable or a secret provider. A DSN may be combined with a password
+// source only. Load resolves the params into the connection's DSN and
+// clears them.
+type ConnectionParams struct {
+	Host     string            `yaml:"host,omitempty" json:"host,omitempty"`
+	Port     int               `yaml:"port,omitempty" json:"port,omitempty"`
+	Socket   string            `yaml:"socket,omitempty" json:"socket,omitempty"`
+	User     string            `yaml:"user,omitempty" json:"user,omitempty"`
+	Database string            `yaml:"database,omitempty" json:"database,omitempty"`
+	Params   map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+
+	// Password sources; at most one may be set.
+	Password       string `yaml:"password,omitempty" json:"password,omitempty"`
+	PasswordFile   string `yaml:"password_file,omitempty" json:"password_file,omitempty"`
+	PasswordEnv    string `yaml:"password_env,omitempty" json:"password_env,omitempty"`
+	PasswordSecret string `yaml:"password_secret,omitempty" json:"password_secret,omitempty"` // "provider:reference"
+}
+
+// structured reports whether the params describe the server (as opposed to
+// only supplying a password).
+func (p ConnectionParams) structured() bool {
+	return p.Host != "" || p.Port != 0 || p.Socket != "" || p.User != "" || p.Database != "" || len(p.Params) > 0
+}
+
+// passwordSources returns the names of the password fields that are set.
+func (p ConnectionParams) passwordSources() []string {
+	var set []string
+	for _, f := range []struct{ name, value string }{
+		{"password", p.Password},
+		{"password_file", p.PasswordFile},
+		{"password_env", p.PasswordEnv},
+		{"password_secret", p.PasswordSecret},
+	} {
+		if f.value != "" {
+			set = append(set, f.name)
+		}
+	}
+	return set
+}
+
+// check validates how the DSN and params of a connection are combined.
+func (p ConnectionParams) check(dsn string) error {
+	if dsn != "" && p.structured() {
+		return fmt.Errorf("set either dsn or host/socket/user/database/params, not both")
+	}
+	if dsn == "" {
+		if !p.structured() {
+			return fmt.Errorf("no dsn or host configured")
+		}
+		if p.Host == "" && p.Socket == "" {
+			return fmt.Errorf("host or socket is required")
+		}
+		if p.Host != "" && p.Socket != "" {
+			return fmt.Errorf("set either host or socket, not both")
+		}
+		if p.User == "" {
+			return fmt.Errorf("user is required")
+		}
+	}
+	if sources := p.passwordSources(); len(sources) > 1 {
+		return fmt.Errorf("only one of %s may be set", strings.Join(sources, ", "))
+	}
+	return nil
+}
+
+// reEnvRef matches ${NAME} references; $${NAME} escapes one.
+var reEnvRef = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateEnv replaces ${NAME} with the value of the environment
+// variable NAME. Unset variables are an error; $${NAME} yields a literal ${NAME}.
+func interpolateEnv(s string) (string, error) {
+	var missing []string
+	out := reEnvRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		name := ref[2 : len(ref)-1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// resolveConnections turns the params of every connection into its DSN.
+// The secret providers are set up only if a connection refers to one.
+func resolveConnections(cfg *Config) error {
+	var resolver *secrets.Resolver
+	for i := range cfg.Connections {
+		conn := &cfg.Connections[i]
+		if conn.PasswordSecret != "" && resolver == nil {
+			var err error
+			if resolver, err = newSecretResolver(cfg); err != nil {
+				return err
+			}
+		}
+		dsn, err := resolveDSN(conn.DSN, conn.ConnectionParams, resolver, cfg.VaultTimeout)
+		if err != nil {
+			return fmt.Errorf("connection %q: %w", conn.Name, err)
+		}
+		conn.DSN = dsn
+		conn.ConnectionParams = ConnectionParams{}
+	}
+	return nil
+}
+
+// resolveDSN interpolates environment variables into the DSN and params,
+// looks up the password and returns the resulting DSN.
+func resolveDSN(dsn string, p ConnectionParams, resolver *secrets.Resolver, timeout time.Duration) (string, error) {
+	if err := p.check(dsn); err != nil {
+		return "", err
+	}
+
+	var err error
+	for _, field := range []*string{&dsn, &p.Host, &p.Socket, &p.User, &p.Database, &p.Password, &p.PasswordFile, &p.PasswordSecret} {
+		if *field, err = interpolateEnv(*field); err != nil {
+			return "", err
+		}
+	}
+	params := make(map[string]string, len(p.Params))
+	for k, v := range p.Params {
+		if params[k], err = interpolateEnv(v); err != nil {
+			return "", err
+		}
+	}
+	p.Params = params
+
+	password, hasPassword, err := lookupPassword(p, resolver, timeout)
+	if err != nil {
+		return "", err
+	}
+	if dsn != "" {
+		if !hasPassword {
+			return dsn, nil
+		}
+		return setDSNPassword(dsn, password)
+	}
+	return buildDSN(p, password, hasPassword), nil
+}
+
+// lookupPassword reads the password from the configured source.
+func lookupPassword(p ConnectionParams, resolver *secrets.Resolver, timeout time.Duration) (string, bool, error) {
+	switch {
+	case p.Password != "":
+		return p.Password, true, nil
+	case p.PasswordFile != "":
+		data, err := os.ReadFile(p.PasswordFile)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read password_file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	case p.PasswordEnv != "":
+		v, ok := os.LookupEnv(p.PasswordEnv)
+		if !ok {
+			return "", false, fmt.Errorf("password_env: environment variable %s is not set", p.PasswordEnv)
+		}
+		return v, true, nil
+	case p.PasswordSecret != "":
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		v, err := resolver.Resolve(ctx, p.PasswordSecret)
+		if err != nil {
+			return "", false, fmt.Errorf("password_secret: %w", err)
+		}
+		return v, true, nil
+	}
+	return "", false, nil
+}
+
+// buildDSN formats params as a go-sql-driver/mysql DSN:
+// user[:password]@tcp(host:port)/database?params, or unix(socket).
+func buildDSN(p ConnectionParams, password string, hasPassword bool) string {
+	var b strings.Builder
+	b.WriteString(p.User)
+	if hasPassword {
+		b.WriteString(":" + password)
+	}
+	if p.Socket != "" {
+		b.WriteString("@unix(" + p.Socket + ")")
+	} else {
+		port := p.Port
+		if port == 0 {
+			port = 3306
+		}
+		b.WriteString("@tcp(" + hostPort(p.Host, port) + ")")
+	}
+	b.WriteString("/" + p.Database)
+
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		b.WriteString(sep + k + "=" + url.QueryEscape(p.Params[k]))
+	}
+	return b.String()
+}
+
+func hostPort(host string, port int) string {
+	if strings.Contains(host, ":") && !strings.HasPrefix(host, "[") {
+		host = "[" + host + "]" // IPv6 literal
+	}
+	return host + ":" + strconv.Itoa(port)
+}
+
+// dsnUserInfoEnd returns the index of the "@" ending the user[:password]
+// part of a DSN, or -1. Like the MySQL driver, it takes the last "@" before
+// the last "/", so passwords may contain "@", "?" or "/".
+func dsnUserInfoEnd(dsn string) int {
+	slash := strings.LastIndex(dsn, "/")
+	if slash == -1 {
+		return -1
+	}
+	return strings.LastIndex(dsn[:slash], "@")
+}
+
+// dsnParamsStart returns the index of the "?" starting the parameters of a
+// DSN, or -1.
+func dsnParamsStart(dsn string) int {
+	slash := strings.LastIndex(dsn, "/")
+	if slash == -1 {
+		return strings.Index(dsn, "?")
+	}
+	if i := strings.Index(dsn[slash:], "?"); i != -1 {
+		return slash + i
+	}
+	return -1
+}
+
+// setDSNPassword replaces the password of a DSN.
+func setDSNPassword(dsn, password string) (string, error) {
+	at := dsnUserInfoEnd(dsn)
+	if at <= 0 {
+		return "", fmt.Errorf("dsn has no user to set the password for")
+	}
+	user := dsn[:at]
+	if i := strings.Index(user, ":"); i != -1 {
+		user = user[:i]
+	}
+	return user + ":" + password + dsn[at:], nil
+}
+
+// newSecretResolver sets up the configured secret providers: an encrypted
+// file (scheme "file") and Vault (scheme "vault").
+func newSecretResolver(cfg *Config) (*secrets.Resolver, error) {
+	r := secrets.NewResolver()
+
+	if cfg.SecretsFile != "" {
+		keyText := os.Getenv("MYSQL_MCP_SECRETS_KEY")
+		if keyText == "" && cfg.SecretsKeyFile != "" {
+			data, err := os.ReadFile(cfg.SecretsKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secrets key file: %w", err)
+			}
+			keyText = string(data)
+		}
+		if keyText == "" {
+			return nil, fmt.Errorf("secrets file %s needs a key: set MYSQL_MCP_SECRETS_KEY or secrets.file.key_file", cfg.SecretsFile)
+		}
+		key, err := secrets.ParseKey(keyText)
+		if err != nil {
+			return nil, err
+		}
+		p, err := secrets.OpenFile(cfg.SecretsFile, key)
+		if err != nil {
+			return nil, err
+		}
+		r.Register("file", p)
+	}
+
+	if cfg.VaultAddress != "" {
+		token := os.Getenv("VAULT_TOKEN")
+		if token == "" && cfg.VaultTokenFile != "" {
+			data, err := os.ReadFile(cfg.VaultTokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read vault token file: %w", err)
+			}
+			token = strings.TrimSpace(string(data))
+		}
+		r.Register("vault", secrets.NewVaultProvider(cfg.VaultAddress, token, cfg.VaultNamespace, cfg.VaultTimeout))
+	}
+
+	return r, nil
+}
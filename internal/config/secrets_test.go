@@ -0,0 +1,246 @@
+package config
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/askdba/mysql-mcp-server/internal/secrets"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_EMPTY", "")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"app@tcp(${DB_HOST}:3306)/shop", "app@tcp(db.internal:3306)/shop"},
+		{"${DB_EMPTY}x", "x"},
+		{"$${DB_HOST}", "${DB_HOST}"},
+		{"pa$$word ${ not a ref", "pa$$word ${ not a ref"},
+	}
+	for _, tt := range tests {
+		if got, err := interpolateEnv(tt.in); err != nil || got != tt.want {
+			t.Errorf("interpolateEnv(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := interpolateEnv("${DB_MISSING_1}/${DB_MISSING_2}"); err == nil || !strings.Contains(err.Error(), "DB_MISSING_1, DB_MISSING_2") {
+		t.Errorf("expected an error naming the unset variables, got %v", err)
+	}
+}
+
+func TestResolveDSN(t *testing.T) {
+	t.Setenv("DB_PASS", "p@ss/w?rd")
+	t.Setenv("DB_HOST", "db.internal")
+	passFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("write password file: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		dsn    string
+		params ConnectionParams
+		want   string
+	}{
+		{
+			name:   "structured with env password",
+			params: ConnectionParams{Host: "${DB_HOST}", User: "app", Database: "shop", PasswordEnv: "DB_PASS", Params: map[string]string{"parseTime": "true", "loc": "Europe/Berlin"}},
+			want:   "app:p@ss/w?rd@tcp(db.internal:3306)/shop?loc=Europe%2FBerlin&parseTime=true",
+		},
+		{
+			name:   "socket with password file",
+			params: ConnectionParams{Socket: "/var/run/mysqld/mysqld.sock", User: "root", PasswordFile: passFile},
+			want:   "root:from-file@unix(/var/run/mysqld/mysqld.sock)/",
+		},
+		{
+			name:   "IPv6 host without password",
+			params: ConnectionParams{Host: "::1", Port: 3307, User: "ro"},
+			want:   "ro@tcp([::1]:3307)/",
+		},
+		{
+			name:   "dsn with interpolated password",
+			dsn:    "app:${DB_PASS}@tcp(db:3306)/shop",
+			params: ConnectionParams{},
+			want:   "app:p@ss/w?rd@tcp(db:3306)/shop",
+		},
+		{
+			name:   "dsn with password source",
+			dsn:    "app:old@tcp(db:3306)/shop?parseTime=true",
+			params: ConnectionParams{Password: "new"},
+			want:   "app:new@tcp(db:3306)/shop?parseTime=true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDSN(tt.dsn, tt.params, nil, 0)
+			if err != nil {
+				t.Fatalf("resolveDSN failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDSN = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Resolved passwords are masked completely and do not confuse ApplySSLToDSN.
+	dsn, _ := resolveDSN("", ConnectionParams{Host: "db", User: "app", PasswordEnv: "DB_PASS"}, nil, 0)
+	if got := maskDSN(dsn); got != "app:***@tcp(db:3306)/" {
+		t.Errorf("maskDSN(%q) = %q", dsn, got)
+	}
+	if got := ApplySSLToDSN(dsn, "true"); got != dsn+"?tls=true" {
+		t.Errorf("ApplySSLToDSN(%q) = %q", dsn, got)
+	}
+
+	errTests := []struct {
+		name   string
+		dsn    string
+		params ConnectionParams
+		want   string
+	}{
+		{"nothing", "", ConnectionParams{}, "no dsn or host"},
+		{"dsn and host", "a@tcp(h)/", ConnectionParams{Host: "h"}, "not both"},
+		{"no user", "", ConnectionParams{Host: "h"}, "user is required"},
+		{"no host", "", ConnectionParams{User: "u"}, "host or socket is required"},
+		{"two passwords", "", ConnectionParams{Host: "h", User: "u", Password: "x", PasswordEnv: "DB_PASS"}, "only one of password, password_env"},
+		{"unset password env", "", ConnectionParams{Host: "h", User: "u", PasswordEnv: "DB_UNSET"}, "DB_UNSET is not set"},
+		{"unset interpolation", "${DB_UNSET}", ConnectionParams{}, "DB_UNSET is not set"},
+		{"password without user", "tcp(h)/db", ConnectionParams{Password: "x"}, "no user"},
+		{"missing password file", "", ConnectionParams{Host: "h", User: "u", PasswordFile: "/nonexistent/pass"}, "password_file"},
+	}
+	for _, tt := range errTests {
+		if _, err := resolveDSN(tt.dsn, tt.params, nil, 0); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestLoadConnectionSecrets(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+	oldPath := ConfigFilePath
+	defer func() { ConfigFilePath = oldPath }()
+
+	dir := t.TempDir()
+	key := make([]byte, secrets.KeySize)
+	secretsFile := filepath.Join(dir, "secrets.enc")
+	if err := secrets.WriteFile(secretsFile, key, map[string]string{"analytics": "file-secret"}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	keyFile := filepath.Join(dir, "secrets.key")
+	if err := os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/mysql/prod" || r.Header.Get("X-Vault-Token") != "s.test" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"password":"vault-secret"},"metadata":{}}}`))
+	}))
+	defer vault.Close()
+	os.Setenv("VAULT_TOKEN", "s.test")
+	os.Setenv("REPLICA_HOST", "replica.internal")
+
+	configPath := filepath.Join(dir, "config.yaml")
+	content := `
+connections:
+  default:
+    host: primary.internal
+    user: app
+    database: shop
+    password_secret: "vault:secret/data/mysql/prod#password"
+    ssl: "true"
+  analytics:
+    dsn: "report@tcp(analytics:3306)/dw"
+    password_secret: "file:analytics"
+  replica:
+    dsn: "ro:${REPLICA_PASS}@tcp(${REPLICA_HOST}:3306)/shop"
+secrets:
+  file:
+    path: ` + secretsFile + `
+    key_file: ` + keyFile + `
+  vault:
+    address: ` + vault.URL + `
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	ConfigFilePath = configPath
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), `connection "replica"`) {
+		t.Fatalf("expected an error for the unset REPLICA_PASS, got %v", err)
+	}
+	os.Setenv("REPLICA_PASS", "env-secret")
+	defer os.Unsetenv("REPLICA_PASS")
+	defer os.Unsetenv("REPLICA_HOST")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := map[string]string{
+		"default":   "app:vault-secret@tcp(primary.internal:3306)/shop",
+		"analytics": "report:file-secret@tcp(analytics:3306)/dw",
+		"replica":   "ro:env-secret@tcp(replica.internal:3306)/shop",
+	}
+	for _, conn := range cfg.Connections {
+		if conn.DSN != want[conn.Name] {
+			t.Errorf("connection %s: DSN = %q, want %q", conn.Name, conn.DSN, want[conn.Name])
+		}
+		if conn.ConnectionParams.structured() || len(conn.passwordSources()) > 0 {
+			t.Errorf("connection %s: params not cleared: %+v", conn.Name, conn.ConnectionParams)
+		}
+	}
+	if printed := PrintConfig(cfg); strings.Contains(printed, "secret@") {
+		t.Errorf("PrintConfig leaks a password:\n%s", printed)
+	}
+
+	// A wrong key fails the load instead of connecting without a password.
+	os.Setenv("MYSQL_MCP_SECRETS_KEY", base64.StdEncoding.EncodeToString(make([]byte, 16)))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "secrets key") {
+		t.Errorf("expected a key error, got %v", err)
+	}
+}
+
+func TestLoadJSONConnectionsWithPasswordEnv(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+	t.Setenv("PROD_PASS", "s3cret")
+	os.Setenv("MYSQL_CONNECTIONS", `[{"name":"prod","host":"db","user":"app","database":"shop","password_env":"PROD_PASS","ssl":"true"}]`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Connections) != 1 || cfg.Connections[0].DSN != "app:s3cret@tcp(db:3306)/shop" || cfg.Connections[0].SSL != "true" {
+		t.Errorf("unexpected connections: %+v", cfg.Connections)
+	}
+}
+
+func TestValidateConfigFileStructured(t *testing.T) {
+	tests := []struct {
+		content string
+		valid   bool
+	}{
+		{"connections:\n  default:\n    host: db\n    user: app\n    password_env: DB_PASS\n", true},
+		{"connections:\n  default:\n    dsn: \"app@tcp(db:3306)/\"\n    password_file: /run/secrets/db\n", true},
+		{"connections:\n  default:\n    host: db\n", false},
+		{"connections:\n  default:\n    host: db\n    user: app\n    password: x\n    password_env: DB_PASS\n", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if err := ValidateConfigFile(path); (err == nil) != tt.valid {
+			t.Errorf("ValidateConfigFile(%q) = %v, want valid=%v", tt.content, err, tt.valid)
+		}
+	}
+}
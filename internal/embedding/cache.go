@@ -0,0 +1,83 @@
+// internal/embedding/cache.go
+package embedding
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a fixed-size LRU cache of embeddings, safe for concurrent use.
+// A nil *Cache is valid and caches nothing.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	items    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	vec []float64
+}
+
+// NewCache returns a cache holding up to capacity embeddings.
+func NewCache(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the embedding stored under key and marks it recently used.
+func (c *Cache) Get(key string) ([]float64, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).vec, true
+}
+
+// Add stores an embedding, evicting the least recently used one when full.
+func (c *Cache) Add(key string, vec []float64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*cacheEntry).vec = vec
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, vec: vec})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of cached embeddings.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Cap returns the cache capacity.
+func (c *Cache) Cap() int {
+	if c == nil {
+		return 0
+	}
+	return c.capacity
+}
@@ -0,0 +1,129 @@
+// internal/embedding/embedding.go
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Provider turns texts into embedding vectors with the given model.
+type Provider interface {
+	Embed(ctx context.Context, model string, texts []string) ([][]float64, error)
+}
+
+// Embedder resolves the model for a vector column and embeds query texts
+// through a provider, caching recent results.
+type Embedder struct {
+	provider     Provider
+	cache        *Cache
+	defaultModel string
+	rules        []modelRule
+}
+
+// modelRule maps a "column", "table.column" or "database.table.column"
+// pattern (shell-style wildcards allowed) to a model.
+type modelRule struct {
+	parts []string
+	model string
+}
+
+// New builds an Embedder. models maps column rules, in the syntax of the
+// masking rules, to the model used for that column; defaultModel applies to
+// columns no rule matches. cacheSize is the number of embeddings kept (0
+// disables the cache).
+func New(provider Provider, defaultModel string, models map[string]string, cacheSize int) (*Embedder, error) {
+	e := &Embedder{provider: provider, defaultModel: defaultModel}
+	if cacheSize > 0 {
+		e.cache = NewCache(cacheSize)
+	}
+	for rule, model := range models {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		model = strings.TrimSpace(model)
+		parts := strings.Split(rule, ".")
+		if rule == "" || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid embedding model rule %q: use column, table.column or database.table.column", rule)
+		}
+		if model == "" {
+			return nil, fmt.Errorf("embedding model rule %q has no model", rule)
+		}
+		for _, p := range parts {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid embedding model rule %q: %w", rule, err)
+			}
+		}
+		e.rules = append(e.rules, modelRule{parts: parts, model: model})
+	}
+	// The most specific rule wins: more parts first, then fewer wildcards.
+	sort.Slice(e.rules, func(i, j int) bool {
+		a, b := e.rules[i], e.rules[j]
+		if len(a.parts) != len(b.parts) {
+			return len(a.parts) > len(b.parts)
+		}
+		if wa, wb := wildcards(a.parts), wildcards(b.parts); wa != wb {
+			return wa < wb
+		}
+		return strings.Join(a.parts, ".") < strings.Join(b.parts, ".")
+	})
+	return e, nil
+}
+
+func wildcards(parts []string) int {
+	n := 0
+	for _, p := range parts {
+		n += strings.Count(p, "*") + strings.Count(p, "?") + strings.Count(p, "[")
+	}
+	return n
+}
+
+// ModelFor returns the model configured for a vector column, or an error
+// when neither a rule nor a default model applies.
+func (e *Embedder) ModelFor(database, table, column string) (string, error) {
+	target := []string{strings.ToLower(database), strings.ToLower(table), strings.ToLower(column)}
+	for _, r := range e.rules {
+		offset := len(target) - len(r.parts)
+		matched := true
+		for i, pattern := range r.parts {
+			if ok, _ := path.Match(pattern, target[offset+i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.model, nil
+		}
+	}
+	if e.defaultModel == "" {
+		return "", fmt.Errorf("no embedding model configured for %s.%s.%s", database, table, column)
+	}
+	return e.defaultModel, nil
+}
+
+// Embed returns the embedding of text for a vector column, the model used
+// and whether it came from the cache.
+func (e *Embedder) Embed(ctx context.Context, database, table, column, text string) ([]float64, string, bool, error) {
+	model, err := e.ModelFor(database, table, column)
+	if err != nil {
+		return nil, "", false, err
+	}
+	key := model + "\x00" + text
+	if vec, ok := e.cache.Get(key); ok {
+		return vec, model, true, nil
+	}
+	vecs, err := e.provider.Embed(ctx, model, []string{text})
+	if err != nil {
+		return nil, model, false, err
+	}
+	if len(vecs) != 1 || len(vecs[0]) == 0 {
+		return nil, model, false, fmt.Errorf("embedding provider returned %d vectors for 1 input", len(vecs))
+	}
+	e.cache.Add(key, vecs[0])
+	return vecs[0], model, false, nil
+}
+
+// CacheStats returns the number of cached embeddings and the cache capacity.
+func (e *Embedder) CacheStats() (size, capacity int) {
+	return e.cache.Len(), e.cache.Cap()
+}
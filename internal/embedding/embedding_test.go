@@ -0,0 +1,162 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	calls  int
+	models []string
+	err    error
+}
+
+func (s *stubProvider) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	s.calls++
+	s.models = append(s.models, model)
+	if s.err != nil {
+		return nil, s.err
+	}
+	vecs := make([][]float64, len(texts))
+	for i, t := range texts {
+		vecs[i] = []float64{float64(len(t)), 1}
+	}
+	return vecs, nil
+}
+
+func TestModelFor(t *testing.T) {
+	e, err := New(&stubProvider{}, "default-model", map[string]string{
+		"embedding":                 "column-model",
+		"docs.*":                    "docs-model",
+		"docs.body":                 "docs-body-model",
+		"shop.products.description": "products-model",
+	}, 0)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		database, table, column, want string
+	}{
+		{"shop", "products", "description", "products-model"},
+		{"kb", "docs", "body", "docs-body-model"},
+		{"kb", "docs", "title_vec", "docs-model"},
+		{"kb", "notes", "EMBEDDING", "column-model"},
+		{"kb", "notes", "vec", "default-model"},
+	}
+	for _, tt := range tests {
+		if got, err := e.ModelFor(tt.database, tt.table, tt.column); err != nil || got != tt.want {
+			t.Errorf("ModelFor(%s.%s.%s) = %q, %v; want %q", tt.database, tt.table, tt.column, got, err, tt.want)
+		}
+	}
+
+	noDefault, _ := New(&stubProvider{}, "", map[string]string{"docs.body": "m"}, 0)
+	if _, err := noDefault.ModelFor("kb", "notes", "vec"); err == nil {
+		t.Error("expected an error when no model applies")
+	}
+
+	for _, bad := range []map[string]string{{"a.b.c.d": "m"}, {"docs.body": " "}, {"[": "m"}} {
+		if _, err := New(&stubProvider{}, "m", bad, 0); err == nil {
+			t.Errorf("expected an error for rules %v", bad)
+		}
+	}
+}
+
+func TestEmbedCaches(t *testing.T) {
+	p := &stubProvider{}
+	e, _ := New(p, "m1", map[string]string{"docs.body": "m2"}, 2)
+	ctx := context.Background()
+
+	vec, model, cached, err := e.Embed(ctx, "kb", "docs", "body", "hello")
+	if err != nil || model != "m2" || cached || vec[0] != 5 {
+		t.Fatalf("unexpected first embedding: %v %s %v %v", vec, model, cached, err)
+	}
+	if _, _, cached, _ := e.Embed(ctx, "kb", "docs", "body", "hello"); !cached || p.calls != 1 {
+		t.Errorf("expected a cache hit, cached=%v calls=%d", cached, p.calls)
+	}
+	// The same text under another model is a different embedding.
+	if _, model, cached, _ := e.Embed(ctx, "kb", "notes", "vec", "hello"); cached || model != "m1" {
+		t.Errorf("expected a miss for another model, got %s cached=%v", model, cached)
+	}
+	if size, capacity := e.CacheStats(); size != 2 || capacity != 2 {
+		t.Errorf("CacheStats = %d/%d", size, capacity)
+	}
+
+	p.err = errors.New("boom")
+	if _, _, _, err := e.Embed(ctx, "kb", "notes", "vec", "other"); err == nil {
+		t.Error("expected the provider error")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := NewCache(2)
+	c.Add("a", []float64{1})
+	c.Add("b", []float64{2})
+	c.Get("a") // b is now least recently used
+	c.Add("c", []float64{3})
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a to be kept")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len = %d", c.Len())
+	}
+
+	var disabled *Cache
+	disabled.Add("a", []float64{1})
+	if _, ok := disabled.Get("a"); ok || disabled.Len() != 0 {
+		t.Error("a nil cache must cache nothing")
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid api key"}}`))
+			return
+		}
+		var req embeddingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Model != "nomic-embed-text" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"` + req.Model + `\" not found"}`))
+			return
+		}
+		// Answer out of order; the provider must sort by index.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider(srv.URL+"/v1/", "secret", 5*time.Second)
+	vecs, err := p.Embed(context.Background(), "nomic-embed-text", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vecs) != 2 || vecs[0][0] != 0.1 || vecs[1][1] != 0.4 {
+		t.Errorf("unexpected vectors: %v", vecs)
+	}
+
+	if _, err := p.Embed(context.Background(), "missing", []string{"a"}); err == nil || !strings.Contains(err.Error(), `model "missing" not found`) {
+		t.Errorf("expected the Ollama-style error message, got %v", err)
+	}
+	bad := NewOpenAIProvider(srv.URL+"/v1", "wrong", 5*time.Second)
+	if _, err := bad.Embed(context.Background(), "nomic-embed-text", []string{"a"}); err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Errorf("expected the OpenAI-style error message, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+// internal/embedding/openai.go
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxResponseBytes bounds the size of an embeddings response.
+const maxResponseBytes = 32 << 20
+
+// OpenAIProvider calls an OpenAI-compatible embeddings endpoint
+// (POST {BaseURL}/embeddings), as served by OpenAI, Ollama, vLLM, LocalAI
+// and similar.
+type OpenAIProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOpenAIProvider returns a provider for baseURL, e.g.
+// "https://api.openai.com/v1" or "http://localhost:11434/v1". apiKey may be
+// empty for local servers.
+func NewOpenAIProvider(baseURL, apiKey string, timeout time.Duration) *OpenAIProvider {
+	return &OpenAIProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+// Embed implements Provider.
+func (p *OpenAIProvider) Embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	body, err := json.Marshal(embeddingsRequest{Model: model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	var out embeddingsResponse
+	decodeErr := json.Unmarshal(data, &out)
+	if resp.StatusCode != http.StatusOK {
+		msg := errorMessage(out.Error)
+		if decodeErr != nil || msg == "" {
+			msg = strings.TrimSpace(string(data))
+			if len(msg) > 200 {
+				msg = msg[:200] + "..."
+			}
+		}
+		return nil, fmt.Errorf("embedding provider returned %s: %s", resp.Status, msg)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("invalid embedding response: %w", decodeErr)
+	}
+
+	vecs := make([][]float64, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, fmt.Errorf("invalid embedding response: index %d out of range", d.Index)
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	for i, v := range vecs {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("invalid embedding response: no embedding for input %d", i)
+		}
+	}
+	return vecs, nil
+}
+
+// errorMessage extracts the message from an OpenAI-style error object
+// ({"message": "..."}) or a plain string error as returned by Ollama.
+func errorMessage(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var obj struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		return obj.Message
+	}
+	return ""
+}
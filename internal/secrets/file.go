@@ -0,0 +1,141 @@
+// internal/secrets/file.go
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySize is the length of the AES-256 key protecting a secrets file.
+const KeySize = 32
+
+// fileFormatVersion is written to every secrets file.
+const fileFormatVersion = 1
+
+// encryptedFile is the on-disk format: a JSON object of secret names to
+// values, sealed with AES-256-GCM.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// FileProvider serves secrets from a local encrypted file. The file is
+// decrypted once, when it is opened.
+type FileProvider struct {
+	values map[string]string
+}
+
+// ParseKey decodes a key given as base64 (e.g. from `openssl rand -base64 32`)
+// or as 64 hex characters.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 2*KeySize {
+		if key, err := hex.DecodeString(s); err == nil {
+			return key, nil
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		key, err = base64.RawStdEncoding.DecodeString(s)
+	}
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("secrets key must be %d bytes, base64 or hex encoded", KeySize)
+	}
+	return key, nil
+}
+
+// OpenFile decrypts the secrets file at path.
+func OpenFile(path string, key []byte) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	var f encryptedFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	if f.Version != fileFormatVersion {
+		return nil, fmt.Errorf("unsupported secrets file version %d", f.Version)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(f.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: bad nonce", path)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(f.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: bad ciphertext", path)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid secrets file %s: bad nonce", path)
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file %s: wrong key or corrupted file", path)
+	}
+
+	p := &FileProvider{}
+	if err := json.Unmarshal(plaintext, &p.values); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// WriteFile encrypts values with key and writes them to path, readable by
+// the owner only.
+func WriteFile(path string, key []byte, values map[string]string) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    fileFormatVersion,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secrets key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Secret implements Provider; ref is the secret's name.
+func (p *FileProvider) Secret(ctx context.Context, ref string) (string, error) {
+	v, ok := p.values[ref]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
@@ -0,0 +1,51 @@
+// internal/secrets/secrets.go
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by providers for references they have no value for.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider looks up secrets by reference. The reference format is up to
+// the provider, e.g. a name in an encrypted file or "path#key" in Vault.
+type Provider interface {
+	Secret(ctx context.Context, ref string) (string, error)
+}
+
+// Resolver dispatches "scheme:ref" references to the provider registered
+// for scheme.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver without providers.
+func NewResolver() *Resolver {
+	return &Resolver{providers: make(map[string]Provider)}
+}
+
+// Register makes p handle references with the given scheme.
+func (r *Resolver) Register(scheme string, p Provider) {
+	r.providers[scheme] = p
+}
+
+// Resolve returns the secret for a "scheme:ref" reference.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, ":")
+	if !ok || scheme == "" || rest == "" {
+		return "", fmt.Errorf("invalid secret reference %q (expected provider:reference)", ref)
+	}
+	p, ok := r.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("secret provider %q is not configured", scheme)
+	}
+	v, err := p.Secret(ctx, rest)
+	if err != nil {
+		return "", fmt.Errorf("%s secret %q: %w", scheme, rest, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey() []byte {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+func TestParseKey(t *testing.T) {
+	key := testKey()
+	for _, s := range []string{
+		base64.StdEncoding.EncodeToString(key),
+		base64.RawStdEncoding.EncodeToString(key) + "\n",
+		"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+	} {
+		got, err := ParseKey(s)
+		if err != nil || string(got) != string(key) {
+			t.Errorf("ParseKey(%q) = %x, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "short", base64.StdEncoding.EncodeToString([]byte("sixteen byte key"))} {
+		if _, err := ParseKey(s); err == nil {
+			t.Errorf("ParseKey(%q) should fail", s)
+		}
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	values := map[string]string{"prod": "p@ss:w/ord?", "empty": ""}
+	if err := WriteFile(path, testKey(), values); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "p@ss") {
+		t.Fatal("secrets file contains a plain text value")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	p, err := OpenFile(path, testKey())
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if v, err := p.Secret(context.Background(), "prod"); err != nil || v != "p@ss:w/ord?" {
+		t.Errorf("Secret(prod) = %q, %v", v, err)
+	}
+	if _, err := p.Secret(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	wrong := testKey()
+	wrong[0] ^= 1
+	if _, err := OpenFile(path, wrong); err == nil || !strings.Contains(err.Error(), "wrong key") {
+		t.Errorf("expected a decryption error, got %v", err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := WriteFile(path, testKey(), map[string]string{"prod": "secret"}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	p, err := OpenFile(path, testKey())
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	r := NewResolver()
+	r.Register("file", p)
+
+	if v, err := r.Resolve(context.Background(), "file:prod"); err != nil || v != "secret" {
+		t.Errorf("Resolve(file:prod) = %q, %v", v, err)
+	}
+	for ref, want := range map[string]string{
+		"prod":        "invalid secret reference",
+		"vault:a#b":   `provider "vault" is not configured`,
+		"file:other":  "secret not found",
+		"file:":       "invalid secret reference",
+		":prod":       "invalid secret reference",
+		"file:prod:x": "secret not found",
+	} {
+		if _, err := r.Resolve(context.Background(), ref); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve(%q) error = %v, want %q", ref, err, want)
+		}
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if r.Header.Get("X-Vault-Namespace") != "team" {
+			t.Errorf("missing namespace header")
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/mysql/prod": // KV v2
+			w.Write([]byte(`{"data":{"data":{"password":"v2-pass","port":3306},"metadata":{"version":3}}}`))
+		case "/v1/kv/mysql/dev": // KV v1
+			w.Write([]byte(`{"data":{"password":"v1-pass"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	p := NewVaultProvider(server.URL+"/", "s.token", "team", time.Second)
+	tests := []struct {
+		ref, want string
+	}{
+		{"secret/data/mysql/prod#password", "v2-pass"},
+		{"/secret/data/mysql/prod#port", "3306"},
+		{"kv/mysql/dev#password", "v1-pass"},
+	}
+	for _, tt := range tests {
+		if v, err := p.Secret(ctx, tt.ref); err != nil || v != tt.want {
+			t.Errorf("Secret(%q) = %q, %v; want %q", tt.ref, v, err, tt.want)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected one request per path, got %d", requests)
+	}
+
+	for _, ref := range []string{"secret/data/mysql/prod#user", "secret/data/missing#password"} {
+		if _, err := p.Secret(ctx, ref); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Secret(%q): expected ErrNotFound, got %v", ref, err)
+		}
+	}
+	if _, err := p.Secret(ctx, "secret/data/mysql/prod"); err == nil {
+		t.Error("expected an error for a reference without a key")
+	}
+
+	denied := NewVaultProvider(server.URL, "bad", "team", time.Second)
+	if _, err := denied.Secret(ctx, "kv/mysql/dev#password"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected a permission error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// internal/secrets/vault.go
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxVaultResponseBytes bounds the size of a Vault response.
+const maxVaultResponseBytes = 1 << 20
+
+// VaultProvider reads secrets over the HTTP API of HashiCorp Vault or a
+// compatible server (e.g. OpenBao). References are "path#key", where path is
+// the API path below /v1, such as "secret/data/mysql/prod" for a KV v2 mount
+// or "kv/mysql/prod" for KV v1.
+type VaultProvider struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+
+	mu    sync.Mutex
+	paths map[string]map[string]json.RawMessage // secrets read so far, by path
+}
+
+// NewVaultProvider returns a provider for the Vault server at address, e.g.
+// "https://vault.example.com:8200". namespace may be empty.
+func NewVaultProvider(address, token, namespace string, timeout time.Duration) *VaultProvider {
+	return &VaultProvider{
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{Timeout: timeout},
+		paths:     make(map[string]map[string]json.RawMessage),
+	}
+}
+
+type vaultResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []string                   `json:"errors"`
+}
+
+// Secret implements Provider. Each path is read once per provider, so
+// several keys of the same secret cost one request.
+func (p *VaultProvider) Secret(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("vault reference must be path#key")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, ok := p.paths[path]
+	if !ok {
+		var err error
+		if data, err = p.read(ctx, path); err != nil {
+			return "", err
+		}
+		p.paths[path] = data
+	}
+
+	raw, ok := data[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s, nil
+	}
+	return string(raw), nil
+}
+
+// read fetches the key/value pairs stored at path. KV v2 responses nest
+// them under data.data next to data.metadata.
+func (p *VaultProvider) read(ctx context.Context, path string) (map[string]json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Vault-Token", p.token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxVaultResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault response: %w", err)
+	}
+	var out vaultResponse
+	decodeErr := json.Unmarshal(body, &out)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.Join(out.Errors, "; ")
+		if decodeErr != nil || msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return nil, fmt.Errorf("vault returned %s: %s", resp.Status, msg)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", decodeErr)
+	}
+
+	if inner, ok := out.Data["data"]; ok {
+		if _, v2 := out.Data["metadata"]; v2 {
+			var data map[string]json.RawMessage
+			if err := json.Unmarshal(inner, &data); err != nil {
+				return nil, fmt.Errorf("invalid vault response: %w", err)
+			}
+			return data, nil
+		}
+	}
+	return out.Data, nil
+}
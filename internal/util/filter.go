@@ -0,0 +1,224 @@
+// internal/util/filter.go
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Limits on the size of a filter.
+const (
+	MaxFilterDepth      = 8
+	MaxFilterConditions = 100
+	MaxFilterValues     = 1000 // values in one IN list
+)
+
+// Filter is a structured row filter: either a condition on one column, or
+// an "and" / "or" group of nested filters. In JSON:
+//
+//	{"column": "price", "op": "<", "value": 20}
+//	{"or": [{"column": "lang", "op": "in", "values": ["en", "de"]},
+//	        {"column": "lang", "op": "is_null"}]}
+//
+// A JSON array is shorthand for an "and" group.
+type Filter struct {
+	Column string        `json:"column,omitempty"`
+	Op     string        `json:"op,omitempty"`
+	Value  interface{}   `json:"value,omitempty"`
+	Values []interface{} `json:"values,omitempty"`
+	And    []Filter      `json:"and,omitempty"`
+	Or     []Filter      `json:"or,omitempty"`
+}
+
+// filterOps maps the accepted operators to their SQL form.
+var filterOps = map[string]string{
+	"=":           "=",
+	"eq":          "=",
+	"!=":          "<>",
+	"<>":          "<>",
+	"ne":          "<>",
+	"<":           "<",
+	"lt":          "<",
+	"<=":          "<=",
+	"lte":         "<=",
+	">":           ">",
+	"gt":          ">",
+	">=":          ">=",
+	"gte":         ">=",
+	"like":        "LIKE",
+	"not_like":    "NOT LIKE",
+	"in":          "IN",
+	"not_in":      "NOT IN",
+	"between":     "BETWEEN",
+	"not_between": "NOT BETWEEN",
+	"is_null":     "IS NULL",
+	"is_not_null": "IS NOT NULL",
+}
+
+// ParseFilter converts a decoded JSON value (as received in a tool input)
+// into a Filter. It returns nil for a nil or empty value.
+func ParseFilter(v interface{}) (*Filter, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	raw = bytes.TrimSpace(raw)
+
+	var f Filter
+	if len(raw) > 0 && raw[0] == '[' {
+		err = decodeFilter(raw, &f.And)
+	} else {
+		err = decodeFilter(raw, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if f.Column == "" && f.Op == "" && f.Value == nil && len(f.Values) == 0 && len(f.And) == 0 && len(f.Or) == 0 {
+		return nil, nil
+	}
+	return &f, nil
+}
+
+func decodeFilter(raw []byte, dst interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	return dec.Decode(dst)
+}
+
+// Compile returns the filter as a SQL condition with ? placeholders, and
+// its arguments. Columns are quoted with QuoteIdent; values are never
+// inlined.
+func (f *Filter) Compile() (string, []interface{}, error) {
+	c := filterCompiler{}
+	sql, err := c.compile(f, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, c.args, nil
+}
+
+type filterCompiler struct {
+	args       []interface{}
+	conditions int
+}
+
+func (c *filterCompiler) compile(f *Filter, depth int) (string, error) {
+	if depth > MaxFilterDepth {
+		return "", fmt.Errorf("filter is nested deeper than %d levels", MaxFilterDepth)
+	}
+	isGroup := len(f.And) > 0 || len(f.Or) > 0
+	switch {
+	case isGroup && (f.Column != "" || f.Op != ""):
+		return "", fmt.Errorf("a filter is either a condition or an and/or group, not both")
+	case len(f.And) > 0 && len(f.Or) > 0:
+		return "", fmt.Errorf("use separate filters for and and or groups")
+	case len(f.And) > 0:
+		return c.group(f.And, " AND ", depth)
+	case len(f.Or) > 0:
+		return c.group(f.Or, " OR ", depth)
+	}
+	return c.condition(f)
+}
+
+func (c *filterCompiler) group(filters []Filter, sep string, depth int) (string, error) {
+	parts := make([]string, len(filters))
+	for i := range filters {
+		sql, err := c.compile(&filters[i], depth+1)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = sql
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, sep) + ")", nil
+}
+
+func (c *filterCompiler) condition(f *Filter) (string, error) {
+	if c.conditions++; c.conditions > MaxFilterConditions {
+		return "", fmt.Errorf("filter has more than %d conditions", MaxFilterConditions)
+	}
+	if f.Column == "" {
+		return "", fmt.Errorf("filter condition requires a column")
+	}
+	col, err := QuoteIdent(f.Column)
+	if err != nil {
+		return "", fmt.Errorf("invalid filter column: %w", err)
+	}
+	op, ok := filterOps[strings.ToLower(strings.TrimSpace(f.Op))]
+	if !ok {
+		return "", fmt.Errorf("unsupported filter operator %q for column %s", f.Op, f.Column)
+	}
+
+	switch op {
+	case "IS NULL", "IS NOT NULL":
+		if f.Value != nil || len(f.Values) > 0 {
+			return "", fmt.Errorf("%s on column %s takes no value", strings.ToLower(f.Op), f.Column)
+		}
+		return col + " " + op, nil
+
+	case "IN", "NOT IN":
+		if f.Value != nil || len(f.Values) == 0 {
+			return "", fmt.Errorf("%s on column %s requires a non-empty values list", strings.ToLower(f.Op), f.Column)
+		}
+		if len(f.Values) > MaxFilterValues {
+			return "", fmt.Errorf("%s on column %s has more than %d values", strings.ToLower(f.Op), f.Column, MaxFilterValues)
+		}
+		for _, v := range f.Values {
+			if err := c.addArg(v, f.Column); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s %s (%s)", col, op, strings.TrimSuffix(strings.Repeat("?, ", len(f.Values)), ", ")), nil
+
+	case "BETWEEN", "NOT BETWEEN":
+		if f.Value != nil || len(f.Values) != 2 {
+			return "", fmt.Errorf("%s on column %s requires exactly two values", strings.ToLower(f.Op), f.Column)
+		}
+		for _, v := range f.Values {
+			if err := c.addArg(v, f.Column); err != nil {
+				return "", err
+			}
+		}
+		return col + " " + op + " ? AND ?", nil
+	}
+
+	if len(f.Values) > 0 {
+		return "", fmt.Errorf("%s on column %s takes a single value, not values", f.Op, f.Column)
+	}
+	if f.Value == nil {
+		return "", fmt.Errorf("%s on column %s requires a value (use is_null to match NULL)", f.Op, f.Column)
+	}
+	if err := c.addArg(f.Value, f.Column); err != nil {
+		return "", err
+	}
+	return col + " " + op + " ?", nil
+}
+
+// addArg appends a scalar filter value as a query argument.
+func (c *filterCompiler) addArg(v interface{}, column string) error {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			c.args = append(c.args, i)
+		} else if f, err := val.Float64(); err == nil {
+			c.args = append(c.args, f)
+		} else {
+			return fmt.Errorf("invalid number %s for column %s", val, column)
+		}
+	case string, bool, float64, int64, int:
+		c.args = append(c.args, val)
+	case nil:
+		return fmt.Errorf("null value for column %s (use is_null or is_not_null)", column)
+	default:
+		return fmt.Errorf("value for column %s must be a string, number or boolean", column)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decode mimics a tool input: the filter arrives as a generic JSON value.
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestFilterCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "single condition",
+			filter:   `{"column": "price", "op": "<", "value": 20}`,
+			wantSQL:  "`price` < ?",
+			wantArgs: []interface{}{int64(20)},
+		},
+		{
+			name:     "word operator and float",
+			filter:   `{"column": "rating", "op": "GTE", "value": 4.5}`,
+			wantSQL:  "`rating` >= ?",
+			wantArgs: []interface{}{4.5},
+		},
+		{
+			name:     "array is an and group",
+			filter:   `[{"column": "lang", "op": "=", "value": "en"}, {"column": "deleted", "op": "=", "value": false}]`,
+			wantSQL:  "(`lang` = ? AND `deleted` = ?)",
+			wantArgs: []interface{}{"en", false},
+		},
+		{
+			name: "nested groups",
+			filter: `{"and": [
+				{"column": "price", "op": "between", "values": [10, 20]},
+				{"or": [
+					{"column": "lang", "op": "in", "values": ["en", "de"]},
+					{"column": "lang", "op": "is_null"}
+				]}
+			]}`,
+			wantSQL:  "(`price` BETWEEN ? AND ? AND (`lang` IN (?, ?) OR `lang` IS NULL))",
+			wantArgs: []interface{}{int64(10), int64(20), "en", "de"},
+		},
+		{
+			name:     "values that trip the where validator are plain arguments",
+			filter:   `{"column": "title", "op": "not_like", "value": "%UNION SELECT INTO 0xDEADBEEFDEADBEEFDEADBEEFDEADBEEF%"}`,
+			wantSQL:  "`title` NOT LIKE ?",
+			wantArgs: []interface{}{"%UNION SELECT INTO 0xDEADBEEFDEADBEEFDEADBEEFDEADBEEF%"},
+		},
+		{
+			name:     "single-element group needs no parentheses",
+			filter:   `{"or": [{"column": "id", "op": "is_not_null"}]}`,
+			wantSQL:  "`id` IS NOT NULL",
+			wantArgs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFilter(decode(t, tt.filter))
+			if err != nil {
+				t.Fatalf("ParseFilter failed: %v", err)
+			}
+			sql, args, err := f.Compile()
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{`{"column": "a", "op": "~", "value": 1}`, "unsupported filter operator"},
+		{`{"column": "a b", "op": "=", "value": 1}`, "invalid filter column"},
+		{`{"op": "=", "value": 1}`, "requires a column"},
+		{`{"column": "a", "op": "=", "value": null}`, "requires a value"},
+		{`{"column": "a", "op": "=", "values": [1, 2]}`, "single value"},
+		{`{"column": "a", "op": "in", "values": []}`, "non-empty values"},
+		{`{"column": "a", "op": "in", "values": [1, null]}`, "null value"},
+		{`{"column": "a", "op": "in", "values": [[1]]}`, "must be a string, number or boolean"},
+		{`{"column": "a", "op": "between", "values": [1]}`, "exactly two values"},
+		{`{"column": "a", "op": "is_null", "value": 1}`, "takes no value"},
+		{`{"column": "a", "op": "=", "value": 1, "and": [{"column": "b", "op": "is_null"}]}`, "not both"},
+		{`{"and": [{"column": "a", "op": "is_null"}], "or": [{"column": "b", "op": "is_null"}]}`, "separate filters"},
+		{`{"column": "a", "operator": "="}`, "unknown field"},
+		{`"a = 1"`, "invalid filter"},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(decode(t, tt.filter))
+		if err == nil {
+			_, _, err = f.Compile()
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.filter, tt.want, err)
+		}
+	}
+
+	deep := `{"column": "a", "op": "is_null"}`
+	for i := 0; i < MaxFilterDepth; i++ {
+		deep = `{"and": [` + deep + `]}`
+	}
+	f, err := ParseFilter(decode(t, deep))
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	if _, _, err := f.Compile(); err == nil || !strings.Contains(err.Error(), "nested deeper") {
+		t.Errorf("expected a depth error, got %v", err)
+	}
+}
+
+func TestParseFilterEmpty(t *testing.T) {
+	for _, s := range []string{`null`, `{}`, `[]`} {
+		f, err := ParseFilter(decode(t, s))
+		if err != nil || f != nil {
+			t.Errorf("ParseFilter(%s) = %v, %v; want nil, nil", s, f, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// internal/util/grants.go
+package util
+
+import (
+	"strings"
+)
+
+// Grant is one statement from SHOW GRANTS.
+type Grant struct {
+	Privileges  []string // upper case, column lists removed, e.g. "SELECT", "ALL PRIVILEGES"
+	Object      string   // e.g. "*.*", "`shop`.*"; empty for role grants
+	Roles       []string // roles granted by a "GRANT role TO user" statement
+	GrantOption bool     // WITH GRANT OPTION or WITH ADMIN OPTION
+}
+
+// Global reports whether the grant applies to all databases.
+func (g Grant) Global() bool {
+	return g.Object == "*.*"
+}
+
+// Has reports whether the grant includes priv (or ALL PRIVILEGES).
+func (g Grant) Has(priv string) bool {
+	priv = strings.ToUpper(priv)
+	for _, p := range g.Privileges {
+		if p == priv || p == "ALL PRIVILEGES" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGrant parses a statement as printed by SHOW GRANTS, such as
+// "GRANT SELECT, INSERT (`id`) ON `shop`.* TO `app`@`%` WITH GRANT OPTION" or
+// "GRANT `reader`@`%` TO `app`@`%`". It returns false for other statements
+// (e.g. REVOKE lines from partial revokes). Keywords are expected in upper
+// case, as SHOW GRANTS prints them.
+func ParseGrant(stmt string) (Grant, bool) {
+	stmt = strings.TrimSpace(stmt)
+	if !strings.HasPrefix(stmt, "GRANT ") {
+		return Grant{}, false
+	}
+	var g Grant
+	if strings.Contains(stmt, " WITH GRANT OPTION") || strings.Contains(stmt, " WITH ADMIN OPTION") {
+		g.GrantOption = true
+	}
+
+	body := stmt[len("GRANT "):]
+	if on := topLevelIndex(body, " ON "); on >= 0 {
+		for _, p := range splitTopLevel(body[:on]) {
+			// "SELECT (`a`, `b`)" -> "SELECT"
+			if i := strings.IndexByte(p, '('); i >= 0 {
+				p = p[:i]
+			}
+			p = strings.ToUpper(strings.Join(strings.Fields(p), " "))
+			if p == "ALL" {
+				p = "ALL PRIVILEGES"
+			}
+			if p != "" {
+				g.Privileges = append(g.Privileges, p)
+			}
+		}
+		rest := strings.TrimSpace(body[on+len(" ON "):])
+		if to := topLevelIndex(rest, " TO "); to >= 0 {
+			rest = rest[:to]
+		}
+		// "TABLE `a`.`b`", "PROCEDURE `a`.`p`"
+		for _, kind := range []string{"TABLE ", "FUNCTION ", "PROCEDURE "} {
+			rest = strings.TrimPrefix(rest, kind)
+		}
+		g.Object = rest
+		return g, true
+	}
+
+	to := topLevelIndex(body, " TO ")
+	if to < 0 {
+		return Grant{}, false
+	}
+	for _, r := range splitTopLevel(body[:to]) {
+		if r = strings.TrimSpace(r); r != "" {
+			g.Roles = append(g.Roles, r)
+		}
+	}
+	return g, true
+}
+
+// QuoteAccount formats user and host as a quoted account name for
+// statements such as SHOW GRANTS FOR.
+func QuoteAccount(user, host string) string {
+	return quoteString(user) + "@" + quoteString(host)
+}
+
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// topLevelIndex returns the index of sep in s outside quotes and parentheses.
+func topLevelIndex(s, sep string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '`' || c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on commas outside quotes and parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	for {
+		i := topLevelIndex(s, ",")
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGrant(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want Grant
+		ok   bool
+	}{
+		{
+			"global",
+			"GRANT SELECT, PROCESS, REPLICATION CLIENT ON *.* TO `mcp`@`localhost`",
+			Grant{Privileges: []string{"SELECT", "PROCESS", "REPLICATION CLIENT"}, Object: "*.*"},
+			true,
+		},
+		{
+			"all with grant option",
+			"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION",
+			Grant{Privileges: []string{"ALL PRIVILEGES"}, Object: "*.*", GrantOption: true},
+			true,
+		},
+		{
+			"column privileges",
+			"GRANT SELECT (`id`, `name`), UPDATE (`name`) ON `shop`.`users` TO 'app'@'%'",
+			Grant{Privileges: []string{"SELECT", "UPDATE"}, Object: "`shop`.`users`"},
+			true,
+		},
+		{
+			"routine",
+			"GRANT EXECUTE ON PROCEDURE `shop`.`refund` TO `app`@`%`",
+			Grant{Privileges: []string{"EXECUTE"}, Object: "`shop`.`refund`"},
+			true,
+		},
+		{
+			"quoted name containing ON",
+			"GRANT SELECT ON `a TO b`.* TO `x`@`%`",
+			Grant{Privileges: []string{"SELECT"}, Object: "`a TO b`.*"},
+			true,
+		},
+		{
+			"roles",
+			"GRANT `reader`@`%`,`writer`@`%` TO `app`@`%` WITH ADMIN OPTION",
+			Grant{Roles: []string{"`reader`@`%`", "`writer`@`%`"}, GrantOption: true},
+			true,
+		},
+		{"partial revoke", "REVOKE INSERT ON `mysql`.* FROM `app`@`%`", Grant{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseGrant(tt.stmt)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGrant(%q) = %+v, %v; want %+v, %v", tt.stmt, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	g, _ := ParseGrant("GRANT ALL PRIVILEGES ON `shop`.* TO `app`@`%`")
+	if !g.Has("delete") || g.Global() {
+		t.Errorf("expected a schema-level grant including DELETE: %+v", g)
+	}
+}
+
+func TestQuoteAccount(t *testing.T) {
+	if got, want := QuoteAccount("o'brien", `%`), `'o''brien'@'%'`; got != want {
+		t.Errorf("QuoteAccount = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+// internal/util/gtid.go
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GTIDInterval is an inclusive range of transaction numbers.
+type GTIDInterval struct {
+	Start, End int64
+}
+
+// GTIDSet maps a source UUID (optionally suffixed with ":tag") to its sorted,
+// non-overlapping transaction intervals.
+type GTIDSet map[string][]GTIDInterval
+
+// ParseGTIDSet parses a MySQL GTID set such as
+// "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:11,4f2b...:1-3".
+// Whitespace and newlines (as printed by SHOW REPLICA STATUS) are ignored.
+func ParseGTIDSet(s string) (GTIDSet, error) {
+	set := GTIDSet{}
+	s = strings.Join(strings.Fields(s), "")
+	if s == "" {
+		return set, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Split(part, ":")
+		if len(fields) < 2 || fields[0] == "" {
+			return nil, fmt.Errorf("invalid GTID set element: %q", part)
+		}
+		key := strings.ToLower(fields[0])
+		for _, f := range fields[1:] {
+			if f == "" {
+				return nil, fmt.Errorf("invalid GTID set element: %q", part)
+			}
+			// MySQL 8.3+ tagged GTIDs: uuid:tag:1-5
+			if f[0] < '0' || f[0] > '9' {
+				key = strings.ToLower(fields[0]) + ":" + strings.ToLower(f)
+				continue
+			}
+			iv, err := parseGTIDInterval(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GTID set element %q: %w", part, err)
+			}
+			set[key] = append(set[key], iv)
+		}
+	}
+	for key, ivs := range set {
+		set[key] = mergeGTIDIntervals(ivs)
+	}
+	return set, nil
+}
+
+func parseGTIDInterval(s string) (GTIDInterval, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return GTIDInterval{}, err
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.ParseInt(endStr, 10, 64); err != nil {
+			return GTIDInterval{}, err
+		}
+	}
+	if start < 1 || end < start {
+		return GTIDInterval{}, fmt.Errorf("invalid interval %q", s)
+	}
+	return GTIDInterval{Start: start, End: end}, nil
+}
+
+func mergeGTIDIntervals(ivs []GTIDInterval) []GTIDInterval {
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].Start < ivs[j].Start })
+	var merged []GTIDInterval
+	for _, iv := range ivs {
+		n := len(merged)
+		if n > 0 && iv.Start <= merged[n-1].End+1 {
+			if iv.End > merged[n-1].End {
+				merged[n-1].End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// Subtract returns the transactions in s that are not in other.
+func (s GTIDSet) Subtract(other GTIDSet) GTIDSet {
+	out := GTIDSet{}
+	for key, ivs := range s {
+		remaining := ivs
+		for _, cut := range other[key] {
+			var next []GTIDInterval
+			for _, iv := range remaining {
+				if cut.End < iv.Start || cut.Start > iv.End {
+					next = append(next, iv)
+					continue
+				}
+				if iv.Start < cut.Start {
+					next = append(next, GTIDInterval{Start: iv.Start, End: cut.Start - 1})
+				}
+				if iv.End > cut.End {
+					next = append(next, GTIDInterval{Start: cut.End + 1, End: iv.End})
+				}
+			}
+			remaining = next
+		}
+		if len(remaining) > 0 {
+			out[key] = remaining
+		}
+	}
+	return out
+}
+
+// Gaps returns the transactions missing between the first and last interval
+// of each source, e.g. "uuid:1-5:8-10" has the gap "uuid:6-7".
+func (s GTIDSet) Gaps() GTIDSet {
+	out := GTIDSet{}
+	for key, ivs := range s {
+		for i := 1; i < len(ivs); i++ {
+			out[key] = append(out[key], GTIDInterval{Start: ivs[i-1].End + 1, End: ivs[i].Start - 1})
+		}
+	}
+	return out
+}
+
+// Count returns the number of transactions in the set.
+func (s GTIDSet) Count() int64 {
+	var n int64
+	for _, ivs := range s {
+		for _, iv := range ivs {
+			n += iv.End - iv.Start + 1
+		}
+	}
+	return n
+}
+
+// String formats the set in MySQL notation with sources in sorted order.
+func (s GTIDSet) String() string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		var b strings.Builder
+		b.WriteString(key)
+		for _, iv := range s[key] {
+			if iv.Start == iv.End {
+				fmt.Fprintf(&b, ":%d", iv.Start)
+			} else {
+				fmt.Fprintf(&b, ":%d-%d", iv.Start, iv.End)
+			}
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,56 @@
+package util
+
+import "testing"
+
+const (
+	uuidA = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	uuidB = "4f2b6a10-71ca-11e1-9e33-c80aa9429562"
+)
+
+func TestParseGTIDSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"single", uuidA + ":5", uuidA + ":5", false},
+		{"ranges merged", uuidA + ":1-5:6-8:10", uuidA + ":1-8:10", false},
+		{"multi source with newline", uuidB + ":1-3,\n" + uuidA + ":1-2", uuidA + ":1-2," + uuidB + ":1-3", false},
+		{"uppercase uuid", "3E11FA47-71CA-11E1-9E33-C80AA9429562:1", uuidA + ":1", false},
+		{"tagged", uuidA + ":1-3:etl:1-2", uuidA + ":1-3," + uuidA + ":etl:1-2", false},
+		{"missing interval", uuidA, "", true},
+		{"bad interval", uuidA + ":5-2", "", true},
+		{"zero", uuidA + ":0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseGTIDSet(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGTIDSet(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && set.String() != tt.want {
+				t.Errorf("ParseGTIDSet(%q) = %q, want %q", tt.input, set.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGTIDSetSubtractAndGaps(t *testing.T) {
+	retrieved, _ := ParseGTIDSet(uuidA + ":1-100," + uuidB + ":1-10")
+	executed, _ := ParseGTIDSet(uuidA + ":1-40:50-90," + uuidB + ":1-10")
+
+	pending := retrieved.Subtract(executed)
+	if got, want := pending.String(), uuidA+":41-49:91-100"; got != want {
+		t.Errorf("Subtract() = %q, want %q", got, want)
+	}
+	if pending.Count() != 19 {
+		t.Errorf("Count() = %d, want 19", pending.Count())
+	}
+
+	if got, want := executed.Gaps().String(), uuidA+":41-49"; got != want {
+		t.Errorf("Gaps() = %q, want %q", got, want)
+	}
+}
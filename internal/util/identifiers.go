@@ -25,8 +25,13 @@ func QuoteIdent(name string) (string, error) {
 
 // MaskDSN hides password in DSN for display.
 // DSN format: user:password@tcp(host:port)/database
+// As in the MySQL driver, the password ends at the last @ before the last /,
+// so passwords containing @, / or ? are masked completely.
 func MaskDSN(dsn string) string {
-	atIdx := strings.Index(dsn, "@")
+	atIdx := strings.LastIndex(dsn, "@")
+	if slash := strings.LastIndex(dsn, "/"); slash != -1 {
+		atIdx = strings.LastIndex(dsn[:slash], "@")
+	}
 	if atIdx == -1 {
 		return dsn
 	}
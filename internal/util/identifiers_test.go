@@ -71,6 +71,11 @@ func TestMaskDSN(t *testing.T) {
 			"user:p:ss:word@tcp(localhost:3306)/db",
 			"user:****@tcp(localhost:3306)/db",
 		},
+		{
+			"special chars in password (@, / and ?)",
+			"user:p@ss/w?rd@tcp(localhost:3306)/db?tls=true",
+			"user:****@tcp(localhost:3306)/db?tls=true",
+		},
 	}
 
 	for _, tt := range tests {
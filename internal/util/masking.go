@@ -0,0 +1,71 @@
+// internal/util/masking.go
+package util
+
+import (
+	"path"
+	"strings"
+)
+
+// MaskedValue replaces the value of any column matched by a masking rule.
+const MaskedValue = "****"
+
+// ColumnMasker decides which columns must have their values hidden in tool output.
+//
+// Rules take the form "column", "table.column" or "database.table.column" and
+// may use shell-style wildcards (see path.Match), e.g. "*.users.email" or
+// "*password*". Matching is case-insensitive, like MySQL identifiers.
+type ColumnMasker struct {
+	rules [][]string
+}
+
+// NewColumnMasker builds a masker from a list of rules. Blank rules are ignored.
+func NewColumnMasker(rules []string) *ColumnMasker {
+	m := &ColumnMasker{}
+	for _, r := range rules {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" {
+			continue
+		}
+		parts := strings.Split(r, ".")
+		if len(parts) > 3 {
+			continue
+		}
+		m.rules = append(m.rules, parts)
+	}
+	return m
+}
+
+// Enabled reports whether any masking rules are configured.
+func (m *ColumnMasker) Enabled() bool {
+	return m != nil && len(m.rules) > 0
+}
+
+// ShouldMask reports whether the given column matches a masking rule.
+// Pass an empty database or table when it is unknown (e.g. ad-hoc queries);
+// only rules with a wildcard in that position can then match.
+func (m *ColumnMasker) ShouldMask(database, table, column string) bool {
+	if !m.Enabled() {
+		return false
+	}
+	target := []string{
+		strings.ToLower(database),
+		strings.ToLower(table),
+		strings.ToLower(column),
+	}
+	for _, rule := range m.rules {
+		// Align the rule with the rightmost parts of the target.
+		offset := len(target) - len(rule)
+		matched := true
+		for i, pattern := range rule {
+			ok, err := path.Match(pattern, target[offset+i])
+			if err != nil || !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
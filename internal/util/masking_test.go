@@ -0,0 +1,52 @@
+package util
+
+import "testing"
+
+func TestColumnMaskerShouldMask(t *testing.T) {
+	m := NewColumnMasker([]string{
+		"ssn",
+		"users.email",
+		"billing.*.card_number",
+		"*password*",
+		"  ",
+	})
+
+	tests := []struct {
+		name     string
+		database string
+		table    string
+		column   string
+		want     bool
+	}{
+		{"bare column any table", "app", "people", "ssn", true},
+		{"bare column case-insensitive", "app", "people", "SSN", true},
+		{"table.column match", "app", "users", "email", true},
+		{"table.column other table", "app", "orders", "email", false},
+		{"table.column unknown table", "app", "", "email", false},
+		{"db.*.column match", "billing", "cards", "card_number", true},
+		{"db.*.column other db", "app", "cards", "card_number", false},
+		{"wildcard column", "app", "users", "password_hash", true},
+		{"wildcard column unknown table", "", "", "old_password", true},
+		{"no match", "app", "users", "name", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.ShouldMask(tt.database, tt.table, tt.column); got != tt.want {
+				t.Errorf("ShouldMask(%q, %q, %q) = %v, want %v", tt.database, tt.table, tt.column, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnMaskerDisabled(t *testing.T) {
+	var nilMasker *ColumnMasker
+	if nilMasker.Enabled() || nilMasker.ShouldMask("db", "t", "c") {
+		t.Error("nil masker should never mask")
+	}
+
+	empty := NewColumnMasker([]string{"", "a.b.c.d"})
+	if empty.Enabled() {
+		t.Error("masker with only blank/invalid rules should be disabled")
+	}
+}
@@ -0,0 +1,590 @@
+// internal/util/sql_lint.go
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// Lint rule identifiers.
+const (
+	LintSelectStar          = "select_star"
+	LintFunctionOnColumn    = "function_on_column"
+	LintLeadingWildcard     = "leading_wildcard_like"
+	LintImplicitConversion  = "implicit_conversion"
+	LintOrAcrossColumns     = "or_across_columns"
+	LintOrderByWithoutLimit = "order_by_without_limit"
+	LintNotInNullable       = "not_in_nullable_subquery"
+)
+
+// Lint severities.
+const (
+	LintWarning = "warning"
+	LintInfo    = "info"
+)
+
+// WideTableColumns is the column count from which SELECT * is reported as a warning.
+const WideTableColumns = 15
+
+// suggestedLimit is the row count used when suggesting a LIMIT clause.
+const suggestedLimit = 100
+
+// LintFinding is one anti-pattern found in a statement.
+type LintFinding struct {
+	Rule       string // one of the Lint* rule identifiers
+	Severity   string // LintWarning or LintInfo
+	Fragment   string // offending part of the statement
+	Message    string // what is wrong
+	Suggestion string // how to fix it
+	Rewrite    string // rewritten fragment or statement, when one can be derived
+}
+
+// LintColumn describes a table column for the rules that depend on schema.
+type LintColumn struct {
+	Name     string
+	DataType string // lower-case base type, e.g. "varchar"
+	Nullable bool
+	Indexed  bool // leading column of at least one index
+}
+
+// LintTable is the schema of one table.
+type LintTable struct {
+	Columns []LintColumn
+}
+
+// Column returns the named column (case-insensitive), or nil.
+func (t *LintTable) Column(name string) *LintColumn {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// LintLookup returns the schema of a table, or nil when it is unknown.
+// schema is the qualifier used in the statement and may be empty.
+type LintLookup func(schema, table string) *LintTable
+
+// LintQuery parses a statement and reports common anti-patterns. lookup may be
+// nil; rules that depend on column types, nullability or indexes then either
+// assume the worst case or are skipped.
+func LintQuery(sqlText string, lookup LintLookup) ([]LintFinding, error) {
+	stmt, err := sqlparser.Parse(strings.TrimSpace(sqlText))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{lookup: lookup, cache: make(map[tableRef]*LintTable)}
+	switch s := stmt.(type) {
+	case *sqlparser.Select:
+		l.lintSelect(s, stmt, true)
+	case *sqlparser.Union:
+		l.lintUnion(s)
+	case *sqlparser.Update:
+		l.lintPredicates(newTableScope(s.TableExprs), s.Where, nil)
+	case *sqlparser.Delete:
+		l.lintPredicates(newTableScope(s.TableExprs), s.Where, nil)
+	default:
+		return nil, nil
+	}
+
+	// Subqueries are linted with their own FROM scope.
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if sub, ok := node.(*sqlparser.Subquery); ok {
+			if sel, ok := sub.Select.(*sqlparser.Select); ok {
+				l.lintSelect(sel, nil, false)
+			}
+		}
+		return true, nil
+	}, stmt)
+
+	return l.findings, nil
+}
+
+type linter struct {
+	lookup   LintLookup
+	cache    map[tableRef]*LintTable
+	findings []LintFinding
+}
+
+func (l *linter) add(f LintFinding) {
+	for _, existing := range l.findings {
+		if existing.Rule == f.Rule && existing.Fragment == f.Fragment {
+			return
+		}
+	}
+	l.findings = append(l.findings, f)
+}
+
+func (l *linter) table(ref tableRef) *LintTable {
+	if l.lookup == nil {
+		return nil
+	}
+	if t, ok := l.cache[ref]; ok {
+		return t
+	}
+	t := l.lookup(ref.schema, ref.name)
+	l.cache[ref] = t
+	return t
+}
+
+// column resolves a column reference to its schema, or nil when unknown.
+// Unqualified columns in a multi-table scope are matched by name.
+func (l *linter) column(scope *tableScope, c *sqlparser.ColName) *LintColumn {
+	if idx := scope.resolve(c); idx >= 0 {
+		if t := l.table(scope.tables[idx]); t != nil {
+			return t.Column(c.Name.String())
+		}
+		return nil
+	}
+	if !c.Qualifier.IsEmpty() {
+		return nil
+	}
+	var found *LintColumn
+	for _, ref := range scope.tables {
+		if t := l.table(ref); t != nil {
+			if col := t.Column(c.Name.String()); col != nil {
+				if found != nil {
+					return nil // ambiguous
+				}
+				found = col
+			}
+		}
+	}
+	return found
+}
+
+func (l *linter) lintUnion(u *sqlparser.Union) {
+	for _, side := range []sqlparser.SelectStatement{u.Left, u.Right} {
+		switch s := side.(type) {
+		case *sqlparser.Select:
+			l.lintSelect(s, nil, true)
+		case *sqlparser.Union:
+			l.lintUnion(s)
+		case *sqlparser.ParenSelect:
+			if sel, ok := s.Select.(*sqlparser.Select); ok {
+				l.lintSelect(sel, nil, true)
+			}
+		}
+	}
+	if len(u.OrderBy) > 0 && u.Limit == nil {
+		u.Limit = &sqlparser.Limit{Rowcount: sqlparser.NewIntVal([]byte(strconv.Itoa(suggestedLimit)))}
+		rewrite := sqlparser.String(u)
+		u.Limit = nil
+		l.add(orderByWithoutLimit(u.OrderBy, rewrite))
+	}
+}
+
+// lintSelect checks one SELECT. stmt is the whole statement when sel is the
+// top-level SELECT (so rewrites can be rendered in full); top is false for
+// subqueries, where SELECT * and a missing LIMIT are normal.
+func (l *linter) lintSelect(sel *sqlparser.Select, stmt sqlparser.Statement, top bool) {
+	scope := newTableScope(sel.From)
+
+	if top {
+		l.lintSelectStar(sel, scope, stmt)
+		if len(sel.OrderBy) > 0 && sel.Limit == nil {
+			var rewrite string
+			if stmt != nil {
+				sel.Limit = &sqlparser.Limit{Rowcount: sqlparser.NewIntVal([]byte(strconv.Itoa(suggestedLimit)))}
+				rewrite = sqlparser.String(stmt)
+				sel.Limit = nil
+			}
+			l.add(orderByWithoutLimit(sel.OrderBy, rewrite))
+		}
+	}
+
+	var unionRewrite func(left, right sqlparser.Expr) string
+	if stmt != nil && len(sel.OrderBy) == 0 && sel.Limit == nil && len(scope.joinConds) == 0 {
+		unionRewrite = func(left, right sqlparser.Expr) string {
+			a, b := *sel, *sel
+			a.Where = sqlparser.NewWhere(sqlparser.WhereStr, left)
+			b.Where = sqlparser.NewWhere(sqlparser.WhereStr, right)
+			return sqlparser.String(&sqlparser.Union{Type: sqlparser.UnionStr, Left: &a, Right: &b})
+		}
+	}
+	l.lintPredicates(scope, sel.Where, unionRewrite)
+}
+
+func orderByWithoutLimit(orderBy sqlparser.OrderBy, rewrite string) LintFinding {
+	return LintFinding{
+		Rule:       LintOrderByWithoutLimit,
+		Severity:   LintInfo,
+		Fragment:   strings.TrimSpace(sqlparser.String(orderBy)),
+		Message:    "ORDER BY without LIMIT sorts the entire result",
+		Suggestion: "add a LIMIT so the server can stop after the first rows, or drop the ORDER BY if the order does not matter",
+		Rewrite:    rewrite,
+	}
+}
+
+func (l *linter) lintSelectStar(sel *sqlparser.Select, scope *tableScope, stmt sqlparser.Statement) {
+	for i, expr := range sel.SelectExprs {
+		star, ok := expr.(*sqlparser.StarExpr)
+		if !ok {
+			continue
+		}
+
+		// Tables the star expands to.
+		refs := scope.tables
+		var prefix string
+		if !star.TableName.IsEmpty() {
+			refs = nil
+			if idx := scope.resolve(&sqlparser.ColName{Qualifier: star.TableName}); idx >= 0 {
+				refs = scope.tables[idx : idx+1]
+			}
+			prefix = star.TableName.Name.String() + "."
+		}
+
+		var columns []string
+		known := len(refs) > 0
+		for j, ref := range refs {
+			t := l.table(ref)
+			if t == nil {
+				known = false
+				break
+			}
+			for _, col := range t.Columns {
+				if len(refs) > 1 && prefix == "" {
+					columns = append(columns, scope.names[j]+"."+col.Name)
+				} else {
+					columns = append(columns, prefix+col.Name)
+				}
+			}
+		}
+
+		f := LintFinding{
+			Rule:       LintSelectStar,
+			Severity:   LintInfo,
+			Fragment:   sqlparser.String(star),
+			Message:    "SELECT * returns every column, including ones the caller may not need",
+			Suggestion: "list only the columns you need; this also lets covering indexes be used",
+		}
+		if known {
+			if len(columns) < WideTableColumns {
+				continue
+			}
+			f.Severity = LintWarning
+			f.Message = fmt.Sprintf("SELECT * returns all %d columns", len(columns))
+			if stmt != nil {
+				list := make(sqlparser.SelectExprs, len(columns))
+				for j, col := range columns {
+					list[j] = &sqlparser.AliasedExpr{Expr: columnExpr(col)}
+				}
+				original := sel.SelectExprs
+				exprs := append(append(append(sqlparser.SelectExprs{}, original[:i]...), list...), original[i+1:]...)
+				sel.SelectExprs = exprs
+				f.Rewrite = sqlparser.String(stmt)
+				sel.SelectExprs = original
+			}
+		}
+		l.add(f)
+	}
+}
+
+func columnExpr(name string) *sqlparser.ColName {
+	qualifier, col, found := strings.Cut(name, ".")
+	if !found {
+		return &sqlparser.ColName{Name: sqlparser.NewColIdent(name)}
+	}
+	return &sqlparser.ColName{
+		Name:      sqlparser.NewColIdent(col),
+		Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent(qualifier)},
+	}
+}
+
+// lintPredicates checks the WHERE and JOIN ... ON conditions of one scope.
+// unionRewrite, when set, renders the statement split on a top-level OR.
+func (l *linter) lintPredicates(scope *tableScope, where *sqlparser.Where, unionRewrite func(left, right sqlparser.Expr) string) {
+	if where != nil {
+		if or, ok := where.Expr.(*sqlparser.OrExpr); ok && unionRewrite != nil {
+			l.lintOr(scope, or, unionRewrite(or.Left, or.Right))
+			l.lintExpr(scope, or.Left)
+			l.lintExpr(scope, or.Right)
+		} else {
+			l.lintExpr(scope, where.Expr)
+		}
+	}
+	for _, cond := range scope.joinConds {
+		l.lintExpr(scope, cond)
+	}
+}
+
+func (l *linter) lintExpr(scope *tableScope, expr sqlparser.Expr) {
+	switch x := expr.(type) {
+	case *sqlparser.AndExpr:
+		l.lintExpr(scope, x.Left)
+		l.lintExpr(scope, x.Right)
+	case *sqlparser.OrExpr:
+		l.lintOr(scope, x, "")
+		l.lintExpr(scope, x.Left)
+		l.lintExpr(scope, x.Right)
+	case *sqlparser.NotExpr:
+		l.lintExpr(scope, x.Expr)
+	case *sqlparser.ParenExpr:
+		l.lintExpr(scope, x.Expr)
+	case *sqlparser.ComparisonExpr:
+		l.lintComparison(scope, x)
+	case *sqlparser.RangeCond:
+		if isConstantExpr(x.From) && isConstantExpr(x.To) {
+			l.lintWrappedColumn(scope, x, x.Left, nil)
+		}
+	}
+}
+
+func (l *linter) lintComparison(scope *tableScope, x *sqlparser.ComparisonExpr) {
+	if x.Operator == sqlparser.NotInStr {
+		if sub, ok := x.Right.(*sqlparser.Subquery); ok {
+			l.lintNotIn(scope, x, sub)
+			return
+		}
+	}
+
+	side, value := x.Left, x.Right
+	if isConstantExpr(side) {
+		side, value = value, side
+	}
+	if !isConstantExpr(value) {
+		return
+	}
+
+	col, isCol := side.(*sqlparser.ColName)
+	if !isCol {
+		l.lintWrappedColumn(scope, x, side, value)
+		return
+	}
+
+	switch x.Operator {
+	case sqlparser.LikeStr:
+		if v, ok := value.(*sqlparser.SQLVal); ok && v.Type == sqlparser.StrVal && strings.HasPrefix(string(v.Val), "%") {
+			term := strings.Trim(string(v.Val), "%")
+			l.add(LintFinding{
+				Rule:       LintLeadingWildcard,
+				Severity:   LintWarning,
+				Fragment:   sqlparser.String(x),
+				Message:    "LIKE with a leading wildcard cannot use an index and scans every row",
+				Suggestion: "use a FULLTEXT index with MATCH ... AGAINST, or anchor the pattern at the start",
+				Rewrite:    fmt.Sprintf("match (%s) against (%s)", sqlparser.String(col), sqlparser.String(sqlparser.NewStrVal([]byte(term)))),
+			})
+		}
+	case sqlparser.EqualStr, sqlparser.NotEqualStr, sqlparser.NullSafeEqualStr, sqlparser.InStr, sqlparser.NotInStr,
+		sqlparser.LessThanStr, sqlparser.GreaterThanStr, sqlparser.LessEqualStr, sqlparser.GreaterEqualStr:
+		meta := l.column(scope, col)
+		if meta == nil || !isStringType(meta.DataType) || !hasNumericLiteral(value) {
+			return
+		}
+		quoted := &sqlparser.ComparisonExpr{Operator: x.Operator, Left: col, Right: quoteNumericLiterals(value)}
+		l.add(LintFinding{
+			Rule:     LintImplicitConversion,
+			Severity: LintWarning,
+			Fragment: sqlparser.String(x),
+			Message: fmt.Sprintf("%s is %s but is compared with a number, so every row is converted and no index is used",
+				col.Name.String(), meta.DataType),
+			Suggestion: "compare with a string literal of the column's type",
+			Rewrite:    sqlparser.String(quoted),
+		})
+	}
+}
+
+// lintWrappedColumn reports a column hidden inside a function or expression.
+func (l *linter) lintWrappedColumn(scope *tableScope, pred, side, value sqlparser.Expr) {
+	var col *sqlparser.ColName
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if c, ok := node.(*sqlparser.ColName); ok && col == nil {
+			col = c
+		}
+		return col == nil, nil
+	}, side)
+	if col == nil || side == sqlparser.Expr(col) {
+		return
+	}
+	if meta := l.column(scope, col); meta != nil && !meta.Indexed {
+		return // there is no index to lose
+	}
+
+	f := LintFinding{
+		Rule:       LintFunctionOnColumn,
+		Severity:   LintWarning,
+		Fragment:   sqlparser.String(pred),
+		Message:    fmt.Sprintf("%s is wrapped in an expression, which prevents index use", sqlparser.String(col)),
+		Suggestion: "move the computation to the constant side so the column is compared directly",
+	}
+	if cmp, ok := pred.(*sqlparser.ComparisonExpr); ok && cmp.Operator == sqlparser.EqualStr {
+		f.Rewrite = dateFunctionRewrite(side, value)
+	}
+	l.add(f)
+}
+
+// dateFunctionRewrite turns YEAR(col) = N and DATE(col) = 'd' into range predicates.
+func dateFunctionRewrite(side, value sqlparser.Expr) string {
+	fn, ok := side.(*sqlparser.FuncExpr)
+	if !ok || len(fn.Exprs) != 1 {
+		return ""
+	}
+	arg, ok := fn.Exprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return ""
+	}
+	col, ok := arg.Expr.(*sqlparser.ColName)
+	if !ok {
+		return ""
+	}
+	v, ok := value.(*sqlparser.SQLVal)
+	if !ok {
+		return ""
+	}
+	name := sqlparser.String(col)
+	switch strings.ToLower(fn.Name.String()) {
+	case "year":
+		year, err := strconv.Atoi(string(v.Val))
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("%s >= '%d-01-01' and %s < '%d-01-01'", name, year, name, year+1)
+	case "date":
+		if v.Type != sqlparser.StrVal {
+			return ""
+		}
+		lit := sqlparser.String(v)
+		return fmt.Sprintf("%s >= %s and %s < %s + interval 1 day", name, lit, name, lit)
+	}
+	return ""
+}
+
+// lintOr reports an OR whose branches filter different columns, which
+// usually means neither branch can use an index on its own.
+func (l *linter) lintOr(scope *tableScope, or *sqlparser.OrExpr, rewrite string) {
+	left, right := referencedColumns(or.Left), referencedColumns(or.Right)
+	if len(left) == 0 || len(right) == 0 || sameColumnSet(left, right) {
+		return
+	}
+	l.add(LintFinding{
+		Rule:       LintOrAcrossColumns,
+		Severity:   LintWarning,
+		Fragment:   sqlparser.String(or),
+		Message:    "OR across different columns usually prevents index use and leads to a full scan",
+		Suggestion: "split the branches into a UNION so each can use its own index (UNION also removes duplicate rows), or make sure every column is indexed so index merge applies",
+		Rewrite:    rewrite,
+	})
+}
+
+func referencedColumns(expr sqlparser.Expr) map[string]bool {
+	cols := make(map[string]bool)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.ColName:
+			cols[strings.ToLower(sqlparser.String(n))] = true
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}, expr)
+	return cols
+}
+
+func sameColumnSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// lintNotIn reports NOT IN over a subquery whose column may be NULL: a single
+// NULL makes the predicate unknown for every row, so nothing is returned.
+func (l *linter) lintNotIn(scope *tableScope, x *sqlparser.ComparisonExpr, sub *sqlparser.Subquery) {
+	sel, ok := sub.Select.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 {
+		return
+	}
+	aliased, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return
+	}
+	col, ok := aliased.Expr.(*sqlparser.ColName)
+	if !ok {
+		return
+	}
+	inner := newTableScope(sel.From)
+	if meta := l.column(inner, col); meta != nil && !meta.Nullable {
+		return
+	}
+
+	// Qualify both sides so the correlated condition cannot be misread.
+	var outer sqlparser.Expr = x.Left
+	if c, ok := x.Left.(*sqlparser.ColName); ok {
+		outer = qualifyColumn(scope, c)
+	}
+	var cond sqlparser.Expr = &sqlparser.ComparisonExpr{Operator: sqlparser.EqualStr, Left: qualifyColumn(inner, col), Right: outer}
+	if sel.Where != nil {
+		cond = &sqlparser.AndExpr{Left: cond, Right: sel.Where.Expr}
+	}
+	exists := *sel
+	exists.SelectExprs = sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: sqlparser.NewIntVal([]byte("1"))}}
+	exists.Where = sqlparser.NewWhere(sqlparser.WhereStr, cond)
+
+	l.add(LintFinding{
+		Rule:       LintNotInNullable,
+		Severity:   LintWarning,
+		Fragment:   sqlparser.String(x),
+		Message:    fmt.Sprintf("NOT IN returns no rows if the subquery yields a NULL, and %s may be NULL", sqlparser.String(col)),
+		Suggestion: "use NOT EXISTS, which is NULL-safe and can be executed as an anti-join",
+		Rewrite:    sqlparser.String(&sqlparser.NotExpr{Expr: &sqlparser.ExistsExpr{Subquery: &sqlparser.Subquery{Select: &exists}}}),
+	})
+}
+
+// qualifyColumn prefixes an unqualified column with the alias (or name) of
+// the only table in scope.
+func qualifyColumn(scope *tableScope, c *sqlparser.ColName) *sqlparser.ColName {
+	if !c.Qualifier.IsEmpty() || len(scope.tables) != 1 {
+		return c
+	}
+	q := *c
+	q.Qualifier = sqlparser.TableName{Name: sqlparser.NewTableIdent(scope.names[0])}
+	return &q
+}
+
+// isStringType reports whether a numeric comparison against the type
+// converts every row. ENUM and SET are left out: MySQL compares them by
+// their numeric value, so quoting the number would change the result.
+func isStringType(dataType string) bool {
+	switch strings.ToLower(dataType) {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return true
+	}
+	return false
+}
+
+func hasNumericLiteral(expr sqlparser.Expr) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if v, ok := node.(*sqlparser.SQLVal); ok && (v.Type == sqlparser.IntVal || v.Type == sqlparser.FloatVal) {
+			found = true
+		}
+		return !found, nil
+	}, expr)
+	return found
+}
+
+func quoteNumericLiterals(expr sqlparser.Expr) sqlparser.Expr {
+	switch v := expr.(type) {
+	case *sqlparser.SQLVal:
+		if v.Type == sqlparser.IntVal || v.Type == sqlparser.FloatVal {
+			return sqlparser.NewStrVal(v.Val)
+		}
+	case sqlparser.ValTuple:
+		out := make(sqlparser.ValTuple, len(v))
+		for i, e := range v {
+			out[i] = quoteNumericLiterals(e)
+		}
+		return out
+	}
+	return expr
+}
@@ -0,0 +1,153 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func lintTestLookup(schema, table string) *LintTable {
+	switch table {
+	case "users":
+		return &LintTable{Columns: []LintColumn{
+			{Name: "id", DataType: "int", Indexed: true},
+			{Name: "phone", DataType: "varchar", Nullable: true, Indexed: true},
+			{Name: "email", DataType: "varchar", Indexed: true},
+			{Name: "created_at", DataType: "datetime", Indexed: true},
+			{Name: "score", DataType: "int"},
+			{Name: "status", DataType: "enum", Indexed: true},
+		}}
+	case "banned":
+		return &LintTable{Columns: []LintColumn{
+			{Name: "user_id", DataType: "int", Nullable: true},
+			{Name: "account_id", DataType: "int"},
+		}}
+	case "wide":
+		t := &LintTable{}
+		for i := 0; i < WideTableColumns; i++ {
+			t.Columns = append(t.Columns, LintColumn{Name: fmt.Sprintf("c%d", i), DataType: "int"})
+		}
+		return t
+	}
+	return nil
+}
+
+func TestLintQueryRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		rule    string
+		rewrite string
+	}{
+		{
+			name:    "order by without limit",
+			sql:     "SELECT id FROM users ORDER BY id",
+			rule:    LintOrderByWithoutLimit,
+			rewrite: "select id from users order by id asc limit 100",
+		},
+		{
+			name:    "implicit conversion",
+			sql:     "SELECT id FROM users WHERE phone IN (5551234, 5550000)",
+			rule:    LintImplicitConversion,
+			rewrite: "phone in ('5551234', '5550000')",
+		},
+		{
+			name:    "year on indexed column",
+			sql:     "SELECT id FROM users u WHERE YEAR(u.created_at) = 2024",
+			rule:    LintFunctionOnColumn,
+			rewrite: "u.created_at >= '2024-01-01' and u.created_at < '2025-01-01'",
+		},
+		{
+			name:    "date on unknown table",
+			sql:     "SELECT id FROM events WHERE DATE(ts) = '2024-01-01'",
+			rule:    LintFunctionOnColumn,
+			rewrite: "ts >= '2024-01-01' and ts < '2024-01-01' + interval 1 day",
+		},
+		{
+			name:    "leading wildcard",
+			sql:     "SELECT id FROM users WHERE email LIKE '%@example.com'",
+			rule:    LintLeadingWildcard,
+			rewrite: "match (email) against ('@example.com')",
+		},
+		{
+			name:    "or across columns",
+			sql:     "SELECT id FROM users WHERE email = 'a@b.c' OR phone = '555'",
+			rule:    LintOrAcrossColumns,
+			rewrite: "select id from users where email = 'a@b.c' union select id from users where phone = '555'",
+		},
+		{
+			name:    "not in nullable subquery",
+			sql:     "SELECT id FROM users WHERE id NOT IN (SELECT user_id FROM banned b WHERE b.account_id = 1)",
+			rule:    LintNotInNullable,
+			rewrite: "not exists (select 1 from banned as b where b.user_id = users.id and b.account_id = 1)",
+		},
+		{
+			name:    "select star on wide table",
+			sql:     "SELECT * FROM wide",
+			rule:    LintSelectStar,
+			rewrite: "select c0, c1, c2, c3, c4, c5, c6, c7, c8, c9, c10, c11, c12, c13, c14 from wide",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := LintQuery(tt.sql, lintTestLookup)
+			if err != nil {
+				t.Fatalf("LintQuery(%q) error: %v", tt.sql, err)
+			}
+			for _, f := range findings {
+				if f.Rule == tt.rule {
+					if f.Rewrite != tt.rewrite {
+						t.Errorf("rewrite = %q, want %q", f.Rewrite, tt.rewrite)
+					}
+					if f.Message == "" || f.Suggestion == "" {
+						t.Errorf("finding without message or suggestion: %+v", f)
+					}
+					return
+				}
+			}
+			t.Errorf("LintQuery(%q) = %+v, want a %s finding", tt.sql, findings, tt.rule)
+		})
+	}
+}
+
+func TestLintQueryClean(t *testing.T) {
+	clean := []string{
+		"SELECT * FROM users WHERE id = 1",                                     // narrow table
+		"SELECT id FROM users WHERE email = 'a' OR email = 'b'",                // OR on one column
+		"SELECT id FROM users WHERE score + 1 > 10",                            // no index on score
+		"SELECT id FROM users WHERE phone = '555'",                             // matching literal type
+		"SELECT id FROM users WHERE status = 1",                                // ENUM compared by index
+		"SELECT id FROM users WHERE id NOT IN (SELECT account_id FROM banned)", // NOT NULL column
+		"SELECT id FROM users ORDER BY id LIMIT 10",
+		"SELECT id FROM users WHERE EXISTS (SELECT * FROM banned WHERE banned.user_id = users.id)",
+		"SHOW TABLES",
+	}
+	for _, sql := range clean {
+		findings, err := LintQuery(sql, lintTestLookup)
+		if err != nil {
+			t.Fatalf("LintQuery(%q) error: %v", sql, err)
+		}
+		if len(findings) != 0 {
+			t.Errorf("LintQuery(%q) = %+v, want no findings", sql, findings)
+		}
+	}
+}
+
+func TestLintQueryWithoutSchema(t *testing.T) {
+	findings, err := LintQuery("SELECT * FROM users WHERE id NOT IN (SELECT user_id FROM banned)", nil)
+	if err != nil {
+		t.Fatalf("LintQuery error: %v", err)
+	}
+	rules := make(map[string]string)
+	for _, f := range findings {
+		rules[f.Rule] = f.Severity
+	}
+	// Without schema, SELECT * is only informational and the subquery column may be NULL.
+	if rules[LintSelectStar] != LintInfo || rules[LintNotInNullable] != LintWarning || len(rules) != 2 {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+
+	if _, err := LintQuery("SELECT FROM", nil); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -0,0 +1,216 @@
+// internal/util/sql_predicates.go
+package util
+
+import (
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// TableFilter lists the columns of one table that a statement filters on
+// with constant values, split by whether an index can use them for an
+// equality lookup or only for a range scan.
+type TableFilter struct {
+	Schema   string   // qualifier used in the statement, empty if unqualified
+	Table    string   // table name (aliases are resolved)
+	Equality []string // columns compared with =, <=> or IN, in order of appearance
+	Range    []string // columns compared with <, >, BETWEEN or a prefix LIKE
+}
+
+// ExtractTableFilters parses a SELECT, UPDATE or DELETE statement and returns
+// the filter columns of each table in its WHERE and JOIN ... ON clauses.
+// Only AND-ed predicates comparing a column with a constant are considered;
+// unqualified columns are attributed only when the statement reads one table.
+// Statement digests are accepted: "?" placeholders and "(...)" IN lists
+// are normalized before parsing.
+func ExtractTableFilters(sqlText string) ([]TableFilter, error) {
+	sqlText = strings.ReplaceAll(sqlText, "(...)", "(?)")
+	stmt, err := sqlparser.Parse(sqlText)
+	if err != nil {
+		return nil, err
+	}
+
+	var from sqlparser.TableExprs
+	var where *sqlparser.Where
+	switch s := stmt.(type) {
+	case *sqlparser.Select:
+		from, where = s.From, s.Where
+	case *sqlparser.Update:
+		from, where = s.TableExprs, s.Where
+	case *sqlparser.Delete:
+		from, where = s.TableExprs, s.Where
+	default:
+		return nil, nil
+	}
+
+	scope := newTableScope(from)
+	e := &filterExtractor{scope: scope, tables: make([]*TableFilter, len(scope.tables))}
+	for i, t := range scope.tables {
+		e.tables[i] = &TableFilter{Schema: t.schema, Table: t.name}
+	}
+	if where != nil {
+		e.addPredicates(where.Expr)
+	}
+	for _, cond := range scope.joinConds {
+		e.addPredicates(cond)
+	}
+
+	var out []TableFilter
+	for _, f := range e.tables {
+		if len(f.Equality) > 0 || len(f.Range) > 0 {
+			out = append(out, *f)
+		}
+	}
+	return out, nil
+}
+
+// tableRef is a base table read by a statement.
+type tableRef struct {
+	schema, name string
+}
+
+// tableScope holds the base tables of one FROM clause and resolves column
+// qualifiers (aliases or table names) to them.
+type tableScope struct {
+	tables    []tableRef
+	names     []string       // alias, or table name when unaliased; parallel to tables
+	aliases   map[string]int // lower-cased names -> index into tables
+	joinConds []sqlparser.Expr
+}
+
+func newTableScope(from sqlparser.TableExprs) *tableScope {
+	s := &tableScope{aliases: make(map[string]int)}
+	for _, te := range from {
+		s.add(te)
+	}
+	return s
+}
+
+func (s *tableScope) add(te sqlparser.TableExpr) {
+	switch t := te.(type) {
+	case *sqlparser.AliasedTableExpr:
+		name, ok := t.Expr.(sqlparser.TableName)
+		if !ok {
+			return // derived table
+		}
+		s.tables = append(s.tables, tableRef{schema: name.Qualifier.String(), name: name.Name.String()})
+		alias := name.Name.String()
+		if !t.As.IsEmpty() {
+			alias = t.As.String()
+		}
+		s.names = append(s.names, alias)
+		s.aliases[strings.ToLower(alias)] = len(s.tables) - 1
+	case *sqlparser.JoinTableExpr:
+		s.add(t.LeftExpr)
+		s.add(t.RightExpr)
+		if t.Condition.On != nil {
+			s.joinConds = append(s.joinConds, t.Condition.On)
+		}
+	case *sqlparser.ParenTableExpr:
+		for _, inner := range t.Exprs {
+			s.add(inner)
+		}
+	}
+}
+
+// resolve returns the index of the table a column belongs to, or -1 when the
+// qualifier is unknown or an unqualified column could come from several tables.
+func (s *tableScope) resolve(c *sqlparser.ColName) int {
+	if q := c.Qualifier.Name.String(); q != "" {
+		if i, ok := s.aliases[strings.ToLower(q)]; ok {
+			return i
+		}
+		return -1
+	}
+	if len(s.tables) == 1 {
+		return 0
+	}
+	return -1
+}
+
+type filterExtractor struct {
+	scope  *tableScope
+	tables []*TableFilter // parallel to scope.tables
+}
+
+func (e *filterExtractor) addPredicates(expr sqlparser.Expr) {
+	switch x := expr.(type) {
+	case *sqlparser.AndExpr:
+		e.addPredicates(x.Left)
+		e.addPredicates(x.Right)
+	case *sqlparser.ParenExpr:
+		e.addPredicates(x.Expr)
+	case *sqlparser.ComparisonExpr:
+		col, value := x.Left, x.Right
+		if _, ok := col.(*sqlparser.ColName); !ok {
+			col, value = value, col
+		}
+		c, ok := col.(*sqlparser.ColName)
+		if !ok || !isConstantExpr(value) {
+			return
+		}
+		switch x.Operator {
+		case sqlparser.EqualStr, sqlparser.NullSafeEqualStr, sqlparser.InStr:
+			e.addColumn(c, true)
+		case sqlparser.LessThanStr, sqlparser.GreaterThanStr, sqlparser.LessEqualStr, sqlparser.GreaterEqualStr:
+			e.addColumn(c, false)
+		case sqlparser.LikeStr:
+			// A leading wildcard cannot use an index.
+			if v, ok := value.(*sqlparser.SQLVal); ok && v.Type == sqlparser.StrVal && strings.HasPrefix(string(v.Val), "%") {
+				return
+			}
+			e.addColumn(c, false)
+		}
+	case *sqlparser.RangeCond:
+		c, ok := x.Left.(*sqlparser.ColName)
+		if ok && x.Operator == sqlparser.BetweenStr && isConstantExpr(x.From) && isConstantExpr(x.To) {
+			e.addColumn(c, false)
+		}
+	}
+}
+
+func (e *filterExtractor) addColumn(c *sqlparser.ColName, equality bool) {
+	idx := e.scope.resolve(c)
+	if idx < 0 {
+		return
+	}
+
+	f := e.tables[idx]
+	name := c.Name.String()
+	for _, existing := range f.Equality {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	if equality {
+		for i, existing := range f.Range {
+			if strings.EqualFold(existing, name) {
+				f.Range = append(f.Range[:i], f.Range[i+1:]...)
+				break
+			}
+		}
+		f.Equality = append(f.Equality, name)
+		return
+	}
+	for _, existing := range f.Range {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	f.Range = append(f.Range, name)
+}
+
+// isConstantExpr reports whether an expression references no columns and
+// contains no subqueries.
+func isConstantExpr(expr sqlparser.Expr) bool {
+	constant := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.ColName, *sqlparser.Subquery:
+			constant = false
+			return false, nil
+		}
+		return true, nil
+	}, expr)
+	return constant
+}
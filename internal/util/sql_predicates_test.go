@@ -0,0 +1,69 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractTableFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []TableFilter
+	}{
+		{
+			name: "equality then range",
+			sql:  "SELECT * FROM orders WHERE created_at > '2024-01-01' AND customer_id = 5 AND status IN ('a', 'b')",
+			want: []TableFilter{{Table: "orders", Equality: []string{"customer_id", "status"}, Range: []string{"created_at"}}},
+		},
+		{
+			name: "digest text",
+			sql:  "SELECT `id` FROM `shop` . `orders` WHERE `status` IN (...) AND ? = `customer_id`",
+			want: []TableFilter{{Schema: "shop", Table: "orders", Equality: []string{"status", "customer_id"}}},
+		},
+		{
+			name: "join with aliases",
+			sql:  "SELECT o.id FROM orders o JOIN customers c ON c.id = o.customer_id AND c.region = 'eu' WHERE o.total BETWEEN 10 AND 20",
+			want: []TableFilter{
+				{Table: "orders", Range: []string{"total"}},
+				{Table: "customers", Equality: []string{"region"}},
+			},
+		},
+		{
+			name: "ignores non-sargable predicates",
+			sql:  "DELETE FROM logs WHERE message LIKE '%error' OR level = 'warn'",
+			want: nil,
+		},
+		{
+			name: "update with prefix like",
+			sql:  "UPDATE users SET active = 0 WHERE email LIKE 'bob%'",
+			want: []TableFilter{{Table: "users", Range: []string{"email"}}},
+		},
+		{
+			name: "unqualified column with several tables",
+			sql:  "SELECT * FROM a, b WHERE x = 1",
+			want: nil,
+		},
+		{
+			name: "not a filter statement",
+			sql:  "INSERT INTO t (a) VALUES (1)",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractTableFilters(tt.sql)
+			if err != nil {
+				t.Fatalf("ExtractTableFilters(%q) error: %v", tt.sql, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractTableFilters(%q) = %+v, want %+v", tt.sql, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ExtractTableFilters("SELECT FROM WHERE"); err == nil {
+		t.Error("expected a parse error")
+	}
+}